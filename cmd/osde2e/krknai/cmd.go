@@ -10,6 +10,7 @@ import (
 	"github.com/openshift/osde2e/pkg/common/config"
 	"github.com/openshift/osde2e/pkg/common/providers/ocmprovider"
 	"github.com/openshift/osde2e/pkg/krknai"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 )
 
@@ -106,12 +107,30 @@ func run(cmd *cobra.Command, argv []string) {
 		log.Printf("error loading initial state: %v", err)
 		os.Exit(1)
 	}
+	if err := krknai.ApplyProfile(); err != nil {
+		log.Printf("error applying krkn-ai profile: %v", err)
+		os.Exit(1)
+	}
+	krknai.LoadKrknAIConfigFromEnv()
 
 	exitCode := runKrknAI(cmd.Context())
 	os.Exit(exitCode)
 }
 
 func runKrknAI(ctx context.Context) int {
+	defer krknai.RedactLogs()()
+
+	metrics, stopMetrics, err := serveMetrics()
+	if err != nil {
+		log.Printf("Failed to serve krkn-ai metrics: %v", err)
+		return config.Failure
+	}
+	defer stopMetrics()
+
+	if clusters := viper.GetString(config.KrknAI.Clusters); clusters != "" {
+		return runKrknAIMulti(ctx, clusters, metrics)
+	}
+
 	log.Println("==== Starting Krkn-ai orchestration ====")
 	orch, err := krknai.New(ctx)
 	if err != nil {
@@ -119,6 +138,13 @@ func runKrknAI(ctx context.Context) int {
 		return config.Failure
 	}
 
+	if k, ok := orch.(*krknai.KrknAI); ok {
+		k.OnProgress(logProgress)
+		if metrics != nil {
+			k.RecordMetrics(metrics, viper.GetString(config.Cluster.ID))
+		}
+	}
+
 	if err := orch.Provision(ctx); err != nil {
 		log.Printf("Provision failed: %v", err)
 		return config.Failure
@@ -147,3 +173,70 @@ func runKrknAI(ctx context.Context) int {
 	log.Println("==== Finished Krkn-ai orchestration ====")
 	return orch.Result().ExitCode
 }
+
+func runKrknAIMulti(ctx context.Context, clusters string, metrics *krknai.Metrics) int {
+	log.Println("==== Starting multi-cluster Krkn-ai orchestration ====")
+	targets, err := krknai.ParseClusterTargets(clusters)
+	if err != nil {
+		log.Printf("Invalid clusters: %v", err)
+		return config.Failure
+	}
+
+	sharedDir := viper.GetString(config.SharedDir)
+	if sharedDir == "" {
+		if sharedDir, err = os.MkdirTemp("", "krkn-ai-clusters-"); err != nil {
+			log.Printf("Failed to create shared directory: %v", err)
+			return config.Failure
+		}
+	}
+
+	results, err := krknai.RunMulti(ctx, targets, krknai.MultiOptions{
+		ResultsDir:  viper.GetString(config.ReportDir),
+		SharedDir:   sharedDir,
+		Concurrency: viper.GetInt(config.KrknAI.ClusterConcurrency),
+		Progress:    logProgress,
+		Metrics:     metrics,
+	})
+	if err != nil {
+		log.Printf("Multi-cluster run failed: %v", err)
+		if results == nil {
+			return config.Failure
+		}
+	}
+
+	exitCode := config.Success
+	for _, result := range results {
+		if !result.Passed {
+			log.Printf("Cluster %s failed: %s", result.Name, result.Error)
+			exitCode = config.Failure
+		}
+	}
+	viper.Set(config.Cluster.Passing, exitCode == config.Success)
+
+	log.Println("==== Finished multi-cluster Krkn-ai orchestration ====")
+	return exitCode
+}
+
+// serveMetrics serves krkn-ai metrics for Prometheus to scrape while the command runs,
+// when KRKN_METRICS_ADDRESS is set. The Metrics returned are nil otherwise.
+func serveMetrics() (*krknai.Metrics, func(), error) {
+	addr := viper.GetString(config.KrknAI.MetricsAddress)
+	if addr == "" {
+		return nil, func() {}, nil
+	}
+	metrics, err := krknai.NewMetrics(prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop, err := krknai.ServeMetrics(addr, prometheus.DefaultGatherer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metrics, stop, nil
+}
+
+// logProgress logs krkn-ai progress as the run goes, since the container output is only
+// logged once the container exits.
+func logProgress(event krknai.ProgressEvent) {
+	log.Printf("Krkn-ai progress: %s", event)
+}
@@ -69,11 +69,19 @@ func New(ctx context.Context, config *Config) (*Engine, error) {
 		return nil, fmt.Errorf("failed to initialize prompt store: %w", err)
 	}
 
+	apiKeyEnvVar, err := llm.APIKeyEnvVar(config.Provider)
+	if err != nil {
+		return nil, err
+	}
 	if config.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required for Log analysis")
+		return nil, fmt.Errorf("%s is required for Log analysis", apiKeyEnvVar)
 	}
 
-	client, err := llm.NewGeminiClient(ctx, config.APIKey)
+	httpClient, err := config.LLMHTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure LLM HTTP client: %w", err)
+	}
+	client, err := llm.NewClient(ctx, config.Provider, config.APIKey, llm.WithHTTPClient(httpClient))
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize LLM client: %w", err)
 	}
@@ -115,16 +123,9 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 		return nil, fmt.Errorf("prompt preparation failed: %w", err)
 	}
 
-	if e.config.LLMConfig != nil {
-		if e.config.LLMConfig.Temperature != nil {
-			llmConfig.Temperature = e.config.LLMConfig.Temperature
-		}
-		if e.config.LLMConfig.MaxTokens != nil {
-			llmConfig.MaxTokens = e.config.LLMConfig.MaxTokens
-		}
-		if e.config.LLMConfig.TopP != nil {
-			llmConfig.TopP = e.config.LLMConfig.TopP
-		}
+	llmConfig.Override(e.config.LLMConfig)
+	if err := llmConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid LLM config: %w", err)
 	}
 
 	result, err := e.llmClient.Analyze(ctx, userPrompt, llmConfig, toolRegistry)
@@ -1,6 +1,8 @@
 package analysisengine
 
 import (
+	"net/http"
+
 	"github.com/openshift/osde2e/internal/llm"
 	"google.golang.org/genai"
 )
@@ -9,7 +11,20 @@ import (
 type BaseConfig struct {
 	ArtifactsDir string              // Directory containing artifacts or results
 	APIKey       string              // LLM API key
+	Provider     string              // LLM provider: gemini (default) or anthropic
 	LLMConfig    *llm.AnalysisConfig // Optional LLM configuration overrides
+	HTTPClient   *http.Client        // Optional client for LLM API requests; overrides ProxyURL and CABundlePath
+	ProxyURL     string              // Optional proxy for LLM API requests
+	CABundlePath string              // Optional PEM bundle trusted for LLM API requests, in addition to the system roots
+}
+
+// LLMHTTPClient returns the HTTP client LLM API requests should use: HTTPClient when set,
+// otherwise one built from ProxyURL and CABundlePath, falling back to http.DefaultClient.
+func (c BaseConfig) LLMHTTPClient() (*http.Client, error) {
+	if c.HTTPClient != nil {
+		return c.HTTPClient, nil
+	}
+	return llm.NewHTTPClient(c.ProxyURL, c.CABundlePath)
 }
 
 // Result represents the analysis output shared across all engines.
@@ -0,0 +1,412 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"github.com/openshift/osde2e/internal/llm/tools"
+)
+
+const (
+	anthropicBaseURL    = "https://api.anthropic.com"
+	anthropicAPIVersion = "2023-06-01"
+	// anthropicMaxTokens is the response limit used when the prompt config sets none;
+	// the Messages API requires one.
+	anthropicMaxTokens = 8192
+)
+
+// AnthropicClient calls the Anthropic Messages API over HTTP.
+type AnthropicClient struct {
+	httpClient *http.Client
+	apiKey     string
+	model      string
+	baseURL    string
+}
+
+func NewAnthropicClient(_ context.Context, apiKey string, opts ...ClientOption) (*AnthropicClient, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("anthropic API key is required")
+	}
+
+	httpClient := newClientOptions(opts).httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &AnthropicClient{
+		httpClient: httpClient,
+		apiKey:     apiKey,
+		model:      "claude-sonnet-4-5",
+		baseURL:    anthropicBaseURL,
+	}, nil
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Temperature *float32           `json:"temperature,omitempty"`
+	TopP        *float32           `json:"top_p,omitempty"`
+	TopK        *int               `json:"top_k,omitempty"`
+	StopSeqs    []string           `json:"stop_sequences,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type      string         `json:"type"`
+	Text      string         `json:"text,omitempty"`
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Input     map[string]any `json:"input,omitempty"`
+	ToolUseID string         `json:"tool_use_id,omitempty"`
+	Content   string         `json:"content,omitempty"`
+	IsError   bool           `json:"is_error,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicResponse struct {
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (a *AnthropicClient) Analyze(ctx context.Context, userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) (*AnalysisResult, error) {
+	return a.handleConversationWithTools(ctx, a.request(userPrompt, config, toolRegistry), toolRegistry, nil)
+}
+
+// AnalyzeStream is Analyze with each turn streamed as server-sent events.
+func (a *AnthropicClient) AnalyzeStream(ctx context.Context, userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) <-chan StreamChunk {
+	req := a.request(userPrompt, config, toolRegistry)
+	req.Stream = true
+	return streamAnalysis(ctx, func(onText func(string)) (*AnalysisResult, error) {
+		return a.handleConversationWithTools(ctx, req, toolRegistry, onText)
+	})
+}
+
+// request builds the initial Messages API request for a prompt.
+func (a *AnthropicClient) request(userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) *anthropicRequest {
+	req := &anthropicRequest{
+		Model:     a.model,
+		MaxTokens: anthropicMaxTokens,
+		Messages: []anthropicMessage{{
+			Role:    "user",
+			Content: []anthropicContentBlock{{Type: "text", Text: userPrompt}},
+		}},
+	}
+
+	if config != nil {
+		if config.SystemInstruction != nil {
+			req.System = *config.SystemInstruction
+		}
+		req.Temperature = config.Temperature
+		req.TopP = config.TopP
+		req.TopK = config.TopK
+		req.StopSeqs = config.StopSequences
+		if config.MaxTokens != nil {
+			req.MaxTokens = *config.MaxTokens
+		}
+		if toolRegistry != nil {
+			req.Tools = anthropicTools(toolRegistry)
+		}
+	}
+
+	return req
+}
+
+func (a *AnthropicClient) handleConversationWithTools(ctx context.Context, req *anthropicRequest, toolRegistry *tools.Registry, onText func(string)) (*AnalysisResult, error) {
+	const maxIterations = 5
+	var toolCalls []*genai.FunctionCall
+	var usage Usage
+
+	for i := range maxIterations {
+		resp, err := a.createMessage(ctx, req, onText)
+		if err != nil {
+			return nil, err
+		}
+		usage.PromptTokens += resp.Usage.InputTokens
+		usage.CompletionTokens += resp.Usage.OutputTokens
+
+		var textContent strings.Builder
+		var toolUses []anthropicContentBlock
+		for _, block := range resp.Content {
+			switch block.Type {
+			case "text":
+				textContent.WriteString(block.Text)
+			case "tool_use":
+				toolUses = append(toolUses, block)
+				toolCalls = append(toolCalls, &genai.FunctionCall{ID: block.ID, Name: block.Name, Args: block.Input})
+			}
+		}
+
+		// If no tool calls, we're done
+		if len(toolUses) == 0 || toolRegistry == nil {
+			return &AnalysisResult{
+				Content:   textContent.String(),
+				ToolCalls: toolCalls,
+				Usage:     usage,
+			}, nil
+		}
+
+		// Return partial result if we hit max iterations
+		if i == maxIterations-1 {
+			return &AnalysisResult{
+				Content:   textContent.String(),
+				ToolCalls: toolCalls,
+				Usage:     usage,
+			}, nil
+		}
+
+		// Process tool calls and continue conversation
+		req.Messages = append(req.Messages,
+			anthropicMessage{Role: "assistant", Content: resp.Content},
+			anthropicMessage{Role: "user", Content: a.processToolUses(ctx, toolUses, toolRegistry)},
+		)
+	}
+
+	return &AnalysisResult{ToolCalls: toolCalls, Usage: usage}, fmt.Errorf("max iterations reached without final response")
+}
+
+// processToolUses runs each requested tool and returns the tool_result blocks for the next turn.
+// Tool failures are reported back to the model rather than ending the conversation.
+func (a *AnthropicClient) processToolUses(ctx context.Context, toolUses []anthropicContentBlock, toolRegistry *tools.Registry) []anthropicContentBlock {
+	results := make([]anthropicContentBlock, 0, len(toolUses))
+	for _, toolUse := range toolUses {
+		block := anthropicContentBlock{Type: "tool_result", ToolUseID: toolUse.ID}
+		result, err := toolRegistry.Execute(ctx, toolUse.Name, toolUse.Input)
+		if err != nil {
+			block.Content = fmt.Sprintf("tool execution failed: %v", err)
+			block.IsError = true
+		} else if s, ok := result.(string); ok {
+			block.Content = s
+		} else if encoded, err := json.Marshal(result); err == nil {
+			block.Content = string(encoded)
+		} else {
+			block.Content = fmt.Sprintf("%v", result)
+		}
+		results = append(results, block)
+	}
+	return results
+}
+
+// createMessage sends one turn of the conversation. Streamed requests forward text deltas
+// to onText and are assembled into the same response a non-streamed request returns.
+func (a *AnthropicClient) createMessage(ctx context.Context, req *anthropicRequest, onText func(string)) (*anthropicResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anthropic request: %w", err)
+	}
+	httpReq.Header.Set("content-type", "application/json")
+	httpReq.Header.Set("x-api-key", a.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	httpResp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic API error: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if req.Stream && httpResp.StatusCode == http.StatusOK {
+		resp, err := readAnthropicStream(httpResp.Body, onText)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Content) == 0 {
+			return nil, fmt.Errorf("no content in anthropic response")
+		}
+		return resp, nil
+	}
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anthropic response: %w", err)
+	}
+
+	var resp anthropicResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("anthropic API error: status %d: failed to parse response: %w", httpResp.StatusCode, err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		if resp.Error != nil {
+			return nil, fmt.Errorf("anthropic API error: status %d: %s: %s", httpResp.StatusCode, resp.Error.Type, resp.Error.Message)
+		}
+		return nil, fmt.Errorf("anthropic API error: status %d", httpResp.StatusCode)
+	}
+	if len(resp.Content) == 0 {
+		return nil, fmt.Errorf("no content in anthropic response")
+	}
+
+	return &resp, nil
+}
+
+// anthropicStreamEvent is a server-sent event of a streamed Messages API response.
+type anthropicStreamEvent struct {
+	Type         string                 `json:"type"`
+	Index        int                    `json:"index"`
+	Message      *anthropicResponse     `json:"message,omitempty"`
+	ContentBlock *anthropicContentBlock `json:"content_block,omitempty"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// readAnthropicStream assembles a streamed response from its server-sent events, passing
+// text deltas to onText. Tool inputs arrive as JSON fragments and are decoded once their
+// content block ends.
+func readAnthropicStream(body io.Reader, onText func(string)) (*anthropicResponse, error) {
+	resp := &anthropicResponse{}
+	toolInputs := make(map[int]*strings.Builder)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse anthropic stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				resp.Usage = event.Message.Usage
+			}
+		case "content_block_start":
+			if event.ContentBlock == nil || event.Index != len(resp.Content) {
+				return nil, fmt.Errorf("unexpected anthropic content block %d", event.Index)
+			}
+			resp.Content = append(resp.Content, *event.ContentBlock)
+			if event.ContentBlock.Type == "tool_use" {
+				toolInputs[event.Index] = &strings.Builder{}
+			}
+		case "content_block_delta":
+			if event.Index < 0 || event.Index >= len(resp.Content) {
+				return nil, fmt.Errorf("unexpected anthropic content block %d", event.Index)
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				resp.Content[event.Index].Text += event.Delta.Text
+				if onText != nil {
+					onText(event.Delta.Text)
+				}
+			case "input_json_delta":
+				if input, ok := toolInputs[event.Index]; ok {
+					input.WriteString(event.Delta.PartialJSON)
+				}
+			}
+		case "content_block_stop":
+			if input, ok := toolInputs[event.Index]; ok && input.Len() > 0 {
+				var args map[string]any
+				if err := json.Unmarshal([]byte(input.String()), &args); err != nil {
+					return nil, fmt.Errorf("failed to parse anthropic tool input: %w", err)
+				}
+				resp.Content[event.Index].Input = args
+			}
+		case "message_delta":
+			resp.StopReason = event.Delta.StopReason
+			if event.Usage != nil {
+				resp.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "error":
+			if event.Error != nil {
+				return nil, fmt.Errorf("anthropic API error: %s: %s", event.Error.Type, event.Error.Message)
+			}
+			return nil, fmt.Errorf("anthropic API error in stream")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read anthropic stream: %w", err)
+	}
+	return resp, nil
+}
+
+// anthropicTools converts the registry's tool declarations to Anthropic tool definitions.
+func anthropicTools(toolRegistry *tools.Registry) []anthropicTool {
+	var defs []anthropicTool
+	for _, tool := range toolRegistry.GetTools() {
+		for _, decl := range tool.FunctionDeclarations {
+			defs = append(defs, anthropicTool{
+				Name:        decl.Name,
+				Description: decl.Description,
+				InputSchema: jsonSchema(decl.Parameters),
+			})
+		}
+	}
+	return defs
+}
+
+// jsonSchema converts a genai schema to the JSON Schema form the Messages API expects.
+func jsonSchema(schema *genai.Schema) map[string]any {
+	if schema == nil {
+		return map[string]any{"type": "object"}
+	}
+
+	out := map[string]any{}
+	if schema.Type != "" {
+		out["type"] = strings.ToLower(string(schema.Type))
+	}
+	if schema.Description != "" {
+		out["description"] = schema.Description
+	}
+	if len(schema.Enum) > 0 {
+		out["enum"] = schema.Enum
+	}
+	if len(schema.Required) > 0 {
+		out["required"] = schema.Required
+	}
+	if schema.Items != nil {
+		out["items"] = jsonSchema(schema.Items)
+	}
+	if len(schema.Properties) > 0 {
+		props := make(map[string]any, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			props[name] = jsonSchema(prop)
+		}
+		out["properties"] = props
+	}
+	return out
+}
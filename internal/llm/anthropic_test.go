@@ -0,0 +1,224 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/openshift/osde2e/internal/aggregator"
+	"github.com/openshift/osde2e/internal/llm/tools"
+)
+
+func TestAnthropicClient_ImplementsInterface(t *testing.T) {
+	var _ StreamingLLMClient = (*AnthropicClient)(nil)
+}
+
+func TestAnthropicClient_AnalyzeWithToolCalls(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "build.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("etcd leader lost\n"), 0o644))
+
+	var requests []anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicAPIVersion, r.Header.Get("anthropic-version"))
+
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = w.Write([]byte(`{"stop_reason":"tool_use","content":[
+				{"type":"text","text":"Reading the log."},
+				{"type":"tool_use","id":"toolu_1","name":"read_file","input":{"files":[{"path":"` + logFile + `"}]}}],
+				"usage":{"input_tokens":100,"output_tokens":20}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"stop_reason":"end_turn","content":[{"type":"text","text":"etcd lost quorum"}],
+			"usage":{"input_tokens":150,"output_tokens":30}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAnthropicClient(context.Background(), "test-key")
+	require.NoError(t, err)
+	client.baseURL = server.URL
+
+	registry := tools.NewRegistry([]aggregator.LogEntry{{Source: logFile}})
+	config := &AnalysisConfig{
+		SystemInstruction: genai.Ptr("You are a log analyst."),
+		MaxTokens:         genai.Ptr(1024),
+		TopK:              genai.Ptr(40),
+		StopSequences:     []string{"</analysis>"},
+	}
+	result, err := client.Analyze(context.Background(), "Why did the job fail?", config, registry)
+	require.NoError(t, err)
+
+	assert.Equal(t, "etcd lost quorum", result.Content)
+	require.Len(t, result.ToolCalls, 1)
+	assert.Equal(t, "read_file", result.ToolCalls[0].Name)
+	assert.Equal(t, Usage{PromptTokens: 250, CompletionTokens: 50}, result.Usage)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, "You are a log analyst.", requests[0].System)
+	assert.Equal(t, 1024, requests[0].MaxTokens)
+	assert.Equal(t, genai.Ptr(40), requests[0].TopK)
+	assert.Equal(t, []string{"</analysis>"}, requests[0].StopSeqs)
+	require.Len(t, requests[0].Tools, 1)
+	assert.Equal(t, "read_file", requests[0].Tools[0].Name)
+	assert.Equal(t, "object", requests[0].Tools[0].InputSchema["type"])
+
+	// The second turn carries the assistant's tool use and the tool's result
+	require.Len(t, requests[1].Messages, 3)
+	toolResult := requests[1].Messages[2].Content[0]
+	assert.Equal(t, "tool_result", toolResult.Type)
+	assert.Equal(t, "toolu_1", toolResult.ToolUseID)
+	assert.Contains(t, toolResult.Content, "etcd leader lost")
+}
+
+func TestAnthropicClient_AnalyzeStream(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "build.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("etcd leader lost\n"), 0o644))
+	toolInput, err := json.Marshal(map[string]any{"files": []map[string]string{{"path": logFile}}})
+	require.NoError(t, err)
+	partialInput, err := json.Marshal(string(toolInput[:10]))
+	require.NoError(t, err)
+	restInput, err := json.Marshal(string(toolInput[10:]))
+	require.NoError(t, err)
+
+	var requests []anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`{"type":"message_start","message":{"content":[],"usage":{"input_tokens":100,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Reading "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"the log."}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"read_file","input":{}}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":` + string(partialInput) + `}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":` + string(restInput) + `}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+			`{"type":"message_stop"}`,
+		}
+		if len(requests) > 1 {
+			events = []string{
+				`{"type":"message_start","message":{"content":[],"usage":{"input_tokens":150,"output_tokens":1}}}`,
+				`{"type":"ping"}`,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"etcd lost "}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"quorum"}}`,
+				`{"type":"content_block_stop","index":0}`,
+				`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":30}}`,
+				`{"type":"message_stop"}`,
+			}
+		}
+		for _, event := range events {
+			var typed struct{ Type string }
+			require.NoError(t, json.Unmarshal([]byte(event), &typed))
+			_, _ = w.Write([]byte("event: " + typed.Type + "\ndata: " + event + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewAnthropicClient(context.Background(), "test-key")
+	require.NoError(t, err)
+	client.baseURL = server.URL
+
+	registry := tools.NewRegistry([]aggregator.LogEntry{{Source: logFile}})
+	config := &AnalysisConfig{SystemInstruction: genai.Ptr("You are a log analyst.")}
+
+	var text []string
+	var final *StreamChunk
+	for chunk := range client.AnalyzeStream(context.Background(), "Why did the job fail?", config, registry) {
+		if chunk.Result != nil || chunk.Err != nil {
+			final = &chunk
+			continue
+		}
+		text = append(text, chunk.Text)
+	}
+
+	assert.Equal(t, []string{"Reading ", "the log.", "etcd lost ", "quorum"}, text)
+	require.NotNil(t, final)
+	require.NoError(t, final.Err)
+	assert.Equal(t, "etcd lost quorum", final.Result.Content)
+	require.Len(t, final.Result.ToolCalls, 1)
+	assert.Equal(t, "read_file", final.Result.ToolCalls[0].Name)
+	assert.Equal(t, Usage{PromptTokens: 250, CompletionTokens: 50}, final.Result.Usage)
+
+	// The tool ran between the streamed turns
+	require.Len(t, requests, 2)
+	assert.True(t, requests[0].Stream)
+	require.Len(t, requests[1].Messages, 3)
+	assert.Equal(t, "Reading the log.", requests[1].Messages[1].Content[0].Text)
+	toolResult := requests[1].Messages[2].Content[0]
+	assert.Equal(t, "toolu_1", toolResult.ToolUseID)
+	assert.Contains(t, toolResult.Content, "etcd leader lost")
+}
+
+func TestAnthropicClient_AnalyzeStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"))
+	}))
+	defer server.Close()
+
+	client, err := NewAnthropicClient(context.Background(), "test-key")
+	require.NoError(t, err)
+	client.baseURL = server.URL
+
+	var last StreamChunk
+	for chunk := range client.AnalyzeStream(context.Background(), "hello", nil, nil) {
+		last = chunk
+	}
+	assert.ErrorContains(t, last.Err, "overloaded_error: Overloaded")
+}
+
+func TestAnthropicClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAnthropicClient(context.Background(), "bad-key")
+	require.NoError(t, err)
+	client.baseURL = server.URL
+
+	_, err = client.Analyze(context.Background(), "hello", nil, nil)
+	assert.ErrorContains(t, err, "status 401: authentication_error: invalid x-api-key")
+}
+
+func TestNewClient(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := NewClient(ctx, ProviderAnthropic, "key")
+	require.NoError(t, err)
+	assert.IsType(t, &AnthropicClient{}, client)
+
+	_, err = NewClient(ctx, ProviderAnthropic, "")
+	assert.Error(t, err)
+
+	_, err = NewClient(ctx, "openai", "key")
+	assert.ErrorContains(t, err, `unknown LLM provider "openai"`)
+
+	envVar, err := APIKeyEnvVar("")
+	require.NoError(t, err)
+	assert.Equal(t, "GEMINI_API_KEY", envVar)
+	envVar, err = APIKeyEnvVar(ProviderAnthropic)
+	require.NoError(t, err)
+	assert.Equal(t, "ANTHROPIC_API_KEY", envVar)
+}
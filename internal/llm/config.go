@@ -1,15 +1,81 @@
 package llm
 
-import "google.golang.org/genai"
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// Legal sampling ranges, checked by Validate. Providers may be stricter: Anthropic caps
+// temperature at 1.
+const (
+	maxTemperature = 2.0
+	maxTopP        = 1.0
+)
 
 type AnalysisConfig struct {
 	SystemInstruction *string  `json:"systemInstruction,omitempty"`
 	Temperature       *float32 `json:"temperature,omitempty"`
 	TopP              *float32 `json:"topP,omitempty"`
+	TopK              *int     `json:"topK,omitempty"`
 	MaxTokens         *int     `json:"maxTokens,omitempty"`
+	// FrequencyPenalty and PresencePenalty are only supported by Gemini
+	FrequencyPenalty *float32 `json:"frequencyPenalty,omitempty"`
+	PresencePenalty  *float32 `json:"presencePenalty,omitempty"`
+	StopSequences    []string `json:"stopSequences,omitempty"`
+}
+
+// Override copies the sampling settings set on overrides onto c, so settings left nil
+// keep c's value. The system instruction is not overridden.
+func (c *AnalysisConfig) Override(overrides *AnalysisConfig) {
+	if overrides == nil {
+		return
+	}
+	if overrides.Temperature != nil {
+		c.Temperature = overrides.Temperature
+	}
+	if overrides.MaxTokens != nil {
+		c.MaxTokens = overrides.MaxTokens
+	}
+	if overrides.TopP != nil {
+		c.TopP = overrides.TopP
+	}
+	if overrides.TopK != nil {
+		c.TopK = overrides.TopK
+	}
+	if overrides.FrequencyPenalty != nil {
+		c.FrequencyPenalty = overrides.FrequencyPenalty
+	}
+	if overrides.PresencePenalty != nil {
+		c.PresencePenalty = overrides.PresencePenalty
+	}
+	if overrides.StopSequences != nil {
+		c.StopSequences = overrides.StopSequences
+	}
+}
+
+// Validate checks that temperature and top_p are within their legal ranges, so a bad
+// setting is reported before a provider rejects the request.
+func (c *AnalysisConfig) Validate() error {
+	var errs []error
+	if t := c.Temperature; t != nil && (*t < 0 || *t > maxTemperature) {
+		errs = append(errs, fmt.Errorf("temperature must be between 0 and %g, got %g", maxTemperature, *t))
+	}
+	if p := c.TopP; p != nil && (*p < 0 || *p > maxTopP) {
+		errs = append(errs, fmt.Errorf("top_p must be between 0 and %g, got %g", maxTopP, *p))
+	}
+	return errors.Join(errs...)
 }
 
 type AnalysisResult struct {
 	Content   string                `json:"content"`
 	ToolCalls []*genai.FunctionCall `json:"tool_calls,omitempty"`
+	Usage     Usage                 `json:"usage"`
+}
+
+// Usage counts the tokens consumed by an analysis, summed over every turn of the tool-use conversation.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
 }
@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genai"
+)
+
+func TestAnalysisConfig_Override(t *testing.T) {
+	config := &AnalysisConfig{
+		SystemInstruction: genai.Ptr("You are a log analyst."),
+		Temperature:       genai.Ptr(float32(0.1)),
+		TopP:              genai.Ptr(float32(0.9)),
+		MaxTokens:         genai.Ptr(4000),
+	}
+	config.Override(nil)
+	assert.Equal(t, float32(0.1), *config.Temperature)
+
+	config.Override(&AnalysisConfig{
+		SystemInstruction: genai.Ptr("ignored"),
+		Temperature:       genai.Ptr(float32(0)),
+		TopK:              genai.Ptr(20),
+		FrequencyPenalty:  genai.Ptr(float32(0.5)),
+		PresencePenalty:   genai.Ptr(float32(-0.5)),
+		StopSequences:     []string{"END"},
+	})
+	assert.Equal(t, &AnalysisConfig{
+		SystemInstruction: genai.Ptr("You are a log analyst."),
+		Temperature:       genai.Ptr(float32(0)),
+		TopP:              genai.Ptr(float32(0.9)),
+		TopK:              genai.Ptr(20),
+		MaxTokens:         genai.Ptr(4000),
+		FrequencyPenalty:  genai.Ptr(float32(0.5)),
+		PresencePenalty:   genai.Ptr(float32(-0.5)),
+		StopSequences:     []string{"END"},
+	}, config, "unset overrides keep the template defaults")
+}
+
+func TestAnalysisConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&AnalysisConfig{}).Validate())
+	assert.NoError(t, (&AnalysisConfig{Temperature: genai.Ptr(float32(2)), TopP: genai.Ptr(float32(0))}).Validate())
+
+	err := (&AnalysisConfig{Temperature: genai.Ptr(float32(-0.1)), TopP: genai.Ptr(float32(1.5))}).Validate()
+	assert.ErrorContains(t, err, "temperature must be between 0 and 2, got -0.1")
+	assert.ErrorContains(t, err, "top_p must be between 0 and 1, got 1.5")
+}
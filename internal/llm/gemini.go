@@ -14,10 +14,11 @@ type GeminiClient struct {
 	model  string
 }
 
-func NewGeminiClient(ctx context.Context, apiKey string) (*GeminiClient, error) {
+func NewGeminiClient(ctx context.Context, apiKey string, opts ...ClientOption) (*GeminiClient, error) {
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: newClientOptions(opts).httpClient,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create genai client: %w", err)
@@ -30,6 +31,20 @@ func NewGeminiClient(ctx context.Context, apiKey string) (*GeminiClient, error)
 }
 
 func (g *GeminiClient) Analyze(ctx context.Context, userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) (*AnalysisResult, error) {
+	contents, genConfig := g.request(userPrompt, config, toolRegistry)
+	return g.handleConversationWithTools(ctx, contents, genConfig, toolRegistry, nil)
+}
+
+// AnalyzeStream is Analyze with each turn streamed through GenerateContentStream.
+func (g *GeminiClient) AnalyzeStream(ctx context.Context, userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) <-chan StreamChunk {
+	contents, genConfig := g.request(userPrompt, config, toolRegistry)
+	return streamAnalysis(ctx, func(onText func(string)) (*AnalysisResult, error) {
+		return g.handleConversationWithTools(ctx, contents, genConfig, toolRegistry, onText)
+	})
+}
+
+// request builds the initial conversation and generation config for a prompt.
+func (g *GeminiClient) request(userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) ([]*genai.Content, *genai.GenerateContentConfig) {
 	contents := []*genai.Content{
 		genai.NewContentFromText(userPrompt, genai.RoleUser),
 	}
@@ -50,27 +65,41 @@ func (g *GeminiClient) Analyze(ctx context.Context, userPrompt string, config *A
 			genConfig.TopP = config.TopP
 		}
 
+		if config.TopK != nil {
+			genConfig.TopK = genai.Ptr(float32(*config.TopK))
+		}
+
 		if config.MaxTokens != nil {
 			genConfig.MaxOutputTokens = int32(*config.MaxTokens)
 		}
 
+		genConfig.FrequencyPenalty = config.FrequencyPenalty
+		genConfig.PresencePenalty = config.PresencePenalty
+		genConfig.StopSequences = config.StopSequences
+
 		if toolRegistry != nil {
 			genConfig.Tools = toolRegistry.GetTools()
 		}
 	}
 
-	return g.handleConversationWithTools(ctx, contents, genConfig, toolRegistry)
+	return contents, genConfig
 }
 
-func (g *GeminiClient) handleConversationWithTools(ctx context.Context, contents []*genai.Content, genConfig *genai.GenerateContentConfig, toolRegistry *tools.Registry) (*AnalysisResult, error) {
+func (g *GeminiClient) handleConversationWithTools(ctx context.Context, contents []*genai.Content, genConfig *genai.GenerateContentConfig, toolRegistry *tools.Registry, onText func(string)) (*AnalysisResult, error) {
 	const maxIterations = 5
 	var toolCalls []*genai.FunctionCall
+	var usage Usage
 
 	for i := range maxIterations {
-		resp, err := g.client.Models.GenerateContent(ctx, g.model, contents, genConfig)
+		resp, err := g.generate(ctx, contents, genConfig, onText)
 		if err != nil {
 			return nil, fmt.Errorf("gemini API error: %w", err)
 		}
+		if md := resp.UsageMetadata; md != nil {
+			usage.PromptTokens += int(md.PromptTokenCount + md.ToolUsePromptTokenCount)
+			// Thinking tokens are billed as output
+			usage.CompletionTokens += int(md.CandidatesTokenCount + md.ThoughtsTokenCount)
+		}
 
 		candidate, err := g.extractCandidate(resp)
 		if err != nil {
@@ -87,6 +116,7 @@ func (g *GeminiClient) handleConversationWithTools(ctx context.Context, contents
 			return &AnalysisResult{
 				Content:   textContent,
 				ToolCalls: toolCalls,
+				Usage:     usage,
 			}, nil
 		}
 
@@ -101,11 +131,48 @@ func (g *GeminiClient) handleConversationWithTools(ctx context.Context, contents
 			return &AnalysisResult{
 				Content:   textContent,
 				ToolCalls: toolCalls,
+				Usage:     usage,
 			}, nil
 		}
 	}
 
-	return &AnalysisResult{ToolCalls: toolCalls}, fmt.Errorf("max iterations reached without final response")
+	return &AnalysisResult{ToolCalls: toolCalls, Usage: usage}, fmt.Errorf("max iterations reached without final response")
+}
+
+// generate returns the model's next turn. With onText set, the turn is streamed, its text
+// forwarded as it arrives, and the chunks merged into one response.
+func (g *GeminiClient) generate(ctx context.Context, contents []*genai.Content, genConfig *genai.GenerateContentConfig, onText func(string)) (*genai.GenerateContentResponse, error) {
+	if onText == nil {
+		return g.client.Models.GenerateContent(ctx, g.model, contents, genConfig)
+	}
+
+	merged := &genai.GenerateContentResponse{}
+	var candidate *genai.Candidate
+	for chunk, err := range g.client.Models.GenerateContentStream(ctx, g.model, contents, genConfig) {
+		if err != nil {
+			return nil, err
+		}
+		// Each chunk reports the usage so far
+		if chunk.UsageMetadata != nil {
+			merged.UsageMetadata = chunk.UsageMetadata
+		}
+		if len(chunk.Candidates) == 0 || chunk.Candidates[0].Content == nil {
+			continue
+		}
+		if candidate == nil {
+			candidate = &genai.Candidate{Content: &genai.Content{Role: chunk.Candidates[0].Content.Role}}
+		}
+		for _, part := range chunk.Candidates[0].Content.Parts {
+			if part.Text != "" {
+				onText(part.Text)
+			}
+			candidate.Content.Parts = append(candidate.Content.Parts, part)
+		}
+	}
+	if candidate != nil {
+		merged.Candidates = []*genai.Candidate{candidate}
+	}
+	return merged, nil
 }
 
 func (g *GeminiClient) extractCandidate(resp *genai.GenerateContentResponse) (*genai.Candidate, error) {
@@ -11,7 +11,7 @@ import (
 )
 
 func TestGeminiClient_ImplementsInterface(t *testing.T) {
-	var _ LLMClient = (*GeminiClient)(nil)
+	var _ StreamingLLMClient = (*GeminiClient)(nil)
 }
 
 func TestGeminiClient_Integration(t *testing.T) {
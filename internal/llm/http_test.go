@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	client, err := NewHTTPClient("", "")
+	require.NoError(t, err)
+	assert.Same(t, http.DefaultClient, client)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err = http.DefaultClient.Get(server.URL)
+	require.Error(t, err, "the test server's certificate should not be trusted by default")
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(bundle, certPEM, 0o644))
+
+	client, err = NewHTTPClient("", bundle)
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	client, err = NewHTTPClient("http://proxy.example.com:3128", "")
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, "https://api.anthropic.com", nil)
+	require.NoError(t, err)
+	proxy, err := client.Transport.(*http.Transport).Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, &url.URL{Scheme: "http", Host: "proxy.example.com:3128"}, proxy)
+}
+
+func TestNewHTTPClient_Errors(t *testing.T) {
+	_, err := NewHTTPClient("", filepath.Join(t.TempDir(), "missing.pem"))
+	assert.ErrorContains(t, err, "failed to read LLM CA bundle")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o644))
+	_, err = NewHTTPClient("", notPEM)
+	assert.ErrorContains(t, err, "contains no PEM certificates")
+
+	_, err = NewHTTPClient("proxy.example.com", "")
+	assert.ErrorContains(t, err, "invalid LLM proxy URL")
+}
+
+func TestAnthropicClient_WithHTTPClient(t *testing.T) {
+	custom := &http.Client{}
+	client, err := NewAnthropicClient(context.Background(), "test-key", WithHTTPClient(custom))
+	require.NoError(t, err)
+	assert.Same(t, custom, client.httpClient)
+
+	client, err = NewAnthropicClient(context.Background(), "test-key", WithHTTPClient(nil))
+	require.NoError(t, err)
+	assert.Same(t, http.DefaultClient, client.httpClient)
+}
@@ -2,10 +2,48 @@ package llm
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/openshift/osde2e/internal/llm/tools"
 )
 
+// Supported LLM providers.
+const (
+	ProviderGemini    = "gemini"
+	ProviderAnthropic = "anthropic"
+)
+
 type LLMClient interface {
 	Analyze(ctx context.Context, userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) (*AnalysisResult, error)
 }
+
+// APIKeyEnvVar returns the environment variable that supplies the API key for provider.
+// An empty provider means Gemini.
+func APIKeyEnvVar(provider string) (string, error) {
+	switch provider {
+	case "", ProviderGemini:
+		return "GEMINI_API_KEY", nil
+	case ProviderAnthropic:
+		return "ANTHROPIC_API_KEY", nil
+	default:
+		return "", fmt.Errorf("unknown LLM provider %q (supported: %s, %s)", provider, ProviderGemini, ProviderAnthropic)
+	}
+}
+
+// NewClient creates the LLM client for provider. An empty provider means Gemini.
+func NewClient(ctx context.Context, provider, apiKey string, opts ...ClientOption) (LLMClient, error) {
+	var client LLMClient
+	var err error
+	switch provider {
+	case "", ProviderGemini:
+		client, err = NewGeminiClient(ctx, apiKey, opts...)
+	case ProviderAnthropic:
+		client, err = NewAnthropicClient(ctx, apiKey, opts...)
+	default:
+		_, err = APIKeyEnvVar(provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
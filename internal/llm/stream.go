@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/openshift/osde2e/internal/llm/tools"
+)
+
+// StreamChunk is one event of a streamed analysis. Text chunks arrive as the model
+// generates them; the final chunk carries the assembled Result or the Err that ended it.
+type StreamChunk struct {
+	Text   string
+	Result *AnalysisResult
+	Err    error
+}
+
+// StreamingLLMClient is an LLMClient that can also deliver its response while it is
+// being generated.
+type StreamingLLMClient interface {
+	LLMClient
+
+	// AnalyzeStream runs the same tool-use conversation as Analyze, sending the text of
+	// every turn as it arrives. Tool calls are handled between turns, so text from turns
+	// that end in a tool call is streamed but not part of Result.Content. The channel is
+	// closed after the final chunk; callers must drain it or cancel ctx.
+	AnalyzeStream(ctx context.Context, userPrompt string, config *AnalysisConfig, toolRegistry *tools.Registry) <-chan StreamChunk
+}
+
+// streamAnalysis runs analyze in the background, forwarding the text it reports and then
+// its outcome through the returned channel. Sends stop once ctx is done.
+func streamAnalysis(ctx context.Context, analyze func(onText func(string)) (*AnalysisResult, error)) <-chan StreamChunk {
+	chunks := make(chan StreamChunk)
+	send := func(chunk StreamChunk) {
+		select {
+		case chunks <- chunk:
+		case <-ctx.Done():
+		}
+	}
+	go func() {
+		defer close(chunks)
+		result, err := analyze(func(text string) { send(StreamChunk{Text: text}) })
+		send(StreamChunk{Result: result, Err: err})
+	}()
+	return chunks
+}
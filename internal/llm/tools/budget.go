@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FileBudgetExhaustedError is the error code in the read_file result returned once the
+// file budget is spent.
+const FileBudgetExhaustedError = "file_budget_exhausted"
+
+// fileBudget caps how many files read_file serves over a whole analysis.
+type fileBudget struct {
+	mu       sync.Mutex
+	max      int
+	served   int
+	exceeded bool
+}
+
+// reserve takes n files from the budget. A request that does not fit is refused as a
+// whole and marks the budget exceeded.
+func (b *fileBudget) reserve(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.served+n > b.max {
+		b.exceeded = true
+		return false
+	}
+	b.served += n
+	return true
+}
+
+// exhausted returns the structured tool error telling the model a request of n files
+// did not fit in the budget. It is returned as the tool result rather than a Go error so
+// every provider passes it back to the model instead of ending the conversation.
+func (b *fileBudget) exhausted(n int) map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	remaining := b.max - b.served
+	return map[string]any{
+		"error": FileBudgetExhaustedError,
+		"message": fmt.Sprintf("read_file budget exhausted: %d of %d files already read, %d requested. "+
+			"Request at most %d more file(s), or finish the analysis with what has been read.", b.served, b.max, n, remaining),
+		"max_files":       b.max,
+		"files_remaining": remaining,
+	}
+}
+
+// wasExceeded reports whether any request was refused.
+func (b *fileBudget) wasExceeded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exceeded
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/openshift/osde2e/internal/aggregator"
 	"github.com/openshift/osde2e/internal/sanitizer"
@@ -12,7 +13,9 @@ import (
 )
 
 type readFileTool struct {
-	sanitizer *sanitizer.Sanitizer
+	sanitizer   *sanitizer.Sanitizer
+	budget      *fileBudget   // Files served across all calls (nil is unlimited)
+	readTimeout time.Duration // Per-file read timeout (0 disables)
 }
 
 // newReadFileTool creates a new read file tool with sanitizer
@@ -73,7 +76,7 @@ func (t *readFileTool) Schema() *genai.Schema {
 	}
 }
 
-func (t *readFileTool) Execute(_ context.Context, params map[string]any, logArtifacts []aggregator.LogEntry) (any, error) {
+func (t *readFileTool) Execute(ctx context.Context, params map[string]any, logArtifacts []aggregator.LogEntry) (any, error) {
 	if logArtifacts == nil {
 		return nil, fmt.Errorf("no log artifacts provided to tool")
 	}
@@ -98,7 +101,11 @@ func (t *readFileTool) Execute(_ context.Context, params map[string]any, logArti
 		return nil, err
 	}
 
-	return t.processFiles(filesArray, shouldSanitize)
+	if t.budget != nil && !t.budget.reserve(len(filesArray)) {
+		return t.budget.exhausted(len(filesArray)), nil
+	}
+
+	return t.processFiles(ctx, filesArray, shouldSanitize)
 }
 
 // validateAllFiles performs upfront validation of all file paths and line ranges.
@@ -137,10 +144,10 @@ func validateAllFiles(filesArray []any, logArtifacts []aggregator.LogEntry) erro
 // processFiles reads all files and returns results.
 // Single file: returns content directly as string.
 // Multiple files: returns map[string]any with path -> content.
-func (t *readFileTool) processFiles(filesArray []any, shouldSanitize bool) (any, error) {
+func (t *readFileTool) processFiles(ctx context.Context, filesArray []any, shouldSanitize bool) (any, error) {
 	if len(filesArray) == 1 {
 		fileMap := filesArray[0].(map[string]any)
-		return t.processSingleFile(fileMap, shouldSanitize)
+		return t.processSingleFile(ctx, fileMap, shouldSanitize)
 	}
 
 	results := make(map[string]any, len(filesArray))
@@ -148,7 +155,7 @@ func (t *readFileTool) processFiles(filesArray []any, shouldSanitize bool) (any,
 		fileMap := item.(map[string]any)
 		path, _ := extractString(fileMap, "path")
 
-		content, err := t.processSingleFile(fileMap, shouldSanitize)
+		content, err := t.processSingleFile(ctx, fileMap, shouldSanitize)
 		if err != nil {
 			results[path] = fmt.Sprintf("error: %v", err)
 			continue
@@ -159,7 +166,7 @@ func (t *readFileTool) processFiles(filesArray []any, shouldSanitize bool) (any,
 }
 
 // processSingleFile reads a single file based on its specification map.
-func (t *readFileTool) processSingleFile(fileMap map[string]any, shouldSanitize bool) (any, error) {
+func (t *readFileTool) processSingleFile(ctx context.Context, fileMap map[string]any, shouldSanitize bool) (any, error) {
 	path, _ := extractString(fileMap, "path")
 	start := extractIntPtr(fileMap, "start")
 	stop := extractIntPtr(fileMap, "stop")
@@ -168,7 +175,7 @@ func (t *readFileTool) processSingleFile(fileMap map[string]any, shouldSanitize
 		fmt.Printf("⚠️  WARNING: Sanitization disabled for file %s - sensitive information may be exposed\n", path)
 	}
 
-	content, err := t.readFileWithLineRange(path, start, stop, shouldSanitize)
+	content, err := t.readWithTimeout(ctx, path, start, stop, shouldSanitize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
 	}
@@ -176,6 +183,35 @@ func (t *readFileTool) processSingleFile(fileMap map[string]any, shouldSanitize
 	return content, nil
 }
 
+// readWithTimeout reads a file like readFileWithLineRange, giving up once readTimeout
+// passes or ctx is done. An abandoned read finishes in the background and is discarded.
+func (t *readFileTool) readWithTimeout(ctx context.Context, filePath string, start, stop *int, shouldSanitize bool) (string, error) {
+	if t.readTimeout <= 0 {
+		return t.readFileWithLineRange(filePath, start, stop, shouldSanitize)
+	}
+
+	type readResult struct {
+		content string
+		err     error
+	}
+	done := make(chan readResult, 1)
+	go func() {
+		content, err := t.readFileWithLineRange(filePath, start, stop, shouldSanitize)
+		done <- readResult{content, err}
+	}()
+
+	timer := time.NewTimer(t.readTimeout)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result.content, result.err
+	case <-timer.C:
+		return "", fmt.Errorf("read timed out after %s", t.readTimeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
 // readFileWithLineRange reads a file and returns content within the specified line range
 func (t *readFileTool) readFileWithLineRange(filePath string, start, stop *int, shouldSanitize bool) (string, error) {
 	// Read lines from file within the specified range
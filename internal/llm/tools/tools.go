@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/openshift/osde2e/internal/aggregator"
 	"google.golang.org/genai"
@@ -20,6 +21,8 @@ type Tool interface {
 type Registry struct {
 	tools        map[string]Tool
 	logArtifacts []aggregator.LogEntry
+	redact       func(string) string // Optional filter applied to tool output
+	readFile     *readFileTool
 }
 
 // NewRegistry creates a new tool registry with the provided log artifacts
@@ -30,11 +33,37 @@ func NewRegistry(logArtifacts []aggregator.LogEntry) *Registry {
 	}
 
 	// Register production tools only
-	r.Register(newReadFileTool())
+	r.readFile = newReadFileTool()
+	r.Register(r.readFile)
 
 	return r
 }
 
+// WithFileLimits caps the files read_file serves over the registry's lifetime at maxFiles
+// (0 is unlimited) and bounds each file read by readTimeout (0 disables). Once the cap is
+// reached, read_file answers with a FileBudgetExhaustedError result.
+func (r *Registry) WithFileLimits(maxFiles int, readTimeout time.Duration) *Registry {
+	r.readFile.budget = nil
+	if maxFiles > 0 {
+		r.readFile.budget = &fileBudget{max: maxFiles}
+	}
+	r.readFile.readTimeout = readTimeout
+	return r
+}
+
+// FileBudgetExceeded reports whether read_file refused a request because the file cap
+// set by WithFileLimits was reached.
+func (r *Registry) FileBudgetExceeded() bool {
+	return r.readFile.budget != nil && r.readFile.budget.wasExceeded()
+}
+
+// WithRedactor sets a function applied to every textual tool result before it is
+// returned to the LLM. Unlike per-call sanitization, the LLM cannot turn it off.
+func (r *Registry) WithRedactor(redact func(string) string) *Registry {
+	r.redact = redact
+	return r
+}
+
 // Register adds a tool to the registry
 func (r *Registry) Register(t Tool) {
 	r.tools[t.Name()] = t
@@ -63,7 +92,31 @@ func (r *Registry) Execute(ctx context.Context, name string, params map[string]a
 	if !exists {
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
-	return tool.Execute(ctx, params, r.logArtifacts)
+	result, err := tool.Execute(ctx, params, r.logArtifacts)
+	if err != nil || r.redact == nil {
+		return result, err
+	}
+	return r.redactResult(result), nil
+}
+
+// redactResult applies the redactor to string results and to the string values of
+// map results, such as the per-file contents of a multi-file read.
+func (r *Registry) redactResult(result any) any {
+	switch v := result.(type) {
+	case string:
+		return r.redact(v)
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, value := range v {
+			if s, ok := value.(string); ok {
+				value = r.redact(s)
+			}
+			redacted[key] = value
+		}
+		return redacted
+	default:
+		return result
+	}
 }
 
 // HandleToolCall processes a function call and returns the appropriate content
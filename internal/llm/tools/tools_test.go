@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/internal/aggregator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WithRedactor(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first.log")
+	second := filepath.Join(tmpDir, "second.log")
+	require.NoError(t, os.WriteFile(first, []byte("node worker-a-secret ready\n"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("cluster secret-id\n"), 0o644))
+
+	registry := NewRegistry([]aggregator.LogEntry{{Source: first}, {Source: second}}).
+		WithRedactor(func(s string) string { return strings.ReplaceAll(s, "secret", "[REDACTED]") })
+	ctx := context.Background()
+
+	// The redactor applies even when the LLM disables sanitization
+	result, err := registry.Execute(ctx, "read_file", map[string]any{
+		"sanitize": false,
+		"files":    []any{map[string]any{"path": first}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1\tnode worker-a-[REDACTED] ready", result)
+
+	result, err = registry.Execute(ctx, "read_file", map[string]any{
+		"files": []any{map[string]any{"path": first}, map[string]any{"path": second}},
+	})
+	require.NoError(t, err)
+	files, ok := result.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "1\tnode worker-a-[REDACTED] ready", files[first])
+	assert.Equal(t, "1\tcluster [REDACTED]-id", files[second])
+}
+
+func TestRegistry_WithFileLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	var logs []aggregator.LogEntry
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name+"\n"), 0o644))
+		logs = append(logs, aggregator.LogEntry{Source: path})
+	}
+	read := func(paths ...string) map[string]any {
+		files := make([]any, 0, len(paths))
+		for _, path := range paths {
+			files = append(files, map[string]any{"path": path})
+		}
+		return map[string]any{"files": files}
+	}
+
+	registry := NewRegistry(logs).WithFileLimits(2, time.Minute)
+	ctx := context.Background()
+
+	result, err := registry.Execute(ctx, "read_file", read(logs[0].Source))
+	require.NoError(t, err)
+	assert.Equal(t, "1\ta.log", result)
+	assert.False(t, registry.FileBudgetExceeded())
+
+	// A request larger than the remaining budget is refused as a whole
+	result, err = registry.Execute(ctx, "read_file", read(logs[1].Source, logs[2].Source))
+	require.NoError(t, err)
+	refusal, ok := result.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, FileBudgetExhaustedError, refusal["error"])
+	assert.Equal(t, 1, refusal["files_remaining"])
+	assert.True(t, registry.FileBudgetExceeded())
+
+	result, err = registry.Execute(ctx, "read_file", read(logs[1].Source))
+	require.NoError(t, err)
+	assert.Equal(t, "1\tb.log", result)
+
+	result, err = registry.Execute(ctx, "read_file", read(logs[2].Source))
+	require.NoError(t, err)
+	assert.Equal(t, FileBudgetExhaustedError, result.(map[string]any)["error"])
+
+	// Without limits every request is served
+	registry = NewRegistry(logs)
+	for range 5 {
+		_, err := registry.Execute(ctx, "read_file", read(logs[0].Source))
+		require.NoError(t, err)
+	}
+	assert.False(t, registry.FileBudgetExceeded())
+}
@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"path/filepath"
 	"strings"
+	"sync"
 	"text/template"
 
 	"google.golang.org/genai"
@@ -35,8 +36,27 @@ type PromptTemplate struct {
 	UserPrompt   string `yaml:"user_prompt"`
 }
 
+// PromptStore holds prompt templates and caches their parsed form, so repeated renders
+// only bind data. It is safe for concurrent use.
 type PromptStore struct {
+	mu        sync.RWMutex
 	templates map[string]*PromptTemplate
+	compiled  map[string]*compiledTemplate
+	hits      int
+	misses    int
+}
+
+// compiledTemplate is the parsed form of a PromptTemplate.
+type compiledTemplate struct {
+	system *template.Template
+	user   *template.Template
+}
+
+// CacheStats reports how often RenderPrompt reused a parsed template.
+type CacheStats struct {
+	Hits    int // Renders that reused a cached template
+	Misses  int // Renders that had to parse the template
+	Entries int // Templates currently cached
 }
 
 // NewPromptStore creates a new prompt store loading templates from the provided filesystem.
@@ -44,12 +64,16 @@ type PromptStore struct {
 func NewPromptStore(templatesFS fs.FS) (*PromptStore, error) {
 	store := &PromptStore{
 		templates: make(map[string]*PromptTemplate),
+		compiled:  make(map[string]*compiledTemplate),
 	}
 
 	return store, store.loadTemplates(templatesFS)
 }
 
 func (ps *PromptStore) loadTemplates(filesystem fs.FS) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
 	return fs.WalkDir(filesystem, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".yaml") {
 			return err
@@ -67,6 +91,7 @@ func (ps *PromptStore) loadTemplates(filesystem fs.FS) error {
 
 		id := strings.TrimSuffix(filepath.Base(path), ".yaml")
 		ps.templates[id] = &template
+		delete(ps.compiled, id)
 		return nil
 	})
 }
@@ -78,6 +103,8 @@ func (ps *PromptStore) RegisterTemplates(templatesFS fs.FS) error {
 }
 
 func (ps *PromptStore) GetTemplate(id string) (*PromptTemplate, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
 	template, exists := ps.templates[id]
 	if !exists {
 		return nil, fmt.Errorf("template %s not found", id)
@@ -86,17 +113,17 @@ func (ps *PromptStore) GetTemplate(id string) (*PromptTemplate, error) {
 }
 
 func (ps *PromptStore) RenderPrompt(templateID string, variables map[string]any) (userPrompt string, config *llm.AnalysisConfig, err error) {
-	template, err := ps.GetTemplate(templateID)
+	compiled, err := ps.compiledTemplate(templateID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	systemPrompt, err := template.render(template.SystemPrompt, variables)
+	systemPrompt, err := execute(compiled.system, variables)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to render system prompt: %w", err)
 	}
 
-	userPrompt, err = template.render(template.UserPrompt, variables)
+	userPrompt, err = execute(compiled.user, variables)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to render user prompt: %w", err)
 	}
@@ -111,12 +138,45 @@ func (ps *PromptStore) RenderPrompt(templateID string, variables map[string]any)
 	return userPrompt, config, nil
 }
 
-func (pt *PromptTemplate) render(promptText string, variables map[string]any) (string, error) {
-	tmpl, err := template.New("prompt").Parse(promptText)
+// CacheStats returns the parsed-template cache counters.
+func (ps *PromptStore) CacheStats() CacheStats {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return CacheStats{Hits: ps.hits, Misses: ps.misses, Entries: len(ps.compiled)}
+}
+
+// compiledTemplate returns the parsed template for id, parsing and caching it on first use.
+func (ps *PromptStore) compiledTemplate(id string) (*compiledTemplate, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if compiled, ok := ps.compiled[id]; ok {
+		ps.hits++
+		return compiled, nil
+	}
+
+	pt, exists := ps.templates[id]
+	if !exists {
+		return nil, fmt.Errorf("template %s not found", id)
+	}
+	ps.misses++
+
+	system, err := template.New("prompt").Parse(pt.SystemPrompt)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to render system prompt: %w", err)
 	}
+	user, err := template.New("prompt").Parse(pt.UserPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render user prompt: %w", err)
+	}
+
+	compiled := &compiledTemplate{system: system, user: user}
+	ps.compiled[id] = compiled
+	return compiled, nil
+}
 
+// execute binds variables to a parsed template. Parsed templates may be executed concurrently.
+func execute(tmpl *template.Template, variables map[string]any) (string, error) {
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, variables); err != nil {
 		return "", err
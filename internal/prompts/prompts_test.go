@@ -1,6 +1,7 @@
 package prompts
 
 import (
+	"sync"
 	"testing"
 	"testing/fstest"
 
@@ -106,3 +107,32 @@ func TestRenderPrompt(t *testing.T) {
 	assert.NotNil(t, config.TopP)
 	assert.NotNil(t, config.MaxTokens)
 }
+
+func TestRenderPrompt_CachesCompiledTemplate(t *testing.T) {
+	store, err := NewPromptStore(fstest.MapFS{
+		"greet.yaml": &fstest.MapFile{Data: []byte("system_prompt: \"system\"\nuser_prompt: \"hello {{.Name}}\"\n")},
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			userPrompt, _, err := store.RenderPrompt("greet", map[string]any{"Name": "krkn"})
+			assert.NoError(t, err)
+			assert.Equal(t, "hello krkn", userPrompt)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, CacheStats{Hits: 9, Misses: 1, Entries: 1}, store.CacheStats())
+
+	// Re-registering a template invalidates its cached form
+	require.NoError(t, store.RegisterTemplates(fstest.MapFS{
+		"greet.yaml": &fstest.MapFile{Data: []byte("system_prompt: \"system\"\nuser_prompt: \"bye {{.Name}}\"\n")},
+	}))
+	userPrompt, _, err := store.RenderPrompt("greet", map[string]any{"Name": "krkn"})
+	require.NoError(t, err)
+	assert.Equal(t, "bye krkn", userPrompt)
+	assert.Equal(t, CacheStats{Hits: 9, Misses: 2, Entries: 1}, store.CacheStats())
+}
@@ -38,6 +38,12 @@ const (
 	// KrknAIModeRun is the mode for run mode
 	KrknAIModeRun = "run"
 
+	// KrknAIModeResume continues an interrupted run from its last generation checkpoint
+	KrknAIModeResume = "resume"
+
+	// KrknAIModeKrkn runs a fixed krkn scenario list once, without the genetic algorithm
+	KrknAIModeKrkn = "krkn"
+
 	// KrknAIVerboseLevel is the verbosity level for krkn-ai output
 	KrknAIVerboseLevel = "2"
 
@@ -598,6 +604,14 @@ var LogAnalysis = struct {
 	// Env: GEMINI_API_KEY
 	APIKey string
 
+	// Provider selects the LLM backend: gemini or anthropic
+	// Env: LLM_PROVIDER
+	Provider string
+
+	// AnthropicAPIKey is the API key used when Provider is anthropic
+	// Env: ANTHROPIC_API_KEY
+	AnthropicAPIKey string
+
 	// Model specifies which LLM model to use
 	// Env: LLM_MODEL
 	Model string
@@ -609,15 +623,30 @@ var LogAnalysis = struct {
 	// SlackChannel is the default Slack channel for OSDE2E notifications
 	// Env: LOG_ANALYSIS_SLACK_CHANNEL
 	SlackChannel string
+
+	// ProxyURL is the proxy LLM API requests are sent through
+	// Env: LLM_PROXY_URL
+	ProxyURL string
+
+	// CABundle is a PEM file of extra CA certificates trusted for LLM API requests
+	// Env: LLM_CA_BUNDLE
+	CABundle string
 }{
-	EnableAnalysis: "logAnalysis.enableAnalysis",
-	APIKey:         "logAnalysis.apiKey",
-	Model:          "logAnalysis.model",
-	SlackWebhook:   "logAnalysis.slackWebhook",
-	SlackChannel:   "logAnalysis.slackChannel",
+	EnableAnalysis:  "logAnalysis.enableAnalysis",
+	APIKey:          "logAnalysis.apiKey",
+	Provider:        "logAnalysis.provider",
+	AnthropicAPIKey: "logAnalysis.anthropicAPIKey",
+	Model:           "logAnalysis.model",
+	SlackWebhook:    "logAnalysis.slackWebhook",
+	SlackChannel:    "logAnalysis.slackChannel",
+	ProxyURL:        "logAnalysis.proxyURL",
+	CABundle:        "logAnalysis.caBundle",
 }
 
-// KrknAI config keys for Kraken AI chaos testing.
+// KrknAI config keys for Kraken AI chaos testing. Parameters noted as templated, and the
+// string values of the overlay and discovered krkn-ai.yaml, may reference the target
+// cluster's {{.ClusterID}}, {{.ClusterVersion}}, {{.BaseDomain}}, {{.AppsDomain}},
+// {{.ConsoleURL}} and {{.APIURL}}, resolved from the cluster before they are applied.
 var KrknAI = struct {
 	// Namespace is the target namespace for chaos testing
 	// Env: KRKN_NAMESPACE
@@ -635,7 +664,7 @@ var KrknAI = struct {
 	// Env: KRKN_SKIP_POD_NAME
 	SkipPodName string
 
-	// FitnessQuery is the Prometheus query for the fitness function
+	// FitnessQuery is the Prometheus query for the fitness function (templated)
 	// Env: KRKN_FITNESS_QUERY
 	FitnessQuery string
 
@@ -651,24 +680,511 @@ var KrknAI = struct {
 	// Env: KRKN_POPULATION
 	Population string
 
-	// HealthCheck is a comma-separated list of health check endpoints in name=url format
+	// HealthCheck is a comma-separated list of health check endpoints in name=url format, each
+	// optionally followed by ;status_code=N;timeout=N;interval=N. Entries update the discovered
+	// application with the same name or are added as new ones. Templated, e.g.
+	// console=https://console-openshift-console.{{.AppsDomain}}.
 	// Env: KRKN_HEALTH_CHECK
 	HealthCheck string
 
+	// HealthCheckApps is a YAML or JSON list of health check applications, each with name,
+	// url, and optional status_code, timeout and interval, applied like HealthCheck entries.
+	// An application may not also be named in HealthCheck. Templated; quote templated values.
+	// Env: KRKN_HEALTH_CHECK_APPS
+	HealthCheckApps string
+
+	// HealthCheckDiscoveryNamespaces is a comma-separated list of namespaces whose Routes and
+	// Ingresses are added as health check applications, e.g. openshift-console,openshift-monitoring.
+	// Endpoints that don't answer with a 2xx status are skipped (empty disables discovery)
+	// Env: KRKN_HEALTH_CHECK_DISCOVERY_NAMESPACES
+	HealthCheckDiscoveryNamespaces string
+
 	// TopScenariosCount is the number of top scenarios to include in analysis
 	// Env: KRKN_TOP_SCENARIOS_COUNT
 	TopScenariosCount string
+
+	// BottomScenariosCount is the number of lowest-fitness scenarios to include in analysis (0 disables)
+	// Env: KRKN_BOTTOM_SCENARIOS_COUNT
+	BottomScenariosCount string
+
+	// PromptTemplatePath is a prompt template file, or a directory containing krknai.yaml,
+	// that replaces the built-in analysis prompt
+	// Env: KRKN_PROMPT_TEMPLATE_PATH
+	PromptTemplatePath string
+
+	// DisableAllScenarios disables every scenario in the discovered config
+	// Env: KRKN_DISABLE_ALL_SCENARIOS
+	DisableAllScenarios string
+
+	// AllowNoScenarios permits writing a config with no scenarios enabled
+	// Env: KRKN_ALLOW_NO_SCENARIOS
+	AllowNoScenarios string
+
+	// OverlayPath is a partial krkn-ai.yaml merged into the discovered config before the
+	// krkn-ai parameters are applied, so parameters win over overlay values (empty disables).
+	// Its string values are templated; quote values that start with a template.
+	// Env: KRKN_OVERLAY_PATH
+	OverlayPath string
+
+	// Overrides sets arbitrary krkn-ai.yaml fields as comma-separated dotted path=value
+	// pairs, e.g. fitness_function.type=weighted,scenario.syn_flood.enable=true. They are
+	// applied over the overlay, and dedicated krkn-ai parameters still win (empty disables).
+	// Templated.
+	// Env: KRKN_OVERRIDES
+	Overrides string
+
+	// ConfigBackupPath saves the discovered config before it is updated: a directory gets a
+	// timestamped krkn-ai-<time>.yaml, any other path is written as-is (empty disables)
+	// Env: KRKN_CONFIG_BACKUP_PATH
+	ConfigBackupPath string
+
+	// ConfigHistory keeps a timestamped copy of every merged config in the config-history
+	// directory next to krkn-ai.yaml, with an index.yaml listing the parameters applied to each
+	// Env: KRKN_CONFIG_HISTORY
+	ConfigHistory string
+
+	// WriteDiff writes a unified diff of the discovered vs updated config to krkn-ai.diff
+	// Env: KRKN_WRITE_DIFF
+	WriteDiff string
+
+	// ConfigDryRun logs the diff the updater would apply to krkn-ai.yaml without writing it, then skips run mode
+	// Env: KRKN_CONFIG_DRY_RUN
+	ConfigDryRun string
+
+	// IncludeHealthCheckFailure sets fitness_function.include_health_check_failure (empty keeps the discovered value)
+	// Env: KRKN_INCLUDE_HEALTH_CHECK_FAILURE
+	IncludeHealthCheckFailure string
+
+	// IncludeHealthCheckResponseTime sets fitness_function.include_health_check_response_time (empty keeps the discovered value)
+	// Env: KRKN_INCLUDE_HEALTH_CHECK_RESPONSE_TIME
+	IncludeHealthCheckResponseTime string
+
+	// IncludeKrknFailure sets fitness_function.include_krkn_failure (empty keeps the discovered value)
+	// Env: KRKN_INCLUDE_KRKN_FAILURE
+	IncludeKrknFailure string
+
+	// FitnessItems is a YAML or JSON list of weighted fitness_function.items, each with a
+	// query, weight, and optionally a name, type (point or range), and direction (increase or
+	// decrease, the way the metric moves when the cluster is hurt). Combining items scores
+	// scenarios on several metrics at once, e.g. API latency, etcd leader changes, and pod
+	// restarts. Items update the discovered item with the same name or are added; the weights
+	// must sum to 1. Templated.
+	// Env: KRKN_FITNESS_ITEMS
+	FitnessItems string
+
+	// FitnessItemsReplace drops the discovered fitness_function.items before FitnessItems are applied
+	// Env: KRKN_FITNESS_ITEMS_REPLACE
+	FitnessItemsReplace string
+
+	// MinGenerations is the fewest generations considered a useful run; fewer logs a warning
+	// Env: KRKN_MIN_GENERATIONS
+	MinGenerations string
+
+	// StrictValidation turns krkn-ai config warnings, such as MinGenerations, into errors
+	// Env: KRKN_STRICT_VALIDATION
+	StrictValidation string
+
+	// HealthCheckProbeConcurrency is the number of health check pre-flight probes run at once
+	// Env: KRKN_HEALTH_CHECK_PROBE_CONCURRENCY
+	HealthCheckProbeConcurrency string
+
+	// HealthCheckProbeDeadline bounds the whole health check pre-flight, e.g. "1m" (0 disables)
+	// Env: KRKN_HEALTH_CHECK_PROBE_DEADLINE
+	HealthCheckProbeDeadline string
+
+	// GenericScenarios is a comma-separated list of scenario entries to write by name, as name or
+	// name=true|false, for scenario types krkn-ai added after the discovered config was generated
+	// Env: KRKN_GENERIC_SCENARIOS
+	GenericScenarios string
+
+	// EnableApplicationOutages sets scenario.application_outages.enable (empty keeps the discovered value)
+	// Env: KRKN_ENABLE_APPLICATION_OUTAGES
+	EnableApplicationOutages string
+
+	// EnableSynFlood sets scenario.syn_flood.enable (empty keeps the discovered value)
+	// Env: KRKN_ENABLE_SYN_FLOOD
+	EnableSynFlood string
+
+	// ScenarioParams is a comma-separated list of per-scenario settings in scenario.param=value
+	// form, e.g. node_cpu_hog.duration=60,pod_scenarios.kill_count=2. Scenarios are not enabled
+	// by setting their parameters (empty keeps the discovered values). Templated.
+	// Env: KRKN_SCENARIO_PARAMS
+	ScenarioParams string
+
+	// MutationRate sets mutation_rate, a probability from 0 to 1 (empty keeps the discovered value)
+	// Env: KRKN_MUTATION_RATE
+	MutationRate string
+
+	// ScenarioMutationRate sets scenario_mutation_rate, a probability from 0 to 1 (empty keeps the discovered value)
+	// Env: KRKN_SCENARIO_MUTATION_RATE
+	ScenarioMutationRate string
+
+	// CrossoverRate sets crossover_rate, a probability from 0 to 1 (empty keeps the discovered value)
+	// Env: KRKN_CROSSOVER_RATE
+	CrossoverRate string
+
+	// PopulationInjectionRate sets population_injection_rate, a probability from 0 to 1 (empty keeps the discovered value)
+	// Env: KRKN_POPULATION_INJECTION_RATE
+	PopulationInjectionRate string
+
+	// PopulationInjectionSize sets population_injection_size, a non-negative count (empty keeps the discovered value)
+	// Env: KRKN_POPULATION_INJECTION_SIZE
+	PopulationInjectionSize string
+
+	// LLMCacheEnabled reuses cached LLM responses for identical prompts instead of calling the provider
+	// Env: KRKN_LLM_CACHE
+	LLMCacheEnabled string
+
+	// LLMCacheDir is where cached LLM responses are stored (default: the user cache directory)
+	// Env: KRKN_LLM_CACHE_DIR
+	LLMCacheDir string
+
+	// BaselineResultsDir is a previous run's results directory; when set, the analysis
+	// compares this run against it and focuses on what changed
+	// Env: KRKN_BASELINE_RESULTS_DIR
+	BaselineResultsDir string
+
+	// Clusters runs krkn-ai against several clusters at once instead of the provisioned one: a
+	// YAML or JSON list of name, cluster_id, and kubeconfig, e.g. [{name: fleet-1, kubeconfig: /kube/1}]
+	// (empty disables). Each cluster's results go to a subdirectory of the report directory.
+	// Env: KRKN_CLUSTERS
+	Clusters string
+
+	// ClusterConcurrency is how many of the Clusters run at once
+	// Env: KRKN_CLUSTER_CONCURRENCY
+	ClusterConcurrency string
+
+	// Profile selects a named bundle of krkn-ai parameters: smoke, nightly, or weekend-deep.
+	// Its values replace the parameter defaults, so parameters set individually still win
+	// Env: KRKN_AI_PROFILE
+	Profile string
+
+	// PreRunHooks are steps run before the krkn-ai run, e.g. to deploy a sample workload: a YAML
+	// or JSON list of hooks with a name and either a shell command or a Go function registered
+	// with krknai.RegisterHook, plus optional timeout (default 10m) and continue_on_error, e.g.
+	// [{name: sample-app, command: oc apply -f app.yaml}]. A failed hook skips the run. Each hook
+	// gets a directory under hooks/ in the report directory, where hooks/results.yaml lists the outcomes.
+	// Env: KRKN_PRE_RUN_HOOKS
+	PreRunHooks string
+
+	// PostRunHooks are steps run after the krkn-ai run, even a failed one, e.g. to capture a
+	// must-gather. They take the same form as PreRunHooks.
+	// Env: KRKN_POST_RUN_HOOKS
+	PostRunHooks string
+
+	// SensitiveParams adds comma-separated parameter names to the ones whose values are masked
+	// in the executor logs and the config backup, diff, and history copies. A name matches any
+	// parameter containing it, case-insensitively; token, password, passwd, secret, auth,
+	// api_key, apikey, credential, and private_key are always masked, as are URL credentials.
+	// Env: KRKN_SENSITIVE_PARAMS
+	SensitiveParams string
+
+	// KubeconfigSource keeps the kubeconfig fresh during the run for runs that outlive its
+	// credentials: "ocm" fetches new credentials for the cluster from the cluster provider,
+	// "file:<path>" re-reads a kubeconfig rotated by something else. Empty uses the shared
+	// kubeconfig as is.
+	// Env: KRKN_KUBECONFIG_SOURCE
+	KubeconfigSource string
+
+	// KubeconfigRefreshInterval is how often the kubeconfig is refreshed from KubeconfigSource,
+	// e.g. "1h"; credentials that expire sooner are refreshed 15 minutes before they do
+	// Env: KRKN_KUBECONFIG_REFRESH_INTERVAL
+	KubeconfigRefreshInterval string
+
+	// ReadinessGate refuses to start run mode while the cluster is degraded: nodes not ready,
+	// cluster operators unavailable, progressing or degraded, or too many pending pods
+	// Env: KRKN_READINESS_GATE
+	ReadinessGate string
+
+	// ReadinessTimeout is how long the readiness gate waits for a degraded cluster to recover,
+	// e.g. "15m" (0 checks once)
+	// Env: KRKN_READINESS_TIMEOUT
+	ReadinessTimeout string
+
+	// ReadinessMaxPendingPods is how many pods may be pending for over a minute before the
+	// readiness gate considers the cluster degraded
+	// Env: KRKN_READINESS_MAX_PENDING_PODS
+	ReadinessMaxPendingPods string
+
+	// MaxRunDuration stops a krkn-ai run mode container that is still running after this
+	// long, e.g. "6h" (0 disables); the analysis still runs on the partial results
+	// Env: KRKN_MAX_RUN_DURATION
+	MaxRunDuration string
+
+	// RunRetries is how many times a failed krkn-ai container run is retried when its output
+	// shows a transient failure such as an image pull or API server connection error (0 disables)
+	// Env: KRKN_RUN_RETRIES
+	RunRetries string
+
+	// RunRetryBackoff is the delay before the first retry, doubled on each subsequent retry, e.g. "30s"
+	// Env: KRKN_RUN_RETRY_BACKOFF
+	RunRetryBackoff string
+
+	// RunRetryPatterns is a comma-separated list of extra container output fragments that
+	// mark a failure as transient, matched case-insensitively
+	// Env: KRKN_RUN_RETRY_PATTERNS
+	RunRetryPatterns string
+
+	// AnalysisTimeout bounds the whole log analysis run, e.g. "15m" (0 disables)
+	// Env: KRKN_ANALYSIS_TIMEOUT
+	AnalysisTimeout string
+
+	// AnalysisCoolDown reuses the prior analysis of unchanged results within this window instead
+	// of calling the LLM again, e.g. "1h" (0 disables)
+	// Env: KRKN_ANALYSIS_COOL_DOWN
+	AnalysisCoolDown string
+
+	// MaxArtifactReads caps the log artifacts the LLM may read during the analysis (0 is unlimited)
+	// Env: KRKN_MAX_ARTIFACT_READS
+	MaxArtifactReads string
+
+	// ArtifactReadTimeout bounds each log artifact read by the LLM, e.g. "30s" (0 disables)
+	// Env: KRKN_ARTIFACT_READ_TIMEOUT
+	ArtifactReadTimeout string
+
+	// PromptTokenCost is the LLM price per million prompt tokens, used to estimate the analysis cost
+	// Env: KRKN_PROMPT_TOKEN_COST
+	PromptTokenCost string
+
+	// CompletionTokenCost is the LLM price per million completion tokens, used to estimate the analysis cost
+	// Env: KRKN_COMPLETION_TOKEN_COST
+	CompletionTokenCost string
+
+	// FitnessThreshold fails the run when the analyzed max fitness score exceeds it (empty disables)
+	// Env: KRKN_FITNESS_THRESHOLD
+	FitnessThreshold string
+
+	// MaxFailedScenarios fails the run when more scenarios than this fail (empty disables)
+	// Env: KRKN_MAX_FAILED_SCENARIOS
+	MaxFailedScenarios string
+
+	// HealthCheckSuccessThreshold fails the run when an application's health check success
+	// rate falls below it, e.g. "0.95" (0 disables)
+	// Env: KRKN_HEALTH_CHECK_SUCCESS_THRESHOLD
+	HealthCheckSuccessThreshold string
+
+	// IncludeNamespaces is a comma-separated list of namespace glob patterns to keep in the
+	// discovered cluster_components (empty keeps all)
+	// Env: KRKN_INCLUDE_NAMESPACES
+	IncludeNamespaces string
+
+	// ExcludeNamespaces is a comma-separated list of namespace glob patterns, e.g. "openshift-*",
+	// to drop from the discovered cluster_components; excludes win over includes
+	// Env: KRKN_EXCLUDE_NAMESPACES
+	ExcludeNamespaces string
+
+	// IncludeNamespaceRegex keeps discovered cluster_components namespaces matching this regular
+	// expression, in addition to IncludeNamespaces, e.g. "^robot-shop$" (empty disables)
+	// Env: KRKN_INCLUDE_NAMESPACE_REGEX
+	IncludeNamespaceRegex string
+
+	// ExcludeNamespaceRegex drops discovered cluster_components namespaces matching this regular
+	// expression, in addition to ExcludeNamespaces, e.g. "^(openshift|kube)-"; excludes win over includes
+	// Env: KRKN_EXCLUDE_NAMESPACE_REGEX
+	ExcludeNamespaceRegex string
+
+	// NodeSelector is a label selector that scopes the discovered cluster_components nodes,
+	// e.g. "node-role.kubernetes.io/worker"
+	// Env: KRKN_NODE_SELECTOR
+	NodeSelector string
+
+	// NodeRoles keeps only discovered cluster_components nodes carrying one of these
+	// comma-separated roles (node-role.kubernetes.io/<role> labels), e.g. "worker,infra",
+	// so node hog scenarios stay off control-plane nodes. Combined with NodeSelector.
+	// Env: KRKN_NODE_ROLES
+	NodeRoles string
+
+	// Scope is where chaos may land: "cluster", or "namespace:<name>" for runs against shared
+	// clusters, which discovers targets in that namespace only, drops nodes from the
+	// discovered cluster_components, and disables node scenarios. In krkn mode it rejects
+	// scenario types that act on nodes or the whole cluster.
+	// Env: KRKN_SCOPE
+	Scope string
+
+	// Mode is the krkn-ai execution mode: run (discover, then run), resume, which skips
+	// discovery and continues the interrupted run in the report directory from its last
+	// generation checkpoint with the current parameters merged into its config, or krkn,
+	// which runs ScenarioList with plain krkn instead of the genetic algorithm
+	// Env: KRKN_MODE
+	Mode string
+
+	// ScenarioList is the krkn mode scenario list: a YAML or JSON list of krkn scenario types
+	// and scenario files, run in order, e.g. [{type: pod_disruption_scenarios, file: /scenarios/etcd.yml}]
+	// Env: KRKN_SCENARIO_LIST
+	ScenarioList string
+
+	// KrknImage is the container image krkn mode runs
+	// Env: KRKN_KRKN_IMAGE
+	KrknImage string
+
+	// SkipDiscovery generates krkn-ai.yaml from the krkn-ai parameters instead of running discover mode
+	// Env: KRKN_SKIP_DISCOVERY
+	SkipDiscovery string
+
+	// Preflight runs the preflight checks against the discovered config and cluster before run mode
+	// Env: KRKN_PREFLIGHT
+	Preflight string
+
+	// PreflightSkip is a comma-separated list of preflight checks to skip: params, config, cluster,
+	// health_checks, fitness_query
+	// Env: KRKN_PREFLIGHT_SKIP
+	PreflightSkip string
+
+	// FitnessQueryCheck runs FitnessQuery against the cluster's Prometheus (through the Thanos
+	// querier) before run mode, failing when it's invalid or returns no series. It runs with or
+	// without the preflight checks.
+	// Env: KRKN_FITNESS_QUERY_CHECK
+	FitnessQueryCheck string
+
+	// ScenarioRules is what happens to enabled scenarios the discovered cluster topology can't
+	// safely run, e.g. node hogs on fewer than 3 workers: "disable" (default) turns them off,
+	// "error" fails the run with guidance, and "off" skips the rules
+	// Env: KRKN_SCENARIO_RULES
+	ScenarioRules string
+
+	// AbortQuery is a PromQL condition that stops the run when it holds, written like an alert
+	// expression, e.g. an API server 5xx ratio "> 0.2". It holds while it returns a non-zero
+	// sample; partial results are kept and the run is recorded as aborted. Templated.
+	// Env: KRKN_ABORT_QUERY
+	AbortQuery string
+
+	// AbortOnDegradedOperators stops the run when a cluster operator stays degraded
+	// (Tests.OperatorSkip is honored)
+	// Env: KRKN_ABORT_ON_DEGRADED_OPERATORS
+	AbortOnDegradedOperators string
+
+	// AbortFor is how long an abort condition must hold before the run is stopped, e.g. "10m"
+	// (0 stops it as soon as one holds)
+	// Env: KRKN_ABORT_FOR
+	AbortFor string
+
+	// AbortPollInterval is how often the abort conditions are checked during the run
+	// Env: KRKN_ABORT_POLL_INTERVAL
+	AbortPollInterval string
+
+	// AbortRemediationHooks are steps run after an aborted run, before the post-run hooks, e.g.
+	// to roll back a workload. They take the same form as PreRunHooks.
+	// Env: KRKN_ABORT_REMEDIATION_HOOKS
+	AbortRemediationHooks string
+
+	// Backend is where krkn-ai runs: "container" (default) runs the image with podman or
+	// docker, "local" runs a krkn-ai installation on this machine, and "job" runs the image
+	// as a Kubernetes Job, e.g. for air-gapped hosts or CI pods without a container runtime.
+	// The job backend can't resume runs and doesn't see kubeconfig rotations.
+	// Env: KRKN_BACKEND
+	Backend string
+
+	// LocalBinary is the krkn-ai command the local backend runs; it gets the arguments and
+	// environment variables the image's entrypoint would
+	// Env: KRKN_LOCAL_BINARY
+	LocalBinary string
+
+	// LocalKrknBinary is the krkn command the local backend runs in krkn mode
+	// Env: KRKN_LOCAL_KRKN_BINARY
+	LocalKrknBinary string
+
+	// BackendKubeconfig is the kubeconfig of the cluster the job backend runs its Job in, e.g. a
+	// CI cluster driving the target; empty runs the Job in the target cluster
+	// Env: KRKN_BACKEND_KUBECONFIG
+	BackendKubeconfig string
+
+	// MetricsAddress is the host:port the krkn-ai command serves Prometheus metrics on at
+	// /metrics while it runs, e.g. :9090 (empty disables)
+	// Env: KRKN_METRICS_ADDRESS
+	MetricsAddress string
 }{
-	Namespace:         "krknAI.namespace",
-	PodLabel:          "krknAI.podLabel",
-	NodeLabel:         "krknAI.nodeLabel",
-	SkipPodName:       "krknAI.skipPodName",
-	FitnessQuery:      "krknAI.fitnessQuery",
-	Scenarios:         "krknAI.scenarios",
-	Generations:       "krknAI.generations",
-	Population:        "krknAI.population",
-	HealthCheck:       "krknAI.healthCheck",
-	TopScenariosCount: "krknAI.topScenariosCount",
+	Namespace:                      "krknAI.namespace",
+	PodLabel:                       "krknAI.podLabel",
+	NodeLabel:                      "krknAI.nodeLabel",
+	SkipPodName:                    "krknAI.skipPodName",
+	FitnessQuery:                   "krknAI.fitnessQuery",
+	Scenarios:                      "krknAI.scenarios",
+	Generations:                    "krknAI.generations",
+	Population:                     "krknAI.population",
+	HealthCheck:                    "krknAI.healthCheck",
+	HealthCheckApps:                "krknAI.healthCheckApps",
+	HealthCheckDiscoveryNamespaces: "krknAI.healthCheckDiscoveryNamespaces",
+	TopScenariosCount:              "krknAI.topScenariosCount",
+	BottomScenariosCount:           "krknAI.bottomScenariosCount",
+	PromptTemplatePath:             "krknAI.promptTemplatePath",
+	DisableAllScenarios:            "krknAI.disableAllScenarios",
+	AllowNoScenarios:               "krknAI.allowNoScenarios",
+	OverlayPath:                    "krknAI.overlayPath",
+	Overrides:                      "krknAI.overrides",
+	ConfigBackupPath:               "krknAI.configBackupPath",
+	ConfigHistory:                  "krknAI.configHistory",
+	WriteDiff:                      "krknAI.writeDiff",
+	ConfigDryRun:                   "krknAI.configDryRun",
+	IncludeHealthCheckFailure:      "krknAI.includeHealthCheckFailure",
+	IncludeHealthCheckResponseTime: "krknAI.includeHealthCheckResponseTime",
+	IncludeKrknFailure:             "krknAI.includeKrknFailure",
+	FitnessItems:                   "krknAI.fitnessItems",
+	FitnessItemsReplace:            "krknAI.fitnessItemsReplace",
+	MinGenerations:                 "krknAI.minGenerations",
+	StrictValidation:               "krknAI.strictValidation",
+	HealthCheckProbeConcurrency:    "krknAI.healthCheckProbeConcurrency",
+	HealthCheckProbeDeadline:       "krknAI.healthCheckProbeDeadline",
+	GenericScenarios:               "krknAI.genericScenarios",
+	EnableApplicationOutages:       "krknAI.enableApplicationOutages",
+	EnableSynFlood:                 "krknAI.enableSynFlood",
+	ScenarioParams:                 "krknAI.scenarioParams",
+	MutationRate:                   "krknAI.mutationRate",
+	ScenarioMutationRate:           "krknAI.scenarioMutationRate",
+	CrossoverRate:                  "krknAI.crossoverRate",
+	PopulationInjectionRate:        "krknAI.populationInjectionRate",
+	PopulationInjectionSize:        "krknAI.populationInjectionSize",
+	LLMCacheEnabled:                "krknAI.llmCacheEnabled",
+	LLMCacheDir:                    "krknAI.llmCacheDir",
+	BaselineResultsDir:             "krknAI.baselineResultsDir",
+	Clusters:                       "krknAI.clusters",
+	ClusterConcurrency:             "krknAI.clusterConcurrency",
+	Profile:                        "krknAI.profile",
+	PreRunHooks:                    "krknAI.preRunHooks",
+	PostRunHooks:                   "krknAI.postRunHooks",
+	SensitiveParams:                "krknAI.sensitiveParams",
+	KubeconfigSource:               "krknAI.kubeconfigSource",
+	KubeconfigRefreshInterval:      "krknAI.kubeconfigRefreshInterval",
+	ReadinessGate:                  "krknAI.readinessGate",
+	ReadinessTimeout:               "krknAI.readinessTimeout",
+	ReadinessMaxPendingPods:        "krknAI.readinessMaxPendingPods",
+	MaxRunDuration:                 "krknAI.maxRunDuration",
+	RunRetries:                     "krknAI.runRetries",
+	RunRetryBackoff:                "krknAI.runRetryBackoff",
+	RunRetryPatterns:               "krknAI.runRetryPatterns",
+	AnalysisTimeout:                "krknAI.analysisTimeout",
+	AnalysisCoolDown:               "krknAI.analysisCoolDown",
+	MaxArtifactReads:               "krknAI.maxArtifactReads",
+	ArtifactReadTimeout:            "krknAI.artifactReadTimeout",
+	PromptTokenCost:                "krknAI.promptTokenCost",
+	CompletionTokenCost:            "krknAI.completionTokenCost",
+	FitnessThreshold:               "krknAI.fitnessThreshold",
+	MaxFailedScenarios:             "krknAI.maxFailedScenarios",
+	HealthCheckSuccessThreshold:    "krknAI.healthCheckSuccessThreshold",
+	IncludeNamespaces:              "krknAI.includeNamespaces",
+	ExcludeNamespaces:              "krknAI.excludeNamespaces",
+	IncludeNamespaceRegex:          "krknAI.includeNamespaceRegex",
+	ExcludeNamespaceRegex:          "krknAI.excludeNamespaceRegex",
+	NodeSelector:                   "krknAI.nodeSelector",
+	NodeRoles:                      "krknAI.nodeRoles",
+	Scope:                          "krknAI.scope",
+	Mode:                           "krknAI.mode",
+	ScenarioList:                   "krknAI.scenarioList",
+	KrknImage:                      "krknAI.krknImage",
+	SkipDiscovery:                  "krknAI.skipDiscovery",
+	Preflight:                      "krknAI.preflight",
+	PreflightSkip:                  "krknAI.preflightSkip",
+	FitnessQueryCheck:              "krknAI.fitnessQueryCheck",
+	ScenarioRules:                  "krknAI.scenarioRules",
+	AbortQuery:                     "krknAI.abortQuery",
+	AbortOnDegradedOperators:       "krknAI.abortOnDegradedOperators",
+	AbortFor:                       "krknAI.abortFor",
+	AbortPollInterval:              "krknAI.abortPollInterval",
+	AbortRemediationHooks:          "krknAI.abortRemediationHooks",
+	Backend:                        "krknAI.backend",
+	LocalBinary:                    "krknAI.localBinary",
+	LocalKrknBinary:                "krknAI.localKrknBinary",
+	BackendKubeconfig:              "krknAI.backendKubeconfig",
+	MetricsAddress:                 "krknAI.metricsAddress",
 }
 
 func InitOSDe2eViper() {
@@ -941,6 +1457,12 @@ func InitOSDe2eViper() {
 	_ = viper.BindEnv(LogAnalysis.APIKey, "GEMINI_API_KEY")
 	RegisterSecret(LogAnalysis.APIKey, "gemini-api-key")
 
+	viper.SetDefault(LogAnalysis.Provider, "gemini")
+	_ = viper.BindEnv(LogAnalysis.Provider, "LLM_PROVIDER")
+
+	_ = viper.BindEnv(LogAnalysis.AnthropicAPIKey, "ANTHROPIC_API_KEY")
+	RegisterSecret(LogAnalysis.AnthropicAPIKey, "anthropic-api-key")
+
 	viper.SetDefault(LogAnalysis.Model, "gemini-2.5-pro")
 	_ = viper.BindEnv(LogAnalysis.Model, "LLM_MODEL")
 
@@ -951,6 +1473,12 @@ func InitOSDe2eViper() {
 	viper.SetDefault(LogAnalysis.SlackChannel, defaultNotificationsChannel)
 	_ = viper.BindEnv(LogAnalysis.SlackChannel, "LOG_ANALYSIS_SLACK_CHANNEL")
 
+	viper.SetDefault(LogAnalysis.ProxyURL, "")
+	_ = viper.BindEnv(LogAnalysis.ProxyURL, "LLM_PROXY_URL")
+
+	viper.SetDefault(LogAnalysis.CABundle, "")
+	_ = viper.BindEnv(LogAnalysis.CABundle, "LLM_CA_BUNDLE")
+
 	// ----- KrknAI Configuration -----
 	viper.SetDefault(KrknAI.Namespace, "default")
 	_ = viper.BindEnv(KrknAI.Namespace, "KRKN_NAMESPACE")
@@ -979,8 +1507,254 @@ func InitOSDe2eViper() {
 	viper.SetDefault(KrknAI.HealthCheck, "")
 	_ = viper.BindEnv(KrknAI.HealthCheck, "KRKN_HEALTH_CHECK")
 
+	viper.SetDefault(KrknAI.HealthCheckApps, "")
+	_ = viper.BindEnv(KrknAI.HealthCheckApps, "KRKN_HEALTH_CHECK_APPS")
+
+	viper.SetDefault(KrknAI.HealthCheckDiscoveryNamespaces, "")
+	_ = viper.BindEnv(KrknAI.HealthCheckDiscoveryNamespaces, "KRKN_HEALTH_CHECK_DISCOVERY_NAMESPACES")
+
 	viper.SetDefault(KrknAI.TopScenariosCount, 10)
 	_ = viper.BindEnv(KrknAI.TopScenariosCount, "KRKN_TOP_SCENARIOS_COUNT")
+
+	viper.SetDefault(KrknAI.BottomScenariosCount, 0)
+	_ = viper.BindEnv(KrknAI.BottomScenariosCount, "KRKN_BOTTOM_SCENARIOS_COUNT")
+
+	viper.SetDefault(KrknAI.PromptTemplatePath, "")
+	_ = viper.BindEnv(KrknAI.PromptTemplatePath, "KRKN_PROMPT_TEMPLATE_PATH")
+
+	viper.SetDefault(KrknAI.DisableAllScenarios, false)
+	_ = viper.BindEnv(KrknAI.DisableAllScenarios, "KRKN_DISABLE_ALL_SCENARIOS")
+
+	viper.SetDefault(KrknAI.AllowNoScenarios, false)
+	_ = viper.BindEnv(KrknAI.AllowNoScenarios, "KRKN_ALLOW_NO_SCENARIOS")
+
+	viper.SetDefault(KrknAI.OverlayPath, "")
+	_ = viper.BindEnv(KrknAI.OverlayPath, "KRKN_OVERLAY_PATH")
+
+	viper.SetDefault(KrknAI.Overrides, "")
+	_ = viper.BindEnv(KrknAI.Overrides, "KRKN_OVERRIDES")
+
+	viper.SetDefault(KrknAI.ConfigBackupPath, "")
+	_ = viper.BindEnv(KrknAI.ConfigBackupPath, "KRKN_CONFIG_BACKUP_PATH")
+
+	viper.SetDefault(KrknAI.ConfigHistory, false)
+	_ = viper.BindEnv(KrknAI.ConfigHistory, "KRKN_CONFIG_HISTORY")
+
+	viper.SetDefault(KrknAI.WriteDiff, false)
+	_ = viper.BindEnv(KrknAI.WriteDiff, "KRKN_WRITE_DIFF")
+
+	viper.SetDefault(KrknAI.ConfigDryRun, false)
+	_ = viper.BindEnv(KrknAI.ConfigDryRun, "KRKN_CONFIG_DRY_RUN")
+
+	viper.SetDefault(KrknAI.IncludeHealthCheckFailure, "")
+	_ = viper.BindEnv(KrknAI.IncludeHealthCheckFailure, "KRKN_INCLUDE_HEALTH_CHECK_FAILURE")
+
+	viper.SetDefault(KrknAI.IncludeHealthCheckResponseTime, "")
+	_ = viper.BindEnv(KrknAI.IncludeHealthCheckResponseTime, "KRKN_INCLUDE_HEALTH_CHECK_RESPONSE_TIME")
+
+	viper.SetDefault(KrknAI.IncludeKrknFailure, "")
+	_ = viper.BindEnv(KrknAI.IncludeKrknFailure, "KRKN_INCLUDE_KRKN_FAILURE")
+
+	viper.SetDefault(KrknAI.FitnessItems, "")
+	_ = viper.BindEnv(KrknAI.FitnessItems, "KRKN_FITNESS_ITEMS")
+
+	viper.SetDefault(KrknAI.FitnessItemsReplace, false)
+	_ = viper.BindEnv(KrknAI.FitnessItemsReplace, "KRKN_FITNESS_ITEMS_REPLACE")
+
+	viper.SetDefault(KrknAI.MinGenerations, 2)
+	_ = viper.BindEnv(KrknAI.MinGenerations, "KRKN_MIN_GENERATIONS")
+
+	viper.SetDefault(KrknAI.StrictValidation, false)
+	_ = viper.BindEnv(KrknAI.StrictValidation, "KRKN_STRICT_VALIDATION")
+
+	viper.SetDefault(KrknAI.HealthCheckProbeConcurrency, 8)
+	_ = viper.BindEnv(KrknAI.HealthCheckProbeConcurrency, "KRKN_HEALTH_CHECK_PROBE_CONCURRENCY")
+
+	viper.SetDefault(KrknAI.HealthCheckProbeDeadline, "1m")
+	_ = viper.BindEnv(KrknAI.HealthCheckProbeDeadline, "KRKN_HEALTH_CHECK_PROBE_DEADLINE")
+
+	viper.SetDefault(KrknAI.GenericScenarios, "")
+	_ = viper.BindEnv(KrknAI.GenericScenarios, "KRKN_GENERIC_SCENARIOS")
+
+	viper.SetDefault(KrknAI.EnableApplicationOutages, "")
+	_ = viper.BindEnv(KrknAI.EnableApplicationOutages, "KRKN_ENABLE_APPLICATION_OUTAGES")
+
+	viper.SetDefault(KrknAI.EnableSynFlood, "")
+	_ = viper.BindEnv(KrknAI.EnableSynFlood, "KRKN_ENABLE_SYN_FLOOD")
+
+	viper.SetDefault(KrknAI.ScenarioParams, "")
+	_ = viper.BindEnv(KrknAI.ScenarioParams, "KRKN_SCENARIO_PARAMS")
+
+	viper.SetDefault(KrknAI.MutationRate, "")
+	_ = viper.BindEnv(KrknAI.MutationRate, "KRKN_MUTATION_RATE")
+
+	viper.SetDefault(KrknAI.ScenarioMutationRate, "")
+	_ = viper.BindEnv(KrknAI.ScenarioMutationRate, "KRKN_SCENARIO_MUTATION_RATE")
+
+	viper.SetDefault(KrknAI.CrossoverRate, "")
+	_ = viper.BindEnv(KrknAI.CrossoverRate, "KRKN_CROSSOVER_RATE")
+
+	viper.SetDefault(KrknAI.PopulationInjectionRate, "")
+	_ = viper.BindEnv(KrknAI.PopulationInjectionRate, "KRKN_POPULATION_INJECTION_RATE")
+
+	viper.SetDefault(KrknAI.PopulationInjectionSize, "")
+	_ = viper.BindEnv(KrknAI.PopulationInjectionSize, "KRKN_POPULATION_INJECTION_SIZE")
+
+	viper.SetDefault(KrknAI.LLMCacheEnabled, false)
+	_ = viper.BindEnv(KrknAI.LLMCacheEnabled, "KRKN_LLM_CACHE")
+
+	viper.SetDefault(KrknAI.LLMCacheDir, "")
+	_ = viper.BindEnv(KrknAI.LLMCacheDir, "KRKN_LLM_CACHE_DIR")
+
+	viper.SetDefault(KrknAI.BaselineResultsDir, "")
+	_ = viper.BindEnv(KrknAI.BaselineResultsDir, "KRKN_BASELINE_RESULTS_DIR")
+
+	viper.SetDefault(KrknAI.Clusters, "")
+	_ = viper.BindEnv(KrknAI.Clusters, "KRKN_CLUSTERS")
+
+	viper.SetDefault(KrknAI.ClusterConcurrency, 2)
+	_ = viper.BindEnv(KrknAI.ClusterConcurrency, "KRKN_CLUSTER_CONCURRENCY")
+
+	viper.SetDefault(KrknAI.Profile, "")
+	_ = viper.BindEnv(KrknAI.Profile, "KRKN_AI_PROFILE")
+
+	viper.SetDefault(KrknAI.PreRunHooks, "")
+	_ = viper.BindEnv(KrknAI.PreRunHooks, "KRKN_PRE_RUN_HOOKS")
+
+	viper.SetDefault(KrknAI.PostRunHooks, "")
+	_ = viper.BindEnv(KrknAI.PostRunHooks, "KRKN_POST_RUN_HOOKS")
+
+	viper.SetDefault(KrknAI.SensitiveParams, "")
+	_ = viper.BindEnv(KrknAI.SensitiveParams, "KRKN_SENSITIVE_PARAMS")
+
+	viper.SetDefault(KrknAI.KubeconfigSource, "")
+	_ = viper.BindEnv(KrknAI.KubeconfigSource, "KRKN_KUBECONFIG_SOURCE")
+
+	viper.SetDefault(KrknAI.KubeconfigRefreshInterval, "1h")
+	_ = viper.BindEnv(KrknAI.KubeconfigRefreshInterval, "KRKN_KUBECONFIG_REFRESH_INTERVAL")
+
+	viper.SetDefault(KrknAI.ReadinessGate, false)
+	_ = viper.BindEnv(KrknAI.ReadinessGate, "KRKN_READINESS_GATE")
+
+	viper.SetDefault(KrknAI.ReadinessTimeout, "0")
+	_ = viper.BindEnv(KrknAI.ReadinessTimeout, "KRKN_READINESS_TIMEOUT")
+
+	viper.SetDefault(KrknAI.ReadinessMaxPendingPods, 0)
+	_ = viper.BindEnv(KrknAI.ReadinessMaxPendingPods, "KRKN_READINESS_MAX_PENDING_PODS")
+
+	viper.SetDefault(KrknAI.MaxRunDuration, "0")
+	_ = viper.BindEnv(KrknAI.MaxRunDuration, "KRKN_MAX_RUN_DURATION")
+
+	viper.SetDefault(KrknAI.RunRetries, 0)
+	_ = viper.BindEnv(KrknAI.RunRetries, "KRKN_RUN_RETRIES")
+
+	viper.SetDefault(KrknAI.RunRetryBackoff, "30s")
+	_ = viper.BindEnv(KrknAI.RunRetryBackoff, "KRKN_RUN_RETRY_BACKOFF")
+
+	viper.SetDefault(KrknAI.RunRetryPatterns, "")
+	_ = viper.BindEnv(KrknAI.RunRetryPatterns, "KRKN_RUN_RETRY_PATTERNS")
+
+	viper.SetDefault(KrknAI.AnalysisTimeout, "0")
+	_ = viper.BindEnv(KrknAI.AnalysisTimeout, "KRKN_ANALYSIS_TIMEOUT")
+
+	viper.SetDefault(KrknAI.AnalysisCoolDown, "0")
+	_ = viper.BindEnv(KrknAI.AnalysisCoolDown, "KRKN_ANALYSIS_COOL_DOWN")
+
+	viper.SetDefault(KrknAI.MaxArtifactReads, 0)
+	_ = viper.BindEnv(KrknAI.MaxArtifactReads, "KRKN_MAX_ARTIFACT_READS")
+
+	viper.SetDefault(KrknAI.ArtifactReadTimeout, "0")
+	_ = viper.BindEnv(KrknAI.ArtifactReadTimeout, "KRKN_ARTIFACT_READ_TIMEOUT")
+
+	viper.SetDefault(KrknAI.PromptTokenCost, 0.0)
+	_ = viper.BindEnv(KrknAI.PromptTokenCost, "KRKN_PROMPT_TOKEN_COST")
+
+	viper.SetDefault(KrknAI.CompletionTokenCost, 0.0)
+	_ = viper.BindEnv(KrknAI.CompletionTokenCost, "KRKN_COMPLETION_TOKEN_COST")
+
+	viper.SetDefault(KrknAI.FitnessThreshold, "")
+	_ = viper.BindEnv(KrknAI.FitnessThreshold, "KRKN_FITNESS_THRESHOLD")
+
+	viper.SetDefault(KrknAI.MaxFailedScenarios, "")
+	_ = viper.BindEnv(KrknAI.MaxFailedScenarios, "KRKN_MAX_FAILED_SCENARIOS")
+
+	viper.SetDefault(KrknAI.HealthCheckSuccessThreshold, 0)
+	_ = viper.BindEnv(KrknAI.HealthCheckSuccessThreshold, "KRKN_HEALTH_CHECK_SUCCESS_THRESHOLD")
+
+	viper.SetDefault(KrknAI.IncludeNamespaces, "")
+	_ = viper.BindEnv(KrknAI.IncludeNamespaces, "KRKN_INCLUDE_NAMESPACES")
+
+	viper.SetDefault(KrknAI.ExcludeNamespaces, "")
+	_ = viper.BindEnv(KrknAI.ExcludeNamespaces, "KRKN_EXCLUDE_NAMESPACES")
+
+	viper.SetDefault(KrknAI.IncludeNamespaceRegex, "")
+	_ = viper.BindEnv(KrknAI.IncludeNamespaceRegex, "KRKN_INCLUDE_NAMESPACE_REGEX")
+
+	viper.SetDefault(KrknAI.ExcludeNamespaceRegex, "")
+	_ = viper.BindEnv(KrknAI.ExcludeNamespaceRegex, "KRKN_EXCLUDE_NAMESPACE_REGEX")
+
+	viper.SetDefault(KrknAI.NodeSelector, "")
+	_ = viper.BindEnv(KrknAI.NodeSelector, "KRKN_NODE_SELECTOR")
+
+	viper.SetDefault(KrknAI.NodeRoles, "")
+	_ = viper.BindEnv(KrknAI.NodeRoles, "KRKN_NODE_ROLES")
+
+	viper.SetDefault(KrknAI.Scope, "cluster")
+	_ = viper.BindEnv(KrknAI.Scope, "KRKN_SCOPE")
+
+	viper.SetDefault(KrknAI.Mode, KrknAIModeRun)
+	_ = viper.BindEnv(KrknAI.Mode, "KRKN_MODE")
+
+	viper.SetDefault(KrknAI.ScenarioList, "")
+	_ = viper.BindEnv(KrknAI.ScenarioList, "KRKN_SCENARIO_LIST")
+
+	viper.SetDefault(KrknAI.KrknImage, "quay.io/krkn-chaos/krkn:latest")
+	_ = viper.BindEnv(KrknAI.KrknImage, "KRKN_KRKN_IMAGE")
+
+	viper.SetDefault(KrknAI.SkipDiscovery, false)
+	_ = viper.BindEnv(KrknAI.SkipDiscovery, "KRKN_SKIP_DISCOVERY")
+
+	viper.SetDefault(KrknAI.Preflight, false)
+	_ = viper.BindEnv(KrknAI.Preflight, "KRKN_PREFLIGHT")
+
+	viper.SetDefault(KrknAI.PreflightSkip, "")
+	_ = viper.BindEnv(KrknAI.PreflightSkip, "KRKN_PREFLIGHT_SKIP")
+
+	viper.SetDefault(KrknAI.FitnessQueryCheck, true)
+	_ = viper.BindEnv(KrknAI.FitnessQueryCheck, "KRKN_FITNESS_QUERY_CHECK")
+
+	viper.SetDefault(KrknAI.ScenarioRules, "disable")
+	_ = viper.BindEnv(KrknAI.ScenarioRules, "KRKN_SCENARIO_RULES")
+
+	viper.SetDefault(KrknAI.AbortQuery, "")
+	_ = viper.BindEnv(KrknAI.AbortQuery, "KRKN_ABORT_QUERY")
+
+	viper.SetDefault(KrknAI.AbortOnDegradedOperators, false)
+	_ = viper.BindEnv(KrknAI.AbortOnDegradedOperators, "KRKN_ABORT_ON_DEGRADED_OPERATORS")
+
+	viper.SetDefault(KrknAI.AbortFor, "10m")
+	_ = viper.BindEnv(KrknAI.AbortFor, "KRKN_ABORT_FOR")
+
+	viper.SetDefault(KrknAI.AbortPollInterval, "30s")
+	_ = viper.BindEnv(KrknAI.AbortPollInterval, "KRKN_ABORT_POLL_INTERVAL")
+
+	viper.SetDefault(KrknAI.AbortRemediationHooks, "")
+	_ = viper.BindEnv(KrknAI.AbortRemediationHooks, "KRKN_ABORT_REMEDIATION_HOOKS")
+
+	viper.SetDefault(KrknAI.Backend, "container")
+	_ = viper.BindEnv(KrknAI.Backend, "KRKN_BACKEND")
+
+	viper.SetDefault(KrknAI.LocalBinary, "krkn-ai")
+	_ = viper.BindEnv(KrknAI.LocalBinary, "KRKN_LOCAL_BINARY")
+
+	viper.SetDefault(KrknAI.LocalKrknBinary, "krkn")
+	_ = viper.BindEnv(KrknAI.LocalKrknBinary, "KRKN_LOCAL_KRKN_BINARY")
+
+	viper.SetDefault(KrknAI.BackendKubeconfig, "")
+	_ = viper.BindEnv(KrknAI.BackendKubeconfig, "KRKN_BACKEND_KUBECONFIG")
+
+	viper.SetDefault(KrknAI.MetricsAddress, "")
+	_ = viper.BindEnv(KrknAI.MetricsAddress, "KRKN_METRICS_ADDRESS")
 }
 
 func init() {
@@ -1056,6 +1830,14 @@ func LoadClusterId() error {
 	return nil
 }
 
+// LogAnalysisAPIKey returns the API key for the configured log analysis LLM provider.
+func LogAnalysisAPIKey() string {
+	if viper.GetString(LogAnalysis.Provider) == "anthropic" {
+		return viper.GetString(LogAnalysis.AnthropicAPIKey)
+	}
+	return viper.GetString(LogAnalysis.APIKey)
+}
+
 // GetTestSuites returns test suites, supporting both new TestSuites and legacy AdHocTestImages formats.
 // Checks TestSuites first, then falls back to legacy AdHocTestImages string slice.
 func GetTestSuites() ([]TestSuite, error) {
@@ -16,7 +16,8 @@ const (
 
 // Client provides methods for interacting with Slack webhooks
 type Client struct {
-	timeout time.Duration
+	timeout    time.Duration
+	httpClient *http.Client
 }
 
 // NewClient creates a new Slack client with default settings
@@ -33,6 +34,15 @@ func NewClientWithTimeout(timeout time.Duration) *Client {
 	}
 }
 
+// NewClientWithHTTPClient creates a new Slack client that sends requests through the
+// given HTTP client, e.g. one configured for an egress proxy or mTLS
+func NewClientWithHTTPClient(httpClient *http.Client) *Client {
+	return &Client{
+		timeout:    DefaultTimeout,
+		httpClient: httpClient,
+	}
+}
+
 // SendWebhook sends a JSON payload to a Slack webhook URL
 // payload can be any struct that will be marshaled to JSON
 func (c *Client) SendWebhook(ctx context.Context, webhookURL string, payload interface{}) error {
@@ -52,9 +62,12 @@ func (c *Client) SendWebhook(ctx context.Context, webhookURL string, payload int
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("User-Agent", "osde2e/1.0")
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: c.timeout,
+	// Use the shared HTTP client if one was provided, otherwise create one with the timeout
+	client := c.httpClient
+	if client == nil {
+		client = &http.Client{
+			Timeout: c.timeout,
+		}
 	}
 
 	// Send request
@@ -71,13 +84,30 @@ func (c *Client) SendWebhook(ctx context.Context, webhookURL string, payload int
 		n, _ := resp.Body.Read(bodyBytes)
 		bodyText := string(bodyBytes[:n])
 
-		return fmt.Errorf("slack webhook returned status %d: %s\nResponse body: %s\nPayload sent: %s",
-			resp.StatusCode, resp.Status, bodyText, string(jsonData))
+		return &WebhookError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       bodyText,
+			Payload:    string(jsonData),
+		}
 	}
 
 	return nil
 }
 
+// WebhookError is returned when a webhook responds with an unsuccessful status
+type WebhookError struct {
+	StatusCode int
+	Status     string
+	Body       string
+	Payload    string
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook returned status %d: %s\nResponse body: %s\nPayload sent: %s",
+		e.StatusCode, e.Status, e.Body, e.Payload)
+}
+
 // SendMessage is a convenience method to send a simple text message to Slack
 func (c *Client) SendMessage(ctx context.Context, webhookURL string, text string) error {
 	msg := text
@@ -0,0 +1,149 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// Reporter delivers an analysis result to a notification backend.
+type Reporter interface {
+	Name() string
+	Report(ctx context.Context, result *AnalysisResult, config *ReporterConfig) error
+}
+
+// PayloadRenderer is implemented by reporters that can show the payload they would
+// send, for dry runs. A nil payload means the reporter would skip the result.
+type PayloadRenderer interface {
+	RenderPayload(result *AnalysisResult, config *ReporterConfig) ([]byte, error)
+}
+
+// httpClientSetter is implemented by reporters that deliver notifications over HTTP.
+type httpClientSetter interface {
+	SetHTTPClient(httpClient *http.Client)
+}
+
+// ReporterRegistry maps reporter types to their implementations.
+type ReporterRegistry struct {
+	mu         sync.RWMutex
+	reporters  map[string]Reporter
+	httpClient *http.Client // Shared by HTTP-based reporters when set
+}
+
+// NewReporterRegistry creates a registry with the built-in reporters registered.
+func NewReporterRegistry() *ReporterRegistry {
+	r := &ReporterRegistry{
+		reporters: make(map[string]Reporter),
+	}
+	r.Register(NewSlackReporter())
+	r.Register(NewWebhookReporter())
+	return r
+}
+
+// WithHTTPClient sets a shared HTTP client on every HTTP-based reporter, including
+// reporters registered later. Reporters use their own default client when unset.
+func (r *ReporterRegistry) WithHTTPClient(httpClient *http.Client) *ReporterRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.httpClient = httpClient
+	for _, reporter := range r.reporters {
+		if setter, ok := reporter.(httpClientSetter); ok {
+			setter.SetHTTPClient(httpClient)
+		}
+	}
+	return r
+}
+
+// Register adds a reporter under its Name, replacing any reporter of the same type.
+func (r *ReporterRegistry) Register(reporter Reporter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if setter, ok := reporter.(httpClientSetter); ok && r.httpClient != nil {
+		setter.SetHTTPClient(r.httpClient)
+	}
+	r.reporters[reporter.Name()] = reporter
+}
+
+// Get returns the reporter registered for the given type.
+func (r *ReporterRegistry) Get(reporterType string) (Reporter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reporter, ok := r.reporters[reporterType]
+	return reporter, ok
+}
+
+// RegisteredReporters returns the sorted types of all registered reporters.
+func (r *ReporterRegistry) RegisteredReporters() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.reporters))
+	for reporterType := range r.reporters {
+		types = append(types, reporterType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// SendNotification delivers the result to every enabled reporter in the config,
+// retrying transient failures according to each reporter's RetryPolicy.
+// A failing reporter does not prevent the remaining reporters from running.
+func (r *ReporterRegistry) SendNotification(ctx context.Context, result *AnalysisResult, config *NotificationConfig) error {
+	var errs *multierror.Error
+	for _, res := range r.Notify(ctx, result, config) {
+		if res.Error != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s reporter: %w", res.Type, res.Error))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// Notify is SendNotification reporting the outcome of each enabled reporter, in config
+// order. With DryRun set, reporters render and log their payload instead of sending it.
+func (r *ReporterRegistry) Notify(ctx context.Context, result *AnalysisResult, config *NotificationConfig) []ReporterResult {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+
+	var results []ReporterResult
+	for i := range config.Reporters {
+		reporterConfig := &config.Reporters[i]
+		if !reporterConfig.Enabled {
+			continue
+		}
+
+		var err error
+		if reporter, ok := r.Get(reporterConfig.Type); !ok {
+			err = fmt.Errorf("unknown reporter type %q", reporterConfig.Type)
+		} else if config.DryRun {
+			err = dryRun(reporter, result, reporterConfig)
+		} else {
+			err = reportWithRetry(ctx, reporter, result, reporterConfig)
+		}
+		results = append(results, ReporterResult{Type: reporterConfig.Type, Success: err == nil, Error: err})
+	}
+	return results
+}
+
+// dryRun logs the payload reporter would send for result.
+func dryRun(reporter Reporter, result *AnalysisResult, config *ReporterConfig) error {
+	renderer, ok := reporter.(PayloadRenderer)
+	if !ok {
+		log.Printf("%s reporter (dry run): payload preview not supported", config.Type)
+		return nil
+	}
+	payload, err := renderer.RenderPayload(result, config)
+	if err != nil {
+		return err
+	}
+	if payload == nil {
+		log.Printf("%s reporter (dry run): result would be skipped", config.Type)
+		return nil
+	}
+	log.Printf("%s reporter (dry run): would send %s", config.Type, payload)
+	return nil
+}
@@ -0,0 +1,188 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeReporter struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (f *fakeReporter) Name() string { return f.name }
+
+func (f *fakeReporter) Report(ctx context.Context, result *AnalysisResult, config *ReporterConfig) error {
+	f.calls++
+	return f.err
+}
+
+func TestReporterRegistry_RegisteredReporters(t *testing.T) {
+	registry := NewReporterRegistry()
+
+	got := registry.RegisteredReporters()
+	if strings.Join(got, ",") != "slack,webhook" {
+		t.Fatalf("expected default reporters [slack webhook], got %v", got)
+	}
+
+	registry.Register(&fakeReporter{name: "email"})
+	registry.Register(&fakeReporter{name: "slack"})
+
+	got = registry.RegisteredReporters()
+	if strings.Join(got, ",") != "email,slack,webhook" {
+		t.Errorf("expected sorted reporters [email slack webhook], got %v", got)
+	}
+}
+
+func TestReporterRegistry_SendNotification(t *testing.T) {
+	registry := &ReporterRegistry{reporters: make(map[string]Reporter)}
+	ok := &fakeReporter{name: "ok"}
+	failing := &fakeReporter{name: "failing", err: errors.New("boom")}
+	disabled := &fakeReporter{name: "disabled"}
+	registry.Register(ok)
+	registry.Register(failing)
+	registry.Register(disabled)
+
+	config := &NotificationConfig{
+		Enabled: true,
+		Reporters: []ReporterConfig{
+			{Type: "failing", Enabled: true},
+			{Type: "ok", Enabled: true},
+			{Type: "disabled", Enabled: false},
+			{Type: "missing", Enabled: true},
+		},
+	}
+
+	err := registry.SendNotification(context.Background(), &AnalysisResult{}, config)
+	if err == nil {
+		t.Fatal("expected an error from the failing and unknown reporters")
+	}
+	if !strings.Contains(err.Error(), "boom") || !strings.Contains(err.Error(), `unknown reporter type "missing"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if ok.calls != 1 || failing.calls != 1 {
+		t.Errorf("expected enabled reporters to be called once, got ok=%d failing=%d", ok.calls, failing.calls)
+	}
+	if disabled.calls != 0 {
+		t.Errorf("expected disabled reporter to be skipped, got %d calls", disabled.calls)
+	}
+
+	config.Enabled = false
+	if err := registry.SendNotification(context.Background(), &AnalysisResult{}, config); err != nil {
+		t.Errorf("expected no error when notifications are disabled, got %v", err)
+	}
+}
+
+func TestReporterRegistry_Notify(t *testing.T) {
+	registry := &ReporterRegistry{reporters: make(map[string]Reporter)}
+	ok := &fakeReporter{name: "ok"}
+	failing := &fakeReporter{name: "failing", err: errors.New("boom")}
+	registry.Register(ok)
+	registry.Register(failing)
+
+	config := &NotificationConfig{
+		Enabled: true,
+		Reporters: []ReporterConfig{
+			{Type: "failing", Enabled: true},
+			{Type: "ok", Enabled: true},
+			{Type: "missing", Enabled: true},
+		},
+	}
+
+	results := registry.Notify(context.Background(), &AnalysisResult{}, config)
+	if len(results) != 3 {
+		t.Fatalf("expected a result per enabled reporter, got %+v", results)
+	}
+	if results[0].Type != "failing" || results[0].Success || results[0].Error == nil {
+		t.Errorf("expected the failing reporter to report its error, got %+v", results[0])
+	}
+	if results[1].Type != "ok" || !results[1].Success || results[1].Error != nil {
+		t.Errorf("expected the ok reporter to succeed after the failure, got %+v", results[1])
+	}
+	if results[2].Type != "missing" || results[2].Success {
+		t.Errorf("expected the unknown reporter to fail, got %+v", results[2])
+	}
+}
+
+func TestReporterRegistry_NotifyDryRun(t *testing.T) {
+	transport := &countingTransport{}
+	registry := NewReporterRegistry().WithHTTPClient(&http.Client{Transport: transport})
+	fake := &fakeReporter{name: "fake"}
+	registry.Register(fake)
+
+	config := &NotificationConfig{
+		Enabled: true,
+		DryRun:  true,
+		Reporters: []ReporterConfig{
+			{Type: "slack", Enabled: true, Settings: map[string]interface{}{"webhook_url": "https://hooks.example.com", "channel": "C123"}},
+			{Type: "webhook", Enabled: true, Settings: map[string]interface{}{}},
+			{Type: "fake", Enabled: true},
+		},
+	}
+
+	results := registry.Notify(context.Background(), &AnalysisResult{Status: "success"}, config)
+	if len(results) != 3 {
+		t.Fatalf("expected a result per enabled reporter, got %+v", results)
+	}
+	if !results[0].Success {
+		t.Errorf("expected the slack payload to render, got %v", results[0].Error)
+	}
+	if results[1].Success || results[1].Error == nil || !strings.Contains(results[1].Error.Error(), "url is required") {
+		t.Errorf("expected the webhook reporter's missing url to fail the dry run, got %+v", results[1])
+	}
+	if !results[2].Success {
+		t.Errorf("expected a reporter without payload preview to pass the dry run, got %v", results[2].Error)
+	}
+	if transport.calls != 0 || fake.calls != 0 {
+		t.Errorf("expected nothing to be sent in a dry run, got %d requests and %d reports", transport.calls, fake.calls)
+	}
+}
+
+type countingTransport struct {
+	calls int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestReporterRegistry_WithHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	registry := NewReporterRegistry().WithHTTPClient(&http.Client{Transport: transport})
+
+	config := &NotificationConfig{
+		Enabled: true,
+		Reporters: []ReporterConfig{{
+			Type:     "slack",
+			Enabled:  true,
+			Settings: map[string]interface{}{"webhook_url": server.URL, "channel": "C123"},
+		}},
+	}
+
+	if err := registry.SendNotification(context.Background(), &AnalysisResult{}, config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transport.calls != 1 {
+		t.Errorf("expected the shared HTTP client to be used once, got %d", transport.calls)
+	}
+
+	// Reporters registered after the client was set also use it
+	registry.Register(NewSlackReporter())
+	if err := registry.SendNotification(context.Background(), &AnalysisResult{}, config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transport.calls != 2 {
+		t.Errorf("expected a newly registered reporter to use the shared HTTP client, got %d calls", transport.calls)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,6 +42,11 @@ func NewSlackReporter() *SlackReporter {
 	}
 }
 
+// SetHTTPClient routes the reporter's webhook requests through the given HTTP client
+func (s *SlackReporter) SetHTTPClient(httpClient *http.Client) {
+	s.client = NewClientWithHTTPClient(httpClient)
+}
+
 // Name returns the reporter identifier
 func (s *SlackReporter) Name() string {
 	return "slack"
@@ -52,9 +58,9 @@ func (s *SlackReporter) Report(ctx context.Context, result *AnalysisResult, conf
 		return nil
 	}
 
-	webhookURL, ok := config.Settings["webhook_url"].(string)
-	if !ok || webhookURL == "" {
-		return fmt.Errorf("webhook_url is required and must be a string")
+	webhookURL, err := s.webhookURL(config)
+	if err != nil {
+		return err
 	}
 
 	payload := s.buildWorkflowPayload(result, config)
@@ -66,6 +72,26 @@ func (s *SlackReporter) Report(ctx context.Context, result *AnalysisResult, conf
 	return nil
 }
 
+// RenderPayload returns the JSON workflow payload Report would send.
+func (s *SlackReporter) RenderPayload(result *AnalysisResult, config *ReporterConfig) ([]byte, error) {
+	if _, err := s.webhookURL(config); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(s.buildWorkflowPayload(result, config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return payload, nil
+}
+
+func (s *SlackReporter) webhookURL(config *ReporterConfig) (string, error) {
+	webhookURL, ok := config.Settings["webhook_url"].(string)
+	if !ok || webhookURL == "" {
+		return "", fmt.Errorf("webhook_url is required and must be a string")
+	}
+	return webhookURL, nil
+}
+
 // WorkflowPayload represents the Slack workflow webhook payload
 type WorkflowPayload struct {
 	Channel        string `json:"channel"`
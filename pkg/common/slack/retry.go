@@ -0,0 +1,89 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultRetryPolicy is applied to reporters that do not configure their own policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     2 * time.Second,
+}
+
+// reportWithRetry calls the reporter until it succeeds, fails with a non-transient
+// error, or runs out of attempts.
+func reportWithRetry(ctx context.Context, reporter Reporter, result *AnalysisResult, config *ReporterConfig) error {
+	policy := DefaultRetryPolicy
+	if config.Retry != nil {
+		policy = *config.Retry
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	backoff := policy.Backoff
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		err = reporter.Report(ctx, result, config)
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("%s reporter succeeded on attempt %d/%d", config.Type, attempt, policy.MaxAttempts)
+			}
+			return nil
+		}
+
+		if !isTransient(err) {
+			log.Printf("%s reporter attempt %d/%d failed, not retrying: %v", config.Type, attempt, policy.MaxAttempts, err)
+			return err
+		}
+		if attempt == policy.MaxAttempts {
+			log.Printf("%s reporter attempt %d/%d failed, giving up: %v", config.Type, attempt, policy.MaxAttempts, err)
+			return err
+		}
+
+		log.Printf("%s reporter attempt %d/%d failed, retrying in %s: %v", config.Type, attempt, policy.MaxAttempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return err
+}
+
+// isTransient reports whether a reporter error is worth retrying: server-side
+// webhook failures, rate limiting, and network errors. Client errors such as a
+// malformed URL or a rejected webhook (4xx) are permanent.
+func isTransient(err error) bool {
+	var webhookErr *WebhookError
+	if errors.As(err, &webhookErr) {
+		return webhookErr.StatusCode >= http.StatusInternalServerError ||
+			webhookErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Timeout() {
+			return true
+		}
+		var netErr net.Error
+		return errors.As(urlErr.Err, &netErr) ||
+			errors.Is(urlErr.Err, io.EOF) ||
+			errors.Is(urlErr.Err, io.ErrUnexpectedEOF)
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newStatusServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		status := statuses[len(statuses)-1]
+		if int(n) <= len(statuses) {
+			status = statuses[n-1]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestSendNotification_RetriesTransientFailures(t *testing.T) {
+	server, calls := newStatusServer(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+
+	config := &NotificationConfig{
+		Enabled: true,
+		Reporters: []ReporterConfig{{
+			Type:     "slack",
+			Enabled:  true,
+			Settings: map[string]interface{}{"webhook_url": server.URL, "channel": "C123"},
+			Retry:    &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+		}},
+	}
+
+	if err := NewReporterRegistry().SendNotification(context.Background(), &AnalysisResult{}, config); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestSendNotification_GivesUpAfterMaxAttempts(t *testing.T) {
+	server, calls := newStatusServer(t, http.StatusServiceUnavailable)
+
+	config := &NotificationConfig{
+		Enabled: true,
+		Reporters: []ReporterConfig{{
+			Type:     "slack",
+			Enabled:  true,
+			Settings: map[string]interface{}{"webhook_url": server.URL, "channel": "C123"},
+			Retry:    &RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond},
+		}},
+	}
+
+	err := NewReporterRegistry().SendNotification(context.Background(), &AnalysisResult{}, config)
+	var webhookErr *WebhookError
+	if !errors.As(err, &webhookErr) || webhookErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 webhook error, got %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestSendNotification_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := newStatusServer(t, http.StatusUnauthorized)
+
+	config := &NotificationConfig{
+		Enabled: true,
+		Reporters: []ReporterConfig{{
+			Type:     "slack",
+			Enabled:  true,
+			Settings: map[string]interface{}{"webhook_url": server.URL, "channel": "C123"},
+			Retry:    &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+		}},
+	}
+
+	if err := NewReporterRegistry().SendNotification(context.Background(), &AnalysisResult{}, config); err == nil {
+		t.Fatal("expected an error for a 401 response")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected a single attempt for a 401, got %d", got)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &WebhookError{StatusCode: http.StatusInternalServerError}, true},
+		{"rate limited", &WebhookError{StatusCode: http.StatusTooManyRequests}, true},
+		{"unauthorized", &WebhookError{StatusCode: http.StatusUnauthorized}, false},
+		{"not found", &WebhookError{StatusCode: http.StatusNotFound}, false},
+		{"missing webhook", errors.New("webhook_url is required and must be a string"), false},
+		{"canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.want {
+				t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	// Malformed URLs fail before any request is made and must not be retried
+	err := NewClient().SendWebhook(context.Background(), "://bad-url", map[string]string{})
+	if err == nil || isTransient(err) {
+		t.Errorf("expected a non-transient error for a malformed URL, got %v", err)
+	}
+
+	// Connection failures are transient
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closedURL := server.URL
+	server.Close()
+	err = NewClient().SendWebhook(context.Background(), closedURL, map[string]string{})
+	if err == nil || !isTransient(err) {
+		t.Errorf("expected a transient error for a refused connection, got %v", err)
+	}
+}
@@ -1,5 +1,7 @@
 package slack
 
+import "time"
+
 // AnalysisResult represents the analysis output passed to reporters.
 type AnalysisResult struct {
 	Status   string         `json:"status"`
@@ -14,10 +16,26 @@ type ReporterConfig struct {
 	Type     string                 `json:"type" yaml:"type"`
 	Enabled  bool                   `json:"enabled" yaml:"enabled"`
 	Settings map[string]interface{} `json:"settings" yaml:"settings"`
+	Retry    *RetryPolicy           `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// RetryPolicy controls how often a reporter is retried after a transient failure.
+// A nil policy on a ReporterConfig falls back to DefaultRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts int           `json:"max_attempts" yaml:"max_attempts"` // Total attempts including the first (minimum 1)
+	Backoff     time.Duration `json:"backoff" yaml:"backoff"`           // Delay before the first retry, doubled on each subsequent retry
 }
 
 // NotificationConfig holds configuration for notification settings
 type NotificationConfig struct {
 	Enabled   bool             `json:"enabled" yaml:"enabled"`
+	DryRun    bool             `json:"dry_run" yaml:"dry_run"` // Log each reporter's payload instead of sending it
 	Reporters []ReporterConfig `json:"reporters" yaml:"reporters"`
 }
+
+// ReporterResult is the outcome of notifying one configured reporter.
+type ReporterResult struct {
+	Type    string
+	Success bool
+	Error   error
+}
@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// severityRank orders the severities recorded in result metadata for the min_severity setting.
+var severityRank = map[string]int{"ok": 0, "warning": 1, "critical": 2}
+
+// WebhookReporter implements Reporter by POSTing the analysis result as JSON to an
+// arbitrary endpoint, such as a PagerDuty or incident-tooling webhook.
+type WebhookReporter struct {
+	httpClient *http.Client
+}
+
+// NewWebhookReporter creates a new generic webhook reporter
+func NewWebhookReporter() *WebhookReporter {
+	return &WebhookReporter{}
+}
+
+// SetHTTPClient routes the reporter's requests through the given HTTP client
+func (w *WebhookReporter) SetHTTPClient(httpClient *http.Client) {
+	w.httpClient = httpClient
+}
+
+// Name returns the reporter identifier
+func (w *WebhookReporter) Name() string {
+	return "webhook"
+}
+
+// Report POSTs the result to the configured url. Settings:
+//   - url: endpoint to POST to (required)
+//   - headers: map of extra request headers, e.g. an Authorization token
+//   - min_severity: only report when the result's severity metadata is at least
+//     this level (ok, warning, critical)
+func (w *WebhookReporter) Report(ctx context.Context, result *AnalysisResult, config *ReporterConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	url, ok := config.Settings["url"].(string)
+	if !ok || url == "" {
+		return fmt.Errorf("url is required and must be a string")
+	}
+
+	body, err := w.RenderPayload(result, config)
+	if err != nil || body == nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", "osde2e/1.0")
+	if headers, ok := config.Settings["headers"].(map[string]interface{}); ok {
+		for name, value := range headers {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("header %q must be a string", name)
+			}
+			req.Header.Set(name, s)
+		}
+	}
+
+	client := w.httpClient
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Unlike Slack, webhook receivers such as PagerDuty answer with other 2xx codes
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &WebhookError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       string(respBody),
+			Payload:    string(body),
+		}
+	}
+	return nil
+}
+
+// RenderPayload returns the JSON body Report would POST, or nil when the result is
+// below min_severity.
+func (w *WebhookReporter) RenderPayload(result *AnalysisResult, config *ReporterConfig) ([]byte, error) {
+	if url, ok := config.Settings["url"].(string); !ok || url == "" {
+		return nil, fmt.Errorf("url is required and must be a string")
+	}
+
+	if minSeverity, ok := config.Settings["min_severity"].(string); ok && minSeverity != "" {
+		minRank, known := severityRank[minSeverity]
+		if !known {
+			return nil, fmt.Errorf("unknown min_severity %q (expected ok, warning or critical)", minSeverity)
+		}
+		severity, _ := result.Metadata["severity"].(string)
+		if rank, ok := severityRank[severity]; !ok || rank < minRank {
+			return nil, nil
+		}
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return body, nil
+}
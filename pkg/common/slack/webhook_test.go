@@ -0,0 +1,85 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookReporter_Report(t *testing.T) {
+	var received *AnalysisResult
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		received = &AnalysisResult{}
+		if err := json.NewDecoder(r.Body).Decode(received); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	reporter := NewWebhookReporter()
+	config := &ReporterConfig{
+		Type:    "webhook",
+		Enabled: true,
+		Settings: map[string]interface{}{
+			"url":          server.URL,
+			"headers":      map[string]interface{}{"Authorization": "Token abc"},
+			"min_severity": "warning",
+		},
+	}
+	result := &AnalysisResult{Status: "completed", Content: "analysis", Metadata: map[string]any{"severity": "critical"}}
+
+	if err := reporter.Report(context.Background(), result, config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received == nil || received.Content != "analysis" || received.Metadata["severity"] != "critical" {
+		t.Errorf("expected the result as the JSON body, got %+v", received)
+	}
+	if auth != "Token abc" {
+		t.Errorf("expected the configured header to be sent, got %q", auth)
+	}
+
+	// Results below min_severity, or without a severity, are not sent
+	received = nil
+	for _, metadata := range []map[string]any{{"severity": "ok"}, nil} {
+		if err := reporter.Report(context.Background(), &AnalysisResult{Metadata: metadata}, config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if received != nil {
+		t.Errorf("expected no request below min_severity, got %+v", received)
+	}
+}
+
+func TestWebhookReporter_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reporter := NewWebhookReporter()
+	ctx := context.Background()
+
+	err := reporter.Report(ctx, &AnalysisResult{}, &ReporterConfig{Enabled: true, Settings: map[string]interface{}{"url": server.URL}})
+	var webhookErr *WebhookError
+	if !errors.As(err, &webhookErr) || webhookErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected a WebhookError with status 503, got %v", err)
+	}
+	if !isTransient(err) {
+		t.Error("expected a 503 to be retried")
+	}
+
+	if err := reporter.Report(ctx, &AnalysisResult{}, &ReporterConfig{Enabled: true}); err == nil {
+		t.Error("expected an error when url is missing")
+	}
+
+	config := &ReporterConfig{Enabled: true, Settings: map[string]interface{}{"url": server.URL, "min_severity": "severe"}}
+	if err := reporter.Report(ctx, &AnalysisResult{}, config); err == nil {
+		t.Error("expected an error for an unknown min_severity")
+	}
+}
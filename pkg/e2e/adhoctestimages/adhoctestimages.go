@@ -168,7 +168,10 @@ func runLogAnalysisForAdHocTestImage(ctx context.Context, logger logr.Logger, te
 	engineConfig := &analysisengine.Config{
 		BaseConfig: analysisengine.BaseConfig{
 			ArtifactsDir: artifactsDir,
-			APIKey:       viper.GetString(config.LogAnalysis.APIKey),
+			APIKey:       config.LogAnalysisAPIKey(),
+			Provider:     viper.GetString(config.LogAnalysis.Provider),
+			ProxyURL:     viper.GetString(config.LogAnalysis.ProxyURL),
+			CABundlePath: viper.GetString(config.LogAnalysis.CABundle),
 		},
 		PromptTemplate: "default",
 		FailureContext: err.Error(),
@@ -232,7 +232,10 @@ func (o *E2EOrchestrator) AnalyzeLogs(ctx context.Context, testErr error) error
 	engineConfig := &analysisengine.Config{
 		BaseConfig: analysisengine.BaseConfig{
 			ArtifactsDir: reportDir,
-			APIKey:       viper.GetString(config.LogAnalysis.APIKey),
+			APIKey:       config.LogAnalysisAPIKey(),
+			Provider:     viper.GetString(config.LogAnalysis.Provider),
+			ProxyURL:     viper.GetString(config.LogAnalysis.ProxyURL),
+			CABundlePath: viper.GetString(config.LogAnalysis.CABundle),
 		},
 		PromptTemplate: "default",
 		FailureContext: testErr.Error(),
@@ -0,0 +1,225 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	"github.com/openshift/osde2e-common/pkg/clients/openshift"
+	"github.com/openshift/osde2e-common/pkg/clients/prometheus"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+// abortCheckTimeout bounds one check of an abort condition, client setup included.
+const abortCheckTimeout = time.Minute
+
+// abortGuardrails decide when a chaos run has degraded the cluster enough to stop it.
+type abortGuardrails struct {
+	query             string        // PromQL condition, breached while it returns a non-zero sample
+	degradedOperators bool          // Whether degraded cluster operators are a breach
+	holdFor           time.Duration // How long a condition must stay breached before the run is stopped
+	interval          time.Duration
+}
+
+// enabled reports whether any abort condition is set.
+func (g abortGuardrails) enabled() bool {
+	return g.query != "" || g.degradedOperators
+}
+
+// abortGuardrailsFromConfig builds the guardrails from the krkn-ai parameters.
+func abortGuardrailsFromConfig(ctx context.Context, templates *templateResolver) (abortGuardrails, error) {
+	query, err := templates.param(ctx, config.KrknAI.AbortQuery)
+	if err != nil {
+		return abortGuardrails{}, err
+	}
+	g := abortGuardrails{
+		query:             strings.TrimSpace(query),
+		degradedOperators: viper.GetBool(config.KrknAI.AbortOnDegradedOperators),
+	}
+	holdFor, err := time.ParseDuration(strings.TrimSpace(viper.GetString(config.KrknAI.AbortFor)))
+	if err != nil || holdFor < 0 {
+		return g, fmt.Errorf("invalid abort duration %q (expected a duration, e.g. 10m)", viper.GetString(config.KrknAI.AbortFor))
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(viper.GetString(config.KrknAI.AbortPollInterval)))
+	if err != nil || interval <= 0 {
+		return g, fmt.Errorf("invalid abort poll interval %q (expected a positive duration, e.g. 30s)", viper.GetString(config.KrknAI.AbortPollInterval))
+	}
+	g.holdFor = holdFor
+	g.interval = interval
+	return g, nil
+}
+
+// abortError is a run stopped because an abort condition held for too long.
+type abortError struct {
+	reason string
+}
+
+func (e *abortError) Error() string {
+	return "run aborted: " + e.reason
+}
+
+// isAborted reports whether err is, or wraps, a run stopped by the abort guardrails.
+func isAborted(err error) bool {
+	var abortErr *abortError
+	return errors.As(err, &abortErr)
+}
+
+// abortCondition reports whether the cluster is degraded in one way, and how.
+type abortCondition struct {
+	name  string
+	check func(ctx context.Context) (breached bool, detail string, err error)
+}
+
+// watchAbortConditions checks conditions every interval until ctx is done, returning nil,
+// or one has been breached for holdFor, returning why. A check that fails is logged and
+// leaves the condition as it was, so a flaky Prometheus neither aborts nor resets the clock.
+func watchAbortConditions(ctx context.Context, conditions []abortCondition, holdFor, interval time.Duration) *abortError {
+	breachedSince := make(map[string]time.Time, len(conditions))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+
+		for _, condition := range conditions {
+			breached, detail, err := condition.check(ctx)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				log.Printf("Warning - failed to check abort condition %s: %v", condition.name, err)
+				continue
+			}
+			if !breached {
+				if _, ok := breachedSince[condition.name]; ok {
+					log.Printf("Abort condition %s cleared", condition.name)
+					delete(breachedSince, condition.name)
+				}
+				continue
+			}
+
+			since, ok := breachedSince[condition.name]
+			if !ok {
+				since = time.Now()
+				breachedSince[condition.name] = since
+				log.Printf("Abort condition %s breached, stopping the run if it holds for %s: %s", condition.name, holdFor, detail)
+			}
+			if time.Since(since) >= holdFor {
+				return &abortError{reason: fmt.Sprintf("%s held for %s: %s", condition.name, time.Since(since).Round(time.Second), detail)}
+			}
+		}
+	}
+}
+
+// queryBreached runs query once; it is breached when it returns a non-zero sample.
+func queryBreached(ctx context.Context, querier promQuerier, query string) (bool, string, error) {
+	value, _, err := querier.Query(ctx, query, time.Now())
+	if err != nil {
+		return false, "", fmt.Errorf("failed to run abort query %q: %w", query, err)
+	}
+
+	var values []float64
+	switch v := value.(type) {
+	case model.Vector:
+		for _, sample := range v {
+			values = append(values, float64(sample.Value))
+		}
+	case *model.Scalar:
+		values = append(values, float64(v.Value))
+	default:
+		return false, "", fmt.Errorf("abort query %q returned a %s, expected a vector or scalar", query, value.Type())
+	}
+
+	var breaching []string
+	for _, sample := range values {
+		if sample != 0 {
+			breaching = append(breaching, strconv.FormatFloat(sample, 'g', 4, 64))
+		}
+	}
+	if len(breaching) == 0 {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("abort query returned %s", strings.Join(breaching, ", ")), nil
+}
+
+// degradedOperators lists the cluster operators reporting Degraded, honoring Tests.OperatorSkip.
+func degradedOperators(ctx context.Context, configClient configclient.ConfigV1Interface) (bool, string, error) {
+	list, err := configClient.ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to list cluster operators: %w", err)
+	}
+	skip := make(map[string]bool)
+	for _, name := range strings.Split(viper.GetString(config.Tests.OperatorSkip), ",") {
+		skip[strings.TrimSpace(name)] = true
+	}
+
+	var degraded []string
+	for _, co := range list.Items {
+		if skip[co.Name] {
+			continue
+		}
+		for _, condition := range co.Status.Conditions {
+			if condition.Type == configv1.OperatorDegraded && condition.Status == configv1.ConditionTrue {
+				degraded = append(degraded, co.Name)
+			}
+		}
+	}
+	if len(degraded) == 0 {
+		return false, "", nil
+	}
+	sort.Strings(degraded)
+	return true, "degraded cluster operators: " + strings.Join(degraded, ", "), nil
+}
+
+// abortConditions returns the conditions g sets up. Clients are built from the kubeconfig
+// in the shared directory on every check so they pick up a rotated kubeconfig.
+func (k *KrknAI) abortConditions(g abortGuardrails) []abortCondition {
+	kubeconfigPath := filepath.Join(k.sharedDir(), kubeconfigFileName)
+	var conditions []abortCondition
+	if g.query != "" {
+		conditions = append(conditions, abortCondition{name: "abort query", check: func(ctx context.Context) (bool, string, error) {
+			ctx, cancel := context.WithTimeout(ctx, abortCheckTimeout)
+			defer cancel()
+			client, err := openshift.NewFromKubeconfig(kubeconfigPath, logr.Discard())
+			if err != nil {
+				return false, "", fmt.Errorf("failed to create openshift client: %w", err)
+			}
+			promClient, err := prometheus.New(ctx, client)
+			if err != nil {
+				return false, "", err
+			}
+			return queryBreached(ctx, promClient.GetClient(), g.query)
+		}})
+	}
+	if g.degradedOperators {
+		conditions = append(conditions, abortCondition{name: "cluster operators", check: func(ctx context.Context) (bool, string, error) {
+			ctx, cancel := context.WithTimeout(ctx, abortCheckTimeout)
+			defer cancel()
+			restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+			}
+			configClient, err := configclient.NewForConfig(restConfig)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to create config client: %w", err)
+			}
+			return degradedOperators(ctx, configClient)
+		}})
+	}
+	return conditions
+}
@@ -0,0 +1,145 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceCondition is an abort condition whose checks return results in order, repeating
+// the last one.
+func sequenceCondition(name string, results ...bool) abortCondition {
+	calls := 0
+	return abortCondition{name: name, check: func(context.Context) (bool, string, error) {
+		breached := results[min(calls, len(results)-1)]
+		calls++
+		return breached, "error rate 0.35", nil
+	}}
+}
+
+func TestWatchAbortConditions(t *testing.T) {
+	t.Run("breach that holds aborts", func(t *testing.T) {
+		start := time.Now()
+		abortErr := watchAbortConditions(context.Background(), []abortCondition{
+			sequenceCondition("healthy", false),
+			sequenceCondition("abort query", true),
+		}, 50*time.Millisecond, 5*time.Millisecond)
+		require.NotNil(t, abortErr)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Contains(t, abortErr.Error(), "run aborted: abort query held for")
+		assert.Contains(t, abortErr.Error(), "error rate 0.35")
+	})
+
+	t.Run("zero duration aborts on the first breach", func(t *testing.T) {
+		abortErr := watchAbortConditions(context.Background(), []abortCondition{sequenceCondition("cluster operators", false, true)}, 0, time.Millisecond)
+		require.NotNil(t, abortErr)
+		assert.Contains(t, abortErr.reason, "cluster operators held for 0s")
+	})
+
+	t.Run("breach that clears resets", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		flapping := abortCondition{name: "abort query", check: func(context.Context) (bool, string, error) {
+			return time.Now().UnixMilli()%20 < 10, "", nil
+		}}
+		assert.Nil(t, watchAbortConditions(ctx, []abortCondition{flapping}, 60*time.Millisecond, time.Millisecond))
+	})
+
+	t.Run("failed checks don't abort", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+		failing := abortCondition{name: "abort query", check: func(context.Context) (bool, string, error) {
+			return false, "", errors.New("prometheus unavailable")
+		}}
+		assert.Nil(t, watchAbortConditions(ctx, []abortCondition{failing}, 0, time.Millisecond))
+	})
+}
+
+func TestQueryBreached(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantDetail string
+		wantErr    string
+	}{
+		{
+			name:       "breaching series",
+			body:       `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"apiserver"},"value":[1700000000,"0.35"]}]}}`,
+			wantDetail: "abort query returned 0.35",
+		},
+		{
+			name: "no series",
+			body: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		},
+		{
+			name: "zero scalar",
+			body: `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"0"]}}`,
+		},
+		{
+			name:    "range result",
+			body:    `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			wantErr: "returned a matrix, expected a vector or scalar",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breached, detail, err := queryBreached(context.Background(), fakePrometheus(t, http.StatusOK, tt.body), "error_ratio > 0.2")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDetail != "", breached)
+			assert.Equal(t, tt.wantDetail, detail)
+		})
+	}
+}
+
+func TestDegradedOperators(t *testing.T) {
+	setupKrknConfig(t, map[string]any{config.Tests.OperatorSkip: "insights"})
+	configClient := configfake.NewSimpleClientset(
+		readinessOperator("dns", configv1.ConditionFalse),
+		readinessOperator("ingress", configv1.ConditionTrue),
+		readinessOperator("insights", configv1.ConditionTrue),
+		readinessOperator("authentication", configv1.ConditionTrue),
+	)
+	breached, detail, err := degradedOperators(context.Background(), configClient.ConfigV1())
+	require.NoError(t, err)
+	assert.True(t, breached)
+	assert.Equal(t, "degraded cluster operators: authentication, ingress", detail)
+
+	breached, _, err = degradedOperators(context.Background(), configfake.NewSimpleClientset(readinessOperator("dns", configv1.ConditionFalse)).ConfigV1())
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestAbortGuardrailsFromConfig(t *testing.T) {
+	setupKrknConfig(t, nil)
+	guardrails, err := abortGuardrailsFromConfig(context.Background(), placeholderTemplates)
+	require.NoError(t, err)
+	assert.False(t, guardrails.enabled(), "no abort conditions by default")
+	assert.Equal(t, 10*time.Minute, guardrails.holdFor)
+	assert.Equal(t, 30*time.Second, guardrails.interval)
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.AbortOnDegradedOperators: true, config.KrknAI.AbortFor: "0"})
+	guardrails, err = abortGuardrailsFromConfig(context.Background(), placeholderTemplates)
+	require.NoError(t, err)
+	assert.True(t, guardrails.enabled())
+	assert.Zero(t, guardrails.holdFor)
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.AbortFor: "ten minutes"})
+	_, err = abortGuardrailsFromConfig(context.Background(), placeholderTemplates)
+	assert.EqualError(t, err, `invalid abort duration "ten minutes" (expected a duration, e.g. 10m)`)
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.AbortPollInterval: "0s"})
+	_, err = abortGuardrailsFromConfig(context.Background(), placeholderTemplates)
+	assert.EqualError(t, err, `invalid abort poll interval "0s" (expected a positive duration, e.g. 30s)`)
+}
@@ -3,12 +3,16 @@ package aggregator
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	internalAggregator "github.com/openshift/osde2e/internal/aggregator"
@@ -32,24 +36,57 @@ const (
 
 	// Top scenarios to include in summary
 	defaultTopScenariosCount = 10
+
+	// Log artifacts read in parallel by Collect
+	defaultConcurrency = 4
+)
+
+// Duplicate scenario handling modes for WithDuplicateHandling.
+const (
+	DuplicatesFlag   = "flag"   // Keep every entry and only report the duplicate count (default)
+	DuplicatesDedupe = "dedupe" // Keep only the last entry for each scenario ID
 )
 
+// scenarioArtifactPattern matches the scenario ID krkn-ai embeds in per-scenario
+// artifact paths, e.g. graphs/scenario_12.png.
+var scenarioArtifactPattern = regexp.MustCompile(`(?i)scenario[_-](\d+)`)
+
+// timeoutColumns are the all.csv header names krkn-ai versions use to record scenario timeouts.
+var timeoutColumns = []string{"timed_out", "timeout", "status"}
+
 // KrknAIAggregator collects and parses krkn-ai chaos test results.
 type KrknAIAggregator struct {
-	logger            logr.Logger
-	topScenariosCount int
-	clusterInfo       *ClusterInfo
+	logger               logr.Logger
+	topScenariosCount    int
+	bottomScenariosCount int
+	clusterInfo          *ClusterInfo
+	collectTimeout       time.Duration
+	scenarioNames        map[string]string // Raw scenario name -> canonical name
+	latestGenOnly        bool
+	duplicates           string
+	scenarioIDs          []int // Only these scenario IDs are analyzed when set
+	concurrency          int   // Maximum log artifacts read at once
+	deduplicate          bool
 }
 
 // KrknAIData holds aggregated krkn-ai results with minimal context.
 type KrknAIData struct {
 	Summary           KrknAISummary                 `json:"summary"`
 	TopScenarios      []ScenarioResult              `json:"topScenarios"`
+	BottomScenarios   []ScenarioResult              `json:"bottomScenarios,omitempty"` // Lowest fitness first; empty unless requested
 	FailedScenarios   []ScenarioResult              `json:"failedScenarios"`
-	HealthCheckReport []HealthCheckResult           `json:"healthCheckReport"`
+	HealthCheckReport HealthCheckReport             `json:"healthCheckReport"`
 	LogArtifacts      []internalAggregator.LogEntry `json:"logArtifacts"`
 	ConfigSummary     string                        `json:"configSummary,omitempty"`
 	ClusterInfo       *ClusterInfo                  `json:"clusterInfo,omitempty"`
+	// ScopedGeneration is set when TopScenarios/FailedScenarios only cover this generation;
+	// Summary always reflects the full run.
+	ScopedGeneration *int `json:"scopedGeneration,omitempty"`
+	// ScenarioIDFilter lists the scenario IDs the data was narrowed to, summary included.
+	ScenarioIDFilter []int `json:"scenarioIdFilter,omitempty"`
+	// BestScenario is the GA's champion: the highest-fitness non-failed scenario, drawn
+	// from ScopedGeneration when set.
+	BestScenario *ScenarioResult `json:"bestScenario,omitempty"`
 }
 
 // KrknAISummary provides high-level statistics about the chaos test run.
@@ -57,6 +94,8 @@ type KrknAISummary struct {
 	TotalScenarioCount      int      `json:"totalScenarioCount"`
 	SuccessfulScenarioCount int      `json:"successfulScenarioCount"`
 	FailedScenarioCount     int      `json:"failedScenarioCount"`
+	TimedOutScenarioCount   int      `json:"timedOutScenarioCount"`
+	DuplicateScenarioCount  int      `json:"duplicateScenarioCount"` // Entries sharing a scenario ID with an earlier entry
 	Generations             int      `json:"generations"`
 	MaxFitnessScore         float64  `json:"maxFitnessScore"`
 	AvgFitnessScore         float64  `json:"avgFitnessScore"`
@@ -73,6 +112,15 @@ type ScenarioResult struct {
 	HealthCheckResponseTimeScore float64 `json:"healthCheckResponseTimeScore"`
 	KrknFailureScore             float64 `json:"krknFailureScore"`
 	FitnessScore                 float64 `json:"fitnessScore"`
+	TimedOut                     bool    `json:"timedOut,omitempty"` // Scenario did not complete before its timeout
+	// Occurrences is the number of failures this entry represents when failed scenarios
+	// are deduplicated; zero means the entry stands alone.
+	Occurrences int `json:"occurrences,omitempty"`
+}
+
+// OccurrenceCount returns how many scenario executions the entry represents.
+func (s ScenarioResult) OccurrenceCount() int {
+	return max(s.Occurrences, 1)
 }
 
 // HealthCheckResult represents health check metrics for a scenario.
@@ -86,11 +134,61 @@ type HealthCheckResult struct {
 	FailureCount        int     `json:"failureCount"`
 }
 
+// HealthCheckReport holds the health check rows of every analyzed scenario.
+type HealthCheckReport []HealthCheckResult
+
+// ApplicationHealth totals the health checks of one application across all scenarios.
+type ApplicationHealth struct {
+	ComponentName       string  `json:"componentName"`
+	SuccessCount        int     `json:"successCount"`
+	FailureCount        int     `json:"failureCount"`
+	AverageResponseTime float64 `json:"averageResponseTime"` // Weighted by each scenario's check count
+}
+
+// SuccessRate returns the fraction of checks that succeeded, or 1 when none ran.
+func (h ApplicationHealth) SuccessRate() float64 {
+	total := h.SuccessCount + h.FailureCount
+	if total == 0 {
+		return 1
+	}
+	return float64(h.SuccessCount) / float64(total)
+}
+
+// Applications totals the report per application, sorted by name.
+func (r HealthCheckReport) Applications() []ApplicationHealth {
+	byName := make(map[string]*ApplicationHealth)
+	responseTime := make(map[string]float64)
+	var names []string
+	for _, row := range r {
+		app, ok := byName[row.ComponentName]
+		if !ok {
+			app = &ApplicationHealth{ComponentName: row.ComponentName}
+			byName[row.ComponentName] = app
+			names = append(names, row.ComponentName)
+		}
+		app.SuccessCount += row.SuccessCount
+		app.FailureCount += row.FailureCount
+		responseTime[row.ComponentName] += row.AverageResponseTime * float64(row.SuccessCount+row.FailureCount)
+	}
+	sort.Strings(names)
+
+	apps := make([]ApplicationHealth, 0, len(names))
+	for _, name := range names {
+		app := byName[name]
+		if checks := app.SuccessCount + app.FailureCount; checks > 0 {
+			app.AverageResponseTime = responseTime[name] / float64(checks)
+		}
+		apps = append(apps, *app)
+	}
+	return apps
+}
+
 // NewKrknAIAggregator creates a new aggregator for krkn-ai results.
 func NewKrknAIAggregator(ctx context.Context) *KrknAIAggregator {
 	return &KrknAIAggregator{
 		logger:            logr.FromContextOrDiscard(ctx),
 		topScenariosCount: defaultTopScenariosCount,
+		concurrency:       defaultConcurrency,
 	}
 }
 
@@ -100,6 +198,67 @@ func (a *KrknAIAggregator) WithTopScenariosCount(count int) *KrknAIAggregator {
 	return a
 }
 
+// WithConcurrency bounds how many log artifacts Collect reads at once. Values below 1
+// read artifacts one at a time.
+func (a *KrknAIAggregator) WithConcurrency(n int) *KrknAIAggregator {
+	a.concurrency = max(n, 1)
+	return a
+}
+
+// WithBottomScenariosCount sets the number of lowest-fitness successful scenarios to
+// include as BottomScenarios. Zero (the default) leaves them out.
+func (a *KrknAIAggregator) WithBottomScenariosCount(count int) *KrknAIAggregator {
+	a.bottomScenariosCount = count
+	return a
+}
+
+// WithCollectTimeout bounds the total time Collect may spend reading results.
+// A zero timeout (the default) means no deadline.
+func (a *KrknAIAggregator) WithCollectTimeout(timeout time.Duration) *KrknAIAggregator {
+	a.collectTimeout = timeout
+	return a
+}
+
+// WithScenarioNormalization maps scenario names as reported by krkn-ai to canonical
+// names (e.g. "pod-scenario" -> "pod_scenarios") so that renames across krkn-ai versions
+// don't fragment results. Names missing from the map are kept as-is.
+func (a *KrknAIAggregator) WithScenarioNormalization(names map[string]string) *KrknAIAggregator {
+	a.scenarioNames = make(map[string]string, len(names))
+	for raw, canonical := range names {
+		a.scenarioNames[raw] = canonical
+	}
+	return a
+}
+
+// WithLatestGenerationOnly restricts TopScenarios and FailedScenarios to the final
+// generation's population. Summary statistics still cover every generation.
+func (a *KrknAIAggregator) WithLatestGenerationOnly(enabled bool) *KrknAIAggregator {
+	a.latestGenOnly = enabled
+	return a
+}
+
+// WithDuplicateHandling sets how repeated scenario IDs (e.g. from retried or merged
+// runs) are treated: DuplicatesFlag (default) or DuplicatesDedupe.
+func (a *KrknAIAggregator) WithDuplicateHandling(mode string) *KrknAIAggregator {
+	a.duplicates = mode
+	return a
+}
+
+// WithScenarioIDFilter narrows the collected data, including the summary, health
+// checks, and per-scenario artifacts, to the given scenario IDs.
+func (a *KrknAIAggregator) WithScenarioIDFilter(ids []int) *KrknAIAggregator {
+	a.scenarioIDs = append([]int(nil), ids...)
+	return a
+}
+
+// WithDeduplication collapses failed scenarios sharing a scenario type and parameters into
+// the first such failure, with Occurrences counting the group. TopScenarios and the
+// summary statistics are unaffected.
+func (a *KrknAIAggregator) WithDeduplication(enabled bool) *KrknAIAggregator {
+	a.deduplicate = enabled
+	return a
+}
+
 // WithClusterInfo sets cluster metadata to include in collected data.
 // A defensive copy is stored so later mutations by the caller don't affect stored data.
 func (a *KrknAIAggregator) WithClusterInfo(info *ClusterInfo) *KrknAIAggregator {
@@ -110,7 +269,10 @@ func (a *KrknAIAggregator) WithClusterInfo(info *ClusterInfo) *KrknAIAggregator
 	return a
 }
 
-// Collect gathers krkn-ai results from the specified directory.
+// Collect gathers krkn-ai results from the specified directory. With a run manifest, the
+// krkn-ai output and container logs are read from the directories it names and its cluster
+// fills in for cluster info not set with WithClusterInfo; without one, krkn-ai's output is
+// expected at the top of resultsDir.
 func (a *KrknAIAggregator) Collect(ctx context.Context, resultsDir string) (*KrknAIData, error) {
 	a.logger.Info("collecting krkn-ai results", "resultsDir", resultsDir)
 
@@ -118,38 +280,80 @@ func (a *KrknAIAggregator) Collect(ctx context.Context, resultsDir string) (*Krk
 		return nil, fmt.Errorf("results directory does not exist: %s", resultsDir)
 	}
 
+	manifest, err := ReadManifest(resultsDir)
+	if err != nil {
+		return nil, err
+	}
+	krknDir, artifactDirs := resultsDir, []string{resultsDir}
+	if manifest != nil {
+		krknDir = filepath.Join(resultsDir, manifest.Layout.Generations)
+		artifactDirs = []string{krknDir, filepath.Join(resultsDir, manifest.Layout.Logs)}
+	}
+
+	if a.collectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.collectTimeout)
+		defer cancel()
+	}
+
 	data := &KrknAIData{}
 	if a.clusterInfo != nil {
 		cp := *a.clusterInfo
 		data.ClusterInfo = &cp
+	} else if manifest != nil && manifest.ClusterID != "" {
+		data.ClusterInfo = &ClusterInfo{ID: manifest.ClusterID, Version: manifest.ClusterVersion}
 	}
 	var collectionErrors []string
 
 	// Collect scenario results from all.csv
-	scenarios, err := a.collectScenarioResults(resultsDir)
+	scenarios, err := a.collectScenarioResults(krknDir)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to collect scenario results: %v", err)
 		a.logger.Error(err, "failed to collect scenario results")
 		collectionErrors = append(collectionErrors, errMsg)
 	} else {
+		scenarios, duplicates := a.handleDuplicates(scenarios)
+		if len(a.scenarioIDs) > 0 {
+			scenarios = a.filterScenarioIDs(scenarios)
+			if len(scenarios) == 0 {
+				return nil, fmt.Errorf("none of the requested scenario IDs %v were found in the results", a.scenarioIDs)
+			}
+			data.ScenarioIDFilter = a.scenarioIDs
+		}
 		a.processScenarios(data, scenarios)
+		data.Summary.DuplicateScenarioCount = duplicates
+	}
+
+	if err := a.checkDeadline(ctx); err != nil {
+		return nil, err
 	}
 
 	// Collect health check report
-	if err := a.collectHealthCheckReport(resultsDir, data); err != nil {
+	if err := a.collectHealthCheckReport(krknDir, data); err != nil {
 		errMsg := fmt.Sprintf("failed to collect health check report: %v", err)
 		a.logger.Error(err, "failed to collect health check report")
 		collectionErrors = append(collectionErrors, errMsg)
 	}
 
+	if err := a.checkDeadline(ctx); err != nil {
+		return nil, err
+	}
+
 	// Collect config summary
-	if err := a.collectConfigSummary(resultsDir, data); err != nil {
+	if err := a.collectConfigSummary(krknDir, data); err != nil {
 		a.logger.Info("config file not found or unreadable", "error", err)
 		// Not critical - continue without config
 	}
 
+	if err := a.checkDeadline(ctx); err != nil {
+		return nil, err
+	}
+
 	// Collect log artifacts for LLM tool access
-	if err := a.collectLogArtifacts(resultsDir, data); err != nil {
+	if err := a.collectLogArtifacts(ctx, artifactDirs, data); err != nil {
+		if ctxErr := a.checkDeadline(ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
 		errMsg := fmt.Sprintf("failed to collect log artifacts: %v", err)
 		a.logger.Error(err, "failed to collect log artifacts")
 		collectionErrors = append(collectionErrors, errMsg)
@@ -183,6 +387,9 @@ func (a *KrknAIAggregator) collectScenarioResults(resultsDir string) ([]Scenario
 		return nil, fmt.Errorf("CSV file is empty or has no data rows")
 	}
 
+	// Timeout state is only recorded by some krkn-ai versions
+	timeoutCol := findColumn(records[0], timeoutColumns)
+
 	// Skip header row
 	var scenarios []ScenarioResult
 	for i, record := range records[1:] {
@@ -196,6 +403,9 @@ func (a *KrknAIAggregator) collectScenarioResults(resultsDir string) ([]Scenario
 			a.logger.Info("failed to parse row", "row", i+2, "error", err)
 			continue
 		}
+		if timeoutCol >= 0 && timeoutCol < len(record) {
+			scenario.TimedOut = isTimedOut(record[timeoutCol])
+		}
 		scenarios = append(scenarios, scenario)
 	}
 
@@ -231,23 +441,54 @@ func (a *KrknAIAggregator) parseScenarioRecord(record []string) (ScenarioResult,
 	}, nil
 }
 
+// findColumn returns the index of the first header matching one of names, or -1.
+func findColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, col := range header {
+			if strings.EqualFold(strings.TrimSpace(col), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isTimedOut interprets a timeout column value, accepting booleans and status strings.
+func isTimedOut(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "yes", "timeout", "timed_out", "timedout":
+		return true
+	}
+	return false
+}
+
 // processScenarios analyzes scenarios and populates summary, top, and failed lists.
 func (a *KrknAIAggregator) processScenarios(data *KrknAIData, scenarios []ScenarioResult) {
 	if len(scenarios) == 0 {
 		return
 	}
 
+	for i := range scenarios {
+		if canonical, ok := a.scenarioNames[scenarios[i].Scenario]; ok {
+			scenarios[i].Scenario = canonical
+		}
+	}
+
 	// Calculate summary statistics
 	var totalFitness float64
 	maxGen := 0
 	scenarioTypes := make(map[string]struct{})
 	var failed []ScenarioResult
+	timedOut := 0
 
 	for _, s := range scenarios {
 		if s.GenerationID > maxGen {
 			maxGen = s.GenerationID
 		}
 		scenarioTypes[s.Scenario] = struct{}{}
+		if s.TimedOut {
+			timedOut++
+		}
 
 		// KrknFailureScore of -1 indicates scenario failure
 		if s.KrknFailureScore < 0 {
@@ -269,17 +510,12 @@ func (a *KrknAIAggregator) processScenarios(data *KrknAIData, scenarios []Scenar
 	// Sort by fitness score descending to get top scenarios
 	sorted := make([]ScenarioResult, len(scenarios))
 	copy(sorted, scenarios)
-	sort.Slice(sorted, func(i, j int) bool {
+	sort.SliceStable(sorted, func(i, j int) bool {
 		return sorted[i].FitnessScore > sorted[j].FitnessScore
 	})
 
 	// Get top N scenarios (excluding failed ones)
-	var topScenarios []ScenarioResult
-	for _, s := range sorted {
-		if s.KrknFailureScore >= 0 && len(topScenarios) < a.topScenariosCount {
-			topScenarios = append(topScenarios, s)
-		}
-	}
+	topScenarios := topSuccessful(sorted, a.topScenariosCount)
 
 	// Calculate max and average fitness (excluding failed)
 	var maxFitness, avgFitness float64
@@ -294,6 +530,7 @@ func (a *KrknAIAggregator) processScenarios(data *KrknAIData, scenarios []Scenar
 		TotalScenarioCount:      len(scenarios),
 		SuccessfulScenarioCount: successCount,
 		FailedScenarioCount:     len(failed),
+		TimedOutScenarioCount:   timedOut,
 		Generations:             maxGen + 1, // 0-indexed
 		MaxFitnessScore:         maxFitness,
 		AvgFitnessScore:         avgFitness,
@@ -301,6 +538,179 @@ func (a *KrknAIAggregator) processScenarios(data *KrknAIData, scenarios []Scenar
 	}
 	data.TopScenarios = topScenarios
 	data.FailedScenarios = failed
+
+	if a.latestGenOnly {
+		sorted = filterGeneration(sorted, maxGen)
+		data.TopScenarios = topSuccessful(sorted, a.topScenariosCount)
+		data.FailedScenarios = filterGeneration(failed, maxGen)
+		data.ScopedGeneration = &maxGen
+	}
+	if a.bottomScenariosCount > 0 {
+		data.BottomScenarios = bottomSuccessful(sorted, a.bottomScenariosCount)
+	}
+	if a.deduplicate {
+		data.FailedScenarios = groupFailures(data.FailedScenarios)
+	}
+
+	if best := topSuccessful(sorted, 1); len(best) > 0 {
+		data.BestScenario = &best[0]
+	}
+}
+
+// groupFailures collapses failures with the same scenario type and parameters into the
+// first of them, keeping file order and recording the group size in Occurrences.
+func groupFailures(failed []ScenarioResult) []ScenarioResult {
+	type signature struct{ scenario, parameters string }
+	index := make(map[signature]int, len(failed))
+	grouped := make([]ScenarioResult, 0, len(failed))
+	for _, s := range failed {
+		key := signature{s.Scenario, strings.TrimSpace(s.Parameters)}
+		if i, ok := index[key]; ok {
+			grouped[i].Occurrences += s.OccurrenceCount()
+			continue
+		}
+		index[key] = len(grouped)
+		s.Occurrences = s.OccurrenceCount()
+		grouped = append(grouped, s)
+	}
+	return grouped
+}
+
+// handleDuplicates counts entries whose scenario ID was already seen and, in dedupe
+// mode, drops all but the last entry for each ID while preserving file order.
+func (a *KrknAIAggregator) handleDuplicates(scenarios []ScenarioResult) ([]ScenarioResult, int) {
+	last := make(map[int]int, len(scenarios))
+	for i, s := range scenarios {
+		last[s.ScenarioID] = i
+	}
+	duplicates := len(scenarios) - len(last)
+	if duplicates == 0 {
+		return scenarios, 0
+	}
+
+	a.logger.Info("found duplicate scenario entries", "duplicates", duplicates, "mode", a.duplicates)
+	if a.duplicates != DuplicatesDedupe {
+		return scenarios, duplicates
+	}
+
+	deduped := make([]ScenarioResult, 0, len(last))
+	for i, s := range scenarios {
+		if last[s.ScenarioID] == i {
+			deduped = append(deduped, s)
+		}
+	}
+	return deduped, duplicates
+}
+
+// filterScenarioIDs keeps the scenarios matching the ID filter, logging requested IDs
+// that have no results.
+func (a *KrknAIAggregator) filterScenarioIDs(scenarios []ScenarioResult) []ScenarioResult {
+	found := make(map[int]bool)
+	var filtered []ScenarioResult
+	for _, s := range scenarios {
+		if a.includesScenario(s.ScenarioID) {
+			filtered = append(filtered, s)
+			found[s.ScenarioID] = true
+		}
+	}
+	for _, id := range a.scenarioIDs {
+		if !found[id] {
+			a.logger.Info("requested scenario ID not found in results", "scenarioId", id)
+		}
+	}
+	return filtered
+}
+
+// includesScenario reports whether the scenario ID passes the filter; every ID does
+// when no filter is set.
+func (a *KrknAIAggregator) includesScenario(id int) bool {
+	if len(a.scenarioIDs) == 0 {
+		return true
+	}
+	for _, want := range a.scenarioIDs {
+		if want == id {
+			return true
+		}
+	}
+	return false
+}
+
+// includesArtifact reports whether an artifact belongs to a filtered-in scenario.
+// Artifacts without a scenario ID in their path are shared and always included.
+func (a *KrknAIAggregator) includesArtifact(relPath string) bool {
+	match := scenarioArtifactPattern.FindStringSubmatch(relPath)
+	if match == nil {
+		return true
+	}
+	id, err := strconv.Atoi(match[1])
+	return err != nil || a.includesScenario(id)
+}
+
+// MergeSummaries combines the summaries of separate runs into one: counts are summed,
+// fitness maxima and generations take the largest value, the average fitness is weighted
+// by each run's successful scenarios, and scenario types are unioned.
+func MergeSummaries(summaries ...KrknAISummary) KrknAISummary {
+	var merged KrknAISummary
+	var totalFitness float64
+	types := make(map[string]struct{})
+	for _, s := range summaries {
+		merged.TotalScenarioCount += s.TotalScenarioCount
+		merged.SuccessfulScenarioCount += s.SuccessfulScenarioCount
+		merged.FailedScenarioCount += s.FailedScenarioCount
+		merged.TimedOutScenarioCount += s.TimedOutScenarioCount
+		merged.DuplicateScenarioCount += s.DuplicateScenarioCount
+		merged.Generations = max(merged.Generations, s.Generations)
+		if s.SuccessfulScenarioCount > 0 {
+			merged.MaxFitnessScore = max(merged.MaxFitnessScore, s.MaxFitnessScore)
+			totalFitness += s.AvgFitnessScore * float64(s.SuccessfulScenarioCount)
+		}
+		for _, t := range s.ScenarioTypes {
+			types[t] = struct{}{}
+		}
+	}
+	if merged.SuccessfulScenarioCount > 0 {
+		merged.AvgFitnessScore = totalFitness / float64(merged.SuccessfulScenarioCount)
+	}
+	merged.ScenarioTypes = make([]string, 0, len(types))
+	for t := range types {
+		merged.ScenarioTypes = append(merged.ScenarioTypes, t)
+	}
+	sort.Strings(merged.ScenarioTypes)
+	return merged
+}
+
+// topSuccessful returns up to n non-failed scenarios from a fitness-sorted slice.
+func topSuccessful(sorted []ScenarioResult, n int) []ScenarioResult {
+	var top []ScenarioResult
+	for _, s := range sorted {
+		if s.KrknFailureScore >= 0 && len(top) < n {
+			top = append(top, s)
+		}
+	}
+	return top
+}
+
+// bottomSuccessful returns up to n non-failed scenarios from the end of a fitness-sorted
+// slice, lowest fitness first.
+func bottomSuccessful(sorted []ScenarioResult, n int) []ScenarioResult {
+	var bottom []ScenarioResult
+	for i := len(sorted) - 1; i >= 0 && len(bottom) < n; i-- {
+		if sorted[i].KrknFailureScore >= 0 {
+			bottom = append(bottom, sorted[i])
+		}
+	}
+	return bottom
+}
+
+// filterGeneration returns the scenarios belonging to the given generation.
+func filterGeneration(scenarios []ScenarioResult, generation int) []ScenarioResult {
+	var filtered []ScenarioResult
+	for _, s := range scenarios {
+		if s.GenerationID == generation {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
 }
 
 // collectHealthCheckReport parses health_check_report.csv.
@@ -334,6 +744,9 @@ func (a *KrknAIAggregator) collectHealthCheckReport(resultsDir string, data *Krk
 			a.logger.Info("failed to parse health check row", "row", i+2, "error", err)
 			continue
 		}
+		if !a.includesScenario(result.ScenarioID) {
+			continue
+		}
 		data.HealthCheckReport = append(data.HealthCheckReport, result)
 	}
 
@@ -396,8 +809,13 @@ func formatConfigSummary(cfg map[string]interface{}) string {
 	// Fitness function
 	if ff, ok := cfg["fitness_function"].(map[string]interface{}); ok {
 		sb.WriteString("\n=== Fitness Function ===\n")
-		for k, v := range ff {
-			sb.WriteString(fmt.Sprintf("%s: %v\n", k, v))
+		keys := make([]string, 0, len(ff))
+		for k := range ff {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("%s: %v\n", k, ff[k]))
 		}
 	}
 
@@ -429,15 +847,85 @@ func formatConfigSummary(cfg map[string]interface{}) string {
 	return sb.String()
 }
 
-// collectLogArtifacts walks the results directory and catalogs available files.
-func (a *KrknAIAggregator) collectLogArtifacts(resultsDir string, data *KrknAIData) error {
-	// Get absolute path for the results directory
-	absResultsDir, err := filepath.Abs(resultsDir)
+// checkDeadline returns an error once the collection context is done.
+func (a *KrknAIAggregator) checkDeadline(ctx context.Context) error {
+	switch err := ctx.Err(); {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded) && a.collectTimeout > 0:
+		return fmt.Errorf("krkn-ai result collection timed out after %s: %w", a.collectTimeout, err)
+	default:
+		return fmt.Errorf("krkn-ai result collection aborted: %w", err)
+	}
+}
+
+// collectLogArtifacts walks the artifact directories and catalogs available files. Files
+// are read by a bounded pool of workers and cataloged in walk order, so the result does
+// not depend on scheduling. A file that cannot be read is still cataloged, without a line
+// count, and its error is returned alongside the others once every file has been read.
+func (a *KrknAIAggregator) collectLogArtifacts(ctx context.Context, dirs []string, data *KrknAIData) error {
+	var paths []string
+	for _, dir := range dirs {
+		dirPaths, err := a.walkArtifacts(ctx, dir)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, dirPaths...)
+	}
+
+	// Each worker writes only its own index, so no locking is needed
+	entries := make([]internalAggregator.LogEntry, len(paths))
+	readErrs := make([]error, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(a.concurrency, 1), len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				lineCount, err := countLines(paths[i])
+				if err != nil {
+					readErrs[i] = fmt.Errorf("%s: %w", paths[i], err)
+				}
+				// Use absolute path so read_file tool can find the file
+				entries[i] = internalAggregator.LogEntry{
+					Source:    paths[i],
+					LineCount: lineCount,
+				}
+			}
+		}()
+	}
+feed:
+	for i := range paths {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data.LogArtifacts = append(data.LogArtifacts, entries...)
+	return errors.Join(readErrs...)
+}
+
+// walkArtifacts returns the absolute paths of the artifacts under dir worth cataloging.
+func (a *KrknAIAggregator) walkArtifacts(ctx context.Context, dir string) ([]string, error) {
+	// Get absolute path for the directory
+	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		absResultsDir = resultsDir
+		absDir = dir
 	}
 
-	return filepath.Walk(absResultsDir, func(path string, info os.FileInfo, err error) error {
+	var paths []string
+	err = filepath.Walk(absDir, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil // Continue on error
 		}
@@ -453,20 +941,26 @@ func (a *KrknAIAggregator) collectLogArtifacts(resultsDir string, data *KrknAIDa
 			return nil
 		}
 
-		lineCount := 0
-		if content, err := os.ReadFile(path); err == nil {
-			lineCount = strings.Count(string(content), "\n")
-			if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
-				lineCount++
-			}
+		if rel, err := filepath.Rel(absDir, path); err == nil && !a.includesArtifact(rel) {
+			return nil
 		}
 
-		// Use absolute path so read_file tool can find the file
-		data.LogArtifacts = append(data.LogArtifacts, internalAggregator.LogEntry{
-			Source:    path,
-			LineCount: lineCount,
-		})
-
+		paths = append(paths, path)
 		return nil
 	})
+	return paths, err
+}
+
+// countLines returns the number of lines in the file at path, counting a final line
+// without a trailing newline.
+func countLines(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	lineCount := strings.Count(string(content), "\n")
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		lineCount++
+	}
+	return lineCount, nil
 }
@@ -2,9 +2,12 @@ package aggregator
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -94,6 +97,50 @@ func TestKrknAIAggregator_WithTopScenariosCount(t *testing.T) {
 	assert.LessOrEqual(t, len(data.TopScenarios), 2)
 }
 
+func TestKrknAIAggregator_WithBottomScenariosCount(t *testing.T) {
+	tempDir := t.TempDir()
+	resultsDir := filepath.Join(tempDir, "results")
+	reportsDir := filepath.Join(resultsDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+
+	createKrknAITestFiles(t, resultsDir, reportsDir)
+
+	ctx := context.Background()
+	data, err := NewKrknAIAggregator(ctx).Collect(ctx, resultsDir)
+	require.NoError(t, err)
+	assert.Nil(t, data.BottomScenarios, "disabled by default")
+
+	data, err = NewKrknAIAggregator(ctx).WithBottomScenariosCount(2).Collect(ctx, resultsDir)
+	require.NoError(t, err)
+
+	// Lowest fitness first; the failed dns-outage scenario is not a candidate
+	require.Len(t, data.BottomScenarios, 2)
+	assert.Equal(t, "pod-scenarios", data.BottomScenarios[0].Scenario)
+	assert.Equal(t, 1.5, data.BottomScenarios[0].FitnessScore)
+	assert.Equal(t, "node-io-hog", data.BottomScenarios[1].Scenario)
+	assert.Equal(t, 2.2, data.TopScenarios[0].FitnessScore, "top scenarios are unaffected")
+}
+
+func TestHealthCheckReport_Applications(t *testing.T) {
+	report := HealthCheckReport{
+		{ScenarioID: 1, ComponentName: "console", AverageResponseTime: 0.1, SuccessCount: 10, FailureCount: 0},
+		{ScenarioID: 1, ComponentName: "api", AverageResponseTime: 0.5, SuccessCount: 5, FailureCount: 5},
+		{ScenarioID: 2, ComponentName: "console", AverageResponseTime: 0.4, SuccessCount: 20, FailureCount: 10},
+		{ScenarioID: 2, ComponentName: "idle"},
+	}
+
+	apps := report.Applications()
+	require.Len(t, apps, 3)
+	assert.Equal(t, ApplicationHealth{ComponentName: "api", SuccessCount: 5, FailureCount: 5, AverageResponseTime: 0.5}, apps[0])
+	assert.Equal(t, "console", apps[1].ComponentName)
+	assert.Equal(t, 30, apps[1].SuccessCount)
+	assert.Equal(t, 10, apps[1].FailureCount)
+	assert.InDelta(t, 0.325, apps[1].AverageResponseTime, 1e-9, "weighted by check count")
+	assert.Equal(t, 0.75, apps[1].SuccessRate())
+	assert.Equal(t, 1.0, apps[2].SuccessRate(), "no checks counts as healthy")
+	assert.Empty(t, HealthCheckReport(nil).Applications())
+}
+
 func TestKrknAIAggregator_SkipsPNGFiles(t *testing.T) {
 	tempDir := t.TempDir()
 	resultsDir := filepath.Join(tempDir, "results")
@@ -354,3 +401,360 @@ func TestCollect_ClusterInfoIsolation(t *testing.T) {
 	data.ClusterInfo.ID = "mutated-output"
 	assert.Equal(t, "test-cluster", agg.clusterInfo.ID, "aggregator's stored copy must not be affected by output mutation")
 }
+
+func TestCollect_Manifest(t *testing.T) {
+	resultsDir := t.TempDir()
+	layout := DefaultLayout()
+	reportsDir := filepath.Join(resultsDir, layout.Generations, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createKrknAITestFiles(t, filepath.Join(resultsDir, layout.Generations), reportsDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(resultsDir, layout.Logs), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultsDir, layout.Logs, "run.log"), []byte("Running scenario: pod_scenarios\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultsDir, layout.Config), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultsDir, layout.Config, "effective-config.yaml"), []byte("generations: 20\n"), 0o644))
+
+	manifest := &Manifest{
+		ClusterID:      "abc123",
+		ClusterVersion: "4.16.2",
+		Mode:           "run",
+		StartTime:      time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC),
+		ExitStatus:     ExitStatusRunning,
+		Layout:         layout,
+	}
+	require.NoError(t, manifest.Write(resultsDir))
+	read, err := ReadManifest(resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, read)
+
+	data, err := NewKrknAIAggregator(context.Background()).Collect(context.Background(), resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 5, data.Summary.TotalScenarioCount, "scenarios are read from the generations directory")
+	assert.Contains(t, data.ConfigSummary, "generations: 20")
+	assert.Equal(t, &ClusterInfo{ID: "abc123", Version: "4.16.2"}, data.ClusterInfo, "the manifest fills in the cluster")
+
+	var sources []string
+	for _, artifact := range data.LogArtifacts {
+		rel, err := filepath.Rel(resultsDir, artifact.Source)
+		require.NoError(t, err)
+		sources = append(sources, rel)
+	}
+	assert.ElementsMatch(t, []string{filepath.Join(layout.Generations, "krkn-ai.yaml"), filepath.Join(layout.Logs, "run.log")}, sources)
+
+	// Cluster info set on the aggregator takes precedence
+	data, err = NewKrknAIAggregator(context.Background()).WithClusterInfo(&ClusterInfo{ID: "other"}).Collect(context.Background(), resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, "other", data.ClusterInfo.ID)
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest, err := ReadManifest(dir)
+	require.NoError(t, err)
+	assert.Nil(t, manifest, "results directories without a manifest have none")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte("layout: {config: config, logs: logs, generations: ../elsewhere, analysis: analysis}\n"), 0o644))
+	_, err = ReadManifest(dir)
+	assert.ErrorContains(t, err, `generations directory "../elsewhere" is not inside the results directory`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte("layout: [\n"), 0o644))
+	_, err = ReadManifest(dir)
+	assert.ErrorContains(t, err, "invalid run manifest")
+}
+
+func TestCollect_TimedOutScenarios(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+
+	allCSV := `generation_id,scenario_id,scenario,parameters,health_check_failure_score,health_check_response_time_score,krkn_failure_score,fitness_score,timed_out
+0,1,node-cpu-hog,"chaos-duration=60",0.0,1.2,0.0,2.2,false
+0,2,pod-scenarios,"namespace=openshift-monitoring",0.0,0.0,-1.0,-1.0,true
+1,3,node-io-hog,"chaos-duration=60",0.0,0.8,0.0,1.8,`
+	require.NoError(t, os.WriteFile(filepath.Join(reportsDir, "all.csv"), []byte(allCSV), 0o644))
+
+	data, err := NewKrknAIAggregator(context.Background()).Collect(context.Background(), tempDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, data.Summary.TimedOutScenarioCount)
+	require.Len(t, data.FailedScenarios, 1)
+	assert.True(t, data.FailedScenarios[0].TimedOut)
+	for _, s := range data.TopScenarios {
+		assert.False(t, s.TimedOut)
+	}
+}
+
+func TestCollect_TimeoutColumnMissing(t *testing.T) {
+	tempDir := t.TempDir()
+	resultsDir := filepath.Join(tempDir, "results")
+	reportsDir := filepath.Join(resultsDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createKrknAITestFiles(t, resultsDir, reportsDir)
+
+	data, err := NewKrknAIAggregator(context.Background()).Collect(context.Background(), resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, data.Summary.TimedOutScenarioCount)
+}
+
+func TestCollect_Timeout(t *testing.T) {
+	tempDir := t.TempDir()
+	resultsDir := filepath.Join(tempDir, "results")
+	reportsDir := filepath.Join(resultsDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createKrknAITestFiles(t, resultsDir, reportsDir)
+
+	ctx := context.Background()
+	agg := NewKrknAIAggregator(ctx).WithCollectTimeout(time.Nanosecond)
+	_, err := agg.Collect(ctx, resultsDir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "krkn-ai result collection timed out after 1ns")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A generous timeout behaves like no timeout
+	data, err := agg.WithCollectTimeout(time.Minute).Collect(ctx, resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 5, data.Summary.TotalScenarioCount)
+}
+
+func TestCollect_Concurrency(t *testing.T) {
+	tempDir := t.TempDir()
+	resultsDir := filepath.Join(tempDir, "results")
+	reportsDir := filepath.Join(resultsDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createKrknAITestFiles(t, resultsDir, reportsDir)
+
+	logsDir := filepath.Join(resultsDir, "logs")
+	require.NoError(t, os.MkdirAll(logsDir, 0o755))
+	for i := range 50 {
+		content := strings.Repeat("line\n", i)
+		require.NoError(t, os.WriteFile(filepath.Join(logsDir, fmt.Sprintf("run_%02d.log", i)), []byte(content), 0o644))
+	}
+
+	ctx := context.Background()
+	serial, err := NewKrknAIAggregator(ctx).WithConcurrency(1).Collect(ctx, resultsDir)
+	require.NoError(t, err)
+	for range 5 {
+		parallel, err := NewKrknAIAggregator(ctx).WithConcurrency(8).Collect(ctx, resultsDir)
+		require.NoError(t, err)
+		assert.Equal(t, serial.LogArtifacts, parallel.LogArtifacts)
+		assert.Equal(t, serial.TopScenarios, parallel.TopScenarios)
+		assert.Equal(t, serial.FailedScenarios, parallel.FailedScenarios)
+	}
+
+	// An unreadable file is reported with its path without dropping the others
+	broken := filepath.Join(logsDir, "broken.log")
+	require.NoError(t, os.Symlink(filepath.Join(tempDir, "missing"), broken))
+	data := &KrknAIData{}
+	err = NewKrknAIAggregator(ctx).WithConcurrency(8).collectLogArtifacts(ctx, []string{resultsDir}, data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), broken)
+	assert.Len(t, data.LogArtifacts, len(serial.LogArtifacts)+1)
+}
+
+func TestKrknAIAggregator_ScenarioNormalization(t *testing.T) {
+	ctx := context.Background()
+	agg := NewKrknAIAggregator(ctx).WithScenarioNormalization(map[string]string{
+		"pod-scenario":  "pod_scenarios",
+		"pod-scenarios": "pod_scenarios",
+	})
+
+	scenarios := []ScenarioResult{
+		{ScenarioID: 1, Scenario: "pod-scenario", FitnessScore: 2.0},
+		{ScenarioID: 2, Scenario: "pod-scenarios", FitnessScore: -1.0, KrknFailureScore: -1.0},
+		{ScenarioID: 3, Scenario: "node-cpu-hog", FitnessScore: 1.5},
+	}
+
+	data := &KrknAIData{}
+	agg.processScenarios(data, scenarios)
+
+	assert.Equal(t, []string{"node-cpu-hog", "pod_scenarios"}, data.Summary.ScenarioTypes)
+	assert.Equal(t, "pod_scenarios", data.TopScenarios[0].Scenario)
+	assert.Equal(t, "pod_scenarios", data.FailedScenarios[0].Scenario)
+	assert.Equal(t, "node-cpu-hog", data.TopScenarios[1].Scenario)
+}
+
+func TestKrknAIAggregator_LatestGenerationOnly(t *testing.T) {
+	ctx := context.Background()
+	agg := NewKrknAIAggregator(ctx).WithTopScenariosCount(2).WithLatestGenerationOnly(true)
+
+	scenarios := []ScenarioResult{
+		{GenerationID: 0, ScenarioID: 1, Scenario: "node-cpu-hog", FitnessScore: 5.0},
+		{GenerationID: 0, ScenarioID: 2, Scenario: "dns-outage", FitnessScore: -1.0, KrknFailureScore: -1.0},
+		{GenerationID: 1, ScenarioID: 3, Scenario: "node-io-hog", FitnessScore: 1.8},
+		{GenerationID: 1, ScenarioID: 4, Scenario: "pod-scenarios", FitnessScore: 1.2},
+		{GenerationID: 1, ScenarioID: 5, Scenario: "node-memory-hog", FitnessScore: 0.5},
+		{GenerationID: 1, ScenarioID: 6, Scenario: "dns-outage", FitnessScore: -1.0, KrknFailureScore: -1.0},
+	}
+
+	data := &KrknAIData{}
+	agg.processScenarios(data, scenarios)
+
+	// Summary covers the full run
+	assert.Equal(t, 6, data.Summary.TotalScenarioCount)
+	assert.Equal(t, 2, data.Summary.FailedScenarioCount)
+	assert.Equal(t, 5.0, data.Summary.MaxFitnessScore)
+
+	// Scenario lists cover only the last generation, still limited to the top N
+	require.NotNil(t, data.ScopedGeneration)
+	assert.Equal(t, 1, *data.ScopedGeneration)
+	require.Len(t, data.TopScenarios, 2)
+	assert.Equal(t, 3, data.TopScenarios[0].ScenarioID)
+	assert.Equal(t, 4, data.TopScenarios[1].ScenarioID)
+	require.Len(t, data.FailedScenarios, 1)
+	assert.Equal(t, 6, data.FailedScenarios[0].ScenarioID)
+}
+
+func TestCollect_DuplicateScenarios(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+
+	allCSV := `generation_id,scenario_id,scenario,parameters,health_check_failure_score,health_check_response_time_score,krkn_failure_score,fitness_score
+0,1,node-cpu-hog,"chaos-duration=60",0.0,1.2,0.0,2.2
+0,2,pod-scenarios,"namespace=openshift-monitoring",0.0,0.0,-1.0,-1.0
+0,2,pod-scenarios,"namespace=openshift-monitoring",0.0,0.5,0.0,1.5
+1,3,node-io-hog,"chaos-duration=60",0.0,0.8,0.0,1.8`
+	require.NoError(t, os.WriteFile(filepath.Join(reportsDir, "all.csv"), []byte(allCSV), 0o644))
+
+	ctx := context.Background()
+
+	// Flag mode (default) keeps every entry and reports the duplicate count
+	data, err := NewKrknAIAggregator(ctx).Collect(ctx, tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, 4, data.Summary.TotalScenarioCount)
+	assert.Equal(t, 1, data.Summary.DuplicateScenarioCount)
+	assert.Equal(t, 1, data.Summary.FailedScenarioCount)
+
+	// Dedupe mode keeps the latest entry for each scenario ID
+	data, err = NewKrknAIAggregator(ctx).WithDuplicateHandling(DuplicatesDedupe).Collect(ctx, tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, data.Summary.TotalScenarioCount)
+	assert.Equal(t, 1, data.Summary.DuplicateScenarioCount)
+	assert.Equal(t, 0, data.Summary.FailedScenarioCount)
+	assert.Equal(t, 3, data.Summary.SuccessfulScenarioCount)
+}
+
+func TestCollect_Deduplication(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+
+	allCSV := `generation_id,scenario_id,scenario,parameters,health_check_failure_score,health_check_response_time_score,krkn_failure_score,fitness_score
+0,1,node-cpu-hog,"chaos-duration=60",0.0,1.2,0.0,2.2
+0,2,pod-scenarios,"namespace=openshift-monitoring",0.0,0.0,-1.0,-1.0
+0,3,node-cpu-hog,"chaos-duration=60",0.0,0.0,-1.0,-1.0
+1,4,pod-scenarios,"namespace=openshift-monitoring",0.0,0.0,-1.0,-1.0
+1,5,pod-scenarios,"namespace=openshift-dns",0.0,0.0,-1.0,-1.0
+2,6,pod-scenarios,"namespace=openshift-monitoring",0.0,0.0,-1.0,-1.0`
+	require.NoError(t, os.WriteFile(filepath.Join(reportsDir, "all.csv"), []byte(allCSV), 0o644))
+
+	ctx := context.Background()
+	plain, err := NewKrknAIAggregator(ctx).Collect(ctx, tempDir)
+	require.NoError(t, err)
+	require.Len(t, plain.FailedScenarios, 5)
+	assert.Zero(t, plain.FailedScenarios[0].Occurrences)
+
+	data, err := NewKrknAIAggregator(ctx).WithDeduplication(true).Collect(ctx, tempDir)
+	require.NoError(t, err)
+
+	// The first failure of each scenario type and parameter set represents its group
+	require.Len(t, data.FailedScenarios, 3)
+	assert.Equal(t, 2, data.FailedScenarios[0].ScenarioID)
+	assert.Equal(t, 3, data.FailedScenarios[0].Occurrences)
+	assert.Equal(t, 3, data.FailedScenarios[1].ScenarioID)
+	assert.Equal(t, 1, data.FailedScenarios[1].Occurrences)
+	assert.Equal(t, 5, data.FailedScenarios[2].ScenarioID)
+	assert.Equal(t, 1, data.FailedScenarios[2].OccurrenceCount())
+
+	// Statistics and top scenarios still cover every execution
+	assert.Equal(t, plain.Summary, data.Summary)
+	assert.Equal(t, plain.TopScenarios, data.TopScenarios)
+}
+
+func TestCollect_ScenarioIDFilter(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createKrknAITestFiles(t, tempDir, reportsDir)
+
+	logsDir := filepath.Join(tempDir, "logs")
+	require.NoError(t, os.MkdirAll(logsDir, 0o755))
+	for _, name := range []string{"scenario_2.log", "scenario_3.log", "krkn-ai.log"} {
+		require.NoError(t, os.WriteFile(filepath.Join(logsDir, name), []byte("log\n"), 0o644))
+	}
+
+	ctx := context.Background()
+	data, err := NewKrknAIAggregator(ctx).WithScenarioIDFilter([]int{2, 5, 42}).Collect(ctx, tempDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{2, 5, 42}, data.ScenarioIDFilter)
+	assert.Equal(t, 2, data.Summary.TotalScenarioCount)
+	assert.Equal(t, 1, data.Summary.FailedScenarioCount)
+	require.Len(t, data.TopScenarios, 1)
+	assert.Equal(t, 2, data.TopScenarios[0].ScenarioID)
+
+	require.Len(t, data.HealthCheckReport, 2)
+	assert.Equal(t, 2, data.HealthCheckReport[0].ScenarioID)
+	assert.Equal(t, 5, data.HealthCheckReport[1].ScenarioID)
+
+	var artifacts []string
+	for _, artifact := range data.LogArtifacts {
+		artifacts = append(artifacts, filepath.Base(artifact.Source))
+	}
+	assert.Contains(t, artifacts, "scenario_2.log")
+	assert.Contains(t, artifacts, "krkn-ai.log", "shared artifacts are kept")
+	assert.NotContains(t, artifacts, "scenario_3.log")
+
+	_, err = NewKrknAIAggregator(ctx).WithScenarioIDFilter([]int{99}).Collect(ctx, tempDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none of the requested scenario IDs [99] were found")
+}
+
+func TestKrknAIAggregator_BestScenario(t *testing.T) {
+	ctx := context.Background()
+	scenarios := []ScenarioResult{
+		{GenerationID: 0, ScenarioID: 1, Scenario: "node-cpu-hog", Parameters: "cpu-percentage=90", FitnessScore: 5.0},
+		{GenerationID: 1, ScenarioID: 2, Scenario: "dns-outage", FitnessScore: 9.0, KrknFailureScore: -1.0},
+		{GenerationID: 1, ScenarioID: 3, Scenario: "node-io-hog", FitnessScore: 1.8},
+	}
+
+	// The champion skips failed scenarios
+	data := &KrknAIData{}
+	NewKrknAIAggregator(ctx).processScenarios(data, scenarios)
+	require.NotNil(t, data.BestScenario)
+	assert.Equal(t, 1, data.BestScenario.ScenarioID)
+	assert.Equal(t, "cpu-percentage=90", data.BestScenario.Parameters)
+
+	// and follows the latest-generation scope
+	scoped := &KrknAIData{}
+	NewKrknAIAggregator(ctx).WithLatestGenerationOnly(true).processScenarios(scoped, scenarios)
+	require.NotNil(t, scoped.BestScenario)
+	assert.Equal(t, 3, scoped.BestScenario.ScenarioID)
+
+	empty := &KrknAIData{}
+	NewKrknAIAggregator(ctx).processScenarios(empty, []ScenarioResult{{ScenarioID: 1, KrknFailureScore: -1.0}})
+	assert.Nil(t, empty.BestScenario)
+}
+
+func TestMergeSummaries(t *testing.T) {
+	merged := MergeSummaries(
+		KrknAISummary{
+			TotalScenarioCount: 4, SuccessfulScenarioCount: 3, FailedScenarioCount: 1, TimedOutScenarioCount: 1,
+			Generations: 2, MaxFitnessScore: 2.0, AvgFitnessScore: 1.0, ScenarioTypes: []string{"pod-scenarios", "dns-outage"},
+		},
+		KrknAISummary{
+			TotalScenarioCount: 2, SuccessfulScenarioCount: 1, FailedScenarioCount: 1,
+			Generations: 5, MaxFitnessScore: 3.0, AvgFitnessScore: 3.0, ScenarioTypes: []string{"node-cpu-hog", "pod-scenarios"},
+		},
+		KrknAISummary{TotalScenarioCount: 1, FailedScenarioCount: 1, MaxFitnessScore: 9.0},
+	)
+
+	assert.Equal(t, 7, merged.TotalScenarioCount)
+	assert.Equal(t, 4, merged.SuccessfulScenarioCount)
+	assert.Equal(t, 3, merged.FailedScenarioCount)
+	assert.Equal(t, 1, merged.TimedOutScenarioCount)
+	assert.Equal(t, 5, merged.Generations)
+	assert.Equal(t, 3.0, merged.MaxFitnessScore, "runs without successful scenarios don't contribute fitness")
+	assert.InDelta(t, 1.5, merged.AvgFitnessScore, 1e-9)
+	assert.Equal(t, []string{"dns-outage", "node-cpu-hog", "pod-scenarios"}, merged.ScenarioTypes)
+}
@@ -0,0 +1,94 @@
+package aggregator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFileName is the run manifest at the top of a results directory.
+const ManifestFileName = "manifest.yaml"
+
+// Run exit statuses recorded in the manifest.
+const (
+	ExitStatusRunning = "running" // The run started and hasn't finished, or was killed
+	ExitStatusPassed  = "passed"
+	ExitStatusFailed  = "failed"
+	ExitStatusAborted = "aborted" // Stopped by the abort guardrails; the results are partial
+)
+
+// ManifestLayout names the subdirectories of a results directory, relative to it.
+type ManifestLayout struct {
+	Config      string `yaml:"config"`      // The configs the run used, credentials redacted
+	Logs        string `yaml:"logs"`        // Container output, one file per mode
+	Generations string `yaml:"generations"` // krkn-ai's output directory: reports, checkpoints, and per-scenario files
+	Analysis    string `yaml:"analysis"`    // The log analysis output
+}
+
+// DefaultLayout returns the layout the executor creates.
+func DefaultLayout() ManifestLayout {
+	return ManifestLayout{Config: "config", Logs: "logs", Generations: "generations", Analysis: "analysis"}
+}
+
+// Manifest describes a krkn-ai run and where its results are. Results directories from
+// before the manifest have none and hold krkn-ai's output at the top.
+type Manifest struct {
+	ClusterID      string            `yaml:"cluster_id,omitempty"`
+	ClusterVersion string            `yaml:"cluster_version,omitempty"`
+	Mode           string            `yaml:"mode"`
+	Parameters     map[string]string `yaml:"parameters,omitempty"` // The krkn-ai parameters set, credentials redacted
+	StartTime      time.Time         `yaml:"start_time"`
+	EndTime        time.Time         `yaml:"end_time,omitempty"`
+	ExitStatus     string            `yaml:"exit_status"`
+	Error          string            `yaml:"error,omitempty"`
+	Layout         ManifestLayout    `yaml:"layout"`
+}
+
+// ReadManifest reads the manifest of resultsDir, returning nil when there is none.
+func ReadManifest(resultsDir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(resultsDir, ManifestFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid run manifest: %w", err)
+	}
+	for _, dir := range []struct{ name, path string }{
+		{"config", manifest.Layout.Config},
+		{"logs", manifest.Layout.Logs},
+		{"generations", manifest.Layout.Generations},
+		{"analysis", manifest.Layout.Analysis},
+	} {
+		if !filepath.IsLocal(dir.path) {
+			return nil, fmt.Errorf("invalid run manifest: %s directory %q is not inside the results directory", dir.name, dir.path)
+		}
+	}
+	return &manifest, nil
+}
+
+// Write writes the manifest to resultsDir, replacing the previous one with a rename so
+// readers never see it partially written.
+func (m *Manifest) Write(resultsDir string) error {
+	content, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run manifest: %w", err)
+	}
+	path := filepath.Join(resultsDir, ManifestFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package analysisengine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const indexFileName = "index.yaml"
+
+// Artifact types recorded in the analysis index.
+const (
+	ArtifactTypeSummary = "summary"
+)
+
+// ArtifactEntry describes one file written to the analysis directory.
+type ArtifactEntry struct {
+	Path string `yaml:"path" json:"path"` // Relative to the analysis directory
+	Type string `yaml:"type" json:"type"`
+}
+
+// writeArtifact writes a file to the analysis directory and records it for the index.
+func (e *Engine) writeArtifact(name, artifactType string, content []byte) error {
+	path := filepath.Join(e.outputDir(), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create analysis directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	for i, entry := range e.artifacts {
+		if entry.Path == name {
+			e.artifacts[i].Type = artifactType
+			return nil
+		}
+	}
+	e.artifacts = append(e.artifacts, ArtifactEntry{Path: name, Type: artifactType})
+	return nil
+}
+
+// writeIndex writes index.yaml listing every artifact written during the run.
+func (e *Engine) writeIndex() error {
+	content, err := yaml.Marshal(map[string]any{
+		"artifacts": e.artifacts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact index: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(e.outputDir(), indexFileName), content, 0o644); err != nil {
+		return fmt.Errorf("failed to write artifact index: %w", err)
+	}
+	return nil
+}
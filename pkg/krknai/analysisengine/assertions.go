@@ -0,0 +1,130 @@
+package analysisengine
+
+import (
+	"fmt"
+	"strings"
+
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+)
+
+// Assertion fields evaluated against the aggregated krkn-ai data.
+const (
+	FieldTotalScenarios      = "total_scenarios"
+	FieldSuccessfulScenarios = "successful_scenarios"
+	FieldFailedScenarios     = "failed_scenarios"
+	FieldTimedOutScenarios   = "timed_out_scenarios"
+	FieldFailureRate         = "failure_rate" // failed / total, 0-1
+	FieldSuccessRate         = "success_rate" // successful / total, 0-1
+	FieldTimeoutRate         = "timeout_rate" // timed out / total, 0-1
+	FieldMaxFitnessScore     = "max_fitness_score"
+	FieldAvgFitnessScore     = "avg_fitness_score"
+)
+
+var assertionFields = map[string]func(krknAggregator.KrknAISummary) float64{
+	FieldTotalScenarios:      func(s krknAggregator.KrknAISummary) float64 { return float64(s.TotalScenarioCount) },
+	FieldSuccessfulScenarios: func(s krknAggregator.KrknAISummary) float64 { return float64(s.SuccessfulScenarioCount) },
+	FieldFailedScenarios:     func(s krknAggregator.KrknAISummary) float64 { return float64(s.FailedScenarioCount) },
+	FieldTimedOutScenarios:   func(s krknAggregator.KrknAISummary) float64 { return float64(s.TimedOutScenarioCount) },
+	FieldFailureRate:         func(s krknAggregator.KrknAISummary) float64 { return rate(s.FailedScenarioCount, s.TotalScenarioCount) },
+	FieldSuccessRate: func(s krknAggregator.KrknAISummary) float64 {
+		return rate(s.SuccessfulScenarioCount, s.TotalScenarioCount)
+	},
+	FieldTimeoutRate: func(s krknAggregator.KrknAISummary) float64 {
+		return rate(s.TimedOutScenarioCount, s.TotalScenarioCount)
+	},
+	FieldMaxFitnessScore: func(s krknAggregator.KrknAISummary) float64 { return s.MaxFitnessScore },
+	FieldAvgFitnessScore: func(s krknAggregator.KrknAISummary) float64 { return s.AvgFitnessScore },
+}
+
+var assertionOps = map[string]func(actual, expected float64) bool{
+	"<":  func(a, e float64) bool { return a < e },
+	"<=": func(a, e float64) bool { return a <= e },
+	">":  func(a, e float64) bool { return a > e },
+	">=": func(a, e float64) bool { return a >= e },
+	"==": func(a, e float64) bool { return a == e },
+	"!=": func(a, e float64) bool { return a != e },
+}
+
+// Assertion is a deterministic check evaluated against the collected results,
+// e.g. {Field: "failed_scenarios", Scenario: "network_chaos", Op: "<=", Value: 2}.
+type Assertion struct {
+	Name     string  `yaml:"name" json:"name"`
+	Field    string  `yaml:"field" json:"field"`
+	Op       string  `yaml:"op" json:"op"`
+	Value    float64 `yaml:"value" json:"value"`
+	Scenario string  `yaml:"scenario,omitempty" json:"scenario,omitempty"` // Limits failed_scenarios to one scenario type
+}
+
+// AssertionResult records the outcome of a single assertion.
+type AssertionResult struct {
+	Assertion `yaml:",inline"`
+	Actual    float64 `yaml:"actual" json:"actual"`
+	Passed    bool    `yaml:"passed" json:"passed"`
+}
+
+// validateAssertions rejects unknown fields and operators before any analysis runs.
+func validateAssertions(assertions []Assertion) error {
+	for i, a := range assertions {
+		if _, ok := assertionFields[a.Field]; !ok {
+			return fmt.Errorf("assertion %d (%s): unsupported field %q", i, a.Name, a.Field)
+		}
+		if _, ok := assertionOps[a.Op]; !ok {
+			return fmt.Errorf("assertion %d (%s): unsupported operator %q", i, a.Name, a.Op)
+		}
+		// Only failed scenarios are retained in full, so other counts cannot be filtered by type
+		if a.Scenario != "" && a.Field != FieldFailedScenarios {
+			return fmt.Errorf("assertion %d (%s): scenario filter is only supported for %s", i, a.Name, FieldFailedScenarios)
+		}
+	}
+	return nil
+}
+
+// evaluateAssertions checks each assertion against the data. Assertions must
+// already have passed validateAssertions.
+func evaluateAssertions(assertions []Assertion, data *krknAggregator.KrknAIData) []AssertionResult {
+	if len(assertions) == 0 {
+		return nil
+	}
+
+	results := make([]AssertionResult, 0, len(assertions))
+	for _, a := range assertions {
+		actual := assertionFields[a.Field](data.Summary)
+		if a.Scenario != "" {
+			actual = 0
+			for _, s := range data.FailedScenarios {
+				if s.Scenario == a.Scenario {
+					actual += float64(s.OccurrenceCount())
+				}
+			}
+		}
+		results = append(results, AssertionResult{
+			Assertion: a,
+			Actual:    actual,
+			Passed:    assertionOps[a.Op](actual, a.Value),
+		})
+	}
+	return results
+}
+
+// failedAssertions returns a description of each failed assertion.
+func failedAssertions(results []AssertionResult) []string {
+	var failed []string
+	for _, r := range results {
+		if r.Passed {
+			continue
+		}
+		name := r.Name
+		if name == "" {
+			name = strings.TrimSpace(fmt.Sprintf("%s %s", r.Field, r.Scenario))
+		}
+		failed = append(failed, fmt.Sprintf("%s: expected %s %g, got %g", name, r.Op, r.Value, r.Actual))
+	}
+	return failed
+}
+
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
@@ -0,0 +1,107 @@
+package analysisengine
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+	"gopkg.in/yaml.v3"
+)
+
+// CampaignRollup combines the analyses of every run in a campaign.
+type CampaignRollup struct {
+	CampaignID string                       `yaml:"campaign_id"`
+	Runs       []CampaignRun                `yaml:"runs"`
+	Statuses   map[string]int               `yaml:"statuses"` // Run count per result status
+	Summary    krknAggregator.KrknAISummary `yaml:"summary"`  // Merged across all runs
+}
+
+// CampaignRun is a single run's entry in a campaign roll-up.
+type CampaignRun struct {
+	SummaryPath string                       `yaml:"summary_path"`
+	Timestamp   string                       `yaml:"timestamp"`
+	ClusterID   string                       `yaml:"cluster_id,omitempty"`
+	Status      string                       `yaml:"status"`
+	Severity    string                       `yaml:"severity,omitempty"`
+	Summary     krknAggregator.KrknAISummary `yaml:"summary"`
+}
+
+// campaignSummary is the subset of summary.yaml needed for a campaign roll-up.
+type campaignSummary struct {
+	Timestamp   string `yaml:"timestamp"`
+	Status      string `yaml:"status"`
+	ClusterInfo *struct {
+		ID string `yaml:"id"`
+	} `yaml:"cluster_info"`
+	RunSummary struct {
+		TotalScenarios      int      `yaml:"total_scenarios"`
+		SuccessfulScenarios int      `yaml:"successful_scenarios"`
+		FailedScenarios     int      `yaml:"failed_scenarios"`
+		TimedOutScenarios   int      `yaml:"timed_out_scenarios"`
+		DuplicateScenarios  int      `yaml:"duplicate_scenarios"`
+		Generations         int      `yaml:"generations"`
+		MaxFitnessScore     float64  `yaml:"max_fitness_score"`
+		AvgFitnessScore     float64  `yaml:"avg_fitness_score"`
+		ScenarioTypes       []string `yaml:"scenario_types"`
+	} `yaml:"run_summary"`
+	Metadata map[string]any `yaml:"metadata"`
+}
+
+// BuildCampaignRollup reads the given summary.yaml files and combines them into a single
+// report. Every summary must carry campaignID in its metadata; runs are ordered by timestamp.
+func BuildCampaignRollup(campaignID string, summaryPaths []string) (*CampaignRollup, error) {
+	if campaignID == "" {
+		return nil, fmt.Errorf("campaign ID is required")
+	}
+	if len(summaryPaths) == 0 {
+		return nil, fmt.Errorf("no summaries provided for campaign %q", campaignID)
+	}
+
+	rollup := &CampaignRollup{CampaignID: campaignID, Statuses: make(map[string]int)}
+	summaries := make([]krknAggregator.KrknAISummary, 0, len(summaryPaths))
+	for _, path := range summaryPaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read summary: %w", err)
+		}
+		var summary campaignSummary
+		if err := yaml.Unmarshal(content, &summary); err != nil {
+			return nil, fmt.Errorf("failed to parse summary %s: %w", path, err)
+		}
+		if id, _ := summary.Metadata["campaign_id"].(string); id != campaignID {
+			return nil, fmt.Errorf("summary %s belongs to campaign %q, not %q", path, id, campaignID)
+		}
+
+		rs := summary.RunSummary
+		runSummary := krknAggregator.KrknAISummary{
+			TotalScenarioCount:      rs.TotalScenarios,
+			SuccessfulScenarioCount: rs.SuccessfulScenarios,
+			FailedScenarioCount:     rs.FailedScenarios,
+			TimedOutScenarioCount:   rs.TimedOutScenarios,
+			DuplicateScenarioCount:  rs.DuplicateScenarios,
+			Generations:             rs.Generations,
+			MaxFitnessScore:         rs.MaxFitnessScore,
+			AvgFitnessScore:         rs.AvgFitnessScore,
+			ScenarioTypes:           rs.ScenarioTypes,
+		}
+		run := CampaignRun{
+			SummaryPath: path,
+			Timestamp:   summary.Timestamp,
+			Status:      summary.Status,
+			Summary:     runSummary,
+		}
+		if summary.ClusterInfo != nil {
+			run.ClusterID = summary.ClusterInfo.ID
+		}
+		run.Severity, _ = summary.Metadata["severity"].(string)
+
+		rollup.Runs = append(rollup.Runs, run)
+		rollup.Statuses[summary.Status]++
+		summaries = append(summaries, runSummary)
+	}
+
+	sort.SliceStable(rollup.Runs, func(i, j int) bool { return rollup.Runs[i].Timestamp < rollup.Runs[j].Timestamp })
+	rollup.Summary = krknAggregator.MergeSummaries(summaries...)
+	return rollup, nil
+}
@@ -0,0 +1,85 @@
+package analysisengine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openshift/osde2e/internal/analysisengine"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+)
+
+// Comparison describes how a run changed relative to a baseline run. Scenarios are
+// compared by type, since the genetic algorithm rarely repeats exact parameters: a type
+// is failing in a run when any of its executions failed.
+type Comparison struct {
+	NewlyFailing        []string                     `json:"newlyFailing"` // Failing now, not in the baseline
+	NewlyPassing        []string                     `json:"newlyPassing"` // Failing in the baseline, now executed without failures
+	MaxFitnessDelta     float64                      `json:"maxFitnessDelta"`
+	AvgFitnessDelta     float64                      `json:"avgFitnessDelta"`
+	FailedScenarioDelta int                          `json:"failedScenarioDelta"`
+	Baseline            krknAggregator.KrknAISummary `json:"baseline"`
+}
+
+// RunComparison analyzes the results in currentDir against those in baselineDir. The
+// comparison is passed to the prompt as Comparison so the narrative focuses on what
+// changed, and its counts and deltas are recorded in the metadata. Outputs are written
+// under currentDir, which defaults to ArtifactsDir; the cool-down cache is not used.
+func (e *Engine) RunComparison(ctx context.Context, baselineDir, currentDir string) (*analysisengine.Result, error) {
+	if baselineDir == "" {
+		return nil, fmt.Errorf("baseline results directory is required")
+	}
+
+	run := e
+	if currentDir != "" && currentDir != e.config.ArtifactsDir {
+		config := *e.config
+		config.ArtifactsDir = currentDir
+		current := *e
+		current.config = &config
+		run = &current
+	}
+	return run.run(ctx, baselineDir, nil)
+}
+
+// compareRuns diffs the current run against the baseline.
+func compareRuns(baseline, current *krknAggregator.KrknAIData) *Comparison {
+	baselineFailing := failingTypes(baseline)
+	currentFailing := failingTypes(current)
+
+	comparison := &Comparison{
+		MaxFitnessDelta:     current.Summary.MaxFitnessScore - baseline.Summary.MaxFitnessScore,
+		AvgFitnessDelta:     current.Summary.AvgFitnessScore - baseline.Summary.AvgFitnessScore,
+		FailedScenarioDelta: current.Summary.FailedScenarioCount - baseline.Summary.FailedScenarioCount,
+		Baseline:            baseline.Summary,
+	}
+	for scenario := range currentFailing {
+		if !baselineFailing[scenario] {
+			comparison.NewlyFailing = append(comparison.NewlyFailing, scenario)
+		}
+	}
+	for _, scenario := range current.Summary.ScenarioTypes {
+		if baselineFailing[scenario] && !currentFailing[scenario] {
+			comparison.NewlyPassing = append(comparison.NewlyPassing, scenario)
+		}
+	}
+	sort.Strings(comparison.NewlyFailing)
+	return comparison
+}
+
+// failingTypes returns the scenario types with at least one failed execution.
+func failingTypes(data *krknAggregator.KrknAIData) map[string]bool {
+	failing := make(map[string]bool, len(data.FailedScenarios))
+	for _, s := range data.FailedScenarios {
+		failing[s.Scenario] = true
+	}
+	return failing
+}
+
+// record adds the comparison counts and deltas to the result metadata.
+func (c *Comparison) record(metadata map[string]any) {
+	metadata["newly_failing_scenarios"] = len(c.NewlyFailing)
+	metadata["newly_passing_scenarios"] = len(c.NewlyPassing)
+	metadata["max_fitness_delta"] = c.MaxFitnessDelta
+	metadata["avg_fitness_delta"] = c.AvgFitnessDelta
+	metadata["failed_scenario_delta"] = c.FailedScenarioDelta
+}
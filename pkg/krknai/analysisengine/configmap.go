@@ -0,0 +1,101 @@
+package analysisengine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/osde2e/internal/analysisengine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// maxConfigMapContentBytes keeps the analysis content well under the 1MiB ConfigMap
+// limit, leaving room for the metadata and other keys.
+const maxConfigMapContentBytes = 900 * 1024
+
+// ConfigMapSink publishes the analysis result to a ConfigMap using the in-cluster client.
+type ConfigMapSink struct {
+	Namespace string
+	Name      string
+}
+
+// newConfigMapClient validates the sink and builds an in-cluster Kubernetes client.
+func newConfigMapClient(sink *ConfigMapSink) (kubernetes.Interface, error) {
+	if sink.Namespace == "" || sink.Name == "" {
+		return nil, fmt.Errorf("ConfigMap sink requires both a namespace and a name")
+	}
+
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error getting in-cluster rest config: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error generating Kube Clientset: %w", err)
+	}
+	return client, nil
+}
+
+// writeConfigMap creates or updates the sink ConfigMap with the result status,
+// metadata, and content. Content too large for a ConfigMap is truncated.
+func (e *Engine) writeConfigMap(ctx context.Context, result *analysisengine.Result) error {
+	sink := e.config.ConfigMapSink
+
+	metadata, err := e.redactor.marshalYAML(result.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	content, truncated := truncateContent(result.Content, maxConfigMapContentBytes)
+	data := map[string]string{
+		"status":            result.Status,
+		"metadata.yaml":     string(metadata),
+		"content":           content,
+		"content_truncated": strconv.FormatBool(truncated),
+	}
+	if result.Error != "" {
+		data["error"] = result.Error
+	}
+
+	configMaps := e.kubeClient.CoreV1().ConfigMaps(sink.Namespace)
+	existing, err := configMaps.Get(ctx, sink.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      sink.Name,
+				Namespace: sink.Namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "osde2e"},
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create ConfigMap %s/%s: %w", sink.Namespace, sink.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ConfigMap %s/%s: %w", sink.Namespace, sink.Name, err)
+	}
+
+	existing.Data = data
+	if _, err := configMaps.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s/%s: %w", sink.Namespace, sink.Name, err)
+	}
+	return nil
+}
+
+// truncateContent cuts content to at most limit bytes without splitting a UTF-8
+// character, appending a note when anything was removed.
+func truncateContent(content string, limit int) (string, bool) {
+	if len(content) <= limit {
+		return content, false
+	}
+	note := fmt.Sprintf("\n\n[truncated: content exceeded %d bytes; see %s for the full analysis]", limit, summaryFileName)
+	cut := strings.ToValidUTF8(content[:limit-len(note)], "")
+	return cut + note, true
+}
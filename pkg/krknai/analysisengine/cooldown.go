@@ -0,0 +1,110 @@
+package analysisengine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openshift/osde2e/internal/aggregator"
+	"github.com/openshift/osde2e/internal/analysisengine"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+	"gopkg.in/yaml.v3"
+)
+
+// cachedSummary is the subset of summary.yaml needed to reuse a prior analysis.
+type cachedSummary struct {
+	Timestamp string         `yaml:"timestamp"`
+	Status    string         `yaml:"status"`
+	Prompt    string         `yaml:"prompt"`
+	Response  string         `yaml:"response"`
+	Metadata  map[string]any `yaml:"metadata"`
+}
+
+// hashData returns a content hash of the collected data. Artifacts under excludeDir
+// (the engine's own output) are ignored so a prior summary doesn't change the hash.
+func hashData(data *krknAggregator.KrknAIData, excludeDir string) (string, error) {
+	cp := *data
+	cp.LogArtifacts = artifactsOutside(data.LogArtifacts, excludeDir)
+
+	content, err := json.Marshal(cp)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// artifactsOutside returns the artifacts that are not inside dir.
+func artifactsOutside(artifacts []aggregator.LogEntry, dir string) []aggregator.LogEntry {
+	var kept []aggregator.LogEntry
+	for _, artifact := range artifacts {
+		if dir != "" && isWithinDir(artifact.Source, dir) {
+			continue
+		}
+		kept = append(kept, artifact)
+	}
+	return kept
+}
+
+// isWithinDir reports whether path is inside dir, comparing absolute paths.
+func isWithinDir(path, dir string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// cacheableStatuses are the summary statuses of an analysis the LLM produced. Assertion and
+// gate failures are kept, since the caller judges the cached analysis again; errors and
+// timeouts have no analysis to reuse.
+var cacheableStatuses = map[string]bool{"completed": true, "assertions_failed": true, "failed": true}
+
+// loadCachedResult returns the prior analysis result when the existing summary was
+// produced from data with the same hash within the cool-down window, otherwise nil. The
+// prior run's assertion and gate outcomes are dropped: they are judged again against the
+// current config.
+func (e *Engine) loadCachedResult(dataHash string) *analysisengine.Result {
+	content, err := os.ReadFile(filepath.Join(e.latestDir(), summaryFileName))
+	if err != nil {
+		return nil
+	}
+
+	var prior cachedSummary
+	if err := yaml.Unmarshal(content, &prior); err != nil || !cacheableStatuses[prior.Status] {
+		return nil
+	}
+	if hash, _ := prior.Metadata["data_hash"].(string); hash != dataHash {
+		return nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, prior.Timestamp)
+	if err != nil || time.Since(timestamp) > e.config.CoolDown {
+		return nil
+	}
+
+	metadata := make(map[string]any, len(prior.Metadata)+1)
+	for k, v := range prior.Metadata {
+		metadata[k] = v
+	}
+	metadata["cached"] = true
+	// Recomputed from the current config by the caller
+	for _, key := range []string{"assertions_total", "assertions_failed", "health_degraded", "degraded_applications", thresholdViolationsKey} {
+		delete(metadata, key)
+	}
+
+	return &analysisengine.Result{
+		Status:   "cached",
+		Content:  prior.Response,
+		Prompt:   prior.Prompt,
+		Metadata: metadata,
+	}
+}
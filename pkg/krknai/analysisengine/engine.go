@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
-	"io/fs"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/gomarkdown/markdown"
 	mdhtml "github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
@@ -19,8 +24,9 @@ import (
 	"github.com/openshift/osde2e/internal/llm"
 	"github.com/openshift/osde2e/internal/llm/tools"
 	"github.com/openshift/osde2e/internal/prompts"
+	"github.com/openshift/osde2e/pkg/common/slack"
 	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
-	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/kubernetes"
 )
 
 //go:embed prompts/*
@@ -37,8 +43,129 @@ const (
 // Config holds configuration for the krkn-ai analysis engine.
 type Config struct {
 	analysisengine.BaseConfig
-	TopScenariosCount int    // Number of top scenarios to include (default: 10)
-	ReportFormat      string // "json" (default), "markdown", or "html"
+
+	// Profile names an environment profile (e.g. "stage", "production") whose values fill
+	// any fields left unset; ProfileDir is searched before the embedded profiles.
+	Profile    string
+	ProfileDir string
+
+	// PromptTemplatePath overrides the embedded krknai.yaml prompt with a template file, or a
+	// directory containing krknai.yaml, so the prompt can change without a rebuild
+	PromptTemplatePath string
+
+	TopScenariosCount    int           // Number of top scenarios to include (default: 10)
+	BottomScenariosCount int           // Number of lowest-fitness scenarios to include (default: 0, disabled)
+	ReportFormat         string        // "json" (default), "markdown", or "html"
+	CoolDown             time.Duration // Reuse the prior summary for unchanged results within this window (0 disables)
+	CollectTimeout       time.Duration // Maximum time to spend collecting results (0 disables)
+	CollectConcurrency   int           // Log artifacts read in parallel while collecting (default: 4)
+	AnalysisTimeout      time.Duration // Maximum time for the whole Run, notifications included (0 disables)
+
+	// MaxArtifactReads caps the log artifacts the LLM may read through read_file over one
+	// analysis (0 is unlimited); requests beyond it get a budget-exhausted tool error and
+	// the metadata records artifacts_budget_exceeded. ArtifactReadTimeout bounds each file
+	// read (0 disables).
+	MaxArtifactReads    int
+	ArtifactReadTimeout time.Duration
+
+	// SummaryFormats lists the summary files to write: "yaml" (summary.yaml, the default),
+	// "json" (summary.json), and "markdown" (summary.md)
+	SummaryFormats []string
+
+	// SummaryScenarioGrouping organizes scenarios in the summary: "fitness" (default), "type", or "outcome"
+	SummaryScenarioGrouping string
+
+	// FitnessThreshold and MaxFailedScenarios gate CI: when the max fitness score exceeds
+	// FitnessThreshold or more scenarios than MaxFailedScenarios fail, Run writes the outputs
+	// and sends notifications with status "failed", then returns ErrThresholdExceeded (nil disables)
+	FitnessThreshold   *float64
+	MaxFailedScenarios *int
+
+	// HealthCheckSuccessThreshold gates on SLO impact: when an application's health check
+	// success rate across the run falls below it (0-1), the metadata sets health_degraded,
+	// lists the degraded_applications, and the result fails like the other gates (0 disables)
+	HealthCheckSuccessThreshold float64
+
+	// Assertions are deterministic checks evaluated against the results and recorded in the summary
+	Assertions []Assertion
+	// FailOnAssertions sets the result status to "assertions_failed" when any assertion fails
+	FailOnAssertions bool
+
+	// TimeZone is the IANA zone used for emitted timestamps, e.g. "Europe/Berlin" (default: UTC)
+	TimeZone string
+
+	// AnalyzeLatestGenerationOnly limits the scenario lists to the final generation (summary stats cover the full run)
+	AnalyzeLatestGenerationOnly bool
+
+	// DeduplicateFailures groups failed scenarios with the same type and parameters into one
+	// entry with an occurrence count, keeping the prompt and summary short
+	DeduplicateFailures bool
+
+	// ScenarioIDFilter restricts the analysis to these scenario IDs and their artifacts (default: all)
+	ScenarioIDFilter []int
+
+	// DuplicateScenarios controls repeated scenario IDs: "flag" (default, count only) or "dedupe" (keep latest)
+	DuplicateScenarios string
+
+	// ScenarioNormalization maps raw krkn-ai scenario names to canonical names (default: identity)
+	ScenarioNormalization map[string]string
+
+	// ThresholdsFile points to a thresholds.yaml with the alerting policy; Thresholds
+	// fields set inline override the file's values
+	ThresholdsFile string
+	Thresholds     *Thresholds
+
+	// NotificationConfig selects the reporters notified after each analysis (nil disables notifications)
+	NotificationConfig *slack.NotificationConfig
+
+	// NotificationHTTPClient is shared by HTTP-based reporters, e.g. for an egress proxy (default: per-reporter client)
+	NotificationHTTPClient *http.Client
+
+	// CannedResponse, when set, is used as the LLM response instead of calling the provider.
+	// The rest of Run (summary, sinks, notifications) runs as usual; no API key is required.
+	CannedResponse string
+
+	// CacheEnabled reuses the stored LLM response for an identical prompt and LLM config
+	// instead of calling the provider, e.g. when iterating locally on the same results.
+	// Responses are stored as JSON under CacheDir (default: <user cache dir>/osde2e/krknai-llm).
+	CacheEnabled bool
+	CacheDir     string
+
+	// PromptTokenCost and CompletionTokenCost price the LLM call per million tokens; when
+	// either is set, the metadata records an estimated_cost alongside the token counts
+	PromptTokenCost     float64
+	CompletionTokenCost float64
+
+	// ConfigMapSink, when set, also publishes the result to a ConfigMap via the in-cluster client
+	ConfigMapSink *ConfigMapSink
+
+	// CampaignID links the analyses of a coordinated chaos campaign; it is recorded in the
+	// metadata and can be rolled up with BuildCampaignRollup
+	CampaignID string
+
+	// EmitSummaryToStdout also writes the serialized summary to stdout for pipelines that
+	// capture output; SkipSummaryFile (only valid with it) drops the summary.yaml write
+	EmitSummaryToStdout bool
+	SkipSummaryFile     bool
+
+	// Redactors scrub identifiers (e.g. cluster IDs, node names) from the prompt, tool
+	// reads, summary, ConfigMap sink, and notifications
+	Redactors []Redactor
+
+	// Retention prunes the outputs of earlier runs after the outputs are written, and turns
+	// on RunDirectories so there are earlier outputs to keep (nil disables)
+	Retention *RetentionPolicy
+
+	// RunDirectories writes each run's outputs to its own llm-analysis/run-<UTC timestamp>
+	// directory instead of overwriting the previous run's, and points llm-analysis/latest
+	// at the newest. RetainRuns (only valid with it) keeps that many run directories,
+	// including the current one, and removes the oldest (0 keeps all).
+	RunDirectories bool
+	RetainRuns     int
+
+	// Metrics receives run durations, outcomes, and LLM usage, e.g. a PrometheusMetrics
+	// (nil disables)
+	Metrics MetricsRecorder
 }
 
 // Engine analyzes krkn-ai chaos test results using LLM.
@@ -47,16 +174,126 @@ type Engine struct {
 	aggregator  *krknAggregator.KrknAIAggregator
 	promptStore *prompts.PromptStore
 	llmClient   llm.LLMClient
+	reporters   *slack.ReporterRegistry
+	artifacts   []ArtifactEntry // Files written to the analysis directory during the current run
+	runDir      string          // The current run's directory under the analysis directory, with RunDirectories
+	runStart    time.Time       // When the current run started; stamps its directory and summary
+	analysisSub string          // The analysis directory relative to ArtifactsDir
+	location    *time.Location
+	kubeClient  kubernetes.Interface // Only set when a ConfigMapSink is configured
+	redactor    redactor
+	stdout      io.Writer // Summary destination for EmitSummaryToStdout (default: os.Stdout)
 }
 
 // New creates a new krkn-ai analysis engine.
 func New(ctx context.Context, config *Config) (*Engine, error) {
+	if config.Profile != "" {
+		profile, err := LoadProfile(config.Profile, config.ProfileDir)
+		if err != nil {
+			return nil, err
+		}
+		merged := *config
+		profile.applyTo(&merged)
+		config = &merged
+	}
+
 	if config.ArtifactsDir == "" {
 		return nil, fmt.Errorf("results directory is required")
 	}
 
-	if config.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required for krkn-ai analysis")
+	apiKeyEnvVar, err := llm.APIKeyEnvVar(config.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if config.APIKey == "" && config.CannedResponse == "" {
+		return nil, fmt.Errorf("%s is required for krkn-ai analysis", apiKeyEnvVar)
+	}
+
+	if err := validateGrouping(config.SummaryScenarioGrouping); err != nil {
+		return nil, err
+	}
+
+	if err := validateAssertions(config.Assertions); err != nil {
+		return nil, err
+	}
+
+	if err := validateSummaryFormats(config.SummaryFormats); err != nil {
+		return nil, err
+	}
+
+	if config.SkipSummaryFile && !config.EmitSummaryToStdout {
+		return nil, fmt.Errorf("SkipSummaryFile requires EmitSummaryToStdout, otherwise the summary is not written anywhere")
+	}
+
+	redactor, err := newRedactor(config.Redactors)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.HealthCheckSuccessThreshold < 0 || config.HealthCheckSuccessThreshold > 1 {
+		return nil, fmt.Errorf("health check success threshold must be between 0 and 1, got %g", config.HealthCheckSuccessThreshold)
+	}
+
+	if config.PromptTokenCost < 0 || config.CompletionTokenCost < 0 {
+		return nil, fmt.Errorf("token costs must not be negative")
+	}
+
+	if config.RetainRuns < 0 {
+		return nil, fmt.Errorf("RetainRuns must not be negative, got %d", config.RetainRuns)
+	}
+
+	if policy := config.Retention; policy != nil {
+		if err := policy.validate(); err != nil {
+			return nil, err
+		}
+		keep := policy.KeepLastSummaries
+		if keep > 0 && config.RetainRuns > 0 && keep != config.RetainRuns {
+			return nil, fmt.Errorf("retention keep_last_summaries %d conflicts with RetainRuns %d", keep, config.RetainRuns)
+		}
+		resolved := *config
+		resolved.RunDirectories = true
+		if keep > 0 {
+			resolved.RetainRuns = keep
+		}
+		config = &resolved
+	}
+	if config.RetainRuns > 0 && !config.RunDirectories {
+		return nil, fmt.Errorf("RetainRuns requires RunDirectories")
+	}
+
+	thresholds, err := resolveThresholds(config.ThresholdsFile, config.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+	if config.ThresholdsFile != "" || config.Thresholds != nil {
+		resolved := *config
+		resolved.Thresholds = thresholds
+		config = &resolved
+	}
+
+	switch config.DuplicateScenarios {
+	case "", krknAggregator.DuplicatesFlag, krknAggregator.DuplicatesDedupe:
+	default:
+		return nil, fmt.Errorf("unsupported duplicate scenario handling %q (expected %q or %q)",
+			config.DuplicateScenarios, krknAggregator.DuplicatesFlag, krknAggregator.DuplicatesDedupe)
+	}
+
+	// A run manifest names the analysis directory; older results directories use llm-analysis
+	analysisSub := analysisDirName
+	manifest, err := krknAggregator.ReadManifest(config.ArtifactsDir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		analysisSub = manifest.Layout.Analysis
+	}
+
+	location := time.UTC
+	if config.TimeZone != "" {
+		location, err = time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", config.TimeZone, err)
+		}
 	}
 
 	// Create krkn-ai specific aggregator
@@ -64,23 +301,59 @@ func New(ctx context.Context, config *Config) (*Engine, error) {
 	if config.TopScenariosCount > 0 {
 		agg.WithTopScenariosCount(config.TopScenariosCount)
 	}
+	if config.BottomScenariosCount > 0 {
+		agg.WithBottomScenariosCount(config.BottomScenariosCount)
+	}
+	if config.CollectTimeout > 0 {
+		agg.WithCollectTimeout(config.CollectTimeout)
+	}
+	if config.CollectConcurrency > 0 {
+		agg.WithConcurrency(config.CollectConcurrency)
+	}
+	if config.AnalyzeLatestGenerationOnly {
+		agg.WithLatestGenerationOnly(true)
+	}
+	if config.DeduplicateFailures {
+		agg.WithDeduplication(true)
+	}
+	if config.DuplicateScenarios != "" {
+		agg.WithDuplicateHandling(config.DuplicateScenarios)
+	}
+	if len(config.ScenarioNormalization) > 0 {
+		agg.WithScenarioNormalization(config.ScenarioNormalization)
+	}
+	if len(config.ScenarioIDFilter) > 0 {
+		agg.WithScenarioIDFilter(config.ScenarioIDFilter)
+	}
 
-	promptStore, err := prompts.NewPromptStore(prompts.DefaultTemplates())
+	promptStore, err := newPromptStore(config.PromptTemplatePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize prompt store: %w", err)
+		return nil, err
 	}
 
-	localFS, err := fs.Sub(krknPrompts, "prompts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to load krkn-ai prompt templates: %w", err)
+	var kubeClient kubernetes.Interface
+	if config.ConfigMapSink != nil {
+		kubeClient, err = newConfigMapClient(config.ConfigMapSink)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize ConfigMap sink: %w", err)
+		}
 	}
-	if err := promptStore.RegisterTemplates(localFS); err != nil {
-		return nil, fmt.Errorf("failed to register krkn-ai prompt templates: %w", err)
+
+	reporters := slack.NewReporterRegistry()
+	if config.NotificationHTTPClient != nil {
+		reporters.WithHTTPClient(config.NotificationHTTPClient)
 	}
 
-	client, err := llm.NewGeminiClient(ctx, config.APIKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize LLM client: %w", err)
+	var client llm.LLMClient = &cannedLLMClient{content: config.CannedResponse}
+	if config.CannedResponse == "" {
+		httpClient, err := config.LLMHTTPClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure LLM HTTP client: %w", err)
+		}
+		client, err = llm.NewClient(ctx, config.Provider, config.APIKey, llm.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize LLM client: %w", err)
+		}
 	}
 
 	return &Engine{
@@ -88,6 +361,11 @@ func New(ctx context.Context, config *Config) (*Engine, error) {
 		aggregator:  agg,
 		promptStore: promptStore,
 		llmClient:   client,
+		reporters:   reporters,
+		analysisSub: analysisSub,
+		location:    location,
+		kubeClient:  kubeClient,
+		redactor:    redactor,
 	}, nil
 }
 
@@ -97,119 +375,341 @@ func (e *Engine) WithClusterInfo(info *krknAggregator.ClusterInfo) *Engine {
 	return e
 }
 
+// RegisteredReporters returns the notification reporter types available to the engine.
+func (e *Engine) RegisteredReporters() []string {
+	if e.reporters == nil {
+		return nil
+	}
+	return e.reporters.RegisteredReporters()
+}
+
 // Run executes the krkn-ai analysis workflow.
+//
+// Returned errors are reserved for setup problems: collecting results, rendering the
+// prompt, formatting the report, or writing the summary. When the LLM call itself fails,
+// Run returns a Result with Status "error" and Error set, alongside the aggregated
+// metadata, and the summary is still written so callers can salvage the run metrics.
+//
+// When AnalysisTimeout (or a deadline on ctx) expires, Run writes a partial summary with
+// Status "timeout" and returns it with an error wrapping context.DeadlineExceeded.
 func (e *Engine) Run(ctx context.Context) (*analysisengine.Result, error) {
+	return e.run(ctx, "", nil)
+}
+
+// run implements Run, comparing against the results in baselineDir when it is set and
+// streaming the response to onChunk when it is set. Every run is reported to the metrics recorder.
+func (e *Engine) run(ctx context.Context, baselineDir string, onChunk func(string)) (*analysisengine.Result, error) {
+	start := time.Now()
+	result, err := e.analyzeRun(ctx, start, baselineDir, onChunk)
+	e.recordRun(start, result)
+	return result, err
+}
+
+// analyzeRun collects the results, calls the LLM, and writes the outputs for run.
+func (e *Engine) analyzeRun(ctx context.Context, start time.Time, baselineDir string, onChunk func(string)) (*analysisengine.Result, error) {
+	e.beginRun(start)
+	if e.config.AnalysisTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.AnalysisTimeout)
+		defer cancel()
+	}
+
 	// Collect krkn-ai results
 	data, err := e.aggregator.Collect(ctx, e.config.ArtifactsDir)
 	if err != nil {
+		if deadlineExceeded(ctx) {
+			return e.timedOut(ctx, e.newResult(&krknAggregator.KrknAIData{}, "", ""), &krknAggregator.KrknAIData{}, "collection")
+		}
 		return nil, fmt.Errorf("failed to collect krkn-ai results: %w", err)
 	}
 
-	// Create tool registry with log artifacts for read_file tool
-	toolRegistry := tools.NewRegistry(data.LogArtifacts)
+	var comparison *Comparison
+	if baselineDir != "" {
+		baseline, err := e.aggregator.Collect(ctx, baselineDir)
+		if err != nil {
+			if deadlineExceeded(ctx) {
+				return e.timedOut(ctx, e.newResult(data, "", ""), data, "baseline collection")
+			}
+			return nil, fmt.Errorf("failed to collect baseline krkn-ai results: %w", err)
+		}
+		comparison = compareRuns(baseline, data)
+	}
 
-	// Prepare template variables from collected data
-	vars := map[string]any{
-		"Summary":           data.Summary,
-		"TopScenarios":      data.TopScenarios,
-		"FailedScenarios":   data.FailedScenarios,
-		"HealthCheckReport": data.HealthCheckReport,
-		"LogArtifacts":      data.LogArtifacts,
-		"ConfigSummary":     data.ConfigSummary,
+	// Skip the LLM call when identical results were analyzed within the cool-down window
+	var dataHash string
+	if e.config.CoolDown > 0 && comparison == nil {
+		dataHash, err = hashData(data, e.analysisDir())
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash krkn-ai results: %w", err)
+		}
+		if cached := e.loadCachedResult(dataHash); cached != nil {
+			// The analysis is reused, but the current assertions and gates still judge the
+			// data, so tightening them takes effect within the cool-down window
+			e.judgeResult(cached, data)
+			if onChunk != nil {
+				onChunk(cached.Content)
+			}
+			return cached, thresholdError(cached)
+		}
 	}
-	if data.ClusterInfo != nil {
-		vars["ClusterInfo"] = data.ClusterInfo
+
+	// Earlier analyses say nothing about the chaos run, and listing them would change the
+	// prompt on every re-run
+	data.LogArtifacts = artifactsOutside(data.LogArtifacts, e.analysisDir())
+
+	// Create tool registry with log artifacts for read_file tool
+	toolRegistry := tools.NewRegistry(data.LogArtifacts).WithFileLimits(e.config.MaxArtifactReads, e.config.ArtifactReadTimeout)
+	if len(e.redactor) > 0 {
+		toolRegistry.WithRedactor(e.redactor.redact)
 	}
 
 	// Render prompt using prompt store
+	vars := promptVariables(data)
+	if comparison != nil {
+		vars["Comparison"] = comparison
+	}
 	userPrompt, llmConfig, err := e.promptStore.RenderPrompt(krknAIPromptTemplate, vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render prompt: %w", err)
 	}
+	userPrompt = e.redactor.redact(userPrompt)
 
 	// Apply LLM config overrides
-	if e.config.LLMConfig != nil {
-		if e.config.LLMConfig.Temperature != nil {
-			llmConfig.Temperature = e.config.LLMConfig.Temperature
-		}
-		if e.config.LLMConfig.MaxTokens != nil {
-			llmConfig.MaxTokens = e.config.LLMConfig.MaxTokens
+	llmConfig.Override(e.config.LLMConfig)
+	if err := llmConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid LLM config: %w", err)
+	}
+
+	// Build analysis result from the aggregated data; LLM output is filled in below
+	analysisResult := e.newResult(data, userPrompt, dataHash)
+	if comparison != nil {
+		comparison.record(analysisResult.Metadata)
+	}
+
+	// Run LLM analysis. A failure here still yields a result carrying the aggregated data.
+	result, err := e.analyze(ctx, userPrompt, llmConfig, toolRegistry, analysisResult.Metadata, onChunk)
+	if toolRegistry.FileBudgetExceeded() {
+		analysisResult.Metadata["artifacts_budget_exceeded"] = true
+	}
+	if err != nil && deadlineExceeded(ctx) {
+		return e.timedOut(ctx, analysisResult, data, "LLM analysis")
+	}
+	if err != nil {
+		analysisResult.Status = "error"
+		analysisResult.Error = fmt.Sprintf("LLM analysis failed: %v", err)
+		if err := e.writeOutputs(ctx, analysisResult, data); err != nil {
+			return nil, err
 		}
-		if e.config.LLMConfig.TopP != nil {
-			llmConfig.TopP = e.config.LLMConfig.TopP
+		e.sendNotifications(ctx, analysisResult)
+		return analysisResult, e.runError(ctx, analysisResult)
+	}
+
+	if err := e.applyResponse(analysisResult, result); err != nil {
+		return nil, err
+	}
+
+	// Write summary and artifact index to results directory
+	if err := e.writeOutputs(ctx, analysisResult, data); err != nil {
+		if deadlineExceeded(ctx) {
+			return e.timedOut(ctx, analysisResult, data, "writing outputs")
 		}
+		return nil, err
+	}
+
+	e.sendNotifications(ctx, analysisResult)
+
+	return analysisResult, e.runError(ctx, analysisResult)
+}
+
+// deadlineExceeded reports whether the run's deadline has passed.
+func deadlineExceeded(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// timedOut marks the result as timed out during stage and writes it as a partial summary.
+// Only the local outputs are written: the ConfigMap sink and notifications need the
+// expired context.
+func (e *Engine) timedOut(ctx context.Context, result *analysisengine.Result, data *krknAggregator.KrknAIData, stage string) (*analysisengine.Result, error) {
+	result.Status = "timeout"
+	result.Error = fmt.Sprintf("analysis timed out during %s", stage)
+	result.Metadata["timed_out_stage"] = stage
+	logr.FromContextOrDiscard(ctx).Error(ctx.Err(), "krkn-ai analysis timed out", "stage", stage)
+	if err := e.writeLocalOutputs(result, data); err != nil {
+		return nil, err
+	}
+	return result, fmt.Errorf("krkn-ai analysis timed out during %s: %w", stage, ctx.Err())
+}
+
+// runError returns the error Run reports for a written result: a deadline that expired
+// while notifying, otherwise any crossed CI gate.
+func (e *Engine) runError(ctx context.Context, result *analysisengine.Result) error {
+	if deadlineExceeded(ctx) {
+		return fmt.Errorf("krkn-ai analysis timed out during notifications: %w", ctx.Err())
 	}
+	return thresholdError(result)
+}
 
-	// Run LLM analysis
-	result, err := e.llmClient.Analyze(ctx, userPrompt, llmConfig, toolRegistry)
+// EvaluateRecorded runs everything downstream of the LLM call against a stored prompt
+// and provider response, for offline evaluation of prompt and post-processing changes.
+// Results are still collected from ArtifactsDir, and the summary and index are written
+// locally, but the cool-down cache, ConfigMap sink, retention, and notifications are skipped.
+// No provider is contacted, so an engine built with CannedResponse needs no API key.
+func (e *Engine) EvaluateRecorded(ctx context.Context, prompt, response string) (*analysisengine.Result, error) {
+	data, err := e.aggregator.Collect(ctx, e.config.ArtifactsDir)
 	if err != nil {
-		return nil, fmt.Errorf("LLM analysis failed: %w", err)
+		return nil, fmt.Errorf("failed to collect krkn-ai results: %w", err)
 	}
 
-	content := result.Content
-	if mustGatherPath := mustGatherRelativePath(e.config.ArtifactsDir); mustGatherPath != "" {
-		content += fmt.Sprintf("\n\n[Cluster must-gather](%s) (inspect cluster state at chaos run time)", mustGatherPath)
+	e.beginRun(time.Now())
+
+	analysisResult := e.newResult(data, e.redactor.redact(prompt), "")
+	analysisResult.Metadata["recorded_response"] = true
+	if err := e.applyResponse(analysisResult, &llm.AnalysisResult{Content: response}); err != nil {
+		return nil, err
 	}
-	if e.config.ReportFormat == "html" {
-		var err error
-		content, err = markdownToHTML(content)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert markdown to HTML: %w", err)
-		}
+
+	if err := e.writeLocalOutputs(analysisResult, data); err != nil {
+		return nil, err
 	}
+	return analysisResult, thresholdError(analysisResult)
+}
 
-	// Build analysis result
+// newResult builds a result carrying the aggregated metadata, thresholds, and
+// assertion outcomes for the given prompt. The LLM output is added by applyResponse.
+func (e *Engine) newResult(data *krknAggregator.KrknAIData, prompt, dataHash string) *analysisengine.Result {
 	analysisResult := &analysisengine.Result{
-		Status:  "completed",
-		Content: content,
-		Prompt:  userPrompt,
+		Status: "completed",
+		Prompt: prompt,
 		Metadata: map[string]any{
 			"analysis_type":        "krknai",
 			"total_scenarios":      data.Summary.TotalScenarioCount,
 			"successful_scenarios": data.Summary.SuccessfulScenarioCount,
 			"failed_scenarios":     data.Summary.FailedScenarioCount,
+			"timed_out_scenarios":  data.Summary.TimedOutScenarioCount,
+			"duplicate_scenarios":  data.Summary.DuplicateScenarioCount,
 			"generations":          data.Summary.Generations,
 			"max_fitness_score":    data.Summary.MaxFitnessScore,
-			"artifacts_examined": func() (count int) {
-				for _, tc := range result.ToolCalls {
-					if tc.Name == "read_file" {
-						count++
-					}
-				}
-				return count
-			}(),
-			"tool_calls": len(result.ToolCalls),
 		},
 	}
+	analysisResult.Metadata["scenario_scope"] = scenarioScope(data)
+	if e.config.CampaignID != "" {
+		analysisResult.Metadata["campaign_id"] = e.config.CampaignID
+	}
+	if e.config.CannedResponse != "" {
+		analysisResult.Metadata["canned_response"] = true
+	}
+	applyThresholds(e.config.Thresholds, analysisResult, data.Summary)
+	if dataHash != "" {
+		analysisResult.Metadata["data_hash"] = dataHash
+	}
+	e.judgeResult(analysisResult, data)
+	return analysisResult
+}
+
+// judgeResult evaluates the assertions and CI gates against the data, marking the result
+// failed when FailOnAssertions is set and an assertion fails or a gate is crossed.
+func (e *Engine) judgeResult(result *analysisengine.Result, data *krknAggregator.KrknAIData) {
+	if assertions := evaluateAssertions(e.config.Assertions, data); assertions != nil {
+		failed := failedAssertions(assertions)
+		result.Metadata["assertions_total"] = len(assertions)
+		result.Metadata["assertions_failed"] = len(failed)
+		if e.config.FailOnAssertions && len(failed) > 0 {
+			result.Status = "assertions_failed"
+			result.Error = fmt.Sprintf("%d of %d assertions failed: %s", len(failed), len(assertions), strings.Join(failed, "; "))
+		}
+	}
+	e.applyGates(result, data)
+}
+
+// applyResponse post-processes the LLM response into the result: the must-gather link,
+// the configured report format, the model's overall severity, and tool and token usage metadata.
+func (e *Engine) applyResponse(analysisResult *analysisengine.Result, result *llm.AnalysisResult) error {
+	content := result.Content
+	if mustGatherPath := mustGatherRelativePath(e.config.ArtifactsDir); mustGatherPath != "" {
+		content += fmt.Sprintf("\n\n[Cluster must-gather](%s) (inspect cluster state at chaos run time)", mustGatherPath)
+	}
+	if e.config.ReportFormat == "html" {
+		var err error
+		content, err = markdownToHTML(content)
+		if err != nil {
+			return fmt.Errorf("failed to convert markdown to HTML: %w", err)
+		}
+	}
 
-	// Write summary to results directory
-	if err := e.writeSummary(analysisResult, data); err != nil {
-		return nil, fmt.Errorf("failed to write analysis summary: %w", err)
+	artifactsExamined := 0
+	for _, tc := range result.ToolCalls {
+		if tc.Name == "read_file" {
+			artifactsExamined++
+		}
+	}
+	analysisResult.Content = content
+	analysisResult.Metadata["llm_severity"] = parseLLMSeverity(result.Content)
+	analysisResult.Metadata["artifacts_examined"] = artifactsExamined
+	analysisResult.Metadata["tool_calls"] = len(result.ToolCalls)
+	analysisResult.Metadata["prompt_tokens"] = result.Usage.PromptTokens
+	analysisResult.Metadata["completion_tokens"] = result.Usage.CompletionTokens
+	if e.config.PromptTokenCost > 0 || e.config.CompletionTokenCost > 0 {
+		cost := (float64(result.Usage.PromptTokens)*e.config.PromptTokenCost +
+			float64(result.Usage.CompletionTokens)*e.config.CompletionTokenCost) / 1e6
+		analysisResult.Metadata["estimated_cost"] = cost
 	}
+	return nil
+}
 
-	return analysisResult, nil
+// writeOutputs writes the local outputs, then publishes the result to the ConfigMap sink
+// if one is configured. Once everything is written, the directories of earlier runs are
+// pruned per RetainRuns and the retention policy.
+func (e *Engine) writeOutputs(ctx context.Context, result *analysisengine.Result, data *krknAggregator.KrknAIData) error {
+	if err := e.writeLocalOutputs(result, data); err != nil {
+		return err
+	}
+	if e.config.ConfigMapSink != nil && e.kubeClient != nil {
+		if err := e.writeConfigMap(ctx, result); err != nil {
+			return fmt.Errorf("failed to publish analysis to ConfigMap: %w", err)
+		}
+	}
+	if _, err := e.pruneRuns(ctx); err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "failed to prune analysis runs")
+	}
+	return nil
 }
 
-// writeSummary writes the analysis result to a YAML summary file.
-func (e *Engine) writeSummary(result *analysisengine.Result, data *krknAggregator.KrknAIData) error {
-	analysisDir := filepath.Join(e.config.ArtifactsDir, analysisDirName)
-	if err := os.MkdirAll(analysisDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create analysis directory: %w", err)
+// writeLocalOutputs redacts the result, then writes the summary followed by the index
+// of every artifact produced.
+func (e *Engine) writeLocalOutputs(result *analysisengine.Result, data *krknAggregator.KrknAIData) error {
+	if len(e.redactor) > 0 {
+		result.Content = e.redactor.redact(result.Content)
+		result.Error = e.redactor.redact(result.Error)
+		result.Metadata = e.redactor.redactMetadata(result.Metadata)
 	}
+	if err := e.writeSummary(result, data); err != nil {
+		return fmt.Errorf("failed to write analysis summary: %w", err)
+	}
+	if err := e.writeIndex(); err != nil {
+		return fmt.Errorf("failed to write analysis index: %w", err)
+	}
+	return e.linkLatestRun()
+}
 
+// writeSummary writes the analysis result in each configured summary format.
+func (e *Engine) writeSummary(result *analysisengine.Result, data *krknAggregator.KrknAIData) error {
 	summary := map[string]any{
-		"timestamp":     time.Now().Format(time.RFC3339),
+		"timestamp":     e.runTime().Format(time.RFC3339),
 		"analysis_type": "krknai",
 		"cluster_info":  data.ClusterInfo,
 		"run_summary": map[string]any{
 			"total_scenarios":      data.Summary.TotalScenarioCount,
 			"successful_scenarios": data.Summary.SuccessfulScenarioCount,
 			"failed_scenarios":     data.Summary.FailedScenarioCount,
+			"timed_out_scenarios":  data.Summary.TimedOutScenarioCount,
+			"duplicate_scenarios":  data.Summary.DuplicateScenarioCount,
 			"generations":          data.Summary.Generations,
 			"max_fitness_score":    data.Summary.MaxFitnessScore,
 			"avg_fitness_score":    data.Summary.AvgFitnessScore,
 			"scenario_types":       data.Summary.ScenarioTypes,
 		},
+		"scenario_scope":   scenarioScope(data),
+		"best_scenario":    data.BestScenario,
 		"top_scenarios":    data.TopScenarios,
 		"failed_scenarios": data.FailedScenarios,
 		"status":           result.Status,
@@ -218,18 +718,108 @@ func (e *Engine) writeSummary(result *analysisengine.Result, data *krknAggregato
 		"metadata":         result.Metadata,
 		"error":            result.Error,
 	}
+	if len(data.BottomScenarios) > 0 {
+		summary["bottom_scenarios"] = data.BottomScenarios
+	}
+	if groups := groupScenarios(e.config.SummaryScenarioGrouping, data); groups != nil {
+		summary["scenario_grouping"] = e.config.SummaryScenarioGrouping
+		summary["scenario_groups"] = groups
+	}
+	if assertions := evaluateAssertions(e.config.Assertions, data); assertions != nil {
+		summary["assertions"] = assertions
+	}
 
-	yamlData, err := yaml.Marshal(summary)
+	yamlData, err := e.redactor.marshalYAML(summary)
 	if err != nil {
 		return fmt.Errorf("failed to marshal summary to YAML: %w", err)
 	}
 
-	summaryPath := filepath.Join(analysisDir, summaryFileName)
-	if err := os.WriteFile(summaryPath, yamlData, 0o644); err != nil {
-		return fmt.Errorf("failed to write summary file: %w", err)
+	if e.config.EmitSummaryToStdout {
+		stdout := e.stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		if _, err := stdout.Write(yamlData); err != nil {
+			return fmt.Errorf("failed to write summary to stdout: %w", err)
+		}
+	}
+	for _, format := range e.summaryFormats() {
+		switch format {
+		case SummaryFormatYAML:
+			if e.config.SkipSummaryFile {
+				continue
+			}
+			if err := e.writeArtifact(summaryFileName, ArtifactTypeSummary, yamlData); err != nil {
+				return err
+			}
+		case SummaryFormatJSON:
+			jsonData, err := summaryJSON(yamlData)
+			if err != nil {
+				return err
+			}
+			if err := e.writeArtifact(summaryJSONFileName, ArtifactTypeSummary, jsonData); err != nil {
+				return err
+			}
+		case SummaryFormatMarkdown:
+			if err := e.writeArtifact(summaryMarkdownFileName, ArtifactTypeSummary, e.summaryMarkdown(result, data)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scenarioScope describes which scenarios the top/failed lists were drawn from.
+func scenarioScope(data *krknAggregator.KrknAIData) string {
+	scope := "all generations"
+	if data.ScopedGeneration != nil {
+		scope = fmt.Sprintf("generation %d", *data.ScopedGeneration)
+	}
+	if len(data.ScenarioIDFilter) > 0 {
+		ids := make([]string, len(data.ScenarioIDFilter))
+		for i, id := range data.ScenarioIDFilter {
+			ids[i] = strconv.Itoa(id)
+		}
+		scope += ", scenario IDs " + strings.Join(ids, ", ")
 	}
+	return scope
+}
 
-	return nil
+// now returns the current time in the configured time zone.
+func (e *Engine) now() time.Time {
+	return e.inZone(time.Now())
+}
+
+// runTime returns when the current run started in the configured time zone, or the
+// current time outside a run.
+func (e *Engine) runTime() time.Time {
+	if e.runStart.IsZero() {
+		return e.now()
+	}
+	return e.inZone(e.runStart)
+}
+
+// inZone returns t in the configured time zone.
+func (e *Engine) inZone(t time.Time) time.Time {
+	if e.location == nil {
+		return t.UTC()
+	}
+	return t.In(e.location)
+}
+
+// AnalysisDir returns the directory the engine writes its output to: the analysis
+// directory of the run manifest, or llm-analysis in results directories without one.
+func (e *Engine) AnalysisDir() string {
+	return e.analysisDir()
+}
+
+// analysisDir returns the directory the engine writes its output to.
+func (e *Engine) analysisDir() string {
+	sub := e.analysisSub
+	if sub == "" {
+		sub = analysisDirName
+	}
+	return filepath.Join(e.config.ArtifactsDir, sub)
 }
 
 // mustGatherRelativePath returns the relative path to the must-gather directory from the
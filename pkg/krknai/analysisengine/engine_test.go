@@ -1,32 +1,111 @@
 package analysisengine
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"io/fs"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/openshift/osde2e/internal/analysisengine"
 	"github.com/openshift/osde2e/internal/llm"
 	"github.com/openshift/osde2e/internal/llm/tools"
 	"github.com/openshift/osde2e/internal/prompts"
+	"github.com/openshift/osde2e/pkg/common/slack"
 	krknAgg "github.com/openshift/osde2e/pkg/krknai/aggregator"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
 	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 // mockLLMClient implements llm.LLMClient for testing.
 type mockLLMClient struct {
 	response *llm.AnalysisResult
 	err      error
+	calls    int
 }
 
 func (m *mockLLMClient) Analyze(_ context.Context, _ string, _ *llm.AnalysisConfig, _ *tools.Registry) (*llm.AnalysisResult, error) {
+	m.calls++
 	return m.response, m.err
 }
 
+// blockingLLMClient blocks until the context is done, like a hung provider call.
+type blockingLLMClient struct{}
+
+func (blockingLLMClient) Analyze(ctx context.Context, _ string, _ *llm.AnalysisConfig, _ *tools.Registry) (*llm.AnalysisResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// streamingLLMClient implements llm.StreamingLLMClient, streaming its chunks before the result.
+type streamingLLMClient struct {
+	chunks []string
+	err    error
+}
+
+func (s *streamingLLMClient) Analyze(_ context.Context, _ string, _ *llm.AnalysisConfig, _ *tools.Registry) (*llm.AnalysisResult, error) {
+	return &llm.AnalysisResult{Content: strings.Join(s.chunks, "")}, s.err
+}
+
+func (s *streamingLLMClient) AnalyzeStream(_ context.Context, _ string, _ *llm.AnalysisConfig, _ *tools.Registry) <-chan llm.StreamChunk {
+	ch := make(chan llm.StreamChunk, len(s.chunks)+1)
+	for _, text := range s.chunks {
+		ch <- llm.StreamChunk{Text: text}
+	}
+	if s.err != nil {
+		ch <- llm.StreamChunk{Err: s.err}
+	} else {
+		ch <- llm.StreamChunk{Result: &llm.AnalysisResult{Content: strings.Join(s.chunks, "")}}
+	}
+	close(ch)
+	return ch
+}
+
+// fileReadingLLMClient reads each of paths through the read_file tool, one call per file,
+// and records the tool results.
+type fileReadingLLMClient struct {
+	paths   []string
+	results []any
+}
+
+func (f *fileReadingLLMClient) Analyze(ctx context.Context, _ string, _ *llm.AnalysisConfig, registry *tools.Registry) (*llm.AnalysisResult, error) {
+	for _, path := range f.paths {
+		result, err := registry.Execute(ctx, "read_file", map[string]any{"files": []any{map[string]any{"path": path}}})
+		if err != nil {
+			return nil, err
+		}
+		f.results = append(f.results, result)
+	}
+	return &llm.AnalysisResult{Content: "Analysis complete"}, nil
+}
+
+// countingReporter implements slack.Reporter and records deliveries.
+type countingReporter struct {
+	calls int
+	last  *slack.AnalysisResult
+}
+
+func (c *countingReporter) Name() string { return "counting" }
+
+func (c *countingReporter) Report(_ context.Context, result *slack.AnalysisResult, _ *slack.ReporterConfig) error {
+	c.calls++
+	c.last = result
+	return nil
+}
+
 func TestNew_ValidConfig(t *testing.T) {
 	// New requires a real Gemini API key to create the client,
 	// so we test validation logic only
@@ -49,6 +128,37 @@ func TestNew_ValidConfig(t *testing.T) {
 	assert.Contains(t, err.Error(), "results directory is required")
 }
 
+func TestNew_Provider(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(ctx, &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: t.TempDir(), Provider: llm.ProviderAnthropic},
+	})
+	assert.ErrorContains(t, err, "ANTHROPIC_API_KEY is required")
+
+	_, err = New(ctx, &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: t.TempDir(), APIKey: "fake-key", Provider: "openai"},
+	})
+	assert.ErrorContains(t, err, `unknown LLM provider "openai"`)
+
+	engine, err := New(ctx, &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: t.TempDir(), APIKey: "fake-key", Provider: llm.ProviderAnthropic},
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &llm.AnthropicClient{}, engine.llmClient)
+}
+
+func TestNew_CABundle(t *testing.T) {
+	_, err := New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{
+			ArtifactsDir: t.TempDir(),
+			APIKey:       "fake-key",
+			CABundlePath: filepath.Join(t.TempDir(), "missing.pem"),
+		},
+	})
+	assert.ErrorContains(t, err, "failed to configure LLM HTTP client: failed to read LLM CA bundle")
+}
+
 func TestPromptTemplatesAvailable(t *testing.T) {
 	store := newTestPromptStore(t)
 
@@ -100,6 +210,7 @@ func TestRenderKrknAIPrompt(t *testing.T) {
 				"ScenarioID":       7,
 				"KrknFailureScore": -1.0,
 				"Parameters":       "namespace: openshift-dns",
+				"Occurrences":      3,
 			},
 		},
 		"HealthCheckReport": []map[string]any{
@@ -137,6 +248,7 @@ func TestRenderKrknAIPrompt(t *testing.T) {
 	assert.Contains(t, userPrompt, "fitness=8.75")
 	assert.Contains(t, userPrompt, "node_selector: node-role.kubernetes.io/worker")
 	assert.Contains(t, userPrompt, "dns-outage")
+	assert.Contains(t, userPrompt, "occurred=3x")
 	assert.Contains(t, userPrompt, "console")
 	assert.Contains(t, userPrompt, "avg=245.70ms")
 	assert.Contains(t, userPrompt, "/results/reports/all.csv (31L)")
@@ -144,6 +256,7 @@ func TestRenderKrknAIPrompt(t *testing.T) {
 
 	assert.NotNil(t, config.SystemInstruction)
 	assert.Contains(t, *config.SystemInstruction, "chaos engineering analyst")
+	assert.Contains(t, *config.SystemInstruction, "Overall severity: <none|low|medium|high|critical>")
 	assert.Contains(t, *config.SystemInstruction, "genetic algorithm")
 }
 
@@ -313,6 +426,430 @@ func TestWriteSummary(t *testing.T) {
 	assert.Equal(t, 5, runSummary["total_scenarios"])
 	assert.Equal(t, 4, runSummary["successful_scenarios"])
 	assert.Equal(t, 1, runSummary["failed_scenarios"])
+	assert.NotContains(t, summary, "bottom_scenarios")
+
+	// Bottom scenarios are only written when requested
+	data.BottomScenarios = []krknAgg.ScenarioResult{{ScenarioID: 4, Scenario: "pod-scenarios", FitnessScore: 0.3}}
+	require.NoError(t, engine.writeSummary(result, data))
+	content, err = os.ReadFile(summaryPath)
+	require.NoError(t, err)
+	summary = nil
+	require.NoError(t, yaml.Unmarshal(content, &summary))
+	bottom, ok := summary["bottom_scenarios"].([]any)
+	require.True(t, ok)
+	require.Len(t, bottom, 1)
+	assert.Equal(t, "pod-scenarios", bottom[0].(map[string]any)["scenario"])
+}
+
+func TestWriteSummary_TimeZone(t *testing.T) {
+	tempDir := t.TempDir()
+	data := &krknAgg.KrknAIData{}
+	result := &analysisengine.Result{Status: "completed"}
+
+	readTimestamp := func() time.Time {
+		content, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+		require.NoError(t, err)
+		var summary struct {
+			Timestamp string `yaml:"timestamp"`
+		}
+		require.NoError(t, yaml.Unmarshal(content, &summary))
+		timestamp, err := time.Parse(time.RFC3339, summary.Timestamp)
+		require.NoError(t, err)
+		return timestamp
+	}
+
+	// Defaults to UTC
+	engine := &Engine{config: &Config{BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir}}}
+	require.NoError(t, engine.writeSummary(result, data))
+	_, offset := readTimestamp().Zone()
+	assert.Equal(t, 0, offset)
+
+	engine.location = time.FixedZone("UTC+9", 9*60*60)
+	require.NoError(t, engine.writeSummary(result, data))
+	_, offset = readTimestamp().Zone()
+	assert.Equal(t, 9*60*60, offset)
+}
+
+func TestWriteSummary_Stdout(t *testing.T) {
+	tempDir := t.TempDir()
+	var stdout bytes.Buffer
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:          analysisengine.BaseConfig{ArtifactsDir: tempDir},
+			EmitSummaryToStdout: true,
+		},
+		stdout: &stdout,
+	}
+	result := &analysisengine.Result{Status: "completed", Content: "analysis"}
+	data := &krknAgg.KrknAIData{}
+
+	require.NoError(t, engine.writeSummary(result, data))
+	fileData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err, "the file is still written by default")
+	assert.Equal(t, string(fileData), stdout.String())
+
+	stdoutOnlyDir := t.TempDir()
+	stdout.Reset()
+	engine.config = &Config{
+		BaseConfig:          analysisengine.BaseConfig{ArtifactsDir: stdoutOnlyDir},
+		EmitSummaryToStdout: true,
+		SkipSummaryFile:     true,
+	}
+	require.NoError(t, engine.writeSummary(result, data))
+	assert.Contains(t, stdout.String(), "status: completed")
+	assert.NoFileExists(t, filepath.Join(stdoutOnlyDir, analysisDirName, summaryFileName))
+
+	_, err = New(context.Background(), &Config{
+		BaseConfig:      analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+		SkipSummaryFile: true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SkipSummaryFile requires EmitSummaryToStdout")
+}
+
+func TestNew_InvalidTimeZone(t *testing.T) {
+	_, err := New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: "/some/dir", APIKey: "fake-key"},
+		TimeZone:   "Mars/Olympus_Mons",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid time zone "Mars/Olympus_Mons"`)
+}
+
+func TestWriteSummary_ScenarioGrouping(t *testing.T) {
+	data := &krknAgg.KrknAIData{
+		TopScenarios: []krknAgg.ScenarioResult{
+			{ScenarioID: 1, Scenario: "node-cpu-hog", FitnessScore: 2.2},
+			{ScenarioID: 2, Scenario: "pod-scenarios", FitnessScore: 1.5},
+			{ScenarioID: 3, Scenario: "node-cpu-hog", FitnessScore: 1.1},
+		},
+		FailedScenarios: []krknAgg.ScenarioResult{
+			{ScenarioID: 4, Scenario: "dns-outage", KrknFailureScore: -1.0, FitnessScore: -1.0},
+			{ScenarioID: 5, Scenario: "pod-scenarios", KrknFailureScore: -1.0, FitnessScore: -1.0, TimedOut: true},
+		},
+	}
+
+	tests := []struct {
+		grouping   string
+		wantGroups []string
+	}{
+		{grouping: "", wantGroups: nil},
+		{grouping: GroupByFitness, wantGroups: nil},
+		{grouping: GroupByType, wantGroups: []string{"dns-outage", "node-cpu-hog", "pod-scenarios"}},
+		{grouping: GroupByOutcome, wantGroups: []string{"failed", "successful", "timed_out"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.grouping, func(t *testing.T) {
+			tempDir := t.TempDir()
+			engine := &Engine{
+				config: &Config{
+					BaseConfig:              analysisengine.BaseConfig{ArtifactsDir: tempDir},
+					SummaryScenarioGrouping: tt.grouping,
+					SummaryFormats:          []string{SummaryFormatYAML, SummaryFormatMarkdown},
+				},
+			}
+			require.NoError(t, engine.writeSummary(&analysisengine.Result{Status: "completed"}, data))
+
+			content, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+			require.NoError(t, err)
+			var summary struct {
+				TopScenarios   []krknAgg.ScenarioResult `yaml:"top_scenarios"`
+				ScenarioGroups []ScenarioGroup          `yaml:"scenario_groups"`
+			}
+			require.NoError(t, yaml.Unmarshal(content, &summary))
+
+			assert.Len(t, summary.TopScenarios, 3, "flat lists are always kept")
+			var names []string
+			for _, g := range summary.ScenarioGroups {
+				names = append(names, g.Name)
+			}
+			assert.Equal(t, tt.wantGroups, names)
+
+			// The Markdown summary has one section per group, or the flat lists without grouping
+			markdown, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryMarkdownFileName))
+			require.NoError(t, err)
+			if tt.wantGroups == nil {
+				assert.Contains(t, string(markdown), "## Top Scenarios")
+				assert.NotContains(t, string(markdown), "## Scenarios by")
+				return
+			}
+			assert.Contains(t, string(markdown), "## Scenarios by "+tt.grouping)
+			assert.NotContains(t, string(markdown), "## Top Scenarios")
+			for _, name := range tt.wantGroups {
+				assert.Contains(t, string(markdown), "### "+name+"\n")
+			}
+		})
+	}
+
+	groups := groupScenarios(GroupByType, data)
+	require.Equal(t, "node-cpu-hog", groups[1].Name)
+	assert.Equal(t, 2.2, groups[1].Scenarios[0].FitnessScore, "scenarios keep fitness order within a group")
+	assert.Equal(t, 1.1, groups[1].Scenarios[1].FitnessScore)
+}
+
+func TestNew_InvalidScenarioGrouping(t *testing.T) {
+	_, err := New(context.Background(), &Config{
+		BaseConfig:              analysisengine.BaseConfig{ArtifactsDir: "/some/dir", APIKey: "fake-key"},
+		SummaryScenarioGrouping: "alphabetical",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported summary scenario grouping")
+}
+
+func TestNew_InvalidAssertion(t *testing.T) {
+	_, err := New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: "/some/dir", APIKey: "fake-key"},
+		Assertions: []Assertion{{Name: "rate", Field: FieldFailureRate, Op: "<=", Value: 0.1, Scenario: "dns-outage"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scenario filter is only supported for failed_scenarios")
+
+	_, err = New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: "/some/dir", APIKey: "fake-key"},
+		Assertions: []Assertion{{Field: "fitness", Op: "<", Value: 1}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported field "fitness"`)
+}
+
+func TestEvaluateAssertions(t *testing.T) {
+	data := &krknAgg.KrknAIData{
+		Summary: krknAgg.KrknAISummary{
+			TotalScenarioCount:      4,
+			SuccessfulScenarioCount: 1,
+			FailedScenarioCount:     3,
+			MaxFitnessScore:         2.5,
+		},
+		FailedScenarios: []krknAgg.ScenarioResult{
+			{Scenario: "network-chaos"},
+			{Scenario: "network-chaos"},
+			{Scenario: "pod-scenarios"},
+		},
+	}
+
+	results := evaluateAssertions([]Assertion{
+		{Name: "network failures", Field: FieldFailedScenarios, Scenario: "network-chaos", Op: "<=", Value: 2},
+		{Name: "failure rate", Field: FieldFailureRate, Op: "<", Value: 0.5},
+		{Name: "fitness", Field: FieldMaxFitnessScore, Op: "<", Value: 3},
+	}, data)
+	require.Len(t, results, 3)
+
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, 2.0, results[0].Actual)
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, 0.75, results[1].Actual)
+	assert.True(t, results[2].Passed)
+
+	assert.Equal(t, []string{"failure rate: expected < 0.5, got 0.75"}, failedAssertions(results))
+	assert.Nil(t, evaluateAssertions(nil, data))
+
+	// Deduplicated failures count every occurrence
+	data.FailedScenarios = []krknAgg.ScenarioResult{{Scenario: "network-chaos", Occurrences: 2}, {Scenario: "pod-scenarios", Occurrences: 1}}
+	results = evaluateAssertions([]Assertion{
+		{Name: "network failures", Field: FieldFailedScenarios, Scenario: "network-chaos", Op: "<=", Value: 2},
+	}, data)
+	assert.Equal(t, 2.0, results[0].Actual)
+}
+
+func TestRun_FailOnAssertions(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			Assertions: []Assertion{
+				{Name: "no dns failures", Field: FieldFailedScenarios, Scenario: "dns-outage", Op: "==", Value: 0},
+				{Name: "enough scenarios", Field: FieldTotalScenarios, Op: ">=", Value: 5},
+			},
+			FailOnAssertions: true,
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}},
+	}
+
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "assertions_failed", result.Status)
+	assert.Equal(t, "1 of 2 assertions failed: no dns failures: expected == 0, got 1", result.Error)
+	assert.Equal(t, "analysis", result.Content)
+	assert.Equal(t, 2, result.Metadata["assertions_total"])
+	assert.Equal(t, 1, result.Metadata["assertions_failed"])
+
+	summaryData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err)
+	var summary struct {
+		Assertions []AssertionResult `yaml:"assertions"`
+	}
+	require.NoError(t, yaml.Unmarshal(summaryData, &summary))
+	require.Len(t, summary.Assertions, 2)
+	assert.False(t, summary.Assertions[0].Passed)
+	assert.Equal(t, "dns-outage", summary.Assertions[0].Scenario)
+	assert.True(t, summary.Assertions[1].Passed)
+
+	// Without FailOnAssertions the results are recorded but the run completes
+	engine.config.FailOnAssertions = false
+	result, err = engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, 1, result.Metadata["assertions_failed"])
+}
+
+func TestLoadProfile(t *testing.T) {
+	profile, err := LoadProfile("production", "")
+	require.NoError(t, err)
+	assert.Equal(t, 20, profile.TopScenariosCount)
+	assert.Equal(t, time.Hour, profile.CoolDown)
+	assert.True(t, profile.FailOnAssertions)
+	require.NoError(t, validateAssertions(profile.Assertions))
+
+	// External profiles take precedence over embedded ones of the same name
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "production.yaml"), []byte("top_scenarios_count: 5\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "perf.yaml"), []byte("time_zone: Europe/Berlin\n"), 0o644))
+
+	profile, err = LoadProfile("production", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 5, profile.TopScenariosCount)
+
+	profile, err = LoadProfile("perf", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", profile.TimeZone)
+
+	_, err = LoadProfile("qa", dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown analysis profile "qa" (available: perf, production, stage)`)
+}
+
+func TestNew_ProfileFillsUnsetFields(t *testing.T) {
+	config := &Config{
+		BaseConfig:        analysisengine.BaseConfig{ArtifactsDir: t.TempDir()},
+		Profile:           "stage",
+		TopScenariosCount: 3,
+		CannedResponse:    "canned",
+	}
+
+	engine, err := New(context.Background(), config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, engine.config.TopScenariosCount, "explicit fields override the profile")
+	assert.Equal(t, "markdown", engine.config.ReportFormat)
+	assert.Equal(t, GroupByType, engine.config.SummaryScenarioGrouping)
+	assert.Equal(t, 30*time.Minute, engine.config.CoolDown)
+
+	// The caller's config is left untouched
+	assert.Empty(t, config.ReportFormat)
+}
+
+func TestNew_InvalidDuplicateHandling(t *testing.T) {
+	_, err := New(context.Background(), &Config{
+		BaseConfig:         analysisengine.BaseConfig{ArtifactsDir: "/some/dir", APIKey: "fake-key"},
+		DuplicateScenarios: "drop",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported duplicate scenario handling "drop"`)
+}
+
+func TestResolveThresholds(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "thresholds.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`severity_bands:
+  warning: 0.1
+  critical: 0.3
+notify_min_fitness: 1.5
+baseline:
+  failure_rate: 0.05
+`), 0o644))
+
+	critical := 0.5
+	thresholds, err := resolveThresholds(file, &Thresholds{SeverityBands: &SeverityBands{Critical: &critical}})
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, *thresholds.SeverityBands.Warning)
+	assert.Equal(t, 0.5, *thresholds.SeverityBands.Critical, "inline values override the file")
+	assert.Equal(t, 1.5, *thresholds.NotifyMinFitness)
+	assert.Equal(t, 0.05, *thresholds.Baseline.FailureRate)
+
+	require.NoError(t, os.WriteFile(file, []byte("severity_bands:\n  warning: 1.5\n"), 0o644))
+	_, err = resolveThresholds(file, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "severity_bands.warning must be between 0 and 1, got 1.5")
+
+	warning := 0.6
+	_, err = resolveThresholds("", &Thresholds{SeverityBands: &SeverityBands{Warning: &warning, Critical: &critical}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not exceed severity_bands.critical")
+}
+
+func TestApplyThresholds(t *testing.T) {
+	warning, critical, minFitness, baseline := 0.1, 0.5, 3.0, 0.1
+	thresholds := &Thresholds{
+		SeverityBands:    &SeverityBands{Warning: &warning, Critical: &critical},
+		NotifyMinFitness: &minFitness,
+		Baseline:         &Baseline{FailureRate: &baseline},
+	}
+	result := &analysisengine.Result{Metadata: map[string]any{}}
+
+	applyThresholds(thresholds, result, krknAgg.KrknAISummary{TotalScenarioCount: 4, FailedScenarioCount: 1, MaxFitnessScore: 2.0})
+
+	assert.Equal(t, SeverityWarning, result.Metadata["severity"])
+	assert.InDelta(t, 0.15, result.Metadata["failure_rate_delta"], 1e-9)
+	assert.Equal(t, true, result.Metadata["notification_suppressed"])
+
+	reporter := &countingReporter{}
+	registry := slack.NewReporterRegistry()
+	registry.Register(reporter)
+	engine := &Engine{
+		config:    &Config{NotificationConfig: &slack.NotificationConfig{Enabled: true, Reporters: []slack.ReporterConfig{{Type: "counting", Enabled: true}}}},
+		reporters: registry,
+	}
+	engine.sendNotifications(context.Background(), result)
+	assert.Equal(t, 0, reporter.calls)
+
+	delete(result.Metadata, "notification_suppressed")
+	engine.sendNotifications(context.Background(), result)
+	assert.Equal(t, 1, reporter.calls)
+}
+
+func TestSendNotifications_Results(t *testing.T) {
+	reporter := &countingReporter{}
+	registry := slack.NewReporterRegistry()
+	registry.Register(reporter)
+	config := &slack.NotificationConfig{
+		Enabled: true,
+		Reporters: []slack.ReporterConfig{
+			{Type: "webhook", Enabled: true, Settings: map[string]interface{}{}},
+			{Type: "counting", Enabled: true},
+		},
+	}
+	engine := &Engine{config: &Config{NotificationConfig: config}, reporters: registry}
+
+	result := &analysisengine.Result{Status: "success", Metadata: map[string]any{}}
+	results := engine.sendNotifications(context.Background(), result)
+	require.Len(t, results, 2)
+	assert.False(t, results[0].Success)
+	assert.True(t, results[1].Success)
+	assert.Equal(t, 1, reporter.calls, "a failing reporter must not stop the others")
+	assert.Equal(t, []map[string]any{
+		{"type": "webhook", "success": false, "error": "url is required and must be a string"},
+		{"type": "counting", "success": true},
+	}, result.Metadata["notifications"])
+
+	config.DryRun = true
+	results = engine.sendNotifications(context.Background(), result)
+	require.Len(t, results, 2)
+	assert.Equal(t, 1, reporter.calls, "a dry run must not deliver")
+	assert.Equal(t, map[string]any{"type": "counting", "success": true, "dry_run": true}, result.Metadata["notifications"].([]map[string]any)[1])
+}
+
+func TestRegisteredReporters(t *testing.T) {
+	engine := &Engine{reporters: slack.NewReporterRegistry()}
+	assert.Equal(t, []string{"slack", "webhook"}, engine.RegisteredReporters())
+
+	assert.Empty(t, (&Engine{}).RegisteredReporters())
 }
 
 func TestRun_WithMockLLM(t *testing.T) {
@@ -366,33 +903,1223 @@ func TestRun_WithMockLLM(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestRun_LLMFailure(t *testing.T) {
+func TestBuildCampaignRollup(t *testing.T) {
+	ctx := context.Background()
+	runCampaign := func(campaignID string) string {
+		tempDir := t.TempDir()
+		reportsDir := filepath.Join(tempDir, "reports")
+		require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+		createTestResultFiles(t, tempDir, reportsDir)
+
+		engine := &Engine{
+			config: &Config{
+				BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+				CampaignID: campaignID,
+			},
+			aggregator:  krknAgg.NewKrknAIAggregator(ctx),
+			promptStore: newTestPromptStore(t),
+			llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "ok"}},
+		}
+		result, err := engine.Run(ctx)
+		require.NoError(t, err)
+		if campaignID != "" {
+			assert.Equal(t, campaignID, result.Metadata["campaign_id"])
+		} else {
+			assert.NotContains(t, result.Metadata, "campaign_id")
+		}
+		return filepath.Join(tempDir, analysisDirName, summaryFileName)
+	}
+
+	first, second := runCampaign("game-day"), runCampaign("game-day")
+	rollup, err := BuildCampaignRollup("game-day", []string{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, "game-day", rollup.CampaignID)
+	assert.Len(t, rollup.Runs, 2)
+	assert.Equal(t, map[string]int{"completed": 2}, rollup.Statuses)
+	assert.Equal(t, 10, rollup.Summary.TotalScenarioCount)
+	assert.Equal(t, 8, rollup.Summary.SuccessfulScenarioCount)
+	assert.Equal(t, rollup.Runs[0].Summary.MaxFitnessScore, rollup.Summary.MaxFitnessScore)
+
+	_, err = BuildCampaignRollup("game-day", []string{first, runCampaign("other")})
+	assert.ErrorContains(t, err, `belongs to campaign "other"`)
+
+	_, err = BuildCampaignRollup("game-day", []string{runCampaign("")})
+	assert.Error(t, err)
+
+	_, err = BuildCampaignRollup("", []string{first})
+	assert.Error(t, err)
+}
+
+func TestRun_WritesArtifactIndex(t *testing.T) {
 	tempDir := t.TempDir()
 	reportsDir := filepath.Join(tempDir, "reports")
 	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}},
+	}
+
+	// Run twice to make sure entries are not duplicated across runs
+	for i := 0; i < 2; i++ {
+		_, err := engine.Run(context.Background())
+		require.NoError(t, err)
+	}
+
+	indexData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, indexFileName))
+	require.NoError(t, err)
+	var index struct {
+		Artifacts []ArtifactEntry `yaml:"artifacts"`
+	}
+	require.NoError(t, yaml.Unmarshal(indexData, &index))
+	assert.Equal(t, []ArtifactEntry{{Path: summaryFileName, Type: ArtifactTypeSummary}}, index.Artifacts)
+}
 
+func TestRun_SummaryFormats(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
 	createTestResultFiles(t, tempDir, reportsDir)
 
-	ctx := context.Background()
-	agg := krknAgg.NewKrknAIAggregator(ctx)
-	promptStore := newTestPromptStore(t)
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:     analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			SummaryFormats: []string{SummaryFormatJSON, SummaryFormatMarkdown, SummaryFormatYAML},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "Cluster recovered from every scenario."}},
+	}
+	_, err := engine.Run(context.Background())
+	require.NoError(t, err)
 
-	mockClient := &mockLLMClient{
-		err: assert.AnError,
+	analysisDir := filepath.Join(tempDir, analysisDirName)
+	assert.FileExists(t, filepath.Join(analysisDir, summaryFileName))
+
+	jsonData, err := os.ReadFile(filepath.Join(analysisDir, summaryJSONFileName))
+	require.NoError(t, err)
+	var summary map[string]any
+	require.NoError(t, json.Unmarshal(jsonData, &summary))
+	assert.Equal(t, "completed", summary["status"])
+	assert.Equal(t, float64(5), summary["run_summary"].(map[string]any)["total_scenarios"])
+
+	markdown, err := os.ReadFile(filepath.Join(analysisDir, summaryMarkdownFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(markdown), "| Total scenarios | 5 |")
+	assert.Contains(t, string(markdown), "| Failed scenarios | 1 |")
+	assert.Contains(t, string(markdown), "## Top Scenarios")
+	assert.Contains(t, string(markdown), "Cluster recovered from every scenario.")
+
+	var paths []string
+	for _, artifact := range engine.artifacts {
+		paths = append(paths, artifact.Path)
+	}
+	assert.Equal(t, []string{summaryJSONFileName, summaryMarkdownFileName, summaryFileName}, paths)
+}
+
+func TestRunComparison(t *testing.T) {
+	baselineDir := t.TempDir()
+	baselineCSV := `generation_id,scenario_id,scenario,parameters,health_check_failure_score,health_check_response_time_score,krkn_failure_score,fitness_score
+0,1,node-cpu-hog,"chaos-duration=60 cpu-percentage=50",0.0,1.0,0.0,2.0
+1,2,pod-scenarios,"namespace=openshift-monitoring",0.0,0.0,-1.0,-1.0
+2,3,dns-outage,"chaos-duration=60 pod-name=test",0.0,0.5,0.0,1.5`
+	require.NoError(t, os.MkdirAll(filepath.Join(baselineDir, "reports"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(baselineDir, "reports", "all.csv"), []byte(baselineCSV), 0o644))
+
+	currentDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(currentDir, "reports"), 0o755))
+	createTestResultFiles(t, currentDir, filepath.Join(currentDir, "reports"))
+
+	engine := &Engine{
+		config:      &Config{BaseConfig: analysisengine.BaseConfig{ArtifactsDir: t.TempDir(), APIKey: "fake-key"}},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "dns-outage regressed"}},
 	}
 
+	result, err := engine.RunComparison(context.Background(), baselineDir, currentDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Metadata["newly_failing_scenarios"])
+	assert.Equal(t, 1, result.Metadata["newly_passing_scenarios"])
+	assert.InDelta(t, 0.2, result.Metadata["max_fitness_delta"], 1e-9)
+	assert.InDelta(t, 0.125, result.Metadata["avg_fitness_delta"], 1e-9)
+	assert.Equal(t, 0, result.Metadata["failed_scenario_delta"])
+
+	assert.Contains(t, result.Prompt, "Newly failing types: dns-outage")
+	assert.Contains(t, result.Prompt, "Newly passing types: pod-scenarios")
+	assert.Contains(t, result.Prompt, "fitness max +0.20 avg +0.12")
+
+	// Outputs go to the current run's directory
+	assert.FileExists(t, filepath.Join(currentDir, analysisDirName, summaryFileName))
+
+	_, err = engine.RunComparison(context.Background(), "", currentDir)
+	assert.ErrorContains(t, err, "baseline results directory is required")
+}
+
+func TestRun_AnalysisTimeout(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	reporter := &countingReporter{}
 	engine := &Engine{
 		config: &Config{
-			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			BaseConfig:      analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			AnalysisTimeout: 50 * time.Millisecond,
+			NotificationConfig: &slack.NotificationConfig{
+				Enabled:   true,
+				Reporters: []slack.ReporterConfig{{Type: reporter.Name(), Enabled: true}},
+			},
 		},
-		aggregator:  agg,
-		promptStore: promptStore,
-		llmClient:   mockClient,
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   blockingLLMClient{},
+		reporters:   slack.NewReporterRegistry(),
 	}
+	engine.reporters.Register(reporter)
 
-	_, err := engine.Run(ctx)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "LLM analysis failed")
+	result, err := engine.Run(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "timed out during LLM analysis")
+	require.NotNil(t, result)
+	assert.Equal(t, "timeout", result.Status)
+	assert.Equal(t, "LLM analysis", result.Metadata["timed_out_stage"])
+	assert.Zero(t, reporter.calls, "notifications need the expired context")
+
+	// The partial summary still carries the aggregated run metrics
+	summaryData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(summaryData), "status: timeout")
+	assert.Contains(t, string(summaryData), "total_scenarios: 5")
+}
+
+func TestRun_ResponseCache(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	cacheDir := t.TempDir()
+	client := &mockLLMClient{response: &llm.AnalysisResult{
+		Content:   "cached analysis",
+		ToolCalls: []*genai.FunctionCall{{Name: "read_file"}},
+		Usage:     llm.Usage{PromptTokens: 100, CompletionTokens: 10},
+	}}
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:   analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			CacheEnabled: true,
+			CacheDir:     cacheDir,
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   client,
+	}
+
+	first, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, false, first.Metadata["cache_hit"])
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	second, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.calls, "the second run is served from the cache")
+	assert.Equal(t, true, second.Metadata["cache_hit"])
+	assert.Equal(t, first.Content, second.Content)
+	assert.Equal(t, 1, second.Metadata["artifacts_examined"])
+	assert.Equal(t, 0, second.Metadata["prompt_tokens"])
+
+	// A different LLM config is a different cache entry
+	temperature := float32(0.9)
+	engine.config.LLMConfig = &llm.AnalysisConfig{Temperature: &temperature}
+	third, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls)
+	assert.Equal(t, false, third.Metadata["cache_hit"])
+}
+
+func TestRun_InvalidLLMConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	client := &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}}
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{
+				ArtifactsDir: tempDir,
+				APIKey:       "fake-key",
+				LLMConfig:    &llm.AnalysisConfig{Temperature: genai.Ptr(float32(3))},
+			},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   client,
+	}
+
+	_, err := engine.Run(context.Background())
+	assert.ErrorContains(t, err, "invalid LLM config: temperature must be between 0 and 2, got 3")
+	assert.Equal(t, 0, client.calls, "the provider is not called")
+}
+
+func TestRun_TokenUsage(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:          analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			PromptTokenCost:     1.25,
+			CompletionTokenCost: 10,
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient: &mockLLMClient{response: &llm.AnalysisResult{
+			Content: "analysis",
+			Usage:   llm.Usage{PromptTokens: 200000, CompletionTokens: 5000},
+		}},
+	}
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 200000, result.Metadata["prompt_tokens"])
+	assert.Equal(t, 5000, result.Metadata["completion_tokens"])
+	assert.InDelta(t, 0.3, result.Metadata["estimated_cost"], 1e-9)
+
+	// The usage is persisted with the summary so historical runs can be compared
+	summaryData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err)
+	var summary struct {
+		Metadata map[string]any `yaml:"metadata"`
+	}
+	require.NoError(t, yaml.Unmarshal(summaryData, &summary))
+	assert.Equal(t, 200000, summary.Metadata["prompt_tokens"])
+	assert.Equal(t, 5000, summary.Metadata["completion_tokens"])
+	assert.InDelta(t, 0.3, summary.Metadata["estimated_cost"], 1e-9)
+}
+
+func TestParseLLMSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "plain", content: "# Report\n\nOverall severity: high", want: LLMSeverityHigh},
+		{name: "markdown emphasis", content: "**Overall Severity:** `Critical`\n", want: LLMSeverityCritical},
+		{name: "list item", content: "- overall severity: none", want: LLMSeverityNone},
+		{name: "last rating wins", content: "Overall severity: low\n...\nOverall severity: medium", want: LLMSeverityMedium},
+		{name: "per-vulnerability severity ignored", content: "Severity: Critical\n", want: LLMSeverityUnknown},
+		{name: "unknown level", content: "Overall severity: catastrophic", want: LLMSeverityUnknown},
+		{name: "missing", content: "analysis", want: LLMSeverityUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseLLMSeverity(tt.content))
+		})
+	}
+}
+
+func TestRun_LLMSeverity(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	reporter := &countingReporter{}
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			NotificationConfig: &slack.NotificationConfig{
+				Enabled:   true,
+				Reporters: []slack.ReporterConfig{{Type: "counting", Enabled: true}},
+			},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		reporters:   slack.NewReporterRegistry(),
+		llmClient: &mockLLMClient{response: &llm.AnalysisResult{
+			Content: "# Krkn-AI Chaos Test Report\n\nOverall severity: **High**",
+		}},
+	}
+	engine.reporters.Register(reporter)
+
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, LLMSeverityHigh, result.Metadata["llm_severity"])
+
+	require.Equal(t, 1, reporter.calls)
+	assert.True(t, strings.HasPrefix(reporter.last.Content, "====== 🚦 Severity ======\nHIGH\n\n"))
+	assert.Equal(t, LLMSeverityHigh, reporter.last.Metadata["llm_severity"])
+}
+
+func TestRun_MaxArtifactReads(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+	var paths []string
+	for _, name := range []string{"scenario-1.log", "scenario-2.log", "scenario-3.log"} {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("chaos injected\n"), 0o644))
+		paths = append(paths, path)
+	}
+
+	newEngine := func(maxReads int, client llm.LLMClient) *Engine {
+		return &Engine{
+			config: &Config{
+				BaseConfig:       analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+				MaxArtifactReads: maxReads,
+			},
+			aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+			promptStore: newTestPromptStore(t),
+			llmClient:   client,
+		}
+	}
+
+	client := &fileReadingLLMClient{paths: paths}
+	result, err := newEngine(2, client).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, true, result.Metadata["artifacts_budget_exceeded"])
+	require.Len(t, client.results, 3)
+	assert.Equal(t, "1\tchaos injected", client.results[1])
+	refusal, ok := client.results[2].(map[string]any)
+	require.True(t, ok, "the third read is refused with a structured tool error")
+	assert.Equal(t, tools.FileBudgetExhaustedError, refusal["error"])
+
+	client = &fileReadingLLMClient{paths: paths}
+	result, err = newEngine(0, client).Run(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, result.Metadata, "artifacts_budget_exceeded")
+	assert.Equal(t, "1\tchaos injected", client.results[2])
+}
+
+func TestRunStream(t *testing.T) {
+	newEngine := func(t *testing.T, client llm.LLMClient) (*Engine, string) {
+		tempDir := t.TempDir()
+		reportsDir := filepath.Join(tempDir, "reports")
+		require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+		createTestResultFiles(t, tempDir, reportsDir)
+		return &Engine{
+			config: &Config{
+				BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			},
+			aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+			promptStore: newTestPromptStore(t),
+			llmClient:   client,
+		}, tempDir
+	}
+
+	t.Run("streaming client", func(t *testing.T) {
+		engine, tempDir := newEngine(t, &streamingLLMClient{chunks: []string{"# Report\n", "All good.\n", "Overall severity: low"}})
+
+		var chunks []string
+		result, err := engine.RunStream(context.Background(), func(text string) { chunks = append(chunks, text) })
+		require.NoError(t, err)
+		assert.Equal(t, []string{"# Report\n", "All good.\n", "Overall severity: low"}, chunks)
+		assert.Equal(t, "completed", result.Status)
+		assert.Equal(t, "# Report\nAll good.\nOverall severity: low", result.Content)
+		assert.Equal(t, LLMSeverityLow, result.Metadata["llm_severity"])
+
+		// The summary is still written once the stream ends
+		summaryData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+		require.NoError(t, err)
+		assert.Contains(t, string(summaryData), "All good.")
+	})
+
+	t.Run("stream error", func(t *testing.T) {
+		engine, _ := newEngine(t, &streamingLLMClient{chunks: []string{"partial"}, err: errors.New("connection reset")})
+
+		var chunks []string
+		result, err := engine.RunStream(context.Background(), func(text string) { chunks = append(chunks, text) })
+		// As with Run, a failed LLM call is reported in the written result
+		require.NoError(t, err)
+		assert.Equal(t, []string{"partial"}, chunks)
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Error, "connection reset")
+	})
+
+	t.Run("non-streaming client", func(t *testing.T) {
+		engine, _ := newEngine(t, &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}})
+
+		var chunks []string
+		result, err := engine.RunStream(context.Background(), func(text string) { chunks = append(chunks, text) })
+		require.NoError(t, err)
+		assert.Equal(t, []string{"analysis"}, chunks)
+		assert.Equal(t, "analysis", result.Content)
+	})
+
+	engine, _ := newEngine(t, &mockLLMClient{})
+	_, err := engine.RunStream(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+func TestRun_ThresholdGates(t *testing.T) {
+	highThreshold, lowThreshold := 1000.0, 0.5
+	oneFailure, noFailures := 1, 0
+	tests := []struct {
+		name               string
+		fitnessThreshold   *float64
+		maxFailedScenarios *int
+		wantViolation      string
+	}{
+		{name: "within gates", fitnessThreshold: &highThreshold, maxFailedScenarios: &oneFailure},
+		{name: "fitness above threshold", fitnessThreshold: &lowThreshold, wantViolation: "exceeds threshold 0.5"},
+		{name: "too many failed scenarios", maxFailedScenarios: &noFailures, wantViolation: "1 failed scenarios exceeds maximum 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			reportsDir := filepath.Join(tempDir, "reports")
+			require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+			createTestResultFiles(t, tempDir, reportsDir)
+
+			reporter := &countingReporter{}
+			engine := &Engine{
+				config: &Config{
+					BaseConfig:         analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+					FitnessThreshold:   tt.fitnessThreshold,
+					MaxFailedScenarios: tt.maxFailedScenarios,
+					NotificationConfig: &slack.NotificationConfig{
+						Enabled:   true,
+						Reporters: []slack.ReporterConfig{{Type: reporter.Name(), Enabled: true}},
+					},
+				},
+				aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+				promptStore: newTestPromptStore(t),
+				llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}},
+				reporters:   slack.NewReporterRegistry(),
+			}
+			engine.reporters.Register(reporter)
+
+			result, err := engine.Run(context.Background())
+			require.NotNil(t, result)
+			assert.Equal(t, 1, reporter.calls, "notifications are sent before returning")
+			assert.FileExists(t, filepath.Join(tempDir, analysisDirName, summaryFileName))
+
+			if tt.wantViolation == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "completed", result.Status)
+				return
+			}
+			require.ErrorIs(t, err, ErrThresholdExceeded)
+			assert.Contains(t, err.Error(), tt.wantViolation)
+			assert.Equal(t, "failed", result.Status)
+			assert.Equal(t, "failed", reporter.last.Status)
+		})
+	}
+}
+
+func TestRun_HealthCheckSuccessThreshold(t *testing.T) {
+	newEngine := func(t *testing.T, threshold float64) *Engine {
+		tempDir := t.TempDir()
+		reportsDir := filepath.Join(tempDir, "reports")
+		require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+		createTestResultFiles(t, tempDir, reportsDir)
+		healthCSV := `scenario_id,component_name,min_response_time,max_response_time,average_response_time,success_count,failure_count
+1,console,0.065,0.400,0.088,100,0
+2,console,0.064,0.280,0.087,90,10
+1,api,0.010,0.900,0.200,40,10
+2,api,0.010,0.900,0.300,45,5`
+		require.NoError(t, os.WriteFile(filepath.Join(reportsDir, "health_check_report.csv"), []byte(healthCSV), 0o644))
+		return &Engine{
+			config: &Config{
+				BaseConfig:                  analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+				HealthCheckSuccessThreshold: threshold,
+			},
+			aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+			promptStore: newTestPromptStore(t),
+			llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}},
+		}
+	}
+
+	// console succeeded 95% of its checks and api 85%
+	result, err := newEngine(t, 0.9).Run(context.Background())
+	require.ErrorIs(t, err, ErrThresholdExceeded)
+	assert.Contains(t, err.Error(), "health check success rate below 90.0%: api 85.0%")
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, true, result.Metadata["health_degraded"])
+	assert.Equal(t, []string{"api"}, result.Metadata["degraded_applications"])
+
+	result, err = newEngine(t, 0.8).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, false, result.Metadata["health_degraded"])
+	assert.NotContains(t, result.Metadata, "degraded_applications")
+
+	result, err = newEngine(t, 0).Run(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, result.Metadata, "health_degraded", "disabled by default")
+
+	_, err = New(context.Background(), &Config{
+		BaseConfig:                  analysisengine.BaseConfig{ArtifactsDir: "/some/dir", APIKey: "fake-key"},
+		HealthCheckSuccessThreshold: 95,
+	})
+	assert.ErrorContains(t, err, "between 0 and 1")
+}
+
+func TestRun_Metrics(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	registry := prometheus.NewRegistry()
+	metrics, err := NewPrometheusMetrics(registry)
+	require.NoError(t, err)
+	_, err = NewPrometheusMetrics(registry)
+	assert.Error(t, err, "collectors can only be registered once")
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			Metrics:    metrics,
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient: &mockLLMClient{response: &llm.AnalysisResult{
+			Content:   "analysis",
+			ToolCalls: []*genai.FunctionCall{{Name: "read_file"}, {Name: "read_file"}},
+			Usage:     llm.Usage{PromptTokens: 1200, CompletionTokens: 300},
+		}},
+	}
+	_, err = engine.Run(context.Background())
+	require.NoError(t, err)
+
+	engine.config.ArtifactsDir = filepath.Join(tempDir, "missing")
+	_, err = engine.Run(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.runs.WithLabelValues("completed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.runs.WithLabelValues("error")), "a run without a result counts as an error")
+	assert.Equal(t, 1200.0, testutil.ToFloat64(metrics.tokens.WithLabelValues("prompt")))
+	assert.Equal(t, 300.0, testutil.ToFloat64(metrics.tokens.WithLabelValues("completion")))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	samples := map[string]uint64{}
+	for _, family := range families {
+		if h := family.GetMetric()[0].GetHistogram(); h != nil {
+			samples[family.GetName()] = h.GetSampleCount()
+		}
+	}
+	assert.Equal(t, uint64(2), samples["krknai_analysis_run_duration_seconds"])
+	assert.Equal(t, uint64(1), samples["krknai_analysis_tool_calls"], "only the provider call is observed")
+}
+
+func TestNew_InvalidSummaryFormats(t *testing.T) {
+	for _, formats := range [][]string{{"xml"}, {"json", "json"}} {
+		_, err := New(context.Background(), &Config{
+			BaseConfig:     analysisengine.BaseConfig{ArtifactsDir: "/some/dir", APIKey: "fake-key"},
+			SummaryFormats: formats,
+		})
+		assert.Error(t, err, "formats %v", formats)
+	}
+}
+
+func TestNew_PromptTemplatePath(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTemplate := func(name, userPrompt string) string {
+		path := filepath.Join(tempDir, name)
+		content := "system_prompt: Custom analyst.\nuser_prompt: |\n  " + userPrompt + "\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	newEngine := func(path string) (*Engine, error) {
+		return New(context.Background(), &Config{
+			BaseConfig:         analysisengine.BaseConfig{ArtifactsDir: tempDir},
+			CannedResponse:     "canned",
+			PromptTemplatePath: path,
+		})
+	}
+
+	t.Run("file override", func(t *testing.T) {
+		engine, err := newEngine(writeTemplate("custom.yaml", "Custom: {{.Summary.TotalScenarioCount}} scenarios"))
+		require.NoError(t, err)
+		userPrompt, config, err := engine.promptStore.RenderPrompt(krknAIPromptTemplate,
+			promptVariables(&krknAgg.KrknAIData{Summary: krknAgg.KrknAISummary{TotalScenarioCount: 7}}))
+		require.NoError(t, err)
+		assert.Equal(t, "Custom: 7 scenarios", userPrompt)
+		assert.Equal(t, "Custom analyst.", *config.SystemInstruction)
+	})
+
+	t.Run("directory override", func(t *testing.T) {
+		dir := filepath.Join(tempDir, "prompts")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "krknai.yaml"),
+			[]byte("system_prompt: Dir analyst.\nuser_prompt: Dir prompt\n"), 0o644))
+		engine, err := newEngine(dir)
+		require.NoError(t, err)
+		userPrompt, _, err := engine.promptStore.RenderPrompt(krknAIPromptTemplate, promptVariables(&krknAgg.KrknAIData{}))
+		require.NoError(t, err)
+		assert.Equal(t, "Dir prompt", userPrompt)
+	})
+
+	t.Run("directory without krknai.yaml", func(t *testing.T) {
+		_, err := newEngine(t.TempDir())
+		assert.ErrorContains(t, err, "has no krknai.yaml")
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := newEngine(filepath.Join(tempDir, "missing.yaml"))
+		assert.Error(t, err)
+	})
+
+	t.Run("template that does not render", func(t *testing.T) {
+		_, err := newEngine(writeTemplate("broken.yaml", "{{.Summary.NoSuchField}}"))
+		assert.ErrorContains(t, err, "invalid krknai prompt template")
+	})
+}
+
+func TestRun_RetentionPrunesOldRuns(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	// Files and directories the engine did not create are never pruned
+	analysisDir := filepath.Join(tempDir, analysisDirName)
+	require.NoError(t, os.MkdirAll(filepath.Join(analysisDir, "run-manual"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(analysisDir, "notes.md"), []byte("keep"), 0o644))
+
+	// A policy keeps earlier runs in run directories even when the flat layout was configured
+	newEngine := func(policy *RetentionPolicy) *Engine {
+		engine, err := New(context.Background(), &Config{
+			BaseConfig:     analysisengine.BaseConfig{ArtifactsDir: tempDir},
+			CannedResponse: "analysis",
+			Retention:      policy,
+		})
+		require.NoError(t, err)
+		return engine
+	}
+	runDirs := func() []string {
+		entries, err := os.ReadDir(analysisDir)
+		require.NoError(t, err)
+		var dirs []string
+		for _, entry := range entries {
+			if runDirPattern.MatchString(entry.Name()) {
+				dirs = append(dirs, entry.Name())
+			}
+		}
+		return dirs
+	}
+
+	// KeepLastSummaries counts the runs, the current one included, like RetainRuns
+	engine := newEngine(&RetentionPolicy{KeepLastSummaries: 2})
+	var runs []string
+	for range 3 {
+		_, err := engine.Run(context.Background())
+		require.NoError(t, err)
+		runs = append(runs, engine.runDir)
+	}
+	assert.Equal(t, runs[1:], runDirs())
+	assert.FileExists(t, filepath.Join(analysisDir, runs[1], summaryFileName))
+	assert.FileExists(t, filepath.Join(analysisDir, "notes.md"))
+	assert.DirExists(t, filepath.Join(analysisDir, "run-manual"))
+
+	// MaxAge goes by when each run started; age the older run as if it ran two days ago
+	aged := runDirName(time.Now().Add(-48 * time.Hour))
+	require.NoError(t, os.Rename(filepath.Join(analysisDir, runs[1]), filepath.Join(analysisDir, aged)))
+	engine = newEngine(&RetentionPolicy{MaxAge: 24 * time.Hour})
+	_, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{runs[2], engine.runDir}, runDirs())
+	assert.FileExists(t, filepath.Join(analysisDir, "notes.md"))
+}
+
+func TestNew_InvalidRetention(t *testing.T) {
+	_, err := New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: t.TempDir(), APIKey: "fake-key"},
+		Retention:  &RetentionPolicy{KeepLastSummaries: -1},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "keep_last_summaries must not be negative")
+
+	_, err = New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: t.TempDir(), APIKey: "fake-key"},
+		Retention:  &RetentionPolicy{KeepLastSummaries: 3},
+		RetainRuns: 2,
+	})
+	assert.ErrorContains(t, err, "retention keep_last_summaries 3 conflicts with RetainRuns 2")
+
+	// The alias may repeat RetainRuns
+	engine, err := New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: t.TempDir(), APIKey: "fake-key"},
+		Retention:  &RetentionPolicy{KeepLastSummaries: 2},
+		RetainRuns: 2,
+	})
+	require.NoError(t, err)
+	assert.True(t, engine.config.RunDirectories)
+	assert.Equal(t, 2, engine.config.RetainRuns)
+}
+
+func TestRun_RunDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	// Directories the engine did not create are never pruned
+	analysisDir := filepath.Join(tempDir, analysisDirName)
+	oldRun := runDirName(time.Now().Add(-time.Hour))
+	for _, name := range []string{oldRun, "run-manual"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(analysisDir, name), 0o755))
+	}
+
+	client := &mockLLMClient{response: &llm.AnalysisResult{Content: "first analysis"}}
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:     analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			RunDirectories: true,
+			RetainRuns:     2,
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   client,
+	}
+
+	_, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	firstRun := engine.runDir
+	assert.Regexp(t, runDirPattern, firstRun)
+	assert.DirExists(t, filepath.Join(analysisDir, oldRun), "kept within RetainRuns")
+
+	client.response = &llm.AnalysisResult{Content: "second analysis"}
+	_, err = engine.Run(context.Background())
+	require.NoError(t, err)
+	require.NotEqual(t, firstRun, engine.runDir)
+
+	entries, err := os.ReadDir(analysisDir)
+	require.NoError(t, err)
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{firstRun, engine.runDir, "run-manual", latestRunLink}, remaining)
+
+	target, err := os.Readlink(filepath.Join(analysisDir, latestRunLink))
+	require.NoError(t, err)
+	assert.Equal(t, engine.runDir, target)
+	summary, err := os.ReadFile(filepath.Join(analysisDir, latestRunLink, summaryFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "second analysis")
+
+	// The run directory is named after the run's start, which the summary is stamped with
+	var stamped struct {
+		Timestamp string `yaml:"timestamp"`
+	}
+	require.NoError(t, yaml.Unmarshal(summary, &stamped))
+	timestamp, err := time.Parse(time.RFC3339, stamped.Timestamp)
+	require.NoError(t, err)
+	started, err := runDirTime(engine.runDir)
+	require.NoError(t, err)
+	assert.Equal(t, started.Truncate(time.Second), timestamp.UTC())
+	assert.FileExists(t, filepath.Join(analysisDir, firstRun, indexFileName))
+
+	_, err = New(context.Background(), &Config{
+		BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+		RetainRuns: 3,
+	})
+	assert.ErrorContains(t, err, "RetainRuns requires RunDirectories")
+}
+
+func TestRun_ConfigMapSink(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	kubeClient := fake.NewSimpleClientset()
+	mockClient := &mockLLMClient{response: &llm.AnalysisResult{Content: "first analysis"}}
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:    analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			ConfigMapSink: &ConfigMapSink{Namespace: "osde2e", Name: "krknai-analysis"},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   mockClient,
+		kubeClient:  kubeClient,
+	}
+
+	_, err := engine.Run(context.Background())
+	require.NoError(t, err)
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("osde2e").Get(context.Background(), "krknai-analysis", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "completed", cm.Data["status"])
+	assert.Equal(t, "first analysis", cm.Data["content"])
+	assert.Equal(t, "false", cm.Data["content_truncated"])
+	assert.Contains(t, cm.Data["metadata.yaml"], "total_scenarios: 5")
+
+	// A second run updates the existing ConfigMap
+	mockClient.response = &llm.AnalysisResult{Content: "second analysis"}
+	_, err = engine.Run(context.Background())
+	require.NoError(t, err)
+
+	cm, err = kubeClient.CoreV1().ConfigMaps("osde2e").Get(context.Background(), "krknai-analysis", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "second analysis", cm.Data["content"])
+}
+
+func TestTruncateContent(t *testing.T) {
+	content, truncated := truncateContent("short", 100)
+	assert.Equal(t, "short", content)
+	assert.False(t, truncated)
+
+	long := strings.Repeat("é", 200) // 2 bytes per rune
+	content, truncated = truncateContent(long, 301)
+	assert.True(t, truncated)
+	assert.LessOrEqual(t, len(content), 301)
+	assert.Contains(t, content, "[truncated: content exceeded 301 bytes")
+	assert.True(t, strings.HasPrefix(content, "éé"))
+	assert.NotContains(t, content, "\uFFFD")
+}
+
+func TestRun_LatestGenerationOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()).WithLatestGenerationOnly(true),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}},
+	}
+
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "generation 2", result.Metadata["scenario_scope"])
+	assert.Equal(t, 5, result.Metadata["total_scenarios"])
+	assert.Contains(t, result.Prompt, "cover only the final generation (gen=2)")
+	assert.NotContains(t, result.Prompt, "node-cpu-hog gen=0")
+}
+
+func TestRun_ScenarioIDFilter(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()).WithScenarioIDFilter([]int{1, 3}),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}},
+	}
+
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "all generations, scenario IDs 1, 3", result.Metadata["scenario_scope"])
+	assert.Equal(t, 2, result.Metadata["total_scenarios"])
+	assert.Contains(t, result.Prompt, "Focus: only scenario IDs 1,3 were requested")
+	assert.NotContains(t, result.Prompt, "id=2 ")
+}
+
+func TestRun_BestScenario(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "analysis"}},
+	}
+
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, result.Prompt, "Best scenario (GA champion): node-cpu-hog gen=0 id=1 fitness=2.20")
+
+	summaryData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err)
+	var summary struct {
+		BestScenario krknAgg.ScenarioResult `yaml:"best_scenario"`
+	}
+	require.NoError(t, yaml.Unmarshal(summaryData, &summary))
+	assert.Equal(t, "node-cpu-hog", summary.BestScenario.Scenario)
+	assert.Equal(t, 2.2, summary.BestScenario.FitnessScore)
+}
+
+func TestNewRedactor(t *testing.T) {
+	r, err := newRedactor([]Redactor{
+		{Name: "openshift-cluster-id"},
+		{Name: "openshift-node-name"},
+		{Pattern: `team-[a-z]+`, Replacement: "[TEAM]"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t,
+		"cluster [CLUSTER-ID-REDACTED] node [NODE-NAME-REDACTED] and [NODE-NAME-REDACTED] owned by [TEAM]",
+		r.redact("cluster 2abcdefghijklmnopqrstuv012345678 node ip-10-0-1-23.ec2.internal and mycluster-x7k2p-worker-us-east-1a-8fj2k owned by team-chaos"))
+
+	for _, tc := range []struct {
+		redactors []Redactor
+		err       string
+	}{
+		{[]Redactor{{Name: "hostname"}}, `unknown redaction pattern "hostname"`},
+		{[]Redactor{{Name: "openshift-cluster-id", Pattern: "x"}}, "set either name or pattern"},
+		{[]Redactor{{Replacement: "x"}}, "a name or pattern is required"},
+		{[]Redactor{{Pattern: "("}}, "invalid pattern"},
+	} {
+		_, err := newRedactor(tc.redactors)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestRun_Redactors(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	const clusterID = "2abcdefghijklmnopqrstuv012345678"
+	redactor, err := newRedactor([]Redactor{{Name: "openshift-cluster-id"}})
+	require.NoError(t, err)
+
+	reporter := &countingReporter{}
+	registry := slack.NewReporterRegistry()
+	registry.Register(reporter)
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:         analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			NotificationConfig: &slack.NotificationConfig{Enabled: true, Reporters: []slack.ReporterConfig{{Type: "counting", Enabled: true}}},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()).WithClusterInfo(&krknAgg.ClusterInfo{ID: clusterID}),
+		promptStore: newTestPromptStore(t),
+		llmClient:   &mockLLMClient{response: &llm.AnalysisResult{Content: "Cluster " + clusterID + " is resilient"}},
+		reporters:   registry,
+		redactor:    redactor,
+	}
+
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.NotContains(t, result.Prompt, clusterID)
+	assert.Contains(t, result.Prompt, "id=[CLUSTER-ID-REDACTED]")
+	assert.Equal(t, "Cluster [CLUSTER-ID-REDACTED] is resilient", result.Content)
+
+	summaryData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(summaryData), clusterID)
+	var summary map[string]any
+	require.NoError(t, yaml.Unmarshal(summaryData, &summary), "redaction must keep the summary valid YAML")
+	assert.Equal(t, "[CLUSTER-ID-REDACTED]", summary["cluster_info"].(map[string]any)["id"])
+
+	require.Equal(t, 1, reporter.calls)
+	assert.NotContains(t, reporter.last.Content, clusterID)
+	assert.NotContains(t, reporter.last.Prompt, clusterID)
+}
+
+func TestRun_CannedResponse(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notificationConfig := slack.BuildNotificationConfig(server.URL, "C123", nil, tempDir)
+	notificationConfig.Reporters[0].Retry = &slack.RetryPolicy{MaxAttempts: 1}
+
+	// No API key is needed when a canned response is supplied
+	engine, err := New(context.Background(), &Config{
+		BaseConfig:         analysisengine.BaseConfig{ArtifactsDir: tempDir},
+		CannedResponse:     "## Canned analysis",
+		NotificationConfig: notificationConfig,
+	})
+	require.NoError(t, err)
+
+	result, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, "## Canned analysis", result.Content)
+	assert.Equal(t, true, result.Metadata["canned_response"])
+
+	_, err = os.Stat(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	assert.NoError(t, err)
+
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "C123", payloads[0]["channel"])
+	assert.Contains(t, payloads[0]["analysis"], "Canned analysis")
+}
+
+func TestEvaluateRecorded(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	mockClient := &mockLLMClient{err: assert.AnError}
+	reporter := &countingReporter{}
+	registry := slack.NewReporterRegistry()
+	registry.Register(reporter)
+	engine := &Engine{
+		config: &Config{
+			BaseConfig:         analysisengine.BaseConfig{ArtifactsDir: tempDir},
+			ReportFormat:       "html",
+			NotificationConfig: &slack.NotificationConfig{Enabled: true, Reporters: []slack.ReporterConfig{{Type: "counting", Enabled: true}}},
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(context.Background()),
+		promptStore: newTestPromptStore(t),
+		llmClient:   mockClient,
+		reporters:   registry,
+	}
+
+	result, err := engine.EvaluateRecorded(context.Background(), "recorded prompt", "## Findings\n\nAll good")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, mockClient.calls)
+	assert.Equal(t, 0, reporter.calls)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, "recorded prompt", result.Prompt)
+	assert.Contains(t, result.Content, "<h2")
+	assert.Equal(t, true, result.Metadata["recorded_response"])
+	assert.Equal(t, 5, result.Metadata["total_scenarios"])
+
+	summaryData, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(summaryData), "recorded prompt")
+}
+
+func TestRun_LLMFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	ctx := context.Background()
+	agg := krknAgg.NewKrknAIAggregator(ctx)
+	promptStore := newTestPromptStore(t)
+
+	mockClient := &mockLLMClient{
+		err: assert.AnError,
+	}
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+		},
+		aggregator:  agg,
+		promptStore: promptStore,
+		llmClient:   mockClient,
+	}
+
+	result, err := engine.Run(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "error", result.Status)
+	assert.Contains(t, result.Error, "LLM analysis failed")
+	assert.Equal(t, 5, result.Metadata["total_scenarios"])
+	assert.Equal(t, 1, result.Metadata["failed_scenarios"])
+
+	// Summary is still written with the error recorded
+	content, err := os.ReadFile(filepath.Join(tempDir, analysisDirName, summaryFileName))
+	require.NoError(t, err)
+	var summary map[string]any
+	require.NoError(t, yaml.Unmarshal(content, &summary))
+	assert.Equal(t, "error", summary["status"])
+	assert.Contains(t, summary["error"], "LLM analysis failed")
+}
+
+func TestRun_CoolDownReusesPriorAnalysis(t *testing.T) {
+	tempDir := t.TempDir()
+	reportsDir := filepath.Join(tempDir, "reports")
+	require.NoError(t, os.MkdirAll(reportsDir, 0o755))
+
+	createTestResultFiles(t, tempDir, reportsDir)
+
+	ctx := context.Background()
+	mockClient := &mockLLMClient{
+		response: &llm.AnalysisResult{Content: "# Report"},
+	}
+
+	engine := &Engine{
+		config: &Config{
+			BaseConfig: analysisengine.BaseConfig{ArtifactsDir: tempDir, APIKey: "fake-key"},
+			CoolDown:   time.Hour,
+		},
+		aggregator:  krknAgg.NewKrknAIAggregator(ctx),
+		promptStore: newTestPromptStore(t),
+		llmClient:   mockClient,
+	}
+
+	first, err := engine.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", first.Status)
+	assert.NotEmpty(t, first.Metadata["data_hash"])
+
+	second, err := engine.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "cached", second.Status)
+	assert.Equal(t, "# Report", second.Content)
+	assert.Equal(t, true, second.Metadata["cached"])
+	assert.Equal(t, 1, mockClient.calls)
+
+	// Gates tightened within the window judge the cached analysis
+	lowThreshold, noFailures := 0.5, 0
+	engine.config.FitnessThreshold = &lowThreshold
+	engine.config.MaxFailedScenarios = &noFailures
+	gated, err := engine.Run(ctx)
+	require.ErrorIs(t, err, ErrThresholdExceeded)
+	assert.Equal(t, "failed", gated.Status)
+	assert.Contains(t, gated.Error, "exceeds threshold 0.5")
+	assert.Contains(t, gated.Error, "1 failed scenarios exceeds maximum 0")
+	assert.Equal(t, true, gated.Metadata["cached"])
+	assert.Equal(t, 1, mockClient.calls)
+	engine.config.FitnessThreshold = nil
+	engine.config.MaxFailedScenarios = nil
+
+	// Changed results invalidate the cache
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "extra.log"), []byte("new\n"), 0o644))
+	third, err := engine.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", third.Status)
+	assert.Equal(t, 2, mockClient.calls)
+
+	// An analysis that crossed a gate is reused too
+	engine.config.FitnessThreshold = &lowThreshold
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "more.log"), []byte("new\n"), 0o644))
+	failed, err := engine.Run(ctx)
+	require.ErrorIs(t, err, ErrThresholdExceeded)
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, 3, mockClient.calls)
+
+	engine.config.FitnessThreshold = nil
+	reused, err := engine.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "cached", reused.Status)
+	assert.Equal(t, 3, mockClient.calls)
 }
 
 func TestRun_MissingResults(t *testing.T) {
@@ -0,0 +1,72 @@
+package analysisengine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openshift/osde2e/internal/analysisengine"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+)
+
+// ErrThresholdExceeded is returned by Run, alongside the written result, when the run
+// crosses FitnessThreshold or MaxFailedScenarios. Callers can map it to a failing exit code.
+var ErrThresholdExceeded = errors.New("krkn-ai analysis threshold exceeded")
+
+// thresholdViolationsKey records the crossed CI gates in the result metadata.
+const thresholdViolationsKey = "threshold_violations"
+
+// applyGates marks the result failed when the data crosses a configured CI gate.
+func (e *Engine) applyGates(result *analysisengine.Result, data *krknAggregator.KrknAIData) {
+	summary := data.Summary
+	var violations []string
+	if t := e.config.FitnessThreshold; t != nil && summary.MaxFitnessScore > *t {
+		violations = append(violations, fmt.Sprintf("max fitness score %g exceeds threshold %g", summary.MaxFitnessScore, *t))
+	}
+	if m := e.config.MaxFailedScenarios; m != nil && summary.FailedScenarioCount > *m {
+		violations = append(violations, fmt.Sprintf("%d failed scenarios exceeds maximum %d", summary.FailedScenarioCount, *m))
+	}
+	if t := e.config.HealthCheckSuccessThreshold; t > 0 {
+		var names, rates []string
+		for _, app := range data.HealthCheckReport.Applications() {
+			if rate := app.SuccessRate(); rate < t {
+				names = append(names, app.ComponentName)
+				rates = append(rates, fmt.Sprintf("%s %.1f%%", app.ComponentName, rate*100))
+			}
+		}
+		result.Metadata["health_degraded"] = len(names) > 0
+		if len(names) > 0 {
+			result.Metadata["degraded_applications"] = names
+			violations = append(violations, fmt.Sprintf("health check success rate below %.1f%%: %s", t*100, strings.Join(rates, ", ")))
+		}
+	}
+	if len(violations) == 0 {
+		return
+	}
+
+	result.Status = "failed"
+	message := strings.Join(violations, "; ")
+	if result.Error != "" {
+		message = result.Error + "; " + message
+	}
+	result.Error = message
+	result.Metadata[thresholdViolationsKey] = violations
+}
+
+// thresholdError returns ErrThresholdExceeded, wrapped with the violations, when the
+// result crossed a CI gate, including a result reused from the cool-down cache.
+func thresholdError(result *analysisengine.Result) error {
+	var violations []string
+	switch v := result.Metadata[thresholdViolationsKey].(type) {
+	case []string:
+		violations = v
+	case []any:
+		for _, item := range v {
+			violations = append(violations, fmt.Sprint(item))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrThresholdExceeded, strings.Join(violations, "; "))
+}
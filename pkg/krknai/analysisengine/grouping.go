@@ -0,0 +1,77 @@
+package analysisengine
+
+import (
+	"fmt"
+	"sort"
+
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+)
+
+// Scenario grouping modes for the written summary.
+const (
+	GroupByFitness = "fitness" // Flat lists ordered by fitness (default)
+	GroupByType    = "type"    // Grouped by scenario type
+	GroupByOutcome = "outcome" // Grouped by successful, failed, and timed-out outcome
+)
+
+// ScenarioGroup is a named set of scenarios in the summary.
+type ScenarioGroup struct {
+	Name      string                          `json:"name" yaml:"name"`
+	Scenarios []krknAggregator.ScenarioResult `json:"scenarios" yaml:"scenarios"`
+}
+
+// validateGrouping returns an error for an unknown SummaryScenarioGrouping value.
+func validateGrouping(grouping string) error {
+	switch grouping {
+	case "", GroupByFitness, GroupByType, GroupByOutcome:
+		return nil
+	}
+	return fmt.Errorf("unsupported summary scenario grouping %q (must be %q, %q or %q)", grouping, GroupByFitness, GroupByType, GroupByOutcome)
+}
+
+// groupScenarios organizes the top and failed scenarios by the given grouping.
+// Returns nil for the default fitness grouping, which keeps the flat lists.
+// Scenarios keep their fitness order within each group.
+func groupScenarios(grouping string, data *krknAggregator.KrknAIData) []ScenarioGroup {
+	var keyFn func(s krknAggregator.ScenarioResult) string
+	switch grouping {
+	case GroupByType:
+		keyFn = func(s krknAggregator.ScenarioResult) string { return s.Scenario }
+	case GroupByOutcome:
+		keyFn = func(s krknAggregator.ScenarioResult) string {
+			switch {
+			case s.TimedOut:
+				return "timed_out"
+			case s.KrknFailureScore < 0:
+				return "failed"
+			default:
+				return "successful"
+			}
+		}
+	default:
+		return nil
+	}
+
+	scenarios := make([]krknAggregator.ScenarioResult, 0, len(data.TopScenarios)+len(data.FailedScenarios))
+	scenarios = append(scenarios, data.TopScenarios...)
+	scenarios = append(scenarios, data.FailedScenarios...)
+	sort.SliceStable(scenarios, func(i, j int) bool {
+		return scenarios[i].FitnessScore > scenarios[j].FitnessScore
+	})
+
+	index := make(map[string]int)
+	var groups []ScenarioGroup
+	for _, s := range scenarios {
+		key := keyFn(s)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ScenarioGroup{Name: key})
+		}
+		groups[i].Scenarios = append(groups[i].Scenarios, s)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
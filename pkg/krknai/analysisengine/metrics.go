@@ -0,0 +1,107 @@
+package analysisengine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/osde2e/internal/analysisengine"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsRecorder receives measurements of every analysis run, for services that run the
+// engine continuously. Implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	// ObserveRunDuration records the wall time of a Run, RunStream, or RunComparison call.
+	ObserveRunDuration(d time.Duration)
+	// IncRunResult counts a finished run by its result status, or "error" when it
+	// returned no result.
+	IncRunResult(status string)
+	// ObserveTokens records the token usage of each provider call; cached and
+	// cool-down responses make no call and are not observed.
+	ObserveTokens(promptTokens, completionTokens int)
+	// ObserveToolCalls records the tool calls the model made in each provider call.
+	ObserveToolCalls(count int)
+}
+
+// noopMetrics is the MetricsRecorder used when Config.Metrics is nil.
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRunDuration(time.Duration) {}
+func (noopMetrics) IncRunResult(string)              {}
+func (noopMetrics) ObserveTokens(int, int)           {}
+func (noopMetrics) ObserveToolCalls(int)             {}
+
+// PrometheusMetrics is a MetricsRecorder backed by Prometheus collectors.
+type PrometheusMetrics struct {
+	runDuration prometheus.Histogram
+	runs        *prometheus.CounterVec
+	tokens      *prometheus.CounterVec
+	toolCalls   prometheus.Histogram
+}
+
+// NewPrometheusMetrics creates the krkn-ai analysis collectors and registers them with
+// registerer, e.g. prometheus.DefaultRegisterer.
+func NewPrometheusMetrics(registerer prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		runDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "krknai_analysis_run_duration_seconds",
+			Help:    "Duration of krkn-ai analysis runs.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		}),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "krknai_analysis_runs_total",
+			Help: "Finished krkn-ai analysis runs by result status.",
+		}, []string{"status"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "krknai_analysis_tokens_total",
+			Help: "LLM tokens consumed by krkn-ai analyses, by type (prompt or completion).",
+		}, []string{"type"}),
+		toolCalls: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "krknai_analysis_tool_calls",
+			Help:    "Tool calls made by the model per krkn-ai analysis.",
+			Buckets: []float64{0, 1, 2, 5, 10, 20, 50},
+		}),
+	}
+	for _, collector := range []prometheus.Collector{m.runDuration, m.runs, m.tokens, m.toolCalls} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register krkn-ai analysis metrics: %w", err)
+		}
+	}
+	return m, nil
+}
+
+func (m *PrometheusMetrics) ObserveRunDuration(d time.Duration) {
+	m.runDuration.Observe(d.Seconds())
+}
+
+func (m *PrometheusMetrics) IncRunResult(status string) {
+	m.runs.WithLabelValues(status).Inc()
+}
+
+func (m *PrometheusMetrics) ObserveTokens(promptTokens, completionTokens int) {
+	m.tokens.WithLabelValues("prompt").Add(float64(promptTokens))
+	m.tokens.WithLabelValues("completion").Add(float64(completionTokens))
+}
+
+func (m *PrometheusMetrics) ObserveToolCalls(count int) {
+	m.toolCalls.Observe(float64(count))
+}
+
+// metrics returns the configured recorder, or a no-op one.
+func (e *Engine) metrics() MetricsRecorder {
+	if e.config.Metrics == nil {
+		return noopMetrics{}
+	}
+	return e.config.Metrics
+}
+
+// recordRun reports a finished run to the metrics recorder.
+func (e *Engine) recordRun(start time.Time, result *analysisengine.Result) {
+	metrics := e.metrics()
+	metrics.ObserveRunDuration(time.Since(start))
+	status := "error"
+	if result != nil {
+		status = result.Status
+	}
+	metrics.IncRunResult(status)
+}
@@ -0,0 +1,71 @@
+package analysisengine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/osde2e/internal/analysisengine"
+	"github.com/openshift/osde2e/internal/llm"
+	"github.com/openshift/osde2e/internal/llm/tools"
+	"github.com/openshift/osde2e/pkg/common/slack"
+)
+
+// cannedLLMClient returns a fixed response instead of calling an LLM provider.
+type cannedLLMClient struct {
+	content string
+}
+
+func (c *cannedLLMClient) Analyze(_ context.Context, _ string, _ *llm.AnalysisConfig, _ *tools.Registry) (*llm.AnalysisResult, error) {
+	return &llm.AnalysisResult{Content: c.content}, nil
+}
+
+// sendNotifications dispatches the result to the configured reporters and records the
+// outcome of each under the notifications metadata key. Delivery failures are logged
+// rather than returned so they never discard the analysis.
+func (e *Engine) sendNotifications(ctx context.Context, result *analysisengine.Result) []slack.ReporterResult {
+	if e.config.NotificationConfig == nil || !e.config.NotificationConfig.Enabled || e.reporters == nil {
+		return nil
+	}
+	if suppressed, _ := result.Metadata["notification_suppressed"].(bool); suppressed {
+		logr.FromContextOrDiscard(ctx).Info("skipping krkn-ai notifications: max fitness score is below notify_min_fitness")
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "skipping krkn-ai notifications: context is done")
+		return nil
+	}
+
+	content := result.Content
+	if severity, ok := result.Metadata["llm_severity"].(string); ok {
+		content = fmt.Sprintf("====== 🚦 Severity ======\n%s\n\n%s", strings.ToUpper(severity), content)
+	}
+	notification := &slack.AnalysisResult{
+		Status:   result.Status,
+		Content:  content,
+		Metadata: result.Metadata,
+		Error:    result.Error,
+		Prompt:   result.Prompt,
+	}
+	results := e.reporters.Notify(ctx, notification, e.config.NotificationConfig)
+
+	summary := make([]map[string]any, 0, len(results))
+	for _, res := range results {
+		entry := map[string]any{"type": res.Type, "success": res.Success}
+		if res.Error != nil {
+			entry["error"] = res.Error.Error()
+			logr.FromContextOrDiscard(ctx).Error(res.Error, "failed to send krkn-ai analysis notification", "reporter", res.Type)
+		}
+		if e.config.NotificationConfig.DryRun {
+			entry["dry_run"] = true
+		}
+		summary = append(summary, entry)
+	}
+	if result.Metadata == nil {
+		result.Metadata = map[string]any{}
+	}
+	result.Metadata["notifications"] = summary
+	return results
+}
@@ -0,0 +1,158 @@
+package analysisengine
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/common/slack"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles/*.yaml
+var embeddedProfiles embed.FS
+
+// Profile holds environment-specific defaults for the analysis engine.
+type Profile struct {
+	TopScenariosCount           int                       `yaml:"top_scenarios_count"`
+	BottomScenariosCount        int                       `yaml:"bottom_scenarios_count"`
+	ReportFormat                string                    `yaml:"report_format"`
+	CoolDown                    time.Duration             `yaml:"cool_down"`
+	CollectTimeout              time.Duration             `yaml:"collect_timeout"`
+	SummaryFormats              []string                  `yaml:"summary_formats"`
+	SummaryScenarioGrouping     string                    `yaml:"summary_scenario_grouping"`
+	Assertions                  []Assertion               `yaml:"assertions"`
+	FailOnAssertions            bool                      `yaml:"fail_on_assertions"`
+	TimeZone                    string                    `yaml:"time_zone"`
+	AnalyzeLatestGenerationOnly bool                      `yaml:"analyze_latest_generation_only"`
+	DuplicateScenarios          string                    `yaml:"duplicate_scenarios"`
+	DeduplicateFailures         bool                      `yaml:"deduplicate_failures"`
+	ScenarioNormalization       map[string]string         `yaml:"scenario_normalization"`
+	NotificationConfig          *slack.NotificationConfig `yaml:"notification"`
+	Redactors                   []Redactor                `yaml:"redactors"`
+	Retention                   *RetentionPolicy          `yaml:"retention"`
+	RunDirectories              bool                      `yaml:"run_directories"`
+	RetainRuns                  int                       `yaml:"retain_runs"`
+}
+
+// LoadProfile reads the named profile, preferring <dir>/<name>.yaml when dir is set
+// and falling back to the profiles embedded in the engine.
+func LoadProfile(name, dir string) (*Profile, error) {
+	fileName := name + ".yaml"
+
+	var content []byte
+	var err error
+	if dir != "" {
+		content, err = os.ReadFile(filepath.Join(dir, fileName))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read profile %q: %w", name, err)
+		}
+	}
+	if content == nil {
+		content, err = embeddedProfiles.ReadFile("profiles/" + fileName)
+		if err != nil {
+			return nil, fmt.Errorf("unknown analysis profile %q (available: %s)", name, strings.Join(availableProfiles(dir), ", "))
+		}
+	}
+
+	var profile Profile
+	if err := yaml.Unmarshal(content, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %q: %w", name, err)
+	}
+	return &profile, nil
+}
+
+// availableProfiles lists the profile names found in dir and the embedded profiles.
+func availableProfiles(dir string) []string {
+	seen := make(map[string]struct{})
+	collect := func(entries []fs.DirEntry) {
+		for _, entry := range entries {
+			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".yaml" {
+				seen[strings.TrimSuffix(entry.Name(), ".yaml")] = struct{}{}
+			}
+		}
+	}
+	if entries, err := embeddedProfiles.ReadDir("profiles"); err == nil {
+		collect(entries)
+	}
+	if dir != "" {
+		if entries, err := os.ReadDir(dir); err == nil {
+			collect(entries)
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyTo fills every zero-valued field of config from the profile, so fields set
+// explicitly on the config take precedence.
+func (p *Profile) applyTo(config *Config) {
+	if config.TopScenariosCount == 0 {
+		config.TopScenariosCount = p.TopScenariosCount
+	}
+	if config.BottomScenariosCount == 0 {
+		config.BottomScenariosCount = p.BottomScenariosCount
+	}
+	if config.ReportFormat == "" {
+		config.ReportFormat = p.ReportFormat
+	}
+	if config.CoolDown == 0 {
+		config.CoolDown = p.CoolDown
+	}
+	if config.CollectTimeout == 0 {
+		config.CollectTimeout = p.CollectTimeout
+	}
+	if len(config.SummaryFormats) == 0 {
+		config.SummaryFormats = p.SummaryFormats
+	}
+	if config.SummaryScenarioGrouping == "" {
+		config.SummaryScenarioGrouping = p.SummaryScenarioGrouping
+	}
+	if len(config.Assertions) == 0 {
+		config.Assertions = p.Assertions
+	}
+	if !config.FailOnAssertions {
+		config.FailOnAssertions = p.FailOnAssertions
+	}
+	if config.TimeZone == "" {
+		config.TimeZone = p.TimeZone
+	}
+	if !config.AnalyzeLatestGenerationOnly {
+		config.AnalyzeLatestGenerationOnly = p.AnalyzeLatestGenerationOnly
+	}
+	if config.DuplicateScenarios == "" {
+		config.DuplicateScenarios = p.DuplicateScenarios
+	}
+	if !config.DeduplicateFailures {
+		config.DeduplicateFailures = p.DeduplicateFailures
+	}
+	if len(config.ScenarioNormalization) == 0 {
+		config.ScenarioNormalization = p.ScenarioNormalization
+	}
+	if config.NotificationConfig == nil {
+		config.NotificationConfig = p.NotificationConfig
+	}
+	if len(config.Redactors) == 0 {
+		config.Redactors = p.Redactors
+	}
+	if config.Retention == nil {
+		config.Retention = p.Retention
+	}
+	if !config.RunDirectories {
+		config.RunDirectories = p.RunDirectories
+	}
+	if config.RetainRuns == 0 {
+		config.RetainRuns = p.RetainRuns
+	}
+}
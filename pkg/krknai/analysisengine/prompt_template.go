@@ -0,0 +1,97 @@
+package analysisengine
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/openshift/osde2e/internal/prompts"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+)
+
+// newPromptStore loads the default and embedded krkn-ai templates, then the override at
+// templatePath when set, and checks that the krkn-ai template renders.
+func newPromptStore(templatePath string) (*prompts.PromptStore, error) {
+	promptStore, err := prompts.NewPromptStore(prompts.DefaultTemplates())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize prompt store: %w", err)
+	}
+
+	localFS, err := fs.Sub(krknPrompts, "prompts")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load krkn-ai prompt templates: %w", err)
+	}
+	if err := promptStore.RegisterTemplates(localFS); err != nil {
+		return nil, fmt.Errorf("failed to register krkn-ai prompt templates: %w", err)
+	}
+
+	if templatePath != "" {
+		overrideFS, err := promptOverrideFS(templatePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := promptStore.RegisterTemplates(overrideFS); err != nil {
+			return nil, fmt.Errorf("failed to load prompt template %s: %w", templatePath, err)
+		}
+	}
+
+	// Render against empty results so a broken template fails here rather than mid-run
+	if _, _, err := promptStore.RenderPrompt(krknAIPromptTemplate, promptVariables(&krknAggregator.KrknAIData{})); err != nil {
+		return nil, fmt.Errorf("invalid %s prompt template: %w", krknAIPromptTemplate, err)
+	}
+	return promptStore, nil
+}
+
+// promptOverrideFS exposes templatePath to the prompt store. A directory must contain
+// krknai.yaml and may hold other templates; a single file is used as the krkn-ai
+// template whatever its name.
+func promptOverrideFS(templatePath string) (fs.FS, error) {
+	info, err := os.Stat(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	templateFile := krknAIPromptTemplate + ".yaml"
+	if info.IsDir() {
+		if _, err := os.Stat(filepath.Join(templatePath, templateFile)); err != nil {
+			return nil, fmt.Errorf("prompt template directory %s has no %s: %w", templatePath, templateFile, err)
+		}
+		return os.DirFS(templatePath), nil
+	}
+
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+	return fstest.MapFS{templateFile: &fstest.MapFile{Data: data}}, nil
+}
+
+// promptVariables returns the template variables available to the krkn-ai prompt.
+func promptVariables(data *krknAggregator.KrknAIData) map[string]any {
+	vars := map[string]any{
+		"Summary":           data.Summary,
+		"TopScenarios":      data.TopScenarios,
+		"FailedScenarios":   data.FailedScenarios,
+		"HealthCheckReport": data.HealthCheckReport,
+		"LogArtifacts":      data.LogArtifacts,
+		"ConfigSummary":     data.ConfigSummary,
+	}
+	if len(data.BottomScenarios) > 0 {
+		vars["BottomScenarios"] = data.BottomScenarios
+	}
+	if data.BestScenario != nil {
+		vars["BestScenario"] = data.BestScenario
+	}
+	if data.ClusterInfo != nil {
+		vars["ClusterInfo"] = data.ClusterInfo
+	}
+	if data.ScopedGeneration != nil {
+		vars["ScopedGeneration"] = *data.ScopedGeneration
+	}
+	if len(data.ScenarioIDFilter) > 0 {
+		vars["ScenarioIDFilter"] = data.ScenarioIDFilter
+	}
+	return vars
+}
@@ -0,0 +1,132 @@
+package analysisengine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultRedactionReplacement = "[REDACTED]"
+
+// builtinRedactors are named patterns for common OpenShift identifiers.
+var builtinRedactors = map[string]Redactor{
+	// OCM internal cluster IDs: 32 lowercase base32 characters
+	"openshift-cluster-id": {
+		Pattern:     `\b[0-9a-v]{32}\b`,
+		Replacement: "[CLUSTER-ID-REDACTED]",
+	},
+	// External cluster IDs (clusterversion spec.clusterID) are UUIDs
+	"openshift-cluster-uuid": {
+		Pattern:     `(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`,
+		Replacement: "[CLUSTER-UUID-REDACTED]",
+	},
+	// AWS private DNS node names and installer-generated <infra-id>-<role>-... names
+	"openshift-node-name": {
+		Pattern:     `\bip-\d{1,3}-\d{1,3}-\d{1,3}-\d{1,3}(?:\.[a-z0-9-]+)*\.(?:compute|ec2)\.internal\b|\b[a-z0-9][a-z0-9-]*-[a-z0-9]{5}-(?:master|worker|infra)(?:-[a-z0-9-]+)?\b`,
+		Replacement: "[NODE-NAME-REDACTED]",
+	},
+}
+
+// Redactor scrubs matches of a pattern from everything the engine emits. Set Name to
+// use a built-in pattern, or Pattern for a custom regular expression.
+type Redactor struct {
+	Name        string `yaml:"name"`
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"` // Default: "[REDACTED]" or the built-in's replacement
+}
+
+type compiledRedactor struct {
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// redactor applies compiled redaction patterns in order.
+type redactor []compiledRedactor
+
+// newRedactor resolves named patterns and compiles the redactors.
+func newRedactor(redactors []Redactor) (redactor, error) {
+	compiled := make(redactor, 0, len(redactors))
+	for i, r := range redactors {
+		if r.Name != "" {
+			builtin, ok := builtinRedactors[r.Name]
+			if !ok {
+				return nil, fmt.Errorf("unknown redaction pattern %q (available: %s)", r.Name, strings.Join(builtinRedactorNames(), ", "))
+			}
+			if r.Pattern != "" {
+				return nil, fmt.Errorf("redactors[%d]: set either name or pattern, not both", i)
+			}
+			if r.Replacement == "" {
+				r.Replacement = builtin.Replacement
+			}
+			r.Pattern = builtin.Pattern
+		}
+		if r.Pattern == "" {
+			return nil, fmt.Errorf("redactors[%d]: a name or pattern is required", i)
+		}
+		regex, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redactors[%d]: invalid pattern: %w", i, err)
+		}
+		if r.Replacement == "" {
+			r.Replacement = defaultRedactionReplacement
+		}
+		compiled = append(compiled, compiledRedactor{regex: regex, replacement: r.Replacement})
+	}
+	return compiled, nil
+}
+
+func builtinRedactorNames() []string {
+	names := make([]string, 0, len(builtinRedactors))
+	for name := range builtinRedactors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// redact replaces every match in s. Replacements are literal.
+func (r redactor) redact(s string) string {
+	for _, c := range r {
+		s = c.regex.ReplaceAllLiteralString(s, c.replacement)
+	}
+	return s
+}
+
+// redactMetadata returns a copy of metadata with string values redacted.
+func (r redactor) redactMetadata(metadata map[string]any) map[string]any {
+	redacted := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		if s, ok := value.(string); ok {
+			value = r.redact(s)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// marshalYAML marshals v with every string scalar redacted, so redaction can't break
+// the YAML structure or miss nested values.
+func (r redactor) marshalYAML(v any) ([]byte, error) {
+	if len(r) == 0 {
+		return yaml.Marshal(v)
+	}
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	r.redactNode(&node)
+	return yaml.Marshal(&node)
+}
+
+func (r redactor) redactNode(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		node.Value = r.redact(node.Value)
+		return
+	}
+	for _, child := range node.Content {
+		r.redactNode(child)
+	}
+}
@@ -0,0 +1,100 @@
+package analysisengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/osde2e/internal/llm"
+	"github.com/openshift/osde2e/internal/llm/tools"
+)
+
+// analyze calls the LLM, or returns the cached response for an identical prompt and LLM
+// config when CacheEnabled is set. A cached response already holds the final content, so
+// its tool calls are not replayed and it is streamed as a single chunk; its token usage is
+// zero since no call was made.
+func (e *Engine) analyze(ctx context.Context, userPrompt string, llmConfig *llm.AnalysisConfig, toolRegistry *tools.Registry, metadata map[string]any, onChunk func(string)) (*llm.AnalysisResult, error) {
+	if !e.config.CacheEnabled || e.config.CannedResponse != "" {
+		return e.callLLM(ctx, userPrompt, llmConfig, toolRegistry, onChunk)
+	}
+
+	log := logr.FromContextOrDiscard(ctx)
+	path, err := e.responseCachePath(userPrompt, llmConfig)
+	if err != nil {
+		log.Error(err, "failed to locate LLM response cache; calling the provider")
+		return e.callLLM(ctx, userPrompt, llmConfig, toolRegistry, onChunk)
+	}
+
+	if cached, err := readCachedResponse(path); err == nil {
+		metadata["cache_hit"] = true
+		if onChunk != nil {
+			onChunk(cached.Content)
+		}
+		return cached, nil
+	} else if !os.IsNotExist(err) {
+		log.Error(err, "ignoring unreadable LLM response cache entry", "path", path)
+	}
+	metadata["cache_hit"] = false
+
+	result, err := e.callLLM(ctx, userPrompt, llmConfig, toolRegistry, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCachedResponse(path, result); err != nil {
+		log.Error(err, "failed to cache LLM response", "path", path)
+	}
+	return result, nil
+}
+
+// responseCachePath returns the cache file for the prompt: a SHA-256 of the provider,
+// rendered prompt, and resolved LLM config, under CacheDir.
+func (e *Engine) responseCachePath(userPrompt string, llmConfig *llm.AnalysisConfig) (string, error) {
+	dir := e.config.CacheDir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(userCacheDir, "osde2e", "krknai-llm")
+	}
+
+	key, err := json.Marshal(struct {
+		Provider string              `json:"provider"`
+		Prompt   string              `json:"prompt"`
+		Config   *llm.AnalysisConfig `json:"config"`
+	}{e.config.Provider, userPrompt, llmConfig})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(key)
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+func readCachedResponse(path string) (*llm.AnalysisResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var result llm.AnalysisResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse cached response: %w", err)
+	}
+	result.Usage = llm.Usage{}
+	return &result, nil
+}
+
+func writeCachedResponse(path string, result *llm.AnalysisResult) error {
+	content, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
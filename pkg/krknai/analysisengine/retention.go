@@ -0,0 +1,28 @@
+package analysisengine
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionPolicy prunes the outputs of earlier analyses. In the flat layout each run
+// overwrites the previous run's outputs, so a policy turns on RunDirectories and prunes the
+// run directories. Zero values disable the corresponding rule; the current run's directory
+// is never removed.
+type RetentionPolicy struct {
+	// KeepLastSummaries is an alias of Config.RetainRuns: it keeps the N most recent runs'
+	// directories, including the current one. Setting both to different values is an error.
+	KeepLastSummaries int `yaml:"keep_last_summaries"`
+	// MaxAge removes the directories of runs started longer ago than this
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+func (p *RetentionPolicy) validate() error {
+	if p.KeepLastSummaries < 0 {
+		return fmt.Errorf("retention keep_last_summaries must not be negative, got %d", p.KeepLastSummaries)
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("retention max_age must not be negative, got %s", p.MaxAge)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package analysisengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// latestRunLink is the symlink in the analysis directory pointing at the newest run
+// directory when RunDirectories is set.
+const latestRunLink = "latest"
+
+// runDirPattern matches the run directories the engine creates; pruning only ever
+// removes directories with these names.
+var runDirPattern = regexp.MustCompile(`^run-\d{8}T\d{6}\.\d{9}Z$`)
+
+// runDirTimeLayout is the timestamp in run directory names. Names sort chronologically.
+const runDirTimeLayout = "20060102T150405.000000000Z"
+
+// runDirName names the run directory for a run started at t.
+func runDirName(t time.Time) string {
+	return "run-" + t.UTC().Format(runDirTimeLayout)
+}
+
+// runDirTime returns when the run with directory name started.
+func runDirTime(name string) (time.Time, error) {
+	return time.Parse(runDirTimeLayout, strings.TrimPrefix(name, "run-"))
+}
+
+// beginRun resets the per-run output state for a run started at start: the artifact
+// list, the start time the summary is stamped with, and, with RunDirectories, a new run
+// directory named after that same time.
+func (e *Engine) beginRun(start time.Time) {
+	e.artifacts = nil
+	e.runStart = start
+	e.runDir = ""
+	if e.config.RunDirectories {
+		e.runDir = runDirName(start)
+	}
+}
+
+// outputDir returns the directory the current run's artifacts are written to: its run
+// directory with RunDirectories, otherwise the analysis directory itself.
+func (e *Engine) outputDir() string {
+	if e.runDir == "" {
+		return e.analysisDir()
+	}
+	return filepath.Join(e.analysisDir(), e.runDir)
+}
+
+// latestDir returns the directory holding the most recent run's outputs.
+func (e *Engine) latestDir() string {
+	if !e.config.RunDirectories {
+		return e.analysisDir()
+	}
+	return filepath.Join(e.analysisDir(), latestRunLink)
+}
+
+// linkLatestRun points the latest symlink at the current run directory. The link is
+// replaced with a rename so readers never see it missing.
+func (e *Engine) linkLatestRun() error {
+	if e.runDir == "" {
+		return nil
+	}
+	link := filepath.Join(e.analysisDir(), latestRunLink)
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(e.runDir, tmp); err != nil {
+		return fmt.Errorf("failed to link latest analysis run: %w", err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to link latest analysis run: %w", err)
+	}
+	return nil
+}
+
+// pruneRuns removes the run directories of earlier runs beyond RetainRuns, counting the
+// current run, along with those older than the retention policy's MaxAge, and returns their
+// names. Only directories matching runDirPattern are considered, so nothing the engine did
+// not create is removed.
+func (e *Engine) pruneRuns(ctx context.Context) ([]string, error) {
+	keep, maxAge := e.config.RetainRuns, time.Duration(0)
+	if policy := e.config.Retention; policy != nil {
+		maxAge = policy.MaxAge
+	}
+	if e.runDir == "" || (keep <= 0 && maxAge == 0) {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(e.analysisDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read analysis directory: %w", err)
+	}
+	var runs []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != e.runDir && runDirPattern.MatchString(entry.Name()) {
+			runs = append(runs, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(runs)))
+
+	logger := logr.FromContextOrDiscard(ctx)
+	var pruned []string
+	for i, name := range runs {
+		beyondLimit := keep > 0 && i >= keep-1
+		expired := false
+		if started, err := runDirTime(name); err == nil && maxAge > 0 {
+			expired = time.Since(started) > maxAge
+		}
+		if !beyondLimit && !expired {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(e.analysisDir(), name)); err != nil {
+			logger.Error(err, "failed to prune analysis run", "dir", name)
+			continue
+		}
+		pruned = append(pruned, name)
+	}
+	if len(pruned) > 0 {
+		logger.Info("pruned old analysis runs", "dirs", pruned)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,43 @@
+package analysisengine
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Overall severity levels the prompt asks the model to rate its findings with, recorded
+// as llm_severity. The failure-rate bands keep the severity key.
+const (
+	LLMSeverityNone     = "none"
+	LLMSeverityLow      = "low"
+	LLMSeverityMedium   = "medium"
+	LLMSeverityHigh     = "high"
+	LLMSeverityCritical = "critical"
+	LLMSeverityUnknown  = "unknown" // The response carried no recognizable rating
+)
+
+var llmSeverities = map[string]bool{
+	LLMSeverityNone:     true,
+	LLMSeverityLow:      true,
+	LLMSeverityMedium:   true,
+	LLMSeverityHigh:     true,
+	LLMSeverityCritical: true,
+}
+
+// llmSeverityPattern matches an "Overall severity: <level>" line, tolerating the markdown
+// emphasis, heading, and list markers models tend to wrap it in.
+var llmSeverityPattern = regexp.MustCompile(`(?im)^[\s>#*_-]*overall severity[\s*_]*:[\s*_` + "`" + `]*([a-z]+)`)
+
+// parseLLMSeverity returns the last overall severity rating in the response, or
+// LLMSeverityUnknown when there is none or it is not one of the known levels.
+func parseLLMSeverity(content string) string {
+	matches := llmSeverityPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return LLMSeverityUnknown
+	}
+	level := strings.ToLower(matches[len(matches)-1][1])
+	if !llmSeverities[level] {
+		return LLMSeverityUnknown
+	}
+	return level
+}
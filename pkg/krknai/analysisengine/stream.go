@@ -0,0 +1,61 @@
+package analysisengine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/osde2e/internal/analysisengine"
+	"github.com/openshift/osde2e/internal/llm"
+	"github.com/openshift/osde2e/internal/llm/tools"
+)
+
+// RunStream is Run with the model's response passed to onChunk as it is generated, for
+// interactive use where a long analysis would otherwise look hung. The returned result and
+// written outputs are the same as Run's. Text from tool-use turns is streamed as well, so
+// the chunks can hold more than the final Content. Responses served from a cache, and
+// providers that cannot stream, arrive as one chunk.
+func (e *Engine) RunStream(ctx context.Context, onChunk func(string)) (*analysisengine.Result, error) {
+	if onChunk == nil {
+		return nil, fmt.Errorf("a chunk callback is required")
+	}
+	return e.run(ctx, "", onChunk)
+}
+
+// callLLM calls the provider, streaming its response to onChunk when it is set, and
+// reports the call's token usage and tool calls to the metrics recorder.
+func (e *Engine) callLLM(ctx context.Context, userPrompt string, llmConfig *llm.AnalysisConfig, toolRegistry *tools.Registry, onChunk func(string)) (*llm.AnalysisResult, error) {
+	result, err := e.callProvider(ctx, userPrompt, llmConfig, toolRegistry, onChunk)
+	if result != nil {
+		e.metrics().ObserveTokens(result.Usage.PromptTokens, result.Usage.CompletionTokens)
+		e.metrics().ObserveToolCalls(len(result.ToolCalls))
+	}
+	return result, err
+}
+
+// callProvider implements callLLM.
+func (e *Engine) callProvider(ctx context.Context, userPrompt string, llmConfig *llm.AnalysisConfig, toolRegistry *tools.Registry, onChunk func(string)) (*llm.AnalysisResult, error) {
+	if onChunk == nil {
+		return e.llmClient.Analyze(ctx, userPrompt, llmConfig, toolRegistry)
+	}
+
+	streaming, ok := e.llmClient.(llm.StreamingLLMClient)
+	if !ok {
+		result, err := e.llmClient.Analyze(ctx, userPrompt, llmConfig, toolRegistry)
+		if err == nil && result.Content != "" {
+			onChunk(result.Content)
+		}
+		return result, err
+	}
+
+	for chunk := range streaming.AnalyzeStream(ctx, userPrompt, llmConfig, toolRegistry) {
+		if chunk.Result != nil || chunk.Err != nil {
+			return chunk.Result, chunk.Err
+		}
+		onChunk(chunk.Text)
+	}
+	// The stream only closes without its final chunk once ctx is done
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("LLM stream ended without a result")
+}
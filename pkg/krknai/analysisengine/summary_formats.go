@@ -0,0 +1,131 @@
+package analysisengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openshift/osde2e/internal/analysisengine"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+	"gopkg.in/yaml.v3"
+)
+
+// Summary output formats for Config.SummaryFormats.
+const (
+	SummaryFormatYAML     = "yaml"
+	SummaryFormatJSON     = "json"
+	SummaryFormatMarkdown = "markdown"
+)
+
+const (
+	summaryJSONFileName     = "summary.json"
+	summaryMarkdownFileName = "summary.md"
+)
+
+// validateSummaryFormats rejects unknown or repeated summary formats.
+func validateSummaryFormats(formats []string) error {
+	seen := make(map[string]bool, len(formats))
+	for _, format := range formats {
+		switch format {
+		case SummaryFormatYAML, SummaryFormatJSON, SummaryFormatMarkdown:
+		default:
+			return fmt.Errorf("invalid summary format %q (expected %s, %s, or %s)", format, SummaryFormatYAML, SummaryFormatJSON, SummaryFormatMarkdown)
+		}
+		if seen[format] {
+			return fmt.Errorf("summary format %q listed more than once", format)
+		}
+		seen[format] = true
+	}
+	return nil
+}
+
+// summaryFormats returns the configured summary formats, defaulting to YAML only.
+func (e *Engine) summaryFormats() []string {
+	if len(e.config.SummaryFormats) == 0 {
+		return []string{SummaryFormatYAML}
+	}
+	return e.config.SummaryFormats
+}
+
+// summaryJSON converts the serialized YAML summary to indented JSON, so both carry the
+// same redacted values.
+func summaryJSON(yamlData []byte) ([]byte, error) {
+	var summary map[string]any
+	if err := yaml.Unmarshal(yamlData, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse summary: %w", err)
+	}
+	content, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal summary to JSON: %w", err)
+	}
+	return append(content, '\n'), nil
+}
+
+// summaryMarkdown renders a human-readable report: the run summary table, the top
+// scenarios (grouped per SummaryScenarioGrouping), and the LLM analysis.
+func (e *Engine) summaryMarkdown(result *analysisengine.Result, data *krknAggregator.KrknAIData) []byte {
+	var b strings.Builder
+	s := data.Summary
+
+	b.WriteString("# Krkn-AI Chaos Analysis\n\n")
+	fmt.Fprintf(&b, "- **Status:** %s\n", result.Status)
+	fmt.Fprintf(&b, "- **Generated:** %s\n", e.runTime().Format("2006-01-02 15:04:05 MST"))
+	if data.ClusterInfo != nil && data.ClusterInfo.ID != "" {
+		fmt.Fprintf(&b, "- **Cluster:** %s\n", data.ClusterInfo.ID)
+	}
+
+	b.WriteString("\n## Run Summary\n\n")
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Total scenarios | %d |\n", s.TotalScenarioCount)
+	fmt.Fprintf(&b, "| Successful scenarios | %d |\n", s.SuccessfulScenarioCount)
+	fmt.Fprintf(&b, "| Failed scenarios | %d |\n", s.FailedScenarioCount)
+	fmt.Fprintf(&b, "| Generations | %d |\n", s.Generations)
+	fmt.Fprintf(&b, "| Max fitness | %.2f |\n", s.MaxFitnessScore)
+	fmt.Fprintf(&b, "| Avg fitness | %.2f |\n", s.AvgFitnessScore)
+
+	if groups := groupScenarios(e.config.SummaryScenarioGrouping, data); groups != nil {
+		// Grouped like the written summary's scenario_groups, one section per group
+		fmt.Fprintf(&b, "\n## Scenarios by %s\n", e.config.SummaryScenarioGrouping)
+		for _, group := range groups {
+			fmt.Fprintf(&b, "\n### %s\n\n", markdownCell(group.Name))
+			writeScenarioTable(&b, group.Scenarios)
+		}
+	} else {
+		b.WriteString("\n## Top Scenarios\n\n")
+		if len(data.TopScenarios) == 0 {
+			b.WriteString("No scenarios recorded.\n")
+		} else {
+			writeScenarioTable(&b, data.TopScenarios)
+		}
+
+		if len(data.BottomScenarios) > 0 {
+			b.WriteString("\n## Bottom Scenarios\n\n")
+			writeScenarioTable(&b, data.BottomScenarios)
+		}
+	}
+
+	b.WriteString("\n## Analysis\n\n")
+	if result.Content != "" {
+		b.WriteString(result.Content)
+		b.WriteString("\n")
+	} else if result.Error != "" {
+		fmt.Fprintf(&b, "Analysis failed: %s\n", result.Error)
+	}
+
+	return []byte(e.redactor.redact(b.String()))
+}
+
+// writeScenarioTable writes scenarios as a Markdown table, numbered in the given order.
+func writeScenarioTable(b *strings.Builder, scenarios []krknAggregator.ScenarioResult) {
+	b.WriteString("| # | Scenario | Generation | Fitness | Parameters |\n|---|---|---|---|---|\n")
+	for i, scenario := range scenarios {
+		fmt.Fprintf(b, "| %d | %s | %d | %.2f | %s |\n", i+1, markdownCell(scenario.Scenario),
+			scenario.GenerationID, scenario.FitnessScore, markdownCell(scenario.Parameters))
+	}
+}
+
+// markdownCell escapes a value for use inside a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
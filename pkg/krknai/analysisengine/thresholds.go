@@ -0,0 +1,191 @@
+package analysisengine
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/openshift/osde2e/internal/analysisengine"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity levels derived from the failure-rate bands.
+const (
+	SeverityOK       = "ok"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Thresholds centralizes the alerting policy applied to a run. Unset (nil) fields are
+// ignored, which lets inline config override individual values from a thresholds file.
+type Thresholds struct {
+	SeverityBands *SeverityBands `yaml:"severity_bands"`
+	// NotifyMinFitness suppresses notifications when the max fitness score is below it
+	NotifyMinFitness *float64  `yaml:"notify_min_fitness"`
+	Baseline         *Baseline `yaml:"baseline"`
+}
+
+// SeverityBands are the failure rates (0-1) at which a run becomes a warning or critical.
+type SeverityBands struct {
+	Warning  *float64 `yaml:"warning"`
+	Critical *float64 `yaml:"critical"`
+}
+
+// Baseline holds expected values that the run is compared against.
+type Baseline struct {
+	FailureRate     *float64 `yaml:"failure_rate"`
+	MaxFitnessScore *float64 `yaml:"max_fitness_score"`
+}
+
+// loadThresholds reads and validates a thresholds file.
+func loadThresholds(path string) (*Thresholds, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read thresholds file: %w", err)
+	}
+
+	var thresholds Thresholds
+	if err := yaml.Unmarshal(content, &thresholds); err != nil {
+		return nil, fmt.Errorf("failed to parse thresholds file %s: %w", path, err)
+	}
+	if err := thresholds.validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds file %s: %w", path, err)
+	}
+	return &thresholds, nil
+}
+
+// resolveThresholds merges inline thresholds over those loaded from file.
+func resolveThresholds(file string, inline *Thresholds) (*Thresholds, error) {
+	resolved := &Thresholds{}
+	if file != "" {
+		loaded, err := loadThresholds(file)
+		if err != nil {
+			return nil, err
+		}
+		resolved = loaded
+	}
+	if inline != nil {
+		resolved = resolved.mergedWith(inline)
+		if err := resolved.validate(); err != nil {
+			return nil, fmt.Errorf("invalid thresholds: %w", err)
+		}
+	}
+	return resolved, nil
+}
+
+// mergedWith returns a copy of t with every field set in override replacing t's value.
+func (t *Thresholds) mergedWith(override *Thresholds) *Thresholds {
+	merged := &Thresholds{NotifyMinFitness: t.NotifyMinFitness}
+	if override.NotifyMinFitness != nil {
+		merged.NotifyMinFitness = override.NotifyMinFitness
+	}
+
+	if t.SeverityBands != nil || override.SeverityBands != nil {
+		bands := SeverityBands{}
+		if t.SeverityBands != nil {
+			bands = *t.SeverityBands
+		}
+		if o := override.SeverityBands; o != nil {
+			if o.Warning != nil {
+				bands.Warning = o.Warning
+			}
+			if o.Critical != nil {
+				bands.Critical = o.Critical
+			}
+		}
+		merged.SeverityBands = &bands
+	}
+
+	if t.Baseline != nil || override.Baseline != nil {
+		baseline := Baseline{}
+		if t.Baseline != nil {
+			baseline = *t.Baseline
+		}
+		if o := override.Baseline; o != nil {
+			if o.FailureRate != nil {
+				baseline.FailureRate = o.FailureRate
+			}
+			if o.MaxFitnessScore != nil {
+				baseline.MaxFitnessScore = o.MaxFitnessScore
+			}
+		}
+		merged.Baseline = &baseline
+	}
+
+	return merged
+}
+
+// validate checks that rates fall within 0-1, bands are ordered, and scores are finite.
+func (t *Thresholds) validate() error {
+	if b := t.SeverityBands; b != nil {
+		if err := validateRate("severity_bands.warning", b.Warning); err != nil {
+			return err
+		}
+		if err := validateRate("severity_bands.critical", b.Critical); err != nil {
+			return err
+		}
+		if b.Warning != nil && b.Critical != nil && *b.Warning > *b.Critical {
+			return fmt.Errorf("severity_bands.warning (%g) must not exceed severity_bands.critical (%g)", *b.Warning, *b.Critical)
+		}
+	}
+	if err := validateScore("notify_min_fitness", t.NotifyMinFitness); err != nil {
+		return err
+	}
+	if b := t.Baseline; b != nil {
+		if err := validateRate("baseline.failure_rate", b.FailureRate); err != nil {
+			return err
+		}
+		if err := validateScore("baseline.max_fitness_score", b.MaxFitnessScore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRate(name string, value *float64) error {
+	if value != nil && (math.IsNaN(*value) || *value < 0 || *value > 1) {
+		return fmt.Errorf("%s must be between 0 and 1, got %g", name, *value)
+	}
+	return nil
+}
+
+func validateScore(name string, value *float64) error {
+	if value != nil && (math.IsNaN(*value) || math.IsInf(*value, 0)) {
+		return fmt.Errorf("%s must be a finite number, got %g", name, *value)
+	}
+	return nil
+}
+
+// applyThresholds records severity, baseline deltas, and notification suppression in
+// the result metadata.
+func applyThresholds(thresholds *Thresholds, result *analysisengine.Result, summary krknAggregator.KrknAISummary) {
+	if thresholds == nil {
+		return
+	}
+	failureRate := rate(summary.FailedScenarioCount, summary.TotalScenarioCount)
+
+	if b := thresholds.SeverityBands; b != nil {
+		severity := SeverityOK
+		switch {
+		case b.Critical != nil && failureRate >= *b.Critical:
+			severity = SeverityCritical
+		case b.Warning != nil && failureRate >= *b.Warning:
+			severity = SeverityWarning
+		}
+		result.Metadata["severity"] = severity
+	}
+
+	if b := thresholds.Baseline; b != nil {
+		if b.FailureRate != nil {
+			result.Metadata["failure_rate_delta"] = failureRate - *b.FailureRate
+		}
+		if b.MaxFitnessScore != nil {
+			result.Metadata["max_fitness_delta"] = summary.MaxFitnessScore - *b.MaxFitnessScore
+		}
+	}
+
+	if thresholds.NotifyMinFitness != nil && summary.MaxFitnessScore < *thresholds.NotifyMinFitness {
+		result.Metadata["notification_suppressed"] = true
+	}
+}
@@ -0,0 +1,91 @@
+package krknai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const appliedParamsFileName = "krkn-ai-applied-params.json"
+
+// Sources recorded for applied changes, one per updater stage.
+const (
+	sourceOverlay   = "overlay"   // The KRKN_OVERLAY_PATH file
+	sourceOverride  = "override"  // KRKN_OVERRIDES
+	sourceParameter = "parameter" // A dedicated krkn-ai parameter, e.g. KRKN_GENERATIONS
+	sourceFilter    = "filter"    // The cluster_components namespace and node filters and the scope
+	sourceRule      = "rule"      // The scenario rules for the discovered cluster topology
+)
+
+// changeTracker attributes the updater's changes to the stage that made them. Each call
+// to record compares the config with the previous snapshot; a field changed by several
+// stages is attributed to the last one.
+type changeTracker struct {
+	last    map[string]interface{}
+	sources map[string]string // Dotted key path to source
+}
+
+func newChangeTracker(cfg map[string]interface{}) *changeTracker {
+	return &changeTracker{last: copyConfigValue(cfg).(map[string]interface{}), sources: map[string]string{}}
+}
+
+// record attributes every field changed since the last snapshot to source.
+func (t *changeTracker) record(cfg map[string]interface{}, source string) {
+	for _, change := range diffConfigs(t.last, cfg) {
+		t.sources[change.Path] = source
+	}
+	t.last = copyConfigValue(cfg).(map[string]interface{})
+}
+
+// attribute sets the Source of each change. A stage that replaced a whole section is
+// matched through the section's path.
+func (t *changeTracker) attribute(changes []fieldChange) []fieldChange {
+	for i := range changes {
+		if source, ok := t.sources[changes[i].Path]; ok {
+			changes[i].Source = source
+			continue
+		}
+		for path, source := range t.sources {
+			if strings.HasPrefix(changes[i].Path, path+".") || strings.HasPrefix(path, changes[i].Path+".") {
+				changes[i].Source = source
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// copyConfigValue deep-copies the maps and lists of a parsed config.
+func copyConfigValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = copyConfigValue(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = copyConfigValue(value)
+		}
+		return out
+	}
+	return v
+}
+
+// writeAppliedParams writes the applied changes as JSON for CI dashboards.
+func writeAppliedParams(path string, changes []fieldChange) error {
+	if changes == nil {
+		changes = []fieldChange{}
+	}
+	content, err := json.MarshalIndent(map[string]any{"changes": changes}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal applied parameters: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write applied parameters: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package krknai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+// Execution backends, selected with KRKN_BACKEND.
+const (
+	backendContainer = "container"
+	backendLocal     = "local"
+	backendJob       = "job"
+)
+
+// invocationPaths are the shared directory, holding the kubeconfig and configs, and the
+// directory krkn-ai writes its output to.
+type invocationPaths struct {
+	shared  string
+	results string
+}
+
+// containerPaths is where the container and job backends mount the two directories.
+var containerPaths = invocationPaths{shared: containerMountPath, results: containerResultsPath}
+
+// hostPaths returns the shared and results directories on this machine.
+func (k *KrknAI) hostPaths() invocationPaths {
+	return invocationPaths{shared: k.sharedDir(), results: k.generationsDir()}
+}
+
+// invocation is one krkn-ai or krkn run, independent of the backend that runs it. Paths
+// in args and env are as the backend's paths returned them.
+type invocation struct {
+	mode       string
+	image      string   // The krkn-ai or krkn image
+	args       []string // Arguments to the image's entrypoint, or to the local binary
+	env        []string // NAME=value
+	privileged bool
+	checkpoint string // The checkpoint to resume from, relative to the results directory
+}
+
+// backend runs an invocation to completion, writing what it logs to stdout and stderr.
+// Cancelling ctx stops the run with SIGTERM so krkn-ai can write out its results.
+type backend interface {
+	name() string
+	// paths returns where the invocation sees host, the directories on this machine.
+	paths(host invocationPaths) invocationPaths
+	run(ctx context.Context, inv invocation, host invocationPaths, stdout, stderr io.Writer) error
+}
+
+// backendFromConfig returns the backend set by KRKN_BACKEND, defaulting to the container runtime.
+func backendFromConfig() (backend, error) {
+	switch name := strings.ToLower(strings.TrimSpace(viper.GetString(config.KrknAI.Backend))); name {
+	case "", backendContainer:
+		return containerBackend{}, nil
+	case backendLocal:
+		return localBackend{
+			binary:     strings.TrimSpace(viper.GetString(config.KrknAI.LocalBinary)),
+			krknBinary: strings.TrimSpace(viper.GetString(config.KrknAI.LocalKrknBinary)),
+		}, nil
+	case backendJob:
+		return jobBackend{kubeconfig: strings.TrimSpace(viper.GetString(config.KrknAI.BackendKubeconfig))}, nil
+	default:
+		return nil, fmt.Errorf("invalid backend %q (expected %s, %s or %s)", name, backendContainer, backendLocal, backendJob)
+	}
+}
+
+// runCommand runs cmd, sending it SIGTERM when its context is cancelled and killing it if
+// it has not exited within the grace period.
+func runCommand(cmd *exec.Cmd, stdout, stderr io.Writer) error {
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = containerStopGracePeriod
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// containerBackend runs the image with podman or docker on this machine.
+type containerBackend struct{}
+
+func (containerBackend) name() string { return backendContainer }
+
+func (containerBackend) paths(invocationPaths) invocationPaths { return containerPaths }
+
+func (containerBackend) run(ctx context.Context, inv invocation, host invocationPaths, stdout, stderr io.Writer) error {
+	runtime, err := detectContainerRuntime()
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"run", "--rm", "--net=host",
+		"-v", fmt.Sprintf("%s:%s:Z", host.shared, containerMountPath),
+		"-v", fmt.Sprintf("%s:%s:Z", host.results, containerResultsPath),
+	}
+	if inv.privileged {
+		args = append(args, "--privileged")
+	}
+	for _, env := range inv.env {
+		args = append(args, "-e", env)
+	}
+	args = append(args, inv.image)
+	args = append(args, inv.args...)
+
+	log.Printf("Executing command: %s %v", runtime, args)
+	// On cancellation the runtime forwards SIGTERM to the container
+	return runCommand(exec.CommandContext(ctx, runtime, args...), stdout, stderr)
+}
+
+// localBackend runs a krkn-ai or krkn installation on this machine, for hosts without a
+// container runtime. The binary gets the image entrypoint's arguments and environment and
+// runs with the caller's privileges.
+type localBackend struct {
+	binary     string
+	krknBinary string // Run in krkn mode
+}
+
+func (localBackend) name() string { return backendLocal }
+
+func (localBackend) paths(host invocationPaths) invocationPaths { return host }
+
+func (b localBackend) run(ctx context.Context, inv invocation, host invocationPaths, stdout, stderr io.Writer) error {
+	binary, setting := b.binary, "KRKN_LOCAL_BINARY"
+	if inv.mode == config.KrknAIModeKrkn {
+		binary, setting = b.krknBinary, "KRKN_LOCAL_KRKN_BINARY"
+	}
+	binaryPath, err := exec.LookPath(binary)
+	if err != nil {
+		return fmt.Errorf("%s binary %q not found: install it or set %s", inv.mode, binary, setting)
+	}
+
+	log.Printf("Executing command: %s %v", binaryPath, inv.args)
+	cmd := exec.CommandContext(ctx, binaryPath, inv.args...)
+	cmd.Dir = host.shared
+	cmd.Env = append(os.Environ(), inv.env...)
+	return runCommand(cmd, stdout, stderr)
+}
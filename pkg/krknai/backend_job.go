@@ -0,0 +1,465 @@
+package krknai
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/openshift/osde2e/pkg/common/util"
+)
+
+const (
+	// Containers of the job pod: krkn-ai itself, and a container that keeps the pod's
+	// volumes around after krkn-ai exits so its results can be copied out
+	jobKrknContainer      = "krkn"
+	jobArtifactsContainer = "pause-for-artifacts"
+	jobArtifactsImage     = "busybox:latest"
+
+	// jobInputPath is where the job pod mounts the shared directory's files before they are
+	// copied to the writable shared directory
+	jobInputPath = "/input"
+
+	// jobPollInterval is how often the job pod is checked while krkn-ai runs.
+	jobPollInterval = 5 * time.Second
+
+	// jobStartTimeout bounds the wait for the krkn container to start: admitting and
+	// scheduling the pod, copying the inputs, and pulling the image.
+	jobStartTimeout = 10 * time.Minute
+
+	// jobCleanupTimeout bounds copying out the results and deleting the job namespace,
+	// which happen even when the run was cancelled.
+	jobCleanupTimeout = 5 * time.Minute
+)
+
+// jobBackend runs the image as a Kubernetes Job, for environments where neither the image
+// nor a container runtime can run locally. Each run gets its own namespace with a
+// cluster-admin service account, deleted afterwards. The shared directory's files are
+// copied into the pod at the start and the results copied out at the end, so a kubeconfig
+// rotated during the run doesn't reach the pod, and a resumed run can't use this backend.
+type jobBackend struct {
+	kubeconfig string // The cluster to run the Job in; empty runs it in the target cluster
+}
+
+func (jobBackend) name() string { return backendJob }
+
+func (jobBackend) paths(invocationPaths) invocationPaths { return containerPaths }
+
+func (b jobBackend) run(ctx context.Context, inv invocation, host invocationPaths, stdout, _ io.Writer) error {
+	if inv.checkpoint != "" {
+		return errors.New("the job backend can't resume a run: use the container or local backend")
+	}
+	kubeconfigPath := b.kubeconfig
+	if kubeconfigPath == "" {
+		kubeconfigPath = filepath.Join(host.shared, kubeconfigFileName)
+	}
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load the job backend kubeconfig: %w", err)
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kube client: %w", err)
+	}
+	inputs, err := readJobInputs(host.shared)
+	if err != nil {
+		return err
+	}
+
+	// Copying out the results and cleaning up outlive a cancelled run
+	cleanupCtx := context.WithoutCancel(ctx)
+	namespace, err := createJobNamespace(ctx, kube)
+	if namespace != "" {
+		defer func() {
+			ctx, cancel := context.WithTimeout(cleanupCtx, jobCleanupTimeout)
+			defer cancel()
+			if err := kube.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{}); err != nil {
+				log.Printf("Warning - failed to delete krkn-ai job namespace %s: %v", namespace, err)
+			}
+		}()
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range jobSecrets(namespace, inputs, inv.env) {
+		if _, err := kube.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create secret %s: %w", secret.Name, err)
+		}
+	}
+	job, err := kube.BatchV1().Jobs(namespace).Create(ctx, krknJob(namespace, inv), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create krkn-ai job: %w", err)
+	}
+	log.Printf("Created krkn-ai job %s/%s", namespace, job.Name)
+
+	startCtx, cancelStart := context.WithTimeout(ctx, jobStartTimeout)
+	pod, err := waitForKrknContainer(startCtx, kube, namespace, job.Name, func(state corev1.ContainerState) bool {
+		return state.Running != nil || state.Terminated != nil
+	})
+	cancelStart()
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("krkn-ai job %s/%s did not start within %s: %w", namespace, job.Name, jobStartTimeout, err)
+		}
+		return err
+	}
+	logCtx, stopLogs := context.WithCancel(cleanupCtx)
+	defer stopLogs()
+	logsDone := make(chan error, 1)
+	go func() { logsDone <- streamKrknLogs(logCtx, kube, pod, stdout) }()
+
+	terminated := func(state corev1.ContainerState) bool { return state.Terminated != nil }
+	finished, waitErr := waitForKrknContainer(ctx, kube, namespace, job.Name, terminated)
+	if waitErr != nil && ctx.Err() != nil {
+		// Stop krkn-ai the way the container runtime would, giving it the same grace period
+		log.Printf("Stopping krkn-ai job %s/%s", namespace, job.Name)
+		if err := podExec(cleanupCtx, restConfig, kube, pod, jobKrknContainer, []string{"sh", "-c", "kill -TERM 1"}, io.Discard); err != nil {
+			log.Printf("Warning - failed to stop krkn-ai job: %v", err)
+		}
+		stopCtx, cancel := context.WithTimeout(cleanupCtx, containerStopGracePeriod)
+		finished, waitErr = waitForKrknContainer(stopCtx, kube, namespace, job.Name, terminated)
+		cancel()
+	}
+	if waitErr != nil {
+		stopLogs()
+	}
+	if err := <-logsDone; err != nil {
+		log.Printf("Warning - failed to stream krkn-ai job logs: %v", err)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(cleanupCtx, jobCleanupTimeout)
+	defer cancel()
+	if err := fetchJobResults(fetchCtx, restConfig, kube, pod, inv.mode, host); err != nil {
+		log.Printf("Warning - failed to copy the krkn-ai job results: %v", err)
+	}
+
+	switch {
+	case waitErr != nil:
+		return waitErr
+	case finished.exitCode != 0:
+		return fmt.Errorf("krkn-ai job container exited with code %d", finished.exitCode)
+	}
+	return ctx.Err()
+}
+
+// readJobInputs reads the files at the top of the shared directory.
+func readJobInputs(sharedDir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(sharedDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared directory: %w", err)
+	}
+	inputs := make(map[string][]byte)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(sharedDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shared directory: %w", err)
+		}
+		inputs[entry.Name()] = data
+	}
+	return inputs, nil
+}
+
+// createJobNamespace creates the namespace for one run, with a cluster-admin service
+// account whose binding is deleted along with the namespace. The namespace is returned
+// whenever it was created, so it can be deleted even when the rest failed.
+func createJobNamespace(ctx context.Context, kube kubernetes.Interface) (string, error) {
+	ns, err := kube.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "osde2e-krkn-ai-" + util.RandomStr(5)},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create krkn-ai job namespace: %w", err)
+	}
+	log.Printf("Created krkn-ai job namespace %s", ns.Name)
+
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: jobKrknContainer, Namespace: ns.Name}}
+	if _, err := kube.CoreV1().ServiceAccounts(ns.Name).Create(ctx, sa, metav1.CreateOptions{}); err != nil {
+		return ns.Name, fmt.Errorf("failed to create krkn-ai service account: %w", err)
+	}
+	crb := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    "osde2e-krkn-ai-cluster-admin-",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ns, corev1.SchemeGroupVersion.WithKind("Namespace"))},
+		},
+		Subjects: []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: sa.Name, Namespace: ns.Name}},
+		RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+	}
+	if _, err := kube.RbacV1().ClusterRoleBindings().Create(ctx, crb, metav1.CreateOptions{}); err != nil {
+		return ns.Name, fmt.Errorf("failed to create krkn-ai cluster role binding: %w", err)
+	}
+	return ns.Name, nil
+}
+
+// jobSecrets holds the shared directory's files and the invocation's environment, which
+// carries the Prometheus token.
+func jobSecrets(namespace string, inputs map[string][]byte, env []string) []*corev1.Secret {
+	envData := make(map[string][]byte, len(env))
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		envData[name] = []byte(value)
+	}
+	return []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "krkn-ai-input", Namespace: namespace}, Data: inputs},
+		{ObjectMeta: metav1.ObjectMeta{Name: "krkn-ai-env", Namespace: namespace}, Data: envData},
+	}
+}
+
+// krknJob is the Job running inv. An init container copies the input secret to the
+// writable shared directory, and the artifacts container keeps the results after krkn-ai
+// exits; both directories are mounted where the container backend mounts them.
+func krknJob(namespace string, inv invocation) *batchv1.Job {
+	mounts := []corev1.VolumeMount{
+		{Name: "shared", MountPath: containerMountPath},
+		{Name: "results", MountPath: containerResultsPath},
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "krkn-ai-" + inv.mode + "-", Namespace: namespace},
+		Spec: batchv1.JobSpec{
+			Parallelism:  ptr.To[int32](1),
+			Completions:  ptr.To[int32](1),
+			BackoffLimit: ptr.To[int32](0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					// Host networking, like the container backend's --net=host, needs the privileged SCC
+					Annotations: map[string]string{"openshift.io/required-scc": "privileged"},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: jobKrknContainer,
+					HostNetwork:        true,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{{
+						Name:         "inputs",
+						Image:        jobArtifactsImage,
+						Command:      []string{"sh", "-c", fmt.Sprintf("cp -L %s/* %s/", jobInputPath, containerMountPath)},
+						VolumeMounts: append([]corev1.VolumeMount{{Name: "input", MountPath: jobInputPath, ReadOnly: true}}, mounts...),
+					}},
+					Containers: []corev1.Container{
+						{
+							Name:            jobKrknContainer,
+							Image:           inv.image,
+							Args:            inv.args,
+							EnvFrom:         []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "krkn-ai-env"}}}},
+							SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(inv.privileged)},
+							VolumeMounts:    mounts,
+						},
+						{
+							Name:         jobArtifactsContainer,
+							Image:        jobArtifactsImage,
+							Command:      []string{"tail", "-f", "/dev/null"},
+							VolumeMounts: mounts,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{Name: "input", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "krkn-ai-input"}}},
+						{Name: "shared", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+						{Name: "results", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// jobPod is the job's pod as last seen, and the krkn container's exit code once it has terminated.
+type jobPod struct {
+	*corev1.Pod
+	exitCode int32
+}
+
+// jobStartFailures are the waiting reasons of a job pod container that won't start
+// without someone changing the image, its pull secret, or the pod's config.
+var jobStartFailures = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+}
+
+// isTransientAPIError reports whether a failed API request may succeed when repeated:
+// API server timeouts, throttling, and dropped connections. Errors such as forbidden or
+// not found are not.
+func isTransientAPIError(err error) bool {
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
+// waitForKrknContainer waits until the krkn container's state satisfies done. A failed
+// pod list that isn't transient, a container stuck on one of jobStartFailures, and a
+// failed init container end the wait. When ctx ends first, the error says what the pod
+// was last seen doing.
+func waitForKrknContainer(ctx context.Context, kube kubernetes.Interface, namespace, jobName string, done func(corev1.ContainerState) bool) (jobPod, error) {
+	var found jobPod
+	lastSeen := "no pod created"
+	err := wait.PollUntilContextCancel(ctx, jobPollInterval, true, func(ctx context.Context) (bool, error) {
+		pods, err := kube.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labels.FormatLabels(map[string]string{"job-name": jobName}),
+		})
+		if err != nil {
+			if ctx.Err() != nil || isTransientAPIError(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to list krkn-ai job pods: %w", err)
+		}
+		if len(pods.Items) == 0 {
+			return false, nil // The pod isn't created yet
+		}
+		pod := &pods.Items[0]
+		lastSeen = "pod " + string(pod.Status.Phase)
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+				return false, fmt.Errorf("krkn-ai job failed to copy its inputs: %s", status.State.Terminated.Message)
+			}
+			if waiting := status.State.Waiting; waiting != nil && jobStartFailures[waiting.Reason] {
+				return false, fmt.Errorf("krkn-ai job init container can't start (%s): %s", waiting.Reason, waiting.Message)
+			}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != jobKrknContainer {
+				continue
+			}
+			if waiting := status.State.Waiting; waiting != nil {
+				lastSeen = fmt.Sprintf("pod %s, krkn container waiting: %s", pod.Status.Phase, waiting.Reason)
+				switch {
+				case waiting.Reason == "ImagePullBackOff" || waiting.Reason == "ErrImagePull":
+					return false, fmt.Errorf("failed to pull image %s: %s", status.Image, waiting.Message)
+				case jobStartFailures[waiting.Reason]:
+					return false, fmt.Errorf("krkn-ai job container can't start (%s): %s", waiting.Reason, waiting.Message)
+				}
+			}
+			if !done(status.State) {
+				return false, nil
+			}
+			found = jobPod{Pod: pod}
+			if status.State.Terminated != nil {
+				found.exitCode = status.State.Terminated.ExitCode
+			}
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("%w (%s)", err, lastSeen)
+	}
+	return found, err
+}
+
+// streamKrknLogs copies the krkn container's logs to w until it exits.
+func streamKrknLogs(ctx context.Context, kube kubernetes.Interface, pod jobPod, w io.Writer) error {
+	stream, err := kube.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: jobKrknContainer, Follow: true}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	_, err = io.Copy(w, stream)
+	return err
+}
+
+// fetchJobResults copies what krkn-ai wrote from the artifacts container: the discovered
+// config in discover mode, the results directory otherwise.
+func fetchJobResults(ctx context.Context, restConfig *rest.Config, kube kubernetes.Interface, pod jobPod, mode string, host invocationPaths) error {
+	command, dir := []string{"tar", "cf", "-", "-C", containerResultsPath, "."}, host.results
+	if mode != config.KrknAIModeRun && mode != config.KrknAIModeKrkn {
+		command, dir = []string{"tar", "cf", "-", "-C", containerMountPath, krknConfigFileName}, host.shared
+	}
+	var archive bytes.Buffer
+	if err := podExec(ctx, restConfig, kube, pod, jobArtifactsContainer, command, &archive); err != nil {
+		return err
+	}
+	return extractTar(&archive, dir)
+}
+
+// podExec runs command in a container of pod, writing its output to stdout.
+func podExec(ctx context.Context, restConfig *rest.Config, kube kubernetes.Interface, pod jobPod, container string, command []string, stdout io.Writer) error {
+	request := kube.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Command:   command,
+			Container: container,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", request.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create pod executor: %w", err)
+	}
+	var stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", strings.Join(command, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// extractTar writes the directories and regular files in the tar stream r under dir,
+// refusing entries that would land outside it.
+func extractTar(r io.Reader, dir string) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read results archive: %w", err)
+		}
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("results archive entry %q is outside the results directory", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("failed to extract results: %w", err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("failed to extract results: %w", err)
+			}
+			f, err := os.Create(target)
+			if err != nil {
+				return fmt.Errorf("failed to extract results: %w", err)
+			}
+			_, err = io.Copy(f, reader)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to extract results: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package krknai
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestBackendFromConfig(t *testing.T) {
+	setupKrknConfig(t, nil)
+	b, err := backendFromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, backendContainer, b.name())
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.Backend: "Local", config.KrknAI.LocalBinary: "/opt/krkn-ai/bin/krkn-ai"})
+	b, err = backendFromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, localBackend{binary: "/opt/krkn-ai/bin/krkn-ai", krknBinary: "krkn"}, b)
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.Backend: "job", config.KrknAI.BackendKubeconfig: "/ci/kubeconfig"})
+	b, err = backendFromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, jobBackend{kubeconfig: "/ci/kubeconfig"}, b)
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.Backend: "ssh"})
+	_, err = backendFromConfig()
+	assert.EqualError(t, err, `invalid backend "ssh" (expected container, local or job)`)
+}
+
+func TestInvocation(t *testing.T) {
+	setupKrknConfig(t, map[string]any{config.KrknAI.KrknImage: "quay.io/krkn-chaos/krkn:latest", config.KrknAI.PodLabel: "app=web"})
+	k := &KrknAI{}
+
+	inv := k.invocation(context.Background(), config.KrknAIModeKrkn, containerPaths)
+	assert.Equal(t, "quay.io/krkn-chaos/krkn:latest", inv.image)
+	assert.True(t, inv.privileged)
+	assert.Equal(t, []string{"--config=/mount/" + krknScenarioConfigFileName, "--output=/krknresults/" + krknReportFileName}, inv.args)
+	assert.Contains(t, inv.env, "KUBECONFIG=/mount/kubeconfig")
+
+	host := invocationPaths{shared: "/work/shared", results: "/work/report/generations"}
+	inv = k.invocation(context.Background(), config.KrknAIModeDiscover, host)
+	assert.Equal(t, DefaultKrknAIImage, inv.image)
+	assert.False(t, inv.privileged)
+	assert.Empty(t, inv.args)
+	assert.Subset(t, inv.env, []string{"MODE=discover", "KUBECONFIG=/work/shared/kubeconfig", "OUTPUT_DIR=/work/shared", "POD_LABEL=app=web"})
+}
+
+func TestRunKrknLocalBackend(t *testing.T) {
+	// A fake krkn that writes its report where --output points and logs its mode
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"for arg in \"$@\"; do case \"$arg\" in --output=*) touch \"${arg#--output=}\";; esac; done\n" +
+		"echo \"MODE=$MODE in $(pwd)\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "krkn"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	reportDir := t.TempDir()
+	sharedDir := filepath.Dir(setupKrknConfig(t, map[string]any{
+		config.ReportDir:      reportDir,
+		config.KrknAI.Backend: backendLocal,
+	}))
+
+	k := &KrknAI{}
+	require.NoError(t, os.MkdirAll(k.generationsDir(), 0o755))
+	require.NoError(t, k.runKrkn(context.Background(), config.KrknAIModeKrkn))
+
+	assert.FileExists(t, filepath.Join(k.generationsDir(), krknReportFileName))
+	output, err := os.ReadFile(filepath.Join(reportDir, resultsLayout.Logs, "krkn.log"))
+	require.NoError(t, err)
+	resolvedShared, err := filepath.EvalSymlinks(sharedDir)
+	require.NoError(t, err)
+	assert.Equal(t, "MODE=krkn in "+resolvedShared+"\n", string(output))
+
+	setupKrknConfig(t, map[string]any{config.ReportDir: reportDir, config.KrknAI.Backend: backendLocal, config.KrknAI.LocalKrknBinary: "no-such-krkn"})
+	assert.ErrorContains(t, k.runKrkn(context.Background(), config.KrknAIModeKrkn), `krkn binary "no-such-krkn" not found: install it or set KRKN_LOCAL_KRKN_BINARY`)
+}
+
+func TestKrknJob(t *testing.T) {
+	inv := invocation{mode: config.KrknAIModeRun, image: DefaultKrknAIImage, env: []string{"MODE=run", "PROMETHEUS_TOKEN=abc=="}, privileged: true}
+	job := krknJob("osde2e-krkn-ai-x1y2z", inv)
+
+	assert.Equal(t, "krkn-ai-run-", job.GenerateName)
+	assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+	pod := job.Spec.Template.Spec
+	assert.True(t, pod.HostNetwork)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.RestartPolicy)
+	require.Len(t, pod.Containers, 2)
+	krkn := pod.Containers[0]
+	assert.Equal(t, jobKrknContainer, krkn.Name)
+	assert.True(t, *krkn.SecurityContext.Privileged)
+	assert.Empty(t, krkn.Env, "the environment comes from a secret")
+	assert.Equal(t, "krkn-ai-env", krkn.EnvFrom[0].SecretRef.Name)
+	assert.Equal(t, []string{containerMountPath, containerResultsPath}, []string{krkn.VolumeMounts[0].MountPath, krkn.VolumeMounts[1].MountPath})
+	assert.Equal(t, krkn.VolumeMounts, pod.Containers[1].VolumeMounts, "the artifacts container keeps both directories")
+
+	secrets := jobSecrets("osde2e-krkn-ai-x1y2z", map[string][]byte{kubeconfigFileName: []byte("apiVersion: v1")}, inv.env)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, []byte("apiVersion: v1"), secrets[0].Data[kubeconfigFileName])
+	assert.Equal(t, map[string][]byte{"MODE": []byte("run"), "PROMETHEUS_TOKEN": []byte("abc==")}, secrets[1].Data)
+
+	err := jobBackend{}.run(context.Background(), invocation{mode: config.KrknAIModeRun, checkpoint: "checkpoints/gen_3.json"}, invocationPaths{}, nil, nil)
+	assert.EqualError(t, err, "the job backend can't resume a run: use the container or local backend")
+}
+
+// tarArchive returns a tar stream of the named entries; names ending in / are directories.
+func tarArchive(t *testing.T, entries map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for name, content := range entries {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if name[len(name)-1] == '/' {
+			header = &tar.Header{Name: name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		require.NoError(t, w.WriteHeader(header))
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return &buf
+}
+
+func TestWaitForKrknContainer(t *testing.T) {
+	started := func(state corev1.ContainerState) bool { return state.Running != nil }
+	jobPodWith := func(state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "krkn-ai-run-x1", Namespace: "ns", Labels: map[string]string{"job-name": "krkn-ai-run"}},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: jobKrknContainer, State: state}},
+			},
+		}
+	}
+	failList := func(err error) *kubefake.Clientset {
+		kube := kubefake.NewSimpleClientset()
+		kube.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, err
+		})
+		return kube
+	}
+	pods := schema.GroupResource{Resource: "pods"}
+
+	pod, err := waitForKrknContainer(context.Background(), kubefake.NewSimpleClientset(jobPodWith(corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})), "ns", "krkn-ai-run", started)
+	require.NoError(t, err)
+	assert.Equal(t, "krkn-ai-run-x1", pod.Name)
+
+	_, err = waitForKrknContainer(context.Background(), failList(apierrors.NewForbidden(pods, "", errors.New("no RBAC"))), "ns", "krkn-ai-run", started)
+	assert.ErrorContains(t, err, "failed to list krkn-ai job pods")
+	assert.True(t, apierrors.IsForbidden(err))
+
+	waiting := jobPodWith(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError", Message: `secret "krkn-ai-env" not found`}})
+	_, err = waitForKrknContainer(context.Background(), kubefake.NewSimpleClientset(waiting), "ns", "krkn-ai-run", started)
+	assert.EqualError(t, err, `krkn-ai job container can't start (CreateContainerConfigError): secret "krkn-ai-env" not found`)
+
+	// Transient list errors and a pod that never starts run into the caller's deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = waitForKrknContainer(ctx, failList(apierrors.NewServiceUnavailable("etcd leader changed")), "ns", "krkn-ai-run", started)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "(no pod created)")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	creating := jobPodWith(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}})
+	_, err = waitForKrknContainer(ctx, kubefake.NewSimpleClientset(creating), "ns", "krkn-ai-run", started)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "(pod Pending, krkn container waiting: ContainerCreating)")
+}
+
+func TestExtractTar(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, extractTar(tarArchive(t, map[string]string{
+		"./":                      "",
+		"./reports/":              "",
+		"./reports/all.csv":       "generation,fitness\n",
+		"checkpoints/gen_1.json":  "{}",
+		"./" + krknReportFileName: "report",
+	}), dir))
+	content, err := os.ReadFile(filepath.Join(dir, "reports", "all.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "generation,fitness\n", string(content))
+	assert.FileExists(t, filepath.Join(dir, "checkpoints", "gen_1.json"))
+	assert.FileExists(t, filepath.Join(dir, krknReportFileName))
+
+	err = extractTar(tarArchive(t, map[string]string{"../escaped": "x"}), dir)
+	assert.EqualError(t, err, `results archive entry "../escaped" is outside the results directory`)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), "escaped"))
+}
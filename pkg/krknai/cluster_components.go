@@ -0,0 +1,211 @@
+package krknai
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// nodeRoleLabelPrefix is the label prefix OpenShift uses for node roles, e.g.
+// node-role.kubernetes.io/worker.
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// componentFilter narrows the discovered cluster_components before a run.
+type componentFilter struct {
+	includeNamespaces []string       // Glob patterns; empty keeps every namespace
+	excludeNamespaces []string       // Glob patterns; take precedence over includes
+	includeRegex      *regexp.Regexp // Keeps matching namespaces alongside includeNamespaces
+	excludeRegex      *regexp.Regexp // Drops matching namespaces alongside excludeNamespaces
+	nodeSelector      labels.Selector
+	nodeRoles         []string // Role names; a node is kept when it has any of them
+}
+
+// componentFilterOptions holds the unparsed cluster_components filter parameters.
+type componentFilterOptions struct {
+	includeNamespaces     string // Comma-separated glob patterns, e.g. "openshift-*"
+	excludeNamespaces     string
+	includeNamespaceRegex string // Regular expression, e.g. "^openshift-"
+	excludeNamespaceRegex string
+	nodeSelector          string // Label selector, e.g. "node-role.kubernetes.io/worker"
+	nodeRoles             string // Comma-separated roles, e.g. "worker,infra"
+}
+
+// parseComponentFilter parses the namespace patterns and regexes, node selector, and node
+// roles in opts. It returns nil when nothing is set.
+func parseComponentFilter(opts componentFilterOptions) (*componentFilter, error) {
+	f := &componentFilter{}
+	for _, expr := range []struct {
+		name  string
+		input string
+		dst   **regexp.Regexp
+	}{{"include", opts.includeNamespaceRegex, &f.includeRegex}, {"exclude", opts.excludeNamespaceRegex, &f.excludeRegex}} {
+		if expr.input = strings.TrimSpace(expr.input); expr.input == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr.input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace %s regex %q: %w", expr.name, expr.input, err)
+		}
+		*expr.dst = re
+	}
+	for _, list := range []struct {
+		input string
+		dst   *[]string
+	}{{opts.includeNamespaces, &f.includeNamespaces}, {opts.excludeNamespaces, &f.excludeNamespaces}} {
+		for _, pattern := range strings.Split(list.input, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+			}
+			*list.dst = append(*list.dst, pattern)
+		}
+	}
+	if nodeSelector := strings.TrimSpace(opts.nodeSelector); nodeSelector != "" {
+		selector, err := labels.Parse(nodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector %q: %w", nodeSelector, err)
+		}
+		f.nodeSelector = selector
+	}
+	for _, role := range strings.Split(opts.nodeRoles, ",") {
+		if role = strings.TrimSpace(role); role == "" {
+			continue
+		}
+		if errs := validation.IsQualifiedName(nodeRoleLabelPrefix + role); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid node role %q: %s", role, strings.Join(errs, "; "))
+		}
+		f.nodeRoles = append(f.nodeRoles, role)
+	}
+	if !f.filtersNamespaces() && !f.filtersNodes() {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// filtersNamespaces reports whether any namespace pattern or regex is set.
+func (f *componentFilter) filtersNamespaces() bool {
+	return len(f.includeNamespaces) > 0 || len(f.excludeNamespaces) > 0 || f.includeRegex != nil || f.excludeRegex != nil
+}
+
+// filtersNodes reports whether a node selector or node roles are set.
+func (f *componentFilter) filtersNodes() bool {
+	return f.nodeSelector != nil || len(f.nodeRoles) > 0
+}
+
+// apply filters cfg's cluster_components in place. Entries may be plain names or maps
+// with a name (and, for nodes, labels); node entries without labels cannot match a
+// selector or role and are dropped when either is set.
+func (f *componentFilter) apply(cfg map[string]interface{}) {
+	components, ok := cfg["cluster_components"].(map[string]interface{})
+	if !ok {
+		log.Println("No cluster_components in Krkn-ai config, skipping namespace and node filters")
+		return
+	}
+
+	if f.filtersNamespaces() {
+		if namespaces, ok := components["namespaces"].([]interface{}); ok {
+			kept := filterComponents(namespaces, func(entry interface{}) bool {
+				return f.namespaceAllowed(componentName(entry))
+			})
+			log.Printf("Namespace filter kept %d of %d namespaces (%d filtered)", len(kept), len(namespaces), len(namespaces)-len(kept))
+			components["namespaces"] = kept
+		}
+	}
+
+	if f.filtersNodes() {
+		if nodes, ok := components["nodes"].([]interface{}); ok {
+			kept := filterComponents(nodes, func(entry interface{}) bool {
+				m, ok := entry.(map[string]interface{})
+				if !ok {
+					return false
+				}
+				nodeLabels := labels.Set{}
+				if raw, ok := m["labels"].(map[string]interface{}); ok {
+					for key, value := range raw {
+						nodeLabels[key] = fmt.Sprint(value)
+					}
+				}
+				return f.nodeAllowed(nodeLabels)
+			})
+			log.Printf("Node filter (selector %q, roles %v) kept %d of %d nodes (%d filtered)",
+				f.nodeSelector, f.nodeRoles, len(kept), len(nodes), len(nodes)-len(kept))
+			components["nodes"] = kept
+		}
+	}
+}
+
+// nodeAllowed reports whether a node with nodeLabels matches the selector and carries at
+// least one of the roles.
+func (f *componentFilter) nodeAllowed(nodeLabels labels.Set) bool {
+	if f.nodeSelector != nil && !f.nodeSelector.Matches(nodeLabels) {
+		return false
+	}
+	if len(f.nodeRoles) == 0 {
+		return true
+	}
+	for _, role := range f.nodeRoles {
+		if nodeLabels.Has(nodeRoleLabelPrefix + role) {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceAllowed reports whether name passes the include and exclude patterns and
+// regexes. Excludes win; with no includes every remaining namespace is kept.
+func (f *componentFilter) namespaceAllowed(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, pattern := range f.excludeNamespaces {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+	if f.excludeRegex != nil && f.excludeRegex.MatchString(name) {
+		return false
+	}
+	if len(f.includeNamespaces) == 0 && f.includeRegex == nil {
+		return true
+	}
+	if f.includeRegex != nil && f.includeRegex.MatchString(name) {
+		return true
+	}
+	for _, pattern := range f.includeNamespaces {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// componentName returns the name of a cluster component entry, either a plain string or
+// a map with a name key.
+func componentName(entry interface{}) string {
+	switch v := entry.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		name, _ := v["name"].(string)
+		return name
+	}
+	return ""
+}
+
+func filterComponents(entries []interface{}, keep func(interface{}) bool) []interface{} {
+	kept := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		if keep(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
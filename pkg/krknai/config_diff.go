@@ -0,0 +1,117 @@
+package krknai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// fieldChange is a config value the updater changed, addressed by its dotted key path.
+// Old or New is nil when the field is absent on that side. Source names the updater
+// input that made the change, when known.
+type fieldChange struct {
+	Path   string `json:"path"`
+	Old    any    `json:"old"`
+	New    any    `json:"new"`
+	Source string `json:"source,omitempty"`
+}
+
+// String formats the change as "path: old -> new".
+func (c fieldChange) String() string {
+	format := func(v any) string {
+		if v == nil {
+			return "<unset>"
+		}
+		return fmt.Sprint(v)
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Path, format(c.Old), format(c.New))
+}
+
+// configChanges lists the fields that differ between the discovered and updated config,
+// sorted by path. Maps are compared key by key; any other value, lists included, is
+// compared whole.
+func configChanges(before, after []byte) ([]fieldChange, error) {
+	var discovered, updated map[string]interface{}
+	if err := yaml.Unmarshal(before, &discovered); err != nil {
+		return nil, fmt.Errorf("failed to parse discovered config: %w", err)
+	}
+	if err := yaml.Unmarshal(after, &updated); err != nil {
+		return nil, fmt.Errorf("failed to parse updated config: %w", err)
+	}
+	return diffConfigs(discovered, updated), nil
+}
+
+// diffConfigs compares two parsed configs the way configChanges does.
+func diffConfigs(discovered, updated map[string]interface{}) []fieldChange {
+	var changes []fieldChange
+	var compare func(path []string, old, new any)
+	compare = func(path []string, old, new any) {
+		oldMap, oldIsMap := old.(map[string]interface{})
+		newMap, newIsMap := new.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			for key := range oldMap {
+				compare(append(path, key), oldMap[key], newMap[key])
+			}
+			for key := range newMap {
+				if _, ok := oldMap[key]; !ok {
+					compare(append(path, key), nil, newMap[key])
+				}
+			}
+			return
+		}
+		if !reflect.DeepEqual(old, new) {
+			changes = append(changes, fieldChange{Path: strings.Join(path, "."), Old: old, New: new})
+		}
+	}
+	compare(nil, discovered, updated)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// configDiff returns a unified diff between the discovered and updated krkn-ai config.
+// An empty string means the contents are identical.
+func configDiff(before, after []byte) (string, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: "a/" + krknConfigFileName,
+		ToFile:   "b/" + krknConfigFileName,
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute config diff: %w", err)
+	}
+	return diff, nil
+}
+
+// writeConfigDiff writes the unified diff between before and after to path.
+func writeConfigDiff(path string, before, after []byte) error {
+	diff, err := configDiff(before, after)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(diff), 0o644); err != nil {
+		return fmt.Errorf("failed to write config diff: %w", err)
+	}
+	return nil
+}
+
+// writeConfigBackup saves the discovered config before it is overwritten. When backupPath
+// is an existing directory, the file is named krkn-ai-<timestamp>.yaml inside it so
+// repeated updates don't collide; otherwise backupPath is used as the file path.
+func writeConfigBackup(backupPath string, discovered []byte, now time.Time) (string, error) {
+	if info, err := os.Stat(backupPath); err == nil && info.IsDir() {
+		backupPath = filepath.Join(backupPath, fmt.Sprintf("krkn-ai-%s.yaml", now.UTC().Format("20060102T150405.000Z")))
+	}
+	if err := os.WriteFile(backupPath, discovered, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write config backup: %w", err)
+	}
+	return backupPath, nil
+}
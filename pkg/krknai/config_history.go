@@ -0,0 +1,74 @@
+package krknai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configHistoryDirName   = "config-history"
+	configHistoryIndexName = "index.yaml"
+)
+
+// configHistoryEntry records one merged config kept in the history: its file name inside
+// the history directory, when it was written, and the parameters applied to it.
+type configHistoryEntry struct {
+	File       string         `yaml:"file"`
+	Time       time.Time      `yaml:"time"`
+	Parameters map[string]any `yaml:"parameters"`
+}
+
+// recordConfigHistory copies the merged config into historyDir as a timestamped
+// krkn-ai-<time>.yaml and appends an entry listing requested to historyDir/index.yaml.
+// It returns the path of the copy.
+func recordConfigHistory(historyDir string, merged []byte, requested map[string]any, now time.Time) (string, error) {
+	if err := os.MkdirAll(historyDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create config history directory: %w", err)
+	}
+
+	indexPath := filepath.Join(historyDir, configHistoryIndexName)
+	var index []configHistoryEntry
+	data, err := os.ReadFile(indexPath)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &index); err != nil {
+			return "", fmt.Errorf("failed to parse config history index %s: %w", indexPath, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return "", fmt.Errorf("failed to read config history index: %w", err)
+	}
+
+	// Updates within the same millisecond get a numbered suffix rather than replacing a copy
+	stamp := now.UTC().Format("20060102T150405.000Z")
+	name := fmt.Sprintf("krkn-ai-%s.yaml", stamp)
+	for n := 2; fileExists(filepath.Join(historyDir, name)); n++ {
+		name = fmt.Sprintf("krkn-ai-%s-%d.yaml", stamp, n)
+	}
+	path := filepath.Join(historyDir, name)
+	if err := os.WriteFile(path, merged, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write config history copy: %w", err)
+	}
+
+	if requested == nil {
+		requested = map[string]any{}
+	}
+	index = append(index, configHistoryEntry{File: name, Time: now.UTC(), Parameters: requested})
+	data, err = yaml.Marshal(index)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config history index: %w", err)
+	}
+	if err := os.WriteFile(indexPath, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write config history index: %w", err)
+	}
+	return path, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
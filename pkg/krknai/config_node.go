@@ -0,0 +1,92 @@
+package krknai
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// marshalPreservingLayout renders cfg by applying its values to the node tree parsed from
+// original, so comments, key order, and the formatting of untouched values survive the
+// update. Only values that differ from original are re-encoded, and keys missing from
+// original are appended to their mapping.
+func marshalPreservingLayout(original []byte, cfg map[string]interface{}) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse Krkn-ai config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if err := syncNode(doc.Content[0], cfg); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal updated config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal updated config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// syncNode updates node in place so it decodes to value. Mappings are updated key by key;
+// any other node is replaced wholesale, keeping its comments, when its value changed.
+func syncNode(node *yaml.Node, value interface{}) error {
+	if desired, ok := value.(map[string]interface{}); ok && node.Kind == yaml.MappingNode {
+		seen := make(map[string]bool, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			seen[key] = true
+			if v, ok := desired[key]; ok {
+				if err := syncNode(node.Content[i+1], v); err != nil {
+					return err
+				}
+			}
+		}
+
+		var added []string
+		for key := range desired {
+			if !seen[key] {
+				added = append(added, key)
+			}
+		}
+		sort.Strings(added)
+		for _, key := range added {
+			var valueNode yaml.Node
+			if err := valueNode.Encode(desired[key]); err != nil {
+				return fmt.Errorf("failed to encode %s: %w", key, err)
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &valueNode)
+		}
+		return nil
+	}
+
+	var replacement yaml.Node
+	if err := replacement.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode config value: %w", err)
+	}
+	var current, updated interface{}
+	if err := node.Decode(&current); err != nil {
+		return fmt.Errorf("failed to decode config value: %w", err)
+	}
+	if err := replacement.Decode(&updated); err != nil {
+		return fmt.Errorf("failed to decode config value: %w", err)
+	}
+	if reflect.DeepEqual(current, updated) {
+		return nil
+	}
+
+	replacement.HeadComment = node.HeadComment
+	replacement.LineComment = node.LineComment
+	replacement.FootComment = node.FootComment
+	*node = replacement
+	return nil
+}
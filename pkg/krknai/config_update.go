@@ -0,0 +1,470 @@
+package krknai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+// configUpdate holds the krkn-ai parameters updateKrknConfig merges into the discovered
+// krkn-ai.yaml, parsed and with their templates resolved for the cluster. A new parameter
+// is added here, to empty, and to the merge stage that writes it.
+type configUpdate struct {
+	overlayPath string
+	overlay     map[string]interface{}
+	overrides   map[string]interface{}
+
+	generations     int
+	population      int
+	gaParams        map[string]any
+	healthCheckApps []map[string]interface{}
+
+	fitnessQuery        string
+	fitnessIncludes     map[string]bool
+	fitnessItems        []fitnessItem
+	fitnessItemsReplace bool
+
+	scenarios           string
+	disableAllScenarios bool
+	allowNoScenarios    bool
+	genericScenarios    map[string]bool
+	scenarioParams      map[string]map[string]interface{}
+
+	components *componentFilter
+	scope      chaosScope
+	rulesMode  string
+}
+
+// parseConfigUpdate parses the krkn-ai parameters, resolving their templates with templates.
+// Health check endpoints are discovered through the cluster at kubeconfigPath.
+func parseConfigUpdate(ctx context.Context, templates *templateResolver, kubeconfigPath string) (*configUpdate, error) {
+	u := &configUpdate{
+		overlayPath:         viper.GetString(config.KrknAI.OverlayPath),
+		fitnessItemsReplace: viper.GetBool(config.KrknAI.FitnessItemsReplace),
+		scenarios:           viper.GetString(config.KrknAI.Scenarios),
+		disableAllScenarios: viper.GetBool(config.KrknAI.DisableAllScenarios),
+	}
+	u.allowNoScenarios = viper.GetBool(config.KrknAI.AllowNoScenarios) || u.disableAllScenarios
+
+	var err error
+	u.fitnessQuery, err = templates.param(ctx, config.KrknAI.FitnessQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	u.overlay, err = readOverlay(u.overlayPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := templates.expandConfig(ctx, u.overlay); err != nil {
+		return nil, fmt.Errorf("overlay %s: %w", u.overlayPath, err)
+	}
+
+	overridesParam, err := templates.param(ctx, config.KrknAI.Overrides)
+	if err != nil {
+		return nil, err
+	}
+	u.overrides, err = parseOverrides(overridesParam)
+	if err != nil {
+		return nil, err
+	}
+
+	runSize, err := parseRunSizeParams(runSizeValues())
+	if err != nil {
+		return nil, err
+	}
+	u.generations = runSize["generations"]
+	u.population = runSize["population_size"]
+
+	u.fitnessIncludes, err = parseFitnessIncludeFlags(map[string]string{
+		"include_health_check_failure":       viper.GetString(config.KrknAI.IncludeHealthCheckFailure),
+		"include_health_check_response_time": viper.GetString(config.KrknAI.IncludeHealthCheckResponseTime),
+		"include_krkn_failure":               viper.GetString(config.KrknAI.IncludeKrknFailure),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fitnessItemsParam, err := templates.param(ctx, config.KrknAI.FitnessItems)
+	if err != nil {
+		return nil, err
+	}
+	u.fitnessItems, err = parseFitnessItems(fitnessItemsParam)
+	if err != nil {
+		return nil, err
+	}
+
+	u.genericScenarios, err = parseGenericScenarios(viper.GetString(config.KrknAI.GenericScenarios))
+	if err != nil {
+		return nil, err
+	}
+
+	// Dedicated scenario toggles are written like generic entries and take precedence over them
+	scenarioToggles, err := parseScenarioToggles(scenarioToggleValues())
+	if err != nil {
+		return nil, err
+	}
+	for name, enabled := range scenarioToggles {
+		u.genericScenarios[name] = enabled
+	}
+
+	u.gaParams, err = parseGAParams(gaParamValues())
+	if err != nil {
+		return nil, err
+	}
+
+	scenarioParamsParam, err := templates.param(ctx, config.KrknAI.ScenarioParams)
+	if err != nil {
+		return nil, err
+	}
+	u.scenarioParams, err = parseScenarioParams(scenarioParamsParam)
+	if err != nil {
+		return nil, err
+	}
+
+	u.components, err = componentFilterFromConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	u.scope, err = scopeFromConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	u.rulesMode, err = scenarioRulesModeFromConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if u.generations > 0 {
+		if err := validateMinGenerations(u.generations, viper.GetInt(config.KrknAI.MinGenerations), viper.GetBool(config.KrknAI.StrictValidation)); err != nil {
+			return nil, err
+		}
+	}
+
+	u.healthCheckApps, err = resolveHealthChecks(ctx, kubeconfigPath, templates)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// empty reports whether the update leaves the discovered config as it is. Enforcing the
+// scenario rules is not empty, since they check the discovered config itself.
+func (u *configUpdate) empty() bool {
+	return len(u.overlay) == 0 && len(u.overrides) == 0 &&
+		u.generations == 0 && u.population == 0 && len(u.gaParams) == 0 && len(u.healthCheckApps) == 0 &&
+		u.fitnessQuery == "" && len(u.fitnessIncludes) == 0 && len(u.fitnessItems) == 0 &&
+		u.scenarios == "" && !u.disableAllScenarios && len(u.genericScenarios) == 0 && len(u.scenarioParams) == 0 &&
+		u.components == nil && !u.scope.namespaced() && u.rulesMode == scenarioRulesOff
+}
+
+// merge applies the update to cfg in precedence order: the overlay, then the overrides,
+// then the dedicated parameters, then the filters, and finally the scenario rules. It
+// returns the tracker attributing each change to its stage and the fields the overrides set.
+func (u *configUpdate) merge(cfg map[string]interface{}) (*changeTracker, []overlayField, error) {
+	// The rules judge the whole cluster, so its topology is taken before the filters narrow it
+	topology := discoveredTopology(cfg)
+
+	// The overlay is applied first so the parameters below take precedence over it
+	tracker := newChangeTracker(cfg)
+	overlayFields := applyOverlay(cfg, u.overlay)
+	if len(overlayFields) > 0 {
+		log.Printf("Applied overlay %s to %d field(s); krkn-ai parameters take precedence", u.overlayPath, len(overlayFields))
+	}
+	tracker.record(cfg, sourceOverlay)
+	overrideFields := applyOverlay(cfg, u.overrides)
+	if len(overrideFields) > 0 {
+		log.Printf("Applied %d krkn-ai override(s); dedicated krkn-ai parameters take precedence", len(overrideFields))
+	}
+	tracker.record(cfg, sourceOverride)
+
+	u.applyRunParams(cfg)
+	u.applyHealthChecks(cfg)
+	if err := u.applyFitness(cfg); err != nil {
+		return nil, nil, err
+	}
+	u.applyScenarios(cfg)
+	applyScenarioParams(cfg, u.scenarioParams)
+	tracker.record(cfg, sourceParameter)
+
+	if u.components != nil {
+		u.components.apply(cfg)
+	}
+	u.scope.apply(cfg)
+	tracker.record(cfg, sourceFilter)
+
+	if _, err := applyScenarioRules(cfg, topology, u.rulesMode); err != nil {
+		return nil, nil, err
+	}
+	tracker.record(cfg, sourceRule)
+
+	logOverlayPrecedence(cfg, overlayFields, "overlay")
+	logOverlayPrecedence(cfg, overrideFields, "override")
+	return tracker, overrideFields, nil
+}
+
+// applyRunParams sets the run size and genetic algorithm settings.
+func (u *configUpdate) applyRunParams(cfg map[string]interface{}) {
+	if u.generations > 0 {
+		cfg["generations"] = u.generations
+		log.Printf("Updated generations to: %d", u.generations)
+	}
+
+	if u.population > 0 {
+		cfg["population_size"] = u.population
+		log.Printf("Updated population_size to: %d", u.population)
+	}
+
+	gaKeys := make([]string, 0, len(u.gaParams))
+	for key := range u.gaParams {
+		gaKeys = append(gaKeys, key)
+	}
+	sort.Strings(gaKeys)
+	for _, key := range gaKeys {
+		log.Printf("Updated %s from %v to %v", key, cfg[key], u.gaParams[key])
+		cfg[key] = u.gaParams[key]
+	}
+}
+
+// applyHealthChecks merges the health check applications by name.
+func (u *configUpdate) applyHealthChecks(cfg map[string]interface{}) {
+	if len(u.healthCheckApps) == 0 {
+		return
+	}
+	hc, ok := cfg["health_checks"].(map[string]interface{})
+	if !ok {
+		hc = map[string]interface{}{}
+	}
+	hc["applications"] = mergeHealthCheckApps(hc["applications"], u.healthCheckApps)
+	cfg["health_checks"] = hc
+}
+
+// applyFitness sets the fitness function query, include flags, and weighted items.
+func (u *configUpdate) applyFitness(cfg map[string]interface{}) error {
+	// Update fitness_function.query if set
+	if u.fitnessQuery != "" {
+		if ff, ok := cfg["fitness_function"].(map[string]interface{}); ok {
+			ff["query"] = u.fitnessQuery
+			log.Printf("Updated fitness_function.query to: %s", u.fitnessQuery)
+		}
+	}
+
+	// Update fitness_function include flags if set
+	if len(u.fitnessIncludes) > 0 {
+		ff, ok := cfg["fitness_function"].(map[string]interface{})
+		if !ok {
+			ff = map[string]interface{}{}
+		}
+		for key, enabled := range u.fitnessIncludes {
+			ff[key] = enabled
+			log.Printf("Updated fitness_function.%s to: %t", key, enabled)
+		}
+		cfg["fitness_function"] = ff
+	}
+
+	if len(u.fitnessItems) > 0 {
+		ff, ok := cfg["fitness_function"].(map[string]interface{})
+		if !ok {
+			ff = map[string]interface{}{}
+		}
+		items := mergeFitnessItems(ff["items"], u.fitnessItems, u.fitnessItemsReplace)
+		if err := validateFitnessItems(items); err != nil {
+			return err
+		}
+		ff["items"] = items
+		cfg["fitness_function"] = ff
+	}
+	return nil
+}
+
+// applyScenarios enables the listed scenarios, or disables them all, then writes the
+// generic scenario entries.
+func (u *configUpdate) applyScenarios(cfg map[string]interface{}) {
+	// Update scenarios if set
+	// If the user has set a list of scenarios, enable all of them
+	// TODO: Add a way to disable scenarios not selected by user
+	if u.scenarios != "" {
+		enabledScenarios := make(map[string]bool)
+		for _, s := range strings.Split(u.scenarios, ",") {
+			enabledScenarios[strings.TrimSpace(s)] = true
+		}
+
+		if scenarioCfg, ok := cfg["scenario"].(map[string]interface{}); ok {
+			for name, val := range scenarioCfg {
+				if scenarioMap, ok := val.(map[string]interface{}); ok {
+					scenarioMap["enable"] = enabledScenarios[name]
+				}
+			}
+			log.Printf("Updated scenarios: %v", u.scenarios)
+		}
+	}
+
+	if u.disableAllScenarios {
+		if scenarioCfg, ok := cfg["scenario"].(map[string]interface{}); ok {
+			for _, val := range scenarioCfg {
+				if scenarioMap, ok := val.(map[string]interface{}); ok {
+					scenarioMap["enable"] = false
+				}
+			}
+			log.Println("Disabled all scenarios")
+		}
+	}
+
+	// Write generic scenario entries by name, adding any the discovered config lacks
+	if len(u.genericScenarios) > 0 {
+		scenarioCfg, ok := cfg["scenario"].(map[string]interface{})
+		if !ok {
+			scenarioCfg = map[string]interface{}{}
+		}
+		for name, enabled := range u.genericScenarios {
+			scenarioMap, ok := scenarioCfg[name].(map[string]interface{})
+			if !ok {
+				scenarioMap = map[string]interface{}{}
+			}
+			scenarioMap["enable"] = enabled
+			scenarioCfg[name] = scenarioMap
+			log.Printf("Updated generic scenario %s: enable=%t", name, enabled)
+		}
+		cfg["scenario"] = scenarioCfg
+	}
+}
+
+// requested returns what the operator asked for, keyed by krkn-ai.yaml field, so the
+// effective config report and config history can show it.
+func (u *configUpdate) requested(overrideFields []overlayField) map[string]any {
+	requested := map[string]any{}
+	for _, field := range overrideFields {
+		requested[field.name()] = field.value
+	}
+	if u.generations > 0 {
+		requested["generations"] = u.generations
+	}
+	if u.population > 0 {
+		requested["population_size"] = u.population
+	}
+	if u.fitnessQuery != "" {
+		requested["fitness_function.query"] = u.fitnessQuery
+	}
+	if len(u.healthCheckApps) > 0 {
+		requested["health_checks.applications"] = u.healthCheckApps
+	}
+	for key, enabled := range u.fitnessIncludes {
+		requested["fitness_function."+key] = enabled
+	}
+	if len(u.fitnessItems) > 0 {
+		items := make([]map[string]interface{}, 0, len(u.fitnessItems))
+		for _, item := range u.fitnessItems {
+			items = append(items, item.toMap())
+		}
+		requested["fitness_function.items"] = items
+	}
+	for key, value := range u.gaParams {
+		requested[key] = value
+	}
+	for name, params := range u.scenarioParams {
+		for key, value := range params {
+			requested["scenario."+name+"."+key] = value
+		}
+	}
+	for name, enabled := range u.genericScenarios {
+		requested["scenario."+name+".enable"] = enabled
+	}
+	switch {
+	case u.disableAllScenarios:
+		requested["scenarios"] = []string{}
+	case u.scenarios != "":
+		var names []string
+		for _, s := range strings.Split(u.scenarios, ",") {
+			names = append(names, strings.TrimSpace(s))
+		}
+		sort.Strings(names)
+		requested["scenarios"] = names
+	}
+	return requested
+}
+
+// writeUpdatedConfig writes the merged config over yamlFile, or with KRKN_CONFIG_DRY_RUN
+// only logs the changes, along with the optional backup, history entry, and diff and the
+// effective config and applied parameter reports. The copies are redacted.
+func writeUpdatedConfig(yamlFile string, data, updatedData []byte, changes []fieldChange, requested map[string]any) error {
+	sharedDir := filepath.Dir(yamlFile)
+
+	if viper.GetBool(config.KrknAI.ConfigDryRun) {
+		diff, err := configDiff(data, updatedData)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			log.Printf("Config dry run: no changes to %s", yamlFile)
+			return nil
+		}
+		lines := make([]string, 0, len(changes))
+		for _, change := range changes {
+			lines = append(lines, "  "+change.String())
+		}
+		log.Printf("Config dry run, %s left unchanged. Fields that would change:\n%s", yamlFile, strings.Join(lines, "\n"))
+		log.Printf("Changes that would be applied:\n%s", diff)
+		return nil
+	}
+
+	// Copies kept for people to read are redacted; krkn-ai.yaml keeps the credentials it needs
+	redact := redactorFromConfig()
+	redactedData, err := redact.redactYAML(data)
+	if err != nil {
+		return err
+	}
+	redactedUpdated, err := redact.redactYAML(updatedData)
+	if err != nil {
+		return err
+	}
+
+	if backupPath := viper.GetString(config.KrknAI.ConfigBackupPath); backupPath != "" {
+		written, err := writeConfigBackup(backupPath, redactedData, time.Now())
+		if err != nil {
+			return err
+		}
+		log.Printf("Discovered config backed up to: %s", written)
+	}
+
+	if err := os.WriteFile(yamlFile, updatedData, 0o644); err != nil {
+		return fmt.Errorf("failed to write updated config: %w", err)
+	}
+
+	if viper.GetBool(config.KrknAI.ConfigHistory) {
+		written, err := recordConfigHistory(filepath.Join(sharedDir, configHistoryDirName), redactedUpdated, redact.redactValue(requested).(map[string]any), time.Now())
+		if err != nil {
+			return err
+		}
+		log.Printf("Merged config recorded in history: %s", written)
+	}
+
+	if viper.GetBool(config.KrknAI.WriteDiff) {
+		diffFile := filepath.Join(sharedDir, krknConfigDiffFileName)
+		if err := writeConfigDiff(diffFile, redactedData, redactedUpdated); err != nil {
+			return err
+		}
+		log.Printf("Config diff written: %s", diffFile)
+	}
+
+	effectiveFile := filepath.Join(sharedDir, effectiveConfigFileName)
+	if err := writeEffectiveConfig(effectiveFile, redact.redactValue(requested).(map[string]any), redactedData, redactedUpdated); err != nil {
+		return err
+	}
+
+	if err := writeAppliedParams(filepath.Join(sharedDir, appliedParamsFileName), redact.redactChanges(changes)); err != nil {
+		return err
+	}
+
+	log.Printf("Config file updated: %s", yamlFile)
+	return nil
+}
@@ -0,0 +1,41 @@
+package krknai
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigUpdateEmpty(t *testing.T) {
+	assert.True(t, (&configUpdate{rulesMode: scenarioRulesOff}).empty())
+
+	// Every parameter the merge writes must count, or setting only it would skip the write
+	updates := map[string]configUpdate{
+		"overlay":             {overlay: map[string]interface{}{"generations": 3}},
+		"overrides":           {overrides: map[string]interface{}{"generations": 3}},
+		"generations":         {generations: 3},
+		"population":          {population: 4},
+		"gaParams":            {gaParams: map[string]any{"mutation_rate": 0.5}},
+		"healthCheckApps":     {healthCheckApps: []map[string]interface{}{{"name": "console"}}},
+		"fitnessQuery":        {fitnessQuery: "sum(up)"},
+		"fitnessIncludes":     {fitnessIncludes: map[string]bool{"include_krkn_failure": true}},
+		"fitnessItems":        {fitnessItems: []fitnessItem{{Name: "cpu"}}},
+		"scenarios":           {scenarios: "pod_scenarios"},
+		"disableAllScenarios": {disableAllScenarios: true},
+		"genericScenarios":    {genericScenarios: map[string]bool{"syn_flood": true}},
+		"scenarioParams":      {scenarioParams: map[string]map[string]interface{}{"node_cpu_hog": {"duration": 60}}},
+		"components":          {components: &componentFilter{}},
+		"scope":               {scope: chaosScope{namespace: "app"}},
+		"rulesMode":           {rulesMode: scenarioRulesError},
+	}
+	for name, update := range updates {
+		if update.rulesMode == "" {
+			update.rulesMode = scenarioRulesOff
+		}
+		assert.False(t, update.empty(), name)
+	}
+
+	// overlayPath, fitnessItemsReplace, and allowNoScenarios only qualify other fields
+	assert.Equal(t, len(updates)+3, reflect.TypeOf(configUpdate{}).NumField(), "a new field must be covered by empty and this test")
+}
@@ -0,0 +1,129 @@
+package krknai
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const effectiveConfigFileName = "effective-config.yaml"
+
+// ParameterValues records how a single krkn-ai parameter was resolved: the value the
+// operator requested (nil when not set), the value discovered in krkn-ai.yaml, and
+// the value actually written for the run.
+type ParameterValues struct {
+	Requested  any `yaml:"requested"`
+	Discovered any `yaml:"discovered"`
+	Effective  any `yaml:"effective"`
+}
+
+// effectiveParameters compares the discovered and updated configs for every parameter
+// the updater manages. requested holds only the parameters the operator set.
+func effectiveParameters(requested map[string]any, before, after []byte) (map[string]ParameterValues, error) {
+	var discovered, effective map[string]interface{}
+	if err := yaml.Unmarshal(before, &discovered); err != nil {
+		return nil, fmt.Errorf("failed to parse discovered config: %w", err)
+	}
+	if err := yaml.Unmarshal(after, &effective); err != nil {
+		return nil, fmt.Errorf("failed to parse updated config: %w", err)
+	}
+
+	nested := func(section, key string) func(map[string]interface{}) any {
+		return func(cfg map[string]interface{}) any { return nestedValue(cfg, section, key) }
+	}
+	topLevel := func(key string) func(map[string]interface{}) any {
+		return func(cfg map[string]interface{}) any { return cfg[key] }
+	}
+	lookups := map[string]func(map[string]interface{}) any{
+		"generations":                                         topLevel("generations"),
+		"population_size":                                     topLevel("population_size"),
+		"mutation_rate":                                       topLevel("mutation_rate"),
+		"scenario_mutation_rate":                              topLevel("scenario_mutation_rate"),
+		"crossover_rate":                                      topLevel("crossover_rate"),
+		"population_injection_rate":                           topLevel("population_injection_rate"),
+		"population_injection_size":                           topLevel("population_injection_size"),
+		"fitness_function.query":                              nested("fitness_function", "query"),
+		"fitness_function.include_health_check_failure":       nested("fitness_function", "include_health_check_failure"),
+		"fitness_function.include_health_check_response_time": nested("fitness_function", "include_health_check_response_time"),
+		"fitness_function.include_krkn_failure":               nested("fitness_function", "include_krkn_failure"),
+		"fitness_function.items":                              nested("fitness_function", "items"),
+		"health_checks.applications":                          nested("health_checks", "applications"),
+		"scenarios":                                           func(cfg map[string]interface{}) any { return enabledScenarioNames(cfg) },
+	}
+
+	// Overrides can request any field; report them by their dotted path
+	for name := range requested {
+		if _, ok := lookups[name]; !ok {
+			path := strings.Split(name, ".")
+			lookups[name] = func(cfg map[string]interface{}) any { return pathValue(cfg, path) }
+		}
+	}
+
+	params := make(map[string]ParameterValues, len(lookups))
+	for name, lookup := range lookups {
+		params[name] = ParameterValues{
+			Requested:  requested[name],
+			Discovered: lookup(discovered),
+			Effective:  lookup(effective),
+		}
+	}
+	return params, nil
+}
+
+// writeEffectiveConfig writes the per-parameter requested/discovered/effective report to path.
+func writeEffectiveConfig(path string, requested map[string]any, before, after []byte) error {
+	params, err := effectiveParameters(requested, before, after)
+	if err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(map[string]any{"parameters": params})
+	if err != nil {
+		return fmt.Errorf("failed to marshal effective config: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write effective config: %w", err)
+	}
+	return nil
+}
+
+// nestedValue returns cfg[section][key], or nil if either level is missing.
+func nestedValue(cfg map[string]interface{}, section, key string) any {
+	if m, ok := cfg[section].(map[string]interface{}); ok {
+		return m[key]
+	}
+	return nil
+}
+
+// pathValue returns the value at the key path in cfg, or nil when it is unset.
+func pathValue(cfg map[string]interface{}, path []string) any {
+	var current any = cfg
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+// enabledScenarioNames returns the sorted names of scenarios with enable: true.
+func enabledScenarioNames(cfg map[string]interface{}) []string {
+	scenarioCfg, ok := cfg["scenario"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	names := []string{}
+	for name, val := range scenarioCfg {
+		if scenarioMap, ok := val.(map[string]interface{}); ok {
+			if enabled, _ := scenarioMap["enable"].(bool); enabled {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,59 @@
+package krknai
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+// krknAIKeyPrefix is the viper key prefix of every krkn-ai parameter.
+const krknAIKeyPrefix = "krknAI."
+
+// krknAIEnvPrefix prefixes the environment variables LoadKrknAIConfigFromEnv reads.
+const krknAIEnvPrefix = "KRKN_AI_"
+
+// krknAIEnvName returns the KRKN_AI_* environment variable of a krkn-ai viper key, e.g.
+// KRKN_AI_HEALTH_CHECK_APPS for krknAI.healthCheckApps.
+func krknAIEnvName(key string) string {
+	var b strings.Builder
+	b.WriteString(krknAIEnvPrefix)
+	for i, r := range strings.TrimPrefix(key, krknAIKeyPrefix) {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// LoadKrknAIConfigFromEnv makes non-empty KRKN_AI_* environment variables the krkn-ai
+// defaults, one per parameter (see krknAIEnvName), so the executor can be configured from a
+// plain container or Prow job environment. Explicit config, i.e. a config file, a flag, or
+// the parameter's own KRKN_* variable, still takes precedence, and the built-in defaults
+// apply to anything unset. Call it after ApplyProfile so the environment also overrides the
+// profile. It returns the number of parameters it set.
+func LoadKrknAIConfigFromEnv() int {
+	var loaded int
+	fields := reflect.ValueOf(config.KrknAI)
+	for i := 0; i < fields.NumField(); i++ {
+		key := fields.Field(i).String()
+		// KRKN_AI_PROFILE is already bound to the profile key and ApplyProfile has read it by
+		// now, so a default set here could no longer select a profile
+		if key == config.KrknAI.Profile {
+			continue
+		}
+		if value := os.Getenv(krknAIEnvName(key)); value != "" {
+			viper.SetDefault(key, value)
+			loaded++
+		}
+	}
+	if loaded > 0 {
+		log.Printf("Loaded %d krkn-ai parameters from %s* environment variables; explicit config takes precedence", loaded, krknAIEnvPrefix)
+	}
+	return loaded
+}
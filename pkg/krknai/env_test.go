@@ -0,0 +1,38 @@
+package krknai
+
+import (
+	"testing"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKrknAIEnvName(t *testing.T) {
+	assert.Equal(t, "KRKN_AI_GENERATIONS", krknAIEnvName(config.KrknAI.Generations))
+	assert.Equal(t, "KRKN_AI_HEALTH_CHECK_APPS", krknAIEnvName(config.KrknAI.HealthCheckApps))
+	assert.Equal(t, "KRKN_AI_LLM_CACHE_ENABLED", krknAIEnvName(config.KrknAI.LLMCacheEnabled))
+}
+
+func TestLoadKrknAIConfigFromEnv(t *testing.T) {
+	// The environment replaces the parameter defaults, so put the previous defaults back afterwards
+	for _, key := range []string{config.KrknAI.Generations, config.KrknAI.Population, config.KrknAI.Scenarios} {
+		previous := viper.Get(key)
+		t.Cleanup(func() { viper.SetDefault(key, previous) })
+	}
+	setupKrknConfig(t, map[string]any{
+		config.KrknAI.Generations: nil,
+		config.KrknAI.Population:  nil,
+		config.KrknAI.Scenarios:   "pod_scenarios",
+	})
+	viper.SetDefault(config.KrknAI.Population, 4)
+
+	t.Setenv("KRKN_AI_GENERATIONS", "7")
+	t.Setenv("KRKN_AI_POPULATION", "")
+	t.Setenv("KRKN_AI_SCENARIOS", "node_cpu_hog")
+
+	assert.Equal(t, 2, LoadKrknAIConfigFromEnv())
+	assert.Equal(t, 7, viper.GetInt(config.KrknAI.Generations), "the environment overrides the default")
+	assert.Equal(t, 4, viper.GetInt(config.KrknAI.Population), "an empty variable leaves the default")
+	assert.Equal(t, "pod_scenarios", viper.GetString(config.KrknAI.Scenarios), "explicit config takes precedence")
+}
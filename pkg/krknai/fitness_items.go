@@ -0,0 +1,153 @@
+package krknai
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fitnessItemTypes are the ways krkn-ai evaluates a fitness item's query: at a single point
+// after the scenario, or over the scenario's duration.
+var fitnessItemTypes = map[string]bool{"point": true, "range": true}
+
+// Fitness item directions. krkn-ai favours the scenarios that score highest, so an item
+// whose metric falls under chaos, such as availability, is given to it negated.
+const (
+	fitnessDirectionIncrease = "increase"
+	fitnessDirectionDecrease = "decrease"
+)
+
+// fitnessWeightTolerance is how far the weights of fitness_function.items may sum from 1.
+const fitnessWeightTolerance = 0.01
+
+// fitnessItem is one weighted entry of fitness_function.items.
+type fitnessItem struct {
+	Name   string  `yaml:"name"`
+	Query  string  `yaml:"query"`
+	Weight float64 `yaml:"weight"`
+	Type   string  `yaml:"type"`
+
+	// Direction is the way the metric moves when a scenario hurts the cluster: increase
+	// (the default) or decrease.
+	Direction string `yaml:"direction"`
+}
+
+// toMap returns the item as a krkn-ai config entry, with the query of a decrease item
+// negated since krkn-ai has no notion of direction.
+func (i fitnessItem) toMap() map[string]interface{} {
+	query := i.Query
+	if i.Direction == fitnessDirectionDecrease {
+		query = "-1 * (" + query + ")"
+	}
+	return map[string]interface{}{"name": i.Name, "query": query, "weight": i.Weight, "type": i.Type}
+}
+
+// parseFitnessItems parses a YAML list of fitness items, e.g.
+// [{name: restarts, query: "sum(kube_pod_container_status_restarts_total)", weight: 0.6}].
+// Each item needs a query and a positive weight. Names must be unique and default to
+// item_<n> by position, type defaults to point, and direction to increase. Weights are
+// checked against the merged items by validateFitnessItems.
+func parseFitnessItems(input string) ([]fitnessItem, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+	var items []fitnessItem
+	if err := yaml.Unmarshal([]byte(input), &items); err != nil {
+		return nil, fmt.Errorf("invalid fitness items (expected a YAML list of name, query, weight, type, and direction): %w", err)
+	}
+	seen := make(map[string]bool, len(items))
+	for idx := range items {
+		item := &items[idx]
+		if strings.TrimSpace(item.Query) == "" {
+			return nil, fmt.Errorf("invalid fitness item %d (query required)", idx+1)
+		}
+		item.Name = strings.TrimSpace(item.Name)
+		if item.Name == "" {
+			item.Name = fmt.Sprintf("item_%d", idx+1)
+		}
+		if seen[item.Name] {
+			return nil, fmt.Errorf("duplicate fitness item %q", item.Name)
+		}
+		seen[item.Name] = true
+		if item.Weight <= 0 {
+			return nil, fmt.Errorf("invalid weight for fitness item %q (expected a positive number): %v", item.Name, item.Weight)
+		}
+		if item.Type == "" {
+			item.Type = "point"
+		}
+		if !fitnessItemTypes[item.Type] {
+			return nil, fmt.Errorf("unknown type %q for fitness item %q (expected point or range)", item.Type, item.Name)
+		}
+		item.Direction = strings.ToLower(strings.TrimSpace(item.Direction))
+		switch item.Direction {
+		case "":
+			item.Direction = fitnessDirectionIncrease
+		case fitnessDirectionIncrease, fitnessDirectionDecrease:
+		default:
+			return nil, fmt.Errorf("unknown direction %q for fitness item %q (expected increase or decrease)", item.Direction, item.Name)
+		}
+	}
+	return items, nil
+}
+
+// mergeFitnessItems applies items to the discovered fitness_function.items, replacing the
+// entry with the same name and appending the rest. With replace, the discovered items are
+// dropped first.
+func mergeFitnessItems(existing interface{}, items []fitnessItem, replace bool) []interface{} {
+	var merged []interface{}
+	if !replace {
+		merged, _ = existing.([]interface{})
+	}
+	for _, item := range items {
+		matched := false
+		for idx, entry := range merged {
+			if componentName(entry) == item.Name {
+				merged[idx] = item.toMap()
+				matched = true
+				break
+			}
+		}
+		if matched {
+			log.Printf("Updated fitness item %s", item.Name)
+		} else {
+			merged = append(merged, item.toMap())
+			log.Printf("Added fitness item %s", item.Name)
+		}
+	}
+	return merged
+}
+
+// validateFitnessItems checks the fitness_function.items krkn-ai will run with: every item
+// needs a known type and a positive weight, and the weights must sum to 1.
+func validateFitnessItems(items []interface{}) error {
+	var total float64
+	var names []string
+	for idx, entry := range items {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid fitness item %d (expected a map)", idx+1)
+		}
+		name := componentName(m)
+		if name == "" {
+			name = fmt.Sprintf("#%d", idx+1)
+		}
+		if t, ok := m["type"]; ok && !fitnessItemTypes[fmt.Sprint(t)] {
+			return fmt.Errorf("unknown type %q for fitness item %q (expected point or range)", t, name)
+		}
+		weight, ok := numberValue(m["weight"])
+		if !ok || weight <= 0 {
+			return fmt.Errorf("invalid weight for fitness item %q (expected a positive number): %v", name, m["weight"])
+		}
+		total += weight
+		names = append(names, name)
+	}
+	if len(items) > 0 && math.Abs(total-1) > fitnessWeightTolerance {
+		sort.Strings(names)
+		return fmt.Errorf("fitness item weights must sum to 1, got %g across %s", total, strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/osde2e-common/pkg/clients/openshift"
+	"github.com/openshift/osde2e-common/pkg/clients/prometheus"
+	"github.com/openshift/osde2e/pkg/common/config"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// fitnessQueryTimeout bounds the fitness query check, client setup included.
+const fitnessQueryTimeout = time.Minute
+
+// promQuerier runs instant PromQL queries; prometheusv1.API implements it.
+type promQuerier interface {
+	Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error)
+}
+
+// checkFitnessQuery runs query once and fails when Prometheus rejects it or it returns
+// no series, since krkn-ai scores every scenario 0 in either case instead of failing.
+func checkFitnessQuery(ctx context.Context, querier promQuerier, query string) (string, error) {
+	value, warnings, err := querier.Query(ctx, query, time.Now())
+	if err != nil {
+		var apiErr *prometheusv1.Error
+		if errors.As(err, &apiErr) && apiErr.Type == prometheusv1.ErrBadData {
+			return "", fmt.Errorf("fitness query %q is invalid: %s", query, apiErr.Msg)
+		}
+		return "", fmt.Errorf("failed to run fitness query %q: %w", query, err)
+	}
+
+	series := 0
+	switch v := value.(type) {
+	case model.Vector:
+		series = len(v)
+	case model.Matrix:
+		series = len(v)
+	case *model.Scalar, *model.String:
+		series = 1
+	}
+	if series == 0 {
+		return "", fmt.Errorf("fitness query %q returned no series; krkn-ai would score every scenario 0", query)
+	}
+
+	message := fmt.Sprintf("fitness query returned %d series", series)
+	if len(warnings) > 0 {
+		message += fmt.Sprintf(" (warnings: %s)", strings.Join(warnings, "; "))
+	}
+	return message, nil
+}
+
+// preflightFitnessQuery runs KRKN_FITNESS_QUERY against the cluster's Thanos querier,
+// reached through kubeconfigPath.
+func preflightFitnessQuery(ctx context.Context, kubeconfigPath string, templates *templateResolver) (string, error) {
+	query, err := templates.param(ctx, config.KrknAI.FitnessQuery)
+	if err != nil {
+		return "", err
+	}
+	if query = strings.TrimSpace(query); query == "" {
+		return "no fitness query set", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fitnessQueryTimeout)
+	defer cancel()
+	client, err := openshift.NewFromKubeconfig(kubeconfigPath, logr.Discard())
+	if err != nil {
+		return "", fmt.Errorf("failed to create openshift client: %w", err)
+	}
+	promClient, err := prometheus.New(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	return checkFitnessQuery(ctx, promClient.GetClient(), query)
+}
+
+// checkFitnessQuery runs the fitness query check on its own, for runs without preflight checks.
+func (k *KrknAI) checkFitnessQuery(ctx context.Context) error {
+	message, err := preflightFitnessQuery(ctx, filepath.Join(k.sharedDir(), kubeconfigFileName), k.templates())
+	if err != nil {
+		return err
+	}
+	log.Printf("Fitness query check passed: %s", message)
+	return nil
+}
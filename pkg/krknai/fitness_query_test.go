@@ -0,0 +1,89 @@
+package krknai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	prometheusapi "github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePrometheus returns a Prometheus API client whose server answers every query with
+// status and body.
+func fakePrometheus(t *testing.T, status int, body string) prometheusv1.API {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	client, err := prometheusapi.NewClient(prometheusapi.Config{Address: server.URL})
+	require.NoError(t, err)
+	return prometheusv1.NewAPI(client)
+}
+
+func TestCheckFitnessQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "series",
+			status: http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"console"},"value":[1700000000,"1"]},{"metric":{"job":"api"},"value":[1700000000,"1"]}]}}`,
+			want:   "fitness query returned 2 series",
+		},
+		{
+			name:   "scalar",
+			status: http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]},"warnings":["partial response"]}`,
+			want:   "fitness query returned 1 series (warnings: partial response)",
+		},
+		{
+			name:    "no series",
+			status:  http.StatusOK,
+			body:    `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			wantErr: `fitness query "sum(probe_success)" returned no series; krkn-ai would score every scenario 0`,
+		},
+		{
+			name:    "syntax error",
+			status:  http.StatusBadRequest,
+			body:    `{"status":"error","errorType":"bad_data","error":"1:4: parse error: unexpected end of input"}`,
+			wantErr: `fitness query "sum(probe_success)" is invalid: 1:4: parse error: unexpected end of input`,
+		},
+		{
+			name:    "unavailable",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"status":"error","errorType":"unavailable","error":"no store available"}`,
+			wantErr: `failed to run fitness query "sum(probe_success)"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := checkFitnessQuery(context.Background(), fakePrometheus(t, tt.status, tt.body), "sum(probe_success)")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, message)
+		})
+	}
+}
+
+func TestPreflightFitnessQuery(t *testing.T) {
+	setupKrknConfig(t, nil)
+	message, err := preflightFitnessQuery(context.Background(), "/nonexistent/kubeconfig", clusterTemplates("/nonexistent/kubeconfig", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "no fitness query set", message, "the cluster isn't contacted without a fitness query")
+}
@@ -0,0 +1,199 @@
+package krknai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Defaults for a generated krkn-ai config; GenerateKrknConfig uses them for anything the
+// parameters leave unset.
+const (
+	defaultGeneratedGenerations  = 5
+	defaultGeneratedPopulation   = 10
+	defaultGeneratedWaitDuration = 120
+	defaultGeneratedFitnessQuery = "sum(kube_pod_container_status_restarts_total)"
+)
+
+// defaultGeneratedScenarios are enabled when no scenario parameter selects any.
+var defaultGeneratedScenarios = []string{"pod_scenarios", "node_cpu_hog", "node_memory_hog"}
+
+// GenerateKrknConfig writes a krkn-ai config to outputPath built purely from the krkn-ai
+// parameters, for pipelines that skip discover mode. It sets the kubeconfig path, the GA
+// parameters, the fitness function, the enabled scenarios, and the health checks, filling
+// runnable defaults for anything unset. A namespace scope disables the node scenarios.
+// Without KRKN_HEALTH_CHECK, the API server's /readyz endpoint from the shared kubeconfig
+// is checked. Health check URLs are not probed.
+func (k *KrknAI) GenerateKrknConfig(outputPath string) error {
+	sharedDir := k.sharedDir()
+
+	runSize, err := parseRunSizeParams(runSizeValues())
+	if err != nil {
+		return err
+	}
+	gaParams, err := parseGAParams(gaParamValues())
+	if err != nil {
+		return err
+	}
+	fitnessIncludes, err := parseFitnessIncludeFlags(map[string]string{
+		"include_health_check_failure":       viper.GetString(config.KrknAI.IncludeHealthCheckFailure),
+		"include_health_check_response_time": viper.GetString(config.KrknAI.IncludeHealthCheckResponseTime),
+		"include_krkn_failure":               viper.GetString(config.KrknAI.IncludeKrknFailure),
+	})
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	templates := k.templates()
+	fitnessItemsParam, err := templates.param(ctx, config.KrknAI.FitnessItems)
+	if err != nil {
+		return err
+	}
+	fitnessItems, err := parseFitnessItems(fitnessItemsParam)
+	if err != nil {
+		return err
+	}
+	scenarios, err := generatedScenarios()
+	if err != nil {
+		return err
+	}
+	scenarioParamsParam, err := templates.param(ctx, config.KrknAI.ScenarioParams)
+	if err != nil {
+		return err
+	}
+	scenarioParams, err := parseScenarioParams(scenarioParamsParam)
+	if err != nil {
+		return err
+	}
+	scope, err := scopeFromConfig()
+	if err != nil {
+		return err
+	}
+
+	generations := defaultGeneratedGenerations
+	if n, ok := runSize["generations"]; ok {
+		generations = n
+	}
+	population := defaultGeneratedPopulation
+	if n, ok := runSize["population_size"]; ok {
+		population = n
+	}
+	if err := validateMinGenerations(generations, viper.GetInt(config.KrknAI.MinGenerations), viper.GetBool(config.KrknAI.StrictValidation)); err != nil {
+		return err
+	}
+
+	fitnessQuery, err := templates.param(ctx, config.KrknAI.FitnessQuery)
+	if err != nil {
+		return err
+	}
+	if fitnessQuery == "" {
+		fitnessQuery = defaultGeneratedFitnessQuery
+	}
+	fitness := map[string]interface{}{"query": fitnessQuery, "type": "point"}
+	for key, enabled := range fitnessIncludes {
+		fitness[key] = enabled
+	}
+	if len(fitnessItems) > 0 {
+		items := mergeFitnessItems(nil, fitnessItems, true)
+		if err := validateFitnessItems(items); err != nil {
+			return err
+		}
+		fitness["items"] = items
+	}
+
+	apps, err := generatedHealthChecks(ctx, filepath.Join(sharedDir, kubeconfigFileName), templates)
+	if err != nil {
+		return err
+	}
+
+	cfg := map[string]interface{}{
+		"kubeconfig_file_path": fmt.Sprintf("%s/%s", containerMountPath, kubeconfigFileName),
+		"generations":          generations,
+		"population_size":      population,
+		"wait_duration":        defaultGeneratedWaitDuration,
+		"fitness_function":     fitness,
+		"health_checks":        map[string]interface{}{"applications": apps},
+		"scenario":             scenarios,
+	}
+	for key, value := range gaParams {
+		cfg[key] = value
+	}
+	applyScenarioParams(cfg, scenarioParams)
+	scope.apply(cfg)
+
+	if err := validateMergedConfig(cfg, false); err != nil {
+		return err
+	}
+
+	content, err := marshalPreservingLayout(nil, cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write generated Krkn-ai config: %w", err)
+	}
+	log.Printf("Generated Krkn-ai config: %s", outputPath)
+	return nil
+}
+
+// generatedScenarios returns the scenario section: the KRKN_SCENARIOS list, then the
+// generic entries and dedicated toggles, falling back to defaultGeneratedScenarios.
+func generatedScenarios() (map[string]interface{}, error) {
+	enabled := make(map[string]bool)
+	for _, name := range strings.Split(viper.GetString(config.KrknAI.Scenarios), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			enabled[name] = true
+		}
+	}
+	generic, err := parseGenericScenarios(viper.GetString(config.KrknAI.GenericScenarios))
+	if err != nil {
+		return nil, err
+	}
+	toggles, err := parseScenarioToggles(scenarioToggleValues())
+	if err != nil {
+		return nil, err
+	}
+	for name, on := range generic {
+		enabled[name] = on
+	}
+	for name, on := range toggles {
+		enabled[name] = on
+	}
+
+	if len(enabled) == 0 {
+		for _, name := range defaultGeneratedScenarios {
+			enabled[name] = true
+		}
+	}
+
+	scenarios := make(map[string]interface{}, len(enabled))
+	for name, on := range enabled {
+		scenarios[name] = map[string]interface{}{"enable": on}
+	}
+	return scenarios, nil
+}
+
+// generatedHealthChecks returns the KRKN_HEALTH_CHECK and KRKN_HEALTH_CHECK_APPS
+// applications, or a check of the API server's /readyz endpoint read from kubeconfigPath,
+// with healthCheckDefaults filled in.
+func generatedHealthChecks(ctx context.Context, kubeconfigPath string, templates *templateResolver) ([]interface{}, error) {
+	overrides, err := configuredHealthChecks(ctx, templates)
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides) == 0 {
+		restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig for the default health check (set KRKN_HEALTH_CHECK instead): %w", err)
+		}
+		overrides = []map[string]interface{}{{"name": "kube-apiserver", "url": strings.TrimSuffix(restConfig.Host, "/") + "/readyz"}}
+	}
+	return mergeHealthCheckApps(nil, overrides), nil
+}
@@ -0,0 +1,126 @@
+package krknai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// discoverHealthChecksFromKubeconfig connects to the cluster in kubeconfigPath and
+// discovers health check applications in namespaces; see discoverHealthChecks.
+func discoverHealthChecksFromKubeconfig(ctx context.Context, kubeconfigPath string, namespaces []string) ([]map[string]interface{}, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig for health check discovery: %w", err)
+	}
+	routes, err := routeclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create route client: %w", err)
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kube client: %w", err)
+	}
+	return discoverHealthChecks(ctx, routes, kube, namespaces)
+}
+
+// discoverHealthChecks returns a health check application for every Route and Ingress
+// host in namespaces, named namespace/name. Routes and rules with TLS use https.
+// Ingresses that OpenShift also exposes as a Route are listed once, and wildcard
+// hosts are skipped. Clusters without the Route API only contribute Ingresses.
+func discoverHealthChecks(ctx context.Context, routes routeclient.Interface, kube kubernetes.Interface, namespaces []string) ([]map[string]interface{}, error) {
+	var apps []map[string]interface{}
+	seen := map[string]bool{}
+	add := func(name, scheme, host, path string) {
+		if host == "" || strings.HasPrefix(host, "*") {
+			return
+		}
+		if path == "" {
+			path = "/"
+		}
+		url := scheme + "://" + host + path
+		if seen[url] {
+			return
+		}
+		seen[url] = true
+		apps = append(apps, map[string]interface{}{"name": name, "url": url})
+	}
+
+	for _, namespace := range namespaces {
+		routeList, err := routes.RouteV1().Routes(namespace).List(ctx, metav1.ListOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			log.Printf("Route API not available, discovering health checks from Ingresses only in %s", namespace)
+		case err != nil:
+			return nil, fmt.Errorf("failed to list routes in %s: %w", namespace, err)
+		default:
+			for _, route := range routeList.Items {
+				host := route.Spec.Host
+				if host == "" && len(route.Status.Ingress) > 0 {
+					host = route.Status.Ingress[0].Host
+				}
+				scheme := "http"
+				if route.Spec.TLS != nil {
+					scheme = "https"
+				}
+				add(namespace+"/"+route.Name, scheme, host, route.Spec.Path)
+			}
+		}
+
+		ingresses, err := kube.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ingresses in %s: %w", namespace, err)
+		}
+		for _, ingress := range ingresses.Items {
+			tlsHosts := map[string]bool{}
+			for _, tls := range ingress.Spec.TLS {
+				for _, host := range tls.Hosts {
+					tlsHosts[host] = true
+				}
+			}
+			for _, rule := range ingress.Spec.Rules {
+				scheme := "http"
+				if tlsHosts[rule.Host] {
+					scheme = "https"
+				}
+				path := ""
+				if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+					path = rule.HTTP.Paths[0].Path
+				}
+				add(namespace+"/"+ingress.Name, scheme, rule.Host, path)
+			}
+		}
+	}
+	sort.SliceStable(apps, func(i, j int) bool { return apps[i]["name"].(string) < apps[j]["name"].(string) })
+	return apps, nil
+}
+
+// reachableHealthChecks probes discovered applications and keeps those that answer with
+// a 2xx status, recording that status as their status_code. Unreachable ones are logged
+// and dropped rather than failing the run, since discovery can't tell which routes serve
+// a health endpoint.
+func reachableHealthChecks(ctx context.Context, apps []map[string]interface{}, concurrency int, deadline time.Duration) []map[string]interface{} {
+	var reachable []map[string]interface{}
+	for i, probe := range probeHealthChecks(ctx, apps, concurrency, deadline) {
+		if probe == nil {
+			continue
+		}
+		if !probe.passed {
+			log.Printf("Skipping discovered health check %s (%s): %s", probe.name, probe.url, probe.detail)
+			continue
+		}
+		apps[i]["status_code"] = probe.status
+		reachable = append(reachable, apps[i])
+	}
+	log.Printf("Discovered %d reachable health check endpoint(s) of %d", len(reachable), len(apps))
+	return reachable
+}
@@ -0,0 +1,102 @@
+package krknai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routefake "github.com/openshift/client-go/route/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDiscoverHealthChecks(t *testing.T) {
+	routes := routefake.NewSimpleClientset(
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"},
+			Spec:       routev1.RouteSpec{Host: "console.apps.example.com", TLS: &routev1.TLSConfig{}},
+		},
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "robot-shop"},
+			Spec:       routev1.RouteSpec{Path: "/health"},
+			Status:     routev1.RouteStatus{Ingress: []routev1.RouteIngress{{Host: "shop.apps.example.com"}}},
+		},
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: "wildcard", Namespace: "robot-shop"},
+			Spec:       routev1.RouteSpec{Host: "*.apps.example.com"},
+		},
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "not-selected"},
+			Spec:       routev1.RouteSpec{Host: "other.apps.example.com"},
+		},
+	)
+	kube := kubefake.NewSimpleClientset(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "robot-shop"},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{Hosts: []string{"web.example.com"}}},
+			Rules: []networkingv1.IngressRule{
+				{Host: "web.example.com"},
+				// Also exposed by the shop route
+				{Host: "shop.apps.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{Path: "/health"}},
+				}}},
+			},
+		},
+	})
+
+	apps, err := discoverHealthChecks(context.Background(), routes, kube, []string{"openshift-console", "robot-shop"})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "openshift-console/console", "url": "https://console.apps.example.com/"},
+		{"name": "robot-shop/shop", "url": "http://shop.apps.example.com/health"},
+		{"name": "robot-shop/web", "url": "https://web.example.com/"},
+	}, apps)
+}
+
+func TestDiscoverHealthChecks_NoRouteAPI(t *testing.T) {
+	routes := routefake.NewSimpleClientset()
+	routes.PrependReactor("list", "routes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "route.openshift.io", Resource: "routes"}, "")
+	})
+	kube := kubefake.NewSimpleClientset(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+		Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "web.example.com"}}},
+	})
+
+	apps, err := discoverHealthChecks(context.Background(), routes, kube, []string{"apps"})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"name": "apps/web", "url": "http://web.example.com/"}}, apps)
+}
+
+func TestReachableHealthChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	apps := reachableHealthChecks(context.Background(), []map[string]interface{}{
+		{"name": "ok", "url": server.URL + "/ok"},
+		{"name": "forbidden", "url": server.URL + "/oauth"},
+		{"name": "empty", "url": server.URL + "/empty"},
+	}, 2, 0)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "ok", "url": server.URL + "/ok", "status_code": 200},
+		{"name": "empty", "url": server.URL + "/empty", "status_code": 204},
+	}, apps)
+}
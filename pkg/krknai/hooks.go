@@ -0,0 +1,278 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// hooksDirName holds each hook's output directory and the hook results in the results directory.
+	hooksDirName = "hooks"
+
+	// hookResultsFileName lists the outcome of every hook that ran.
+	hookResultsFileName = "results.yaml"
+
+	// hookOutputFileName is where a command hook's output is written in its output directory.
+	hookOutputFileName = "output.log"
+
+	// defaultHookTimeout applies to hooks without a timeout of their own.
+	defaultHookTimeout = 10 * time.Minute
+
+	hookPhasePre         = "pre"
+	hookPhasePost        = "post"
+	hookPhaseRemediation = "remediation"
+)
+
+// HookContext is what a hook gets to work with.
+type HookContext struct {
+	Phase          string // pre, post, or remediation
+	KubeconfigPath string
+	SharedDir      string
+	OutputDir      string // The hook's own directory under the results directory
+	RunErr         error  // Post and remediation hooks only: the krkn-ai run's error, nil when it succeeded
+}
+
+// HookFunc is a Go function a hook can run.
+type HookFunc func(ctx context.Context, hc HookContext) error
+
+var hookRegistry = struct {
+	sync.RWMutex
+	funcs map[string]HookFunc
+}{funcs: map[string]HookFunc{}}
+
+// RegisterHook registers fn under name so pre- and post-run hooks can run it with func: name.
+func RegisterHook(name string, fn HookFunc) {
+	hookRegistry.Lock()
+	defer hookRegistry.Unlock()
+	if _, ok := hookRegistry.funcs[name]; ok {
+		panic(fmt.Sprintf("Duplicate krkn-ai hook name %s!", name))
+	}
+	hookRegistry.funcs[name] = fn
+}
+
+func registeredHook(name string) (HookFunc, bool) {
+	hookRegistry.RLock()
+	defer hookRegistry.RUnlock()
+	fn, ok := hookRegistry.funcs[name]
+	return fn, ok
+}
+
+// runHook is one configured pre- or post-run step: a shell command or a registered function.
+type runHook struct {
+	Name            string `yaml:"name"`
+	Command         string `yaml:"command"`           // Run with sh -c in the hook's output directory
+	Func            string `yaml:"func"`              // Name of a function registered with RegisterHook
+	Timeout         string `yaml:"timeout"`           // Go duration; defaults to 10m
+	ContinueOnError bool   `yaml:"continue_on_error"` // Record a failure without failing the run
+
+	timeout time.Duration
+}
+
+// hookResult is the outcome of one hook, as written to hooks/results.yaml.
+type hookResult struct {
+	Phase     string  `yaml:"phase"`
+	Name      string  `yaml:"name"`
+	Passed    bool    `yaml:"passed"`
+	Duration  float64 `yaml:"duration_seconds"`
+	OutputDir string  `yaml:"output_dir"` // Relative to the results directory
+	Error     string  `yaml:"error,omitempty"`
+}
+
+// parseHooks parses a YAML or JSON list of hooks, e.g.
+// [{name: sample-app, command: oc apply -f app.yaml}, {name: must-gather, func: must-gather, timeout: 30m}].
+// Each hook needs a unique name and exactly one of command and func; functions must be registered.
+func parseHooks(input string) ([]runHook, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+	var hooks []runHook
+	if err := yaml.Unmarshal([]byte(input), &hooks); err != nil {
+		return nil, fmt.Errorf("invalid hooks (expected a YAML or JSON list of name and command or func): %w", err)
+	}
+	seen := make(map[string]bool, len(hooks))
+	for idx := range hooks {
+		hook := &hooks[idx]
+		hook.Name = strings.TrimSpace(hook.Name)
+		hook.Func = strings.TrimSpace(hook.Func)
+		if !dirNamePattern.MatchString(hook.Name) {
+			return nil, fmt.Errorf("invalid hook %d name %q (expected letters, digits, '.', '_', or '-')", idx+1, hook.Name)
+		}
+		if seen[hook.Name] {
+			return nil, fmt.Errorf("duplicate hook %q", hook.Name)
+		}
+		seen[hook.Name] = true
+		if (strings.TrimSpace(hook.Command) == "") == (hook.Func == "") {
+			return nil, fmt.Errorf("invalid hook %q (exactly one of command and func required)", hook.Name)
+		}
+		if hook.Func != "" {
+			if _, ok := registeredHook(hook.Func); !ok {
+				return nil, fmt.Errorf("invalid hook %q (no function registered as %q)", hook.Name, hook.Func)
+			}
+		}
+		hook.timeout = defaultHookTimeout
+		if hook.Timeout != "" {
+			timeout, err := time.ParseDuration(hook.Timeout)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("invalid hook %q timeout %q (expected a positive duration, e.g. 5m)", hook.Name, hook.Timeout)
+			}
+			hook.timeout = timeout
+		}
+	}
+	return hooks, nil
+}
+
+// runWithHooks runs the pre-run hooks, run, and then the post-run hooks, which run even
+// when run fails so they can collect diagnostics. A failed pre-run hook skips run, and a
+// run the abort guardrails stopped gets the remediation hooks before the post-run hooks.
+// Hook outcomes are written to hooks/results.yaml in the results directory; a hook failure
+// fails the execution unless the hook continues on error, and run's error takes precedence.
+func (k *KrknAI) runWithHooks(ctx context.Context, run func() error) error {
+	preHooks, err := parseHooks(viper.GetString(config.KrknAI.PreRunHooks))
+	if err != nil {
+		return fmt.Errorf("invalid pre-run hooks: %w", err)
+	}
+	postHooks, err := parseHooks(viper.GetString(config.KrknAI.PostRunHooks))
+	if err != nil {
+		return fmt.Errorf("invalid post-run hooks: %w", err)
+	}
+	remediationHooks, err := parseHooks(viper.GetString(config.KrknAI.AbortRemediationHooks))
+	if err != nil {
+		return fmt.Errorf("invalid abort remediation hooks: %w", err)
+	}
+	if len(preHooks) == 0 && len(postHooks) == 0 && len(remediationHooks) == 0 {
+		return run()
+	}
+
+	var results []hookResult
+	preResults, hookErr := k.runHooks(ctx, hookPhasePre, preHooks, nil)
+	results = append(results, preResults...)
+	var runErr error
+	if hookErr == nil {
+		runErr = run()
+	}
+	if isAborted(runErr) && len(remediationHooks) > 0 {
+		remediationResults, remediationErr := k.runHooks(ctx, hookPhaseRemediation, remediationHooks, runErr)
+		results = append(results, remediationResults...)
+		hookErr = errors.Join(hookErr, remediationErr)
+	}
+	postResults, postErr := k.runHooks(ctx, hookPhasePost, postHooks, runErr)
+	results = append(results, postResults...)
+	hookErr = errors.Join(hookErr, postErr)
+
+	if err := k.writeHookResults(results); err != nil {
+		log.Printf("Failed to write hook results: %v", err)
+	}
+	if runErr != nil {
+		return runErr
+	}
+	return hookErr
+}
+
+// runHooks runs hooks in order. A pre-run hook failure stops the remaining pre-run hooks;
+// post-run hooks all run.
+func (k *KrknAI) runHooks(ctx context.Context, phase string, hooks []runHook, runErr error) ([]hookResult, error) {
+	var results []hookResult
+	var errs []error
+	for i, hook := range hooks {
+		outputDir := filepath.Join(hooksDirName, fmt.Sprintf("%s-%02d-%s", phase, i+1, hook.Name))
+		hc := HookContext{
+			Phase:          phase,
+			KubeconfigPath: filepath.Join(k.sharedDir(), kubeconfigFileName),
+			SharedDir:      k.sharedDir(),
+			OutputDir:      filepath.Join(k.reportDir(), outputDir),
+			RunErr:         runErr,
+		}
+
+		log.Printf("Running %s-run hook %s", phase, hook.Name)
+		start := time.Now()
+		err := runHookStep(ctx, hook, hc)
+		result := hookResult{Phase: phase, Name: hook.Name, Passed: err == nil, Duration: time.Since(start).Seconds(), OutputDir: outputDir}
+		if err == nil {
+			results = append(results, result)
+			log.Printf("Hook %s (%s-run) passed in %s", hook.Name, phase, time.Since(start).Round(time.Millisecond))
+			continue
+		}
+
+		result.Error = err.Error()
+		results = append(results, result)
+		if hook.ContinueOnError {
+			log.Printf("Hook %s (%s-run) failed, continuing: %v", hook.Name, phase, err)
+			continue
+		}
+		log.Printf("Hook %s (%s-run) failed: %v", hook.Name, phase, err)
+		errs = append(errs, fmt.Errorf("%s-run hook %s failed: %w", phase, hook.Name, err))
+		if phase == hookPhasePre {
+			break
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// runHookStep runs one hook within its timeout.
+func runHookStep(ctx context.Context, hook runHook, hc HookContext) error {
+	if err := os.MkdirAll(hc.OutputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create hook output directory: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, hook.timeout)
+	defer cancel()
+
+	if hook.Func != "" {
+		fn, ok := registeredHook(hook.Func)
+		if !ok {
+			return fmt.Errorf("no function registered as %q", hook.Func)
+		}
+		return fn(ctx, hc)
+	}
+
+	output, err := os.Create(filepath.Join(hc.OutputDir, hookOutputFileName))
+	if err != nil {
+		return fmt.Errorf("failed to create hook output file: %w", err)
+	}
+	defer output.Close()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.Dir = hc.OutputDir
+	cmd.Env = append(os.Environ(),
+		"KUBECONFIG="+hc.KubeconfigPath,
+		"KRKN_HOOK_PHASE="+hc.Phase,
+		"KRKN_HOOK_OUTPUT_DIR="+hc.OutputDir,
+		"KRKN_SHARED_DIR="+hc.SharedDir,
+	)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", hook.timeout)
+		}
+		return fmt.Errorf("command failed: %w", err)
+	}
+	return nil
+}
+
+// writeHookResults writes the hook outcomes to hooks/results.yaml in the results directory.
+func (k *KrknAI) writeHookResults(results []hookResult) error {
+	dir := filepath.Join(k.reportDir(), hooksDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	content, err := yaml.Marshal(map[string]any{"hooks": results})
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook results: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, hookResultsFileName), content, 0o644); err != nil {
+		return fmt.Errorf("failed to write hook results: %w", err)
+	}
+	return nil
+}
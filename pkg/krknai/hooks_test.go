@@ -0,0 +1,191 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// registerTestHook registers fn for the duration of the test.
+func registerTestHook(t *testing.T, name string, fn HookFunc) {
+	t.Helper()
+
+	RegisterHook(name, fn)
+	t.Cleanup(func() {
+		hookRegistry.Lock()
+		defer hookRegistry.Unlock()
+		delete(hookRegistry.funcs, name)
+	})
+}
+
+// readHookResults parses hooks/results.yaml in reportDir.
+func readHookResults(t *testing.T, reportDir string) []hookResult {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(reportDir, hooksDirName, hookResultsFileName))
+	require.NoError(t, err)
+	var results struct {
+		Hooks []hookResult `yaml:"hooks"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &results))
+	for i := range results.Hooks {
+		results.Hooks[i].Duration = 0
+	}
+	return results.Hooks
+}
+
+func TestParseHooks(t *testing.T) {
+	registerTestHook(t, "test-must-gather", func(context.Context, HookContext) error { return nil })
+
+	hooks, err := parseHooks(`[{"name": "sample-app", "command": "oc apply -f app.yaml"}, {"name": "must-gather", "func": "test-must-gather", "timeout": "30m", "continue_on_error": true}]`)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	assert.Equal(t, "oc apply -f app.yaml", hooks[0].Command)
+	assert.Equal(t, defaultHookTimeout, hooks[0].timeout)
+	assert.Equal(t, "test-must-gather", hooks[1].Func)
+	assert.Equal(t, 30*60.0, hooks[1].timeout.Seconds())
+	assert.True(t, hooks[1].ContinueOnError)
+
+	hooks, err = parseHooks(" ")
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+
+	for input, want := range map[string]string{
+		"name: app":         "invalid hooks",
+		"[{command: true}]": `invalid hook 1 name ""`,
+		"[{name: app, command: a}, {name: app, command: b}]": `duplicate hook "app"`,
+		"[{name: app}]": "exactly one of command and func required",
+		"[{name: app, command: a, func: test-must-gather}]": "exactly one of command and func required",
+		"[{name: app, func: missing}]":                      `no function registered as "missing"`,
+		"[{name: app, command: a, timeout: soon}]":          `timeout "soon"`,
+	} {
+		_, err := parseHooks(input)
+		assert.ErrorContains(t, err, want, input)
+	}
+}
+
+func TestRunWithHooks(t *testing.T) {
+	var postHook HookContext
+	registerTestHook(t, "test-record", func(_ context.Context, hc HookContext) error {
+		postHook = hc
+		return os.WriteFile(filepath.Join(hc.OutputDir, "collected"), []byte("diagnostics"), 0o644)
+	})
+
+	t.Run("pre and post hooks around the run", func(t *testing.T) {
+		reportDir := t.TempDir()
+		yamlFile := setupKrknConfig(t, map[string]any{
+			config.ReportDir:           reportDir,
+			config.KrknAI.PreRunHooks:  `[{name: workload, command: 'echo "$KRKN_HOOK_PHASE $KUBECONFIG" > deployed'}]`,
+			config.KrknAI.PostRunHooks: "[{name: gather, func: test-record}]",
+		})
+		ran := false
+		err := (&KrknAI{}).runWithHooks(context.Background(), func() error {
+			_, statErr := os.Stat(filepath.Join(reportDir, hooksDirName, "pre-01-workload", "deployed"))
+			assert.NoError(t, statErr, "pre-run hooks run before the run")
+			ran = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, ran)
+
+		deployed, err := os.ReadFile(filepath.Join(reportDir, hooksDirName, "pre-01-workload", "deployed"))
+		require.NoError(t, err)
+		assert.Equal(t, "pre "+filepath.Join(filepath.Dir(yamlFile), kubeconfigFileName)+"\n", string(deployed))
+		assert.FileExists(t, filepath.Join(reportDir, hooksDirName, "pre-01-workload", hookOutputFileName))
+		assert.FileExists(t, filepath.Join(reportDir, hooksDirName, "post-01-gather", "collected"))
+		assert.NoError(t, postHook.RunErr)
+
+		assert.Equal(t, []hookResult{
+			{Phase: "pre", Name: "workload", Passed: true, OutputDir: filepath.Join(hooksDirName, "pre-01-workload")},
+			{Phase: "post", Name: "gather", Passed: true, OutputDir: filepath.Join(hooksDirName, "post-01-gather")},
+		}, readHookResults(t, reportDir))
+	})
+
+	t.Run("failed pre-run hook skips the run", func(t *testing.T) {
+		reportDir := t.TempDir()
+		setupKrknConfig(t, map[string]any{
+			config.ReportDir:           reportDir,
+			config.KrknAI.PreRunHooks:  "[{name: broken, command: 'echo no cluster; exit 3'}, {name: skipped, command: 'true'}]",
+			config.KrknAI.PostRunHooks: "[{name: gather, func: test-record}]",
+		})
+		ran := false
+		err := (&KrknAI{}).runWithHooks(context.Background(), func() error {
+			ran = true
+			return nil
+		})
+		assert.ErrorContains(t, err, "pre-run hook broken failed: command failed: exit status 3")
+		assert.False(t, ran)
+
+		output, readErr := os.ReadFile(filepath.Join(reportDir, hooksDirName, "pre-01-broken", hookOutputFileName))
+		require.NoError(t, readErr)
+		assert.Equal(t, "no cluster\n", string(output))
+		results := readHookResults(t, reportDir)
+		require.Len(t, results, 2, "post-run hooks still run")
+		assert.Equal(t, "broken", results[0].Name)
+		assert.False(t, results[0].Passed)
+		assert.Equal(t, "gather", results[1].Name)
+	})
+
+	t.Run("failed run", func(t *testing.T) {
+		reportDir := t.TempDir()
+		setupKrknConfig(t, map[string]any{
+			config.ReportDir:           reportDir,
+			config.KrknAI.PostRunHooks: "[{name: slow, command: 'sleep 5', timeout: 50ms, continue_on_error: true}, {name: gather, func: test-record}]",
+		})
+		runErr := errors.New("run mode failed")
+		err := (&KrknAI{}).runWithHooks(context.Background(), func() error { return runErr })
+		assert.ErrorIs(t, err, runErr)
+		assert.ErrorIs(t, postHook.RunErr, runErr, "post-run hooks see the run's error")
+
+		results := readHookResults(t, reportDir)
+		require.Len(t, results, 2)
+		assert.Equal(t, "timed out after 50ms", results[0].Error, "a hook continuing on error doesn't stop the others")
+		assert.True(t, results[1].Passed)
+	})
+
+	t.Run("aborted run is remediated", func(t *testing.T) {
+		reportDir := t.TempDir()
+		setupKrknConfig(t, map[string]any{
+			config.ReportDir:                    reportDir,
+			config.KrknAI.AbortRemediationHooks: "[{name: rollback, command: 'echo $KRKN_HOOK_PHASE > rolled-back'}]",
+			config.KrknAI.PostRunHooks:          "[{name: gather, func: test-record}]",
+		})
+		runErr := fmt.Errorf("run mode failed: %w", &abortError{reason: "abort query held for 10m0s"})
+		err := (&KrknAI{}).runWithHooks(context.Background(), func() error { return runErr })
+		assert.ErrorIs(t, err, runErr)
+
+		rolledBack, readErr := os.ReadFile(filepath.Join(reportDir, hooksDirName, "remediation-01-rollback", "rolled-back"))
+		require.NoError(t, readErr)
+		assert.Equal(t, "remediation\n", string(rolledBack))
+		assert.Equal(t, []hookResult{
+			{Phase: "remediation", Name: "rollback", Passed: true, OutputDir: filepath.Join(hooksDirName, "remediation-01-rollback")},
+			{Phase: "post", Name: "gather", Passed: true, OutputDir: filepath.Join(hooksDirName, "post-01-gather")},
+		}, readHookResults(t, reportDir), "remediation runs before the post-run hooks")
+	})
+
+	t.Run("failed run isn't remediated", func(t *testing.T) {
+		reportDir := t.TempDir()
+		setupKrknConfig(t, map[string]any{
+			config.ReportDir:                    reportDir,
+			config.KrknAI.AbortRemediationHooks: "[{name: rollback, command: 'true'}]",
+		})
+		err := (&KrknAI{}).runWithHooks(context.Background(), func() error { return errors.New("run mode failed") })
+		assert.EqualError(t, err, "run mode failed")
+		assert.Empty(t, readHookResults(t, reportDir))
+	})
+
+	t.Run("no hooks", func(t *testing.T) {
+		reportDir := t.TempDir()
+		setupKrknConfig(t, map[string]any{config.ReportDir: reportDir})
+		require.NoError(t, (&KrknAI{}).runWithHooks(context.Background(), func() error { return nil }))
+		assert.NoDirExists(t, filepath.Join(reportDir, hooksDirName))
+	})
+}
@@ -0,0 +1,220 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// krknScenarioConfigFileName is the krkn config generated for krkn mode, written to the
+	// shared directory and copied, redacted, to the results' config directory.
+	krknScenarioConfigFileName = "krkn-config.yaml"
+
+	// krknReportFileName is the report krkn writes to the results' generations directory.
+	krknReportFileName = "kraken.report"
+
+	// krknScenarioDirName holds the scenario files copied into the shared directory.
+	krknScenarioDirName = "scenarios"
+)
+
+// krknScenarioTypePattern matches krkn chaos_scenarios keys, e.g. pod_disruption_scenarios.
+var krknScenarioTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// krknScenario is one entry of the krkn mode scenario list: a scenario file on the host and
+// the krkn scenario type that runs it.
+type krknScenario struct {
+	Type string `yaml:"type"`
+	File string `yaml:"file"`
+}
+
+// parseScenarioList parses the krkn mode scenario list, a YAML or JSON list such as
+// [{type: pod_disruption_scenarios, file: /scenarios/etcd.yml}]. Scenario files must exist.
+func parseScenarioList(input string) ([]krknScenario, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("krkn mode needs a scenario list (KRKN_SCENARIO_LIST)")
+	}
+	var scenarios []krknScenario
+	if err := yaml.Unmarshal([]byte(input), &scenarios); err != nil {
+		return nil, fmt.Errorf("invalid scenario list (expected a YAML or JSON list of type and file): %w", err)
+	}
+	if len(scenarios) == 0 {
+		return nil, errors.New("scenario list is empty")
+	}
+	for idx := range scenarios {
+		scenario := &scenarios[idx]
+		scenario.Type = strings.TrimSpace(scenario.Type)
+		scenario.File = strings.TrimSpace(scenario.File)
+		if !krknScenarioTypePattern.MatchString(scenario.Type) {
+			return nil, fmt.Errorf("invalid scenario %d type %q (expected a krkn scenario type, e.g. pod_disruption_scenarios)", idx+1, scenario.Type)
+		}
+		if scenario.File == "" {
+			return nil, fmt.Errorf("invalid scenario %d (file required)", idx+1)
+		}
+		if info, err := os.Stat(scenario.File); err != nil {
+			return nil, fmt.Errorf("invalid scenario %d: %w", idx+1, err)
+		} else if info.IsDir() {
+			return nil, fmt.Errorf("invalid scenario %d: %s is a directory", idx+1, scenario.File)
+		}
+	}
+	return scenarios, nil
+}
+
+// buildKrknScenarioConfig returns a krkn config that runs files, the container paths of
+// the scenarios, once and in order. Consecutive scenarios of one type share a
+// chaos_scenarios entry. Health check applications are watched without failing the run,
+// like krkn-ai does.
+func buildKrknScenarioConfig(scenarios []krknScenario, files []string, healthChecks []map[string]interface{}) map[string]interface{} {
+	var chaosScenarios []interface{}
+	for i, scenario := range scenarios {
+		if i > 0 && scenarios[i-1].Type == scenario.Type {
+			entry := chaosScenarios[len(chaosScenarios)-1].(map[string]interface{})
+			entry[scenario.Type] = append(entry[scenario.Type].([]interface{}), files[i])
+			continue
+		}
+		chaosScenarios = append(chaosScenarios, map[string]interface{}{scenario.Type: []interface{}{files[i]}})
+	}
+
+	cfg := map[string]interface{}{
+		"kraken": map[string]interface{}{
+			"kubeconfig_path": path.Join(containerMountPath, kubeconfigFileName),
+			"exit_on_failure": false,
+			"chaos_scenarios": chaosScenarios,
+		},
+		"tunings": map[string]interface{}{
+			"wait_duration": 60,
+			"iterations":    1,
+			"daemon_mode":   false,
+		},
+	}
+	if len(healthChecks) > 0 {
+		checks := make([]interface{}, 0, len(healthChecks))
+		for _, app := range healthChecks {
+			checks = append(checks, map[string]interface{}{
+				"url":             app["url"],
+				"bearer_token":    nil,
+				"auth":            nil,
+				"exit_on_failure": false,
+			})
+		}
+		cfg["health_checks"] = map[string]interface{}{"interval": 2, "config": checks}
+	}
+	return cfg
+}
+
+// writeKrknScenarioConfig copies the scenario files into the shared directory and writes
+// the krkn config running them, with the krkn-ai overlay and overrides merged in, to the
+// shared directory.
+func (k *KrknAI) writeKrknScenarioConfig(ctx context.Context) error {
+	sharedDir := k.sharedDir()
+	scenarios, err := parseScenarioList(viper.GetString(config.KrknAI.ScenarioList))
+	if err != nil {
+		return err
+	}
+	scope, err := scopeFromConfig()
+	if err != nil {
+		return err
+	}
+	if err := scope.checkKrknScenarios(scenarios); err != nil {
+		return err
+	}
+	overlayPath := viper.GetString(config.KrknAI.OverlayPath)
+	overlay, err := readOverlay(overlayPath)
+	if err != nil {
+		return err
+	}
+	templates := k.templates()
+	if err := templates.expandConfig(ctx, overlay); err != nil {
+		return fmt.Errorf("overlay %s: %w", overlayPath, err)
+	}
+	overridesParam, err := templates.param(ctx, config.KrknAI.Overrides)
+	if err != nil {
+		return err
+	}
+	overrides, err := parseOverrides(overridesParam)
+	if err != nil {
+		return err
+	}
+	healthCheckApps, err := resolveHealthChecks(ctx, filepath.Join(sharedDir, kubeconfigFileName), templates)
+	if err != nil {
+		return err
+	}
+
+	scenarioDir := filepath.Join(sharedDir, krknScenarioDirName)
+	if err := os.MkdirAll(scenarioDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create scenario directory: %w", err)
+	}
+	// Numbered names keep scenario files with the same base name apart
+	files := make([]string, len(scenarios))
+	for i, scenario := range scenarios {
+		data, err := os.ReadFile(scenario.File)
+		if err != nil {
+			return fmt.Errorf("failed to read scenario %s: %w", scenario.File, err)
+		}
+		name := fmt.Sprintf("%02d-%s", i+1, filepath.Base(scenario.File))
+		if err := os.WriteFile(filepath.Join(scenarioDir, name), data, 0o644); err != nil {
+			return fmt.Errorf("failed to copy scenario %s: %w", scenario.File, err)
+		}
+		files[i] = path.Join(containerMountPath, krknScenarioDirName, name)
+	}
+
+	cfg := buildKrknScenarioConfig(scenarios, files, healthCheckApps)
+	if fields := applyOverlay(cfg, overlay); len(fields) > 0 {
+		log.Printf("Applied overlay %s to %d krkn config field(s)", overlayPath, len(fields))
+	}
+	if fields := applyOverlay(cfg, overrides); len(fields) > 0 {
+		log.Printf("Applied %d krkn config override(s)", len(fields))
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal krkn config: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sharedDir, krknScenarioConfigFileName), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write krkn config: %w", err)
+	}
+	log.Printf("Krkn config with %d scenario(s) written to %s", len(scenarios), filepath.Join(sharedDir, krknScenarioConfigFileName))
+	return nil
+}
+
+// runScenarioList is krkn mode: it writes the krkn config for the scenario list and runs
+// it with plain krkn, with the same hooks, kubeconfig refresh, readiness gate, deadline and
+// retries as run mode.
+func (k *KrknAI) runScenarioList(ctx context.Context) error {
+	log.Println("Krkn mode, generating krkn config from the scenario list")
+	if err := k.writeKrknScenarioConfig(ctx); err != nil {
+		return fmt.Errorf("failed to generate krkn config: %w", err)
+	}
+
+	if viper.GetBool(config.KrknAI.ConfigDryRun) {
+		log.Println("Krkn config dry run finished, skipping the krkn run")
+		return nil
+	}
+
+	return k.withKubeconfigRotation(ctx, config.KrknAIModeKrkn, func() error {
+		return k.runWithHooks(ctx, func() error {
+			if viper.GetBool(config.KrknAI.ReadinessGate) {
+				log.Println("Checking cluster readiness before the krkn run")
+				if err := k.waitForClusterReady(ctx); err != nil {
+					return err
+				}
+			}
+
+			log.Println("Krkn run")
+			if err := k.runWithDeadline(ctx, config.KrknAIModeKrkn); err != nil {
+				return fmt.Errorf("krkn run failed: %w", err)
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package krknai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeScenarioFiles writes empty scenario files named names and returns their paths.
+func writeScenarioFiles(t *testing.T, names ...string) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("# "+name), 0o644))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestParseScenarioList(t *testing.T) {
+	files := writeScenarioFiles(t, "etcd.yml", "node.yml")
+	scenarios, err := parseScenarioList(fmt.Sprintf(`[{"type": "pod_disruption_scenarios", "file": %q}, {"type": " node_scenarios ", "file": %q}]`, files[0], files[1]))
+	require.NoError(t, err)
+	assert.Equal(t, []krknScenario{
+		{Type: "pod_disruption_scenarios", File: files[0]},
+		{Type: "node_scenarios", File: files[1]},
+	}, scenarios)
+
+	for input, want := range map[string]string{
+		"":                                     "needs a scenario list",
+		"[]":                                   "scenario list is empty",
+		"type: pod_disruption_scenarios":       "invalid scenario list",
+		"[{type: Pod-Scenarios, file: x.yml}]": `type "Pod-Scenarios"`,
+		"[{type: pod_disruption_scenarios}]":   "file required",
+		"[{type: pod_disruption_scenarios, file: " + filepath.Dir(files[0]) + "}]":                    "is a directory",
+		"[{type: pod_disruption_scenarios, file: " + filepath.Join(t.TempDir(), "missing.yml") + "}]": "no such file",
+	} {
+		_, err := parseScenarioList(input)
+		assert.ErrorContains(t, err, want, input)
+	}
+}
+
+func TestWriteKrknScenarioConfig(t *testing.T) {
+	files := writeScenarioFiles(t, "etcd.yml", filepath.Join("more", "etcd.yml"), "node.yml")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.ScenarioList: fmt.Sprintf("[{type: pod_disruption_scenarios, file: %s}, {type: pod_disruption_scenarios, file: %s}, {type: node_scenarios, file: %s}]",
+			files[0], files[1], files[2]),
+		config.KrknAI.HealthCheck: "console=" + server.URL,
+		config.KrknAI.Overrides:   "tunings.iterations=3",
+	})
+	sharedDir := filepath.Dir(yamlFile)
+
+	require.NoError(t, (&KrknAI{}).writeKrknScenarioConfig(context.Background()))
+
+	cfg := readKrknConfig(t, filepath.Join(sharedDir, krknScenarioConfigFileName))
+	assert.Equal(t, map[string]interface{}{
+		"kubeconfig_path": "/mount/kubeconfig",
+		"exit_on_failure": false,
+		"chaos_scenarios": []interface{}{
+			map[string]interface{}{"pod_disruption_scenarios": []interface{}{"/mount/scenarios/01-etcd.yml", "/mount/scenarios/02-etcd.yml"}},
+			map[string]interface{}{"node_scenarios": []interface{}{"/mount/scenarios/03-node.yml"}},
+		},
+	}, cfg["kraken"])
+	assert.Equal(t, map[string]interface{}{"wait_duration": 60, "iterations": 3, "daemon_mode": false}, cfg["tunings"], "overrides apply to the krkn config")
+	assert.Equal(t, map[string]interface{}{
+		"interval": 2,
+		"config": []interface{}{map[string]interface{}{
+			"url": server.URL, "bearer_token": nil, "auth": nil, "exit_on_failure": false,
+		}},
+	}, cfg["health_checks"])
+
+	copied, err := os.ReadFile(filepath.Join(sharedDir, krknScenarioDirName, "02-etcd.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "# more/etcd.yml", string(copied))
+}
+
+func TestRunScenarioList(t *testing.T) {
+	// A fake runtime that records the arguments it was run with
+	binDir := t.TempDir()
+	argsFile := filepath.Join(binDir, "args")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s\n", argsFile)
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "podman"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	files := writeScenarioFiles(t, "etcd.yml")
+	setupKrknConfig(t, map[string]any{
+		config.ReportDir:           t.TempDir(),
+		config.KrknAI.ScenarioList: fmt.Sprintf("[{type: pod_disruption_scenarios, file: %s}]", files[0]),
+		config.KrknAI.KrknImage:    "quay.io/krkn-chaos/krkn:v1",
+	})
+
+	require.NoError(t, (&KrknAI{}).runScenarioList(context.Background()))
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(string(args)), "quay.io/krkn-chaos/krkn:v1 --config=/mount/krkn-config.yaml --output=/krknresults/kraken.report"), string(args))
+	assert.NotContains(t, string(args), DefaultKrknAIImage, "krkn mode runs plain krkn")
+}
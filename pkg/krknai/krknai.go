@@ -4,12 +4,17 @@ package krknai
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/openshift/osde2e-common/pkg/clients/openshift"
@@ -35,8 +40,12 @@ const (
 	containerResultsPath = "/krknresults/"
 
 	// File names
-	kubeconfigFileName = "kubeconfig"
-	krknConfigFileName = "krkn-ai.yaml"
+	kubeconfigFileName     = "kubeconfig"
+	krknConfigFileName     = "krkn-ai.yaml"
+	krknConfigDiffFileName = "krkn-ai.diff"
+
+	// containerStopGracePeriod is how long a cancelled container may take to exit after SIGTERM
+	containerStopGracePeriod = 2 * time.Minute
 )
 
 // KrknAI implements the orchestrator.Orchestrator interface for Kraken AI chaos testing.
@@ -44,6 +53,32 @@ type KrknAI struct {
 	provider       spi.Provider
 	result         *orchestrator.Result
 	analysisResult *analysisengine.Result
+	checkpoint     *generationCheckpoint // Set when resuming an interrupted run
+	progress       ProgressFunc          // Receives progress events parsed from the container output
+	metrics        *Metrics              // Records the runs when set
+	metricsCluster string                // The cluster label of the recorded runs
+
+	templateResolver *templateResolver // Resolves cluster templates in parameters; created on first use
+
+	// Per-cluster directories of a multi-cluster run; empty uses SharedDir and ReportDir
+	sharedDirPath string
+	reportDirPath string
+}
+
+// sharedDir returns the directory holding the kubeconfig and krkn-ai.yaml.
+func (k *KrknAI) sharedDir() string {
+	if k.sharedDirPath != "" {
+		return k.sharedDirPath
+	}
+	return viper.GetString(config.SharedDir)
+}
+
+// reportDir returns the directory krkn-ai writes its results to.
+func (k *KrknAI) reportDir() string {
+	if k.reportDirPath != "" {
+		return k.reportDirPath
+	}
+	return viper.GetString(config.ReportDir)
 }
 
 // New creates a new KrknAI orchestrator instance.
@@ -83,28 +118,121 @@ func (k *KrknAI) Provision(ctx context.Context) error {
 }
 
 // Execute runs the configured test suites including chaos testing scenarios.
-// The execution flow: discover mode -> update YAML -> run mode
+// The execution flow: discover mode -> update YAML -> run mode. In resume mode the
+// interrupted run's config and last checkpoint replace discovery; krkn mode runs the
+// scenario list with plain krkn instead. Outside dry runs, the report directory gets the
+// config, logs, generations, and analysis directories and a manifest.yaml describing the run.
 func (k *KrknAI) Execute(ctx context.Context) error {
+	if viper.GetBool(config.DryRun) {
+		return k.execute(ctx)
+	}
+
+	manifest, err := k.beginResults()
+	if err != nil {
+		return k.handleExecutionError(err)
+	}
+	err = k.execute(ctx)
+	if finishErr := k.finishResults(manifest, err); finishErr != nil {
+		log.Printf("Warning - failed to complete the results directory: %v", finishErr)
+	}
+	return err
+}
+
+// execute runs the execution flow Execute describes.
+func (k *KrknAI) execute(ctx context.Context) error {
 	k.result.TestsPassed = true
 	viper.Set(config.Cluster.Passing, k.result.TestsPassed)
 
 	if !viper.GetBool(config.DryRun) {
-		// Step 1: Run discover mode to identify chaos targets
-		log.Println("Krkn-ai discover mode")
-		if err := k.runKrknContainer(ctx, config.KrknAIModeDiscover); err != nil {
-			return k.handleExecutionError(fmt.Errorf("discover mode failed: %w", err))
+		mode, err := parseMode(viper.GetString(config.KrknAI.Mode))
+		if err != nil {
+			return k.handleExecutionError(err)
+		}
+		// The scope decides where discover mode looks, so it must be valid before any mode runs
+		if _, err := scopeFromConfig(); err != nil {
+			return k.handleExecutionError(err)
+		}
+		if mode == config.KrknAIModeKrkn {
+			if err := k.runScenarioList(ctx); err != nil {
+				return k.handleExecutionError(err)
+			}
+			log.Println("krkn execution completed")
+			return nil
+		}
+
+		resume := mode == config.KrknAIModeResume
+		skipDiscovery := viper.GetBool(config.KrknAI.SkipDiscovery)
+		switch {
+		case resume:
+			// Step 1: Pick up the interrupted run instead of discovering targets
+			log.Println("Resuming krkn-ai run")
+			checkpoint, err := prepareResume(k.sharedDir(), k.generationsDir())
+			if err != nil {
+				return k.handleExecutionError(fmt.Errorf("failed to resume: %w", err))
+			}
+			k.checkpoint = checkpoint
+		case skipDiscovery:
+			// Step 1: Generate the config from the krkn-ai parameters instead of discovering it
+			log.Println("Skipping krkn-ai discover mode, generating config")
+			configPath := filepath.Join(k.sharedDir(), krknConfigFileName)
+			if err := k.GenerateKrknConfig(configPath); err != nil {
+				return k.handleExecutionError(fmt.Errorf("failed to generate config: %w", err))
+			}
+		default:
+			// Step 1: Run discover mode to identify chaos targets
+			log.Println("Krkn-ai discover mode")
+			if err := k.runWithRetry(ctx, config.KrknAIModeDiscover); err != nil {
+				return k.handleExecutionError(fmt.Errorf("discover mode failed: %w", err))
+			}
+		}
+
+		if viper.GetBool(config.KrknAI.Preflight) {
+			log.Println("Running krkn-ai preflight checks")
+			if err := k.runPreflight(ctx); err != nil {
+				return k.handleExecutionError(err)
+			}
+		} else if viper.GetBool(config.KrknAI.FitnessQueryCheck) && viper.GetString(config.KrknAI.FitnessQuery) != "" {
+			// A bad fitness query doesn't fail krkn-ai, it zeroes the fitness of the whole run
+			log.Println("Checking the fitness query against the cluster")
+			if err := k.checkFitnessQuery(ctx); err != nil {
+				return k.handleExecutionError(err)
+			}
+		}
+
+		// Step 2: Update the YAML config with discovered targets (skip in dry-run mode);
+		// a generated config already carries the parameters
+		if resume || !skipDiscovery {
+			log.Println("Updating config with discovered targets")
+			if err := k.updateKrknConfig(ctx); err != nil {
+				return k.handleExecutionError(fmt.Errorf("failed to update config: %w", err))
+			}
 		}
 
-		// Step 2: Update the YAML config with discovered targets (skip in dry-run mode)
-		log.Println("Updating config with discovered targets")
-		if err := k.updateKrknConfig(ctx); err != nil {
-			return k.handleExecutionError(fmt.Errorf("failed to update config: %w", err))
+		if viper.GetBool(config.KrknAI.ConfigDryRun) {
+			log.Println("Krkn-ai config dry run finished, skipping run mode")
+			return nil
 		}
 
-		// Step 3: Run run mode with the updated config
-		log.Println("Krkn-ai run mode")
-		if err := k.runKrknContainer(ctx, config.KrknAIModeRun); err != nil {
-			return k.handleExecutionError(fmt.Errorf("run mode failed: %w", err))
+		// Step 3: Run run mode with the updated config, between the pre- and post-run hooks,
+		// keeping the kubeconfig fresh throughout
+		err = k.withKubeconfigRotation(ctx, config.KrknAIModeRun, func() error {
+			return k.runWithHooks(ctx, func() error {
+				if viper.GetBool(config.KrknAI.ReadinessGate) {
+					log.Println("Checking cluster readiness before run mode")
+					if err := k.waitForClusterReady(ctx); err != nil {
+						return err
+					}
+				}
+
+				log.Println("Krkn-ai run mode")
+				if err := k.runWithDeadline(ctx, config.KrknAIModeRun); err != nil {
+					return fmt.Errorf("run mode failed: %w", err)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return k.handleExecutionError(err)
 		}
 	} else {
 		log.Println("Krkn-ai dry mode finished")
@@ -121,37 +249,116 @@ func (k *KrknAI) handleExecutionError(err error) error {
 	return err
 }
 
-// runKrknContainer executes the Krkn-ai container using podman or docker with the specified mode.
-func (k *KrknAI) runKrknContainer(ctx context.Context, mode string) error {
-	runtime, err := detectContainerRuntime()
+// runPreflight runs the preflight checks against the discovered config, logging each result.
+func (k *KrknAI) runPreflight(ctx context.Context) error {
+	sharedDir := k.sharedDir()
+	cfg := PreflightConfig{KubeconfigPath: filepath.Join(sharedDir, kubeconfigFileName), templates: k.templates()}
+	if err := parsePreflightSkip(viper.GetString(config.KrknAI.PreflightSkip), &cfg); err != nil {
+		return err
+	}
+	if !viper.GetBool(config.KrknAI.FitnessQueryCheck) {
+		cfg.SkipFitnessQuery = true
+	}
+
+	report := Preflight(ctx, cfg, filepath.Join(sharedDir, krknConfigFileName))
+	for _, check := range report.Checks {
+		status := "PASS"
+		switch {
+		case check.Skipped:
+			status = "SKIP"
+		case !check.Passed:
+			status = "FAIL"
+		}
+		log.Printf("Preflight %s [%s]: %s", check.Name, status, check.Message)
+	}
+	return report.Err()
+}
+
+// runWithDeadline runs the container in mode (run or krkn), stopping it once
+// KRKN_MAX_RUN_DURATION has elapsed, retries included, or an abort condition has held for
+// KRKN_ABORT_FOR. Whatever krkn-ai wrote to the report directory by then is left for the analysis.
+func (k *KrknAI) runWithDeadline(ctx context.Context, mode string) error {
+	guardrails, err := abortGuardrailsFromConfig(ctx, k.templates())
 	if err != nil {
 		return err
 	}
+	maxDuration := viper.GetDuration(config.KrknAI.MaxRunDuration)
+	if maxDuration <= 0 && !guardrails.enabled() {
+		return k.runWithRetry(ctx, mode)
+	}
 
-	// Build base container arguments (common to both modes)
-	args := []string{"run", "--rm", "--net=host"}
+	runCtx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+	if maxDuration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, maxDuration)
+		defer cancel()
+	}
+	if guardrails.enabled() {
+		watchCtx, stopWatching := context.WithCancel(runCtx)
+		watched := make(chan struct{})
+		go func() {
+			defer close(watched)
+			if abortErr := watchAbortConditions(watchCtx, k.abortConditions(guardrails), guardrails.holdFor, guardrails.interval); abortErr != nil {
+				log.Printf("Stopping the krkn-ai run: %s", abortErr.reason)
+				abort(abortErr)
+			}
+		}()
+		defer func() {
+			stopWatching()
+			<-watched
+		}()
+	}
 
-	// Add volume mounts
-	args = append(args,
-		"-v", fmt.Sprintf("%s:%s:Z", viper.GetString(config.SharedDir), containerMountPath),
-		"-v", fmt.Sprintf("%s:%s:Z", viper.GetString(config.ReportDir), containerResultsPath),
-	)
+	err = k.runWithRetry(runCtx, mode)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if cause := context.Cause(runCtx); isAborted(cause) {
+		log.Printf("Krkn-ai run aborted, partial results are in %s", k.reportDir())
+		return cause
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		log.Printf("Krkn-ai run stopped after %s, partial results are in %s", maxDuration, k.reportDir())
+		return fmt.Errorf("run exceeded the max run duration of %s: %w", maxDuration, context.DeadlineExceeded)
+	}
+	return err
+}
 
-	// Add common environment variables
-	args = append(args,
-		"-e", fmt.Sprintf("MODE=%s", mode),
-		"-e", fmt.Sprintf("KUBECONFIG=%s/%s", containerMountPath, kubeconfigFileName),
-		"-e", fmt.Sprintf("VERBOSE=%s", config.KrknAIVerboseLevel),
-	)
+// invocation builds the krkn-ai run of mode, with the directories at paths. Krkn mode
+// runs the plain krkn image instead.
+func (k *KrknAI) invocation(ctx context.Context, mode string, paths invocationPaths) invocation {
+	inv := invocation{
+		mode:  mode,
+		image: DefaultKrknAIImage,
+		env: []string{
+			fmt.Sprintf("MODE=%s", mode),
+			fmt.Sprintf("KUBECONFIG=%s", path.Join(paths.shared, kubeconfigFileName)),
+			fmt.Sprintf("VERBOSE=%s", config.KrknAIVerboseLevel),
+		},
+	}
 
 	// Add mode-specific flags and environment variables
-	if mode == config.KrknAIModeRun {
+	switch mode {
+	case config.KrknAIModeKrkn:
+		// Krkn mode: the generated krkn config and its report in the results directory
+		inv.privileged = true
+		inv.image = viper.GetString(config.KrknAI.KrknImage)
+		inv.args = []string{
+			"--config=" + path.Join(paths.shared, krknScenarioConfigFileName),
+			"--output=" + path.Join(paths.results, krknReportFileName),
+		}
+	case config.KrknAIModeRun:
 		// Run mode: privileged flag, config file, results output, and Prometheus token
-		args = append(args, "--privileged")
-		args = append(args,
-			"-e", fmt.Sprintf("CONFIG_FILE=%s/%s", containerMountPath, krknConfigFileName),
-			"-e", fmt.Sprintf("OUTPUT_DIR=%s", containerResultsPath),
+		inv.privileged = true
+		inv.env = append(inv.env,
+			fmt.Sprintf("CONFIG_FILE=%s", path.Join(paths.shared, krknConfigFileName)),
+			fmt.Sprintf("OUTPUT_DIR=%s", paths.results),
 		)
+		if k.checkpoint != nil {
+			inv.checkpoint = filepath.ToSlash(k.checkpoint.Path)
+			inv.env = append(inv.env, fmt.Sprintf("CHECKPOINT_FILE=%s", path.Join(paths.results, inv.checkpoint)))
+		}
 
 		// Fetch Prometheus token from cluster
 		log.Println("Fetching Prometheus token from cluster")
@@ -160,52 +367,81 @@ func (k *KrknAI) runKrknContainer(ctx context.Context, mode string) error {
 			log.Printf("Warning - failed to fetch Prometheus token: %v", err)
 			log.Println("Continuing without Prometheus token")
 		} else {
-			args = append(args, "-e", fmt.Sprintf("PROMETHEUS_TOKEN=%s", promToken))
+			inv.env = append(inv.env, fmt.Sprintf("PROMETHEUS_TOKEN=%s", promToken))
 		}
-	} else {
+	default:
 		// Discover mode: namespace/pod/node targeting
-		args = append(args,
-			"-e", fmt.Sprintf("OUTPUT_DIR=%s", containerMountPath),
-			"-e", fmt.Sprintf("NAMESPACE=%s", viper.GetString(config.KrknAI.Namespace)),
-			"-e", fmt.Sprintf("POD_LABEL=%s", viper.GetString(config.KrknAI.PodLabel)),
+		inv.env = append(inv.env,
+			fmt.Sprintf("OUTPUT_DIR=%s", paths.shared),
+			fmt.Sprintf("NAMESPACE=%s", discoverNamespace()),
+			fmt.Sprintf("POD_LABEL=%s", viper.GetString(config.KrknAI.PodLabel)),
 		)
 
 		if nodeLabel := viper.GetString(config.KrknAI.NodeLabel); nodeLabel != "" {
-			args = append(args, "-e", fmt.Sprintf("NODE_LABEL=%s", nodeLabel))
+			inv.env = append(inv.env, fmt.Sprintf("NODE_LABEL=%s", nodeLabel))
 		}
 		if skipPodName := viper.GetString(config.KrknAI.SkipPodName); skipPodName != "" {
-			args = append(args, "-e", fmt.Sprintf("SKIP_POD_NAME=%s", skipPodName))
+			inv.env = append(inv.env, fmt.Sprintf("SKIP_POD_NAME=%s", skipPodName))
 		}
 	}
+	return inv
+}
 
-	// Add the image name
-	args = append(args, DefaultKrknAIImage)
-
-	log.Printf("Executing command: %s %v", runtime, args)
-
-	cmd := exec.CommandContext(ctx, runtime, args...)
+// runKrkn runs krkn-ai in mode on the backend set by KRKN_BACKEND.
+func (k *KrknAI) runKrkn(ctx context.Context, mode string) error {
+	b, err := backendFromConfig()
+	if err != nil {
+		return err
+	}
+	host := k.hostPaths()
+	inv := k.invocation(ctx, mode, b.paths(host))
+
+	emit := k.progress
+	if k.metrics != nil {
+		emit = func(event ProgressEvent) {
+			k.metrics.observe(k.metricsCluster, event)
+			if k.progress != nil {
+				k.progress(event)
+			}
+		}
+	}
 
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	var stdoutWriter, stderrWriter io.Writer = &stdout, &stderr
+	if emit != nil {
+		// Both streams feed one parser, since krkn-ai logs to stderr and krkn to stdout
+		parser := &progressParser{mode: mode, emit: emit}
+		stdoutProgress, stderrProgress := &progressWriter{parser: parser}, &progressWriter{parser: parser}
+		stdoutWriter = io.MultiWriter(&stdout, stdoutProgress)
+		stderrWriter = io.MultiWriter(&stderr, stderrProgress)
+		defer stdoutProgress.flush()
+		defer stderrProgress.flush()
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("container execution failed: %w", err)
+	if k.metrics != nil {
+		k.metrics.runStarted(k.metricsCluster, mode)
+	}
+	runErr := b.run(ctx, inv, host, stdoutWriter, stderrWriter)
+	if k.metrics != nil {
+		k.metrics.runFinished(k.metricsCluster, mode, runStatus(ctx, runErr))
 	}
 
-	log.Printf("Container output:\n%s", stdout.String())
+	log.Printf("Krkn-ai output (%s backend):\n%s", b.name(), stdout.String())
 	if stderr.Len() > 0 {
-		log.Printf("Container stderr:\n%s", stderr.String())
+		log.Printf("Krkn-ai stderr (%s backend):\n%s", b.name(), stderr.String())
 	}
+	k.writeContainerLog(mode, append(stdout.Bytes(), stderr.Bytes()...))
 
+	if runErr != nil {
+		return &containerError{err: runErr, output: stdout.String() + stderr.String()}
+	}
 	return nil
 }
 
 // getPrometheusToken retrieves a token for the prometheus-k8s service account from the cluster.
 func (k *KrknAI) getPrometheusToken(ctx context.Context) (string, error) {
 	// Get kubeconfig from shared dir
-	sharedDir := viper.GetString(config.SharedDir)
-	kubeconfigPath := filepath.Join(sharedDir, kubeconfigFileName)
+	kubeconfigPath := filepath.Join(k.sharedDir(), kubeconfigFileName)
 
 	// Create openshift client from kubeconfig
 	client, err := openshift.NewFromKubeconfig(kubeconfigPath, logr.Discard())
@@ -219,27 +455,20 @@ func (k *KrknAI) getPrometheusToken(ctx context.Context) (string, error) {
 
 // updateKrknConfig updates the Krkn-ai output YAML with values from viper config.
 func (k *KrknAI) updateKrknConfig(ctx context.Context) error {
-	sharedDir := viper.GetString(config.SharedDir)
-	fitnessQuery := viper.GetString(config.KrknAI.FitnessQuery)
-	scenarios := viper.GetString(config.KrknAI.Scenarios)
-	generations := viper.GetInt(config.KrknAI.Generations)
-	population := viper.GetInt(config.KrknAI.Population)
-	healthCheck := viper.GetString(config.KrknAI.HealthCheck)
-
-	var healthCheckApps []map[string]interface{}
-	if healthCheck != "" {
-		apps, err := parseHealthCheckEndpoints(healthCheck)
-		if err != nil {
-			return err
-		}
-		if err := validateHealthCheckURLsReachable(ctx, apps); err != nil {
-			return err
-		}
-		healthCheckApps = apps
+	sharedDir := k.sharedDir()
+
+	// Report every invalid parameter at once rather than the first one parsed below
+	if err := validateParams(); err != nil {
+		return fmt.Errorf("invalid krkn-ai parameters:\n%w", err)
 	}
 
-	// Skip if no config values to update
-	if fitnessQuery == "" && scenarios == "" && generations == 0 && population == 0 && healthCheck == "" {
+	// Templates are resolved for this cluster before anything is parsed or merged
+	templates := k.templates()
+	update, err := parseConfigUpdate(ctx, templates, filepath.Join(sharedDir, kubeconfigFileName))
+	if err != nil {
+		return err
+	}
+	if update.empty() {
 		return nil
 	}
 
@@ -260,66 +489,142 @@ func (k *KrknAI) updateKrknConfig(ctx context.Context) error {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return fmt.Errorf("failed to parse Krkn-ai config file: %w", err)
 	}
+	if err := templates.expandConfig(ctx, cfg); err != nil {
+		return err
+	}
 
-	if generations > 0 {
-		cfg["generations"] = generations
-		log.Printf("Updated generations to: %d", generations)
+	tracker, overrideFields, err := update.merge(cfg)
+	if err != nil {
+		return err
+	}
+	if err := validateMergedConfig(cfg, update.allowNoScenarios); err != nil {
+		return err
 	}
 
-	if population > 0 {
-		cfg["population_size"] = population
-		log.Printf("Updated population_size to: %d", population)
+	// Write updated YAML back, keeping the discovered file's comments and layout
+	updatedData, err := marshalPreservingLayout(data, cfg)
+	if err != nil {
+		return err
 	}
 
-	if len(healthCheckApps) > 0 {
-		hc, ok := cfg["health_checks"].(map[string]interface{})
-		if !ok {
-			hc = map[string]interface{}{}
-		}
-		hc["applications"] = healthCheckApps
-		cfg["health_checks"] = hc
-		log.Printf("Updated health_checks with %d endpoint(s)", len(healthCheckApps))
+	changes, err := configChanges(data, updatedData)
+	if err != nil {
+		return err
 	}
+	return writeUpdatedConfig(yamlFile, data, updatedData, tracker.attribute(changes), update.requested(overrideFields))
+}
 
-	// Update fitness_function.query if set
-	if fitnessQuery != "" {
-		if ff, ok := cfg["fitness_function"].(map[string]interface{}); ok {
-			ff["query"] = fitnessQuery
-			log.Printf("Updated fitness_function.query to: %s", fitnessQuery)
-		}
+// gaParamValues returns the configured genetic algorithm settings keyed by their krkn-ai.yaml name.
+func gaParamValues() map[string]string {
+	return map[string]string{
+		"mutation_rate":             viper.GetString(config.KrknAI.MutationRate),
+		"scenario_mutation_rate":    viper.GetString(config.KrknAI.ScenarioMutationRate),
+		"crossover_rate":            viper.GetString(config.KrknAI.CrossoverRate),
+		"population_injection_rate": viper.GetString(config.KrknAI.PopulationInjectionRate),
+		"population_injection_size": viper.GetString(config.KrknAI.PopulationInjectionSize),
 	}
+}
 
-	// Update scenarios if set
-	// If the user has set a list of scenarios, enable all of them
-	// TODO: Add a way to disable scenarios not selected by user
-	if scenarios != "" {
-		enabledScenarios := make(map[string]bool)
-		for _, s := range strings.Split(scenarios, ",") {
-			enabledScenarios[strings.TrimSpace(s)] = true
-		}
+// scenarioToggleValues returns the dedicated scenario toggles keyed by their krkn-ai.yaml
+// scenario name.
+func scenarioToggleValues() map[string]string {
+	return map[string]string{
+		"application_outages": viper.GetString(config.KrknAI.EnableApplicationOutages),
+		"syn_flood":           viper.GetString(config.KrknAI.EnableSynFlood),
+	}
+}
 
-		if scenarioCfg, ok := cfg["scenario"].(map[string]interface{}); ok {
-			for name, val := range scenarioCfg {
-				if scenarioMap, ok := val.(map[string]interface{}); ok {
-					scenarioMap["enable"] = enabledScenarios[name]
-				}
+// configuredHealthChecks returns the health check applications set by KRKN_HEALTH_CHECK
+// followed by those listed in KRKN_HEALTH_CHECK_APPS, with their templates resolved. An
+// application may only be named in one of them.
+func configuredHealthChecks(ctx context.Context, templates *templateResolver) ([]map[string]interface{}, error) {
+	endpoints, err := templates.param(ctx, config.KrknAI.HealthCheck)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := parseHealthCheckEndpoints(endpoints)
+	if err != nil {
+		return nil, err
+	}
+	appList, err := templates.param(ctx, config.KrknAI.HealthCheckApps)
+	if err != nil {
+		return nil, err
+	}
+	listed, err := parseHealthCheckApps(appList)
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range listed {
+		for _, existing := range apps {
+			if existing["name"] == app["name"] {
+				return nil, fmt.Errorf("health check %q is set by both KRKN_HEALTH_CHECK and KRKN_HEALTH_CHECK_APPS", app["name"])
 			}
-			log.Printf("Updated scenarios: %v", scenarios)
 		}
 	}
+	return append(apps, listed...), nil
+}
 
-	// Write updated YAML back
-	updatedData, err := yaml.Marshal(cfg)
+// resolveHealthChecks returns the health check applications to write: the configured
+// ones, which must all be reachable, preceded by the reachable applications discovered in
+// KRKN_HEALTH_CHECK_DISCOVERY_NAMESPACES through kubeconfigPath that aren't configured by name.
+func resolveHealthChecks(ctx context.Context, kubeconfigPath string, templates *templateResolver) ([]map[string]interface{}, error) {
+	configured, err := configuredHealthChecks(ctx, templates)
 	if err != nil {
-		return fmt.Errorf("failed to marshal updated config: %w", err)
+		return nil, err
+	}
+	concurrency := viper.GetInt(config.KrknAI.HealthCheckProbeConcurrency)
+	deadline := viper.GetDuration(config.KrknAI.HealthCheckProbeDeadline)
+	if len(configured) > 0 {
+		if err := validateHealthCheckURLsReachable(ctx, configured, concurrency, deadline); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := os.WriteFile(yamlFile, updatedData, 0o644); err != nil {
-		return fmt.Errorf("failed to write updated config: %w", err)
+	var namespaces []string
+	for _, namespace := range strings.Split(viper.GetString(config.KrknAI.HealthCheckDiscoveryNamespaces), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return configured, nil
+	}
+	discovered, err := discoverHealthChecksFromKubeconfig(ctx, kubeconfigPath, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[interface{}]bool, len(configured))
+	for _, app := range configured {
+		names[app["name"]] = true
+	}
+	var candidates []map[string]interface{}
+	for _, app := range discovered {
+		if !names[app["name"]] {
+			candidates = append(candidates, app)
+		}
 	}
+	return append(reachableHealthChecks(ctx, candidates, concurrency, deadline), configured...), nil
+}
 
-	log.Printf("Config file updated: %s", yamlFile)
-	return nil
+// componentFilterFromConfig returns the cluster_components filter set by the krkn-ai
+// namespace and node parameters, or nil when none are set.
+func componentFilterFromConfig() (*componentFilter, error) {
+	return parseComponentFilter(componentFilterOptions{
+		includeNamespaces:     viper.GetString(config.KrknAI.IncludeNamespaces),
+		excludeNamespaces:     viper.GetString(config.KrknAI.ExcludeNamespaces),
+		includeNamespaceRegex: viper.GetString(config.KrknAI.IncludeNamespaceRegex),
+		excludeNamespaceRegex: viper.GetString(config.KrknAI.ExcludeNamespaceRegex),
+		nodeSelector:          viper.GetString(config.KrknAI.NodeSelector),
+		nodeRoles:             viper.GetString(config.KrknAI.NodeRoles),
+	})
+}
+
+// runSizeValues returns the configured run size settings keyed by their krkn-ai.yaml name.
+func runSizeValues() map[string]string {
+	return map[string]string{
+		"generations":     viper.GetString(config.KrknAI.Generations),
+		"population_size": viper.GetString(config.KrknAI.Population),
+	}
 }
 
 // detectContainerRuntime finds an available container runtime (podman or docker).
@@ -342,7 +647,7 @@ func detectContainerRuntime() (string, error) {
 func (k *KrknAI) AnalyzeLogs(ctx context.Context, testErr error) error {
 	log.Println("Running krkn-ai log analysis...")
 
-	reportDir := viper.GetString(config.ReportDir)
+	reportDir := k.reportDir()
 	if reportDir == "" {
 		return fmt.Errorf("no report directory available for log analysis")
 	}
@@ -350,9 +655,37 @@ func (k *KrknAI) AnalyzeLogs(ctx context.Context, testErr error) error {
 	engineConfig := &krknaiengine.Config{
 		BaseConfig: analysisengine.BaseConfig{
 			ArtifactsDir: reportDir,
-			APIKey:       viper.GetString(config.LogAnalysis.APIKey),
+			APIKey:       config.LogAnalysisAPIKey(),
+			Provider:     viper.GetString(config.LogAnalysis.Provider),
+			ProxyURL:     viper.GetString(config.LogAnalysis.ProxyURL),
+			CABundlePath: viper.GetString(config.LogAnalysis.CABundle),
 		},
-		TopScenariosCount: viper.GetInt(config.KrknAI.TopScenariosCount),
+		TopScenariosCount:           viper.GetInt(config.KrknAI.TopScenariosCount),
+		BottomScenariosCount:        viper.GetInt(config.KrknAI.BottomScenariosCount),
+		PromptTemplatePath:          viper.GetString(config.KrknAI.PromptTemplatePath),
+		PromptTokenCost:             viper.GetFloat64(config.KrknAI.PromptTokenCost),
+		CompletionTokenCost:         viper.GetFloat64(config.KrknAI.CompletionTokenCost),
+		AnalysisTimeout:             viper.GetDuration(config.KrknAI.AnalysisTimeout),
+		CoolDown:                    viper.GetDuration(config.KrknAI.AnalysisCoolDown),
+		MaxArtifactReads:            viper.GetInt(config.KrknAI.MaxArtifactReads),
+		ArtifactReadTimeout:         viper.GetDuration(config.KrknAI.ArtifactReadTimeout),
+		HealthCheckSuccessThreshold: viper.GetFloat64(config.KrknAI.HealthCheckSuccessThreshold),
+		CacheEnabled:                viper.GetBool(config.KrknAI.LLMCacheEnabled),
+		CacheDir:                    viper.GetString(config.KrknAI.LLMCacheDir),
+	}
+	if threshold := strings.TrimSpace(viper.GetString(config.KrknAI.FitnessThreshold)); threshold != "" {
+		value, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return fmt.Errorf("invalid fitness threshold (expected a number): %q", threshold)
+		}
+		engineConfig.FitnessThreshold = &value
+	}
+	if maxFailed := strings.TrimSpace(viper.GetString(config.KrknAI.MaxFailedScenarios)); maxFailed != "" {
+		value, err := strconv.Atoi(maxFailed)
+		if err != nil || value < 0 {
+			return fmt.Errorf("invalid max failed scenarios (expected a non-negative integer): %q", maxFailed)
+		}
+		engineConfig.MaxFailedScenarios = &value
 	}
 
 	engine, err := krknaiengine.New(ctx, engineConfig)
@@ -360,14 +693,35 @@ func (k *KrknAI) AnalyzeLogs(ctx context.Context, testErr error) error {
 		return fmt.Errorf("failed to create krkn-ai analysis engine: %w", err)
 	}
 
-	result, err := engine.Run(ctx)
+	var result *analysisengine.Result
+	if baselineDir := viper.GetString(config.KrknAI.BaselineResultsDir); baselineDir != "" {
+		result, err = engine.RunComparison(ctx, baselineDir, reportDir)
+	} else {
+		result, err = engine.Run(ctx)
+	}
+	if errors.Is(err, krknaiengine.ErrThresholdExceeded) {
+		// The analysis completed; the chaos run found a weakness beyond the CI gate
+		k.analysisResult = result
+		k.handleExecutionError(err)
+		return err
+	}
 	if err != nil {
+		// A timed-out run still returns its partial result
+		if result != nil {
+			k.analysisResult = result
+		}
 		return fmt.Errorf("krkn-ai log analysis failed: %w", err)
 	}
 
 	k.analysisResult = result
+	switch result.Status {
+	case "error":
+		return fmt.Errorf("krkn-ai log analysis failed: %s", result.Error)
+	case "assertions_failed":
+		return fmt.Errorf("krkn-ai assertions failed: %s", result.Error)
+	}
 
-	log.Printf("Krkn-AI analysis completed. Results: %s/llm-analysis/", reportDir)
+	log.Printf("Krkn-AI analysis completed. Results: %s", engine.AnalysisDir())
 
 	return nil
 }
@@ -2,13 +2,21 @@ package krknai
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
 	"github.com/openshift/osde2e/pkg/common/config"
@@ -30,6 +38,86 @@ func TestDetectContainerRuntime(t *testing.T) {
 	t.Logf("Detected container runtime: %s", runtime)
 }
 
+func TestRunWithDeadline(t *testing.T) {
+	// A fake runtime that stands in for a hung run and exits once it is sent SIGTERM
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nsleep 60 &\npid=$!\ntrap 'kill $pid; echo stopped; exit 143' TERM\nwait $pid\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "podman"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	setupKrknConfig(t, map[string]any{
+		config.ReportDir:             t.TempDir(),
+		config.KrknAI.MaxRunDuration: "200ms",
+	})
+
+	start := time.Now()
+	err := (&KrknAI{}).runWithDeadline(context.Background(), config.KrknAIModeRun)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "max run duration of 200ms")
+	assert.Less(t, time.Since(start), 30*time.Second, "the container is stopped rather than waited for")
+}
+
+func TestRetryPolicyFromConfig(t *testing.T) {
+	setupKrknConfig(t, map[string]any{
+		config.KrknAI.RunRetries:       2,
+		config.KrknAI.RunRetryBackoff:  "5s",
+		config.KrknAI.RunRetryPatterns: " Quota Exceeded ,",
+	})
+	policy := retryPolicyFromConfig()
+	assert.Equal(t, 2, policy.maxRetries)
+	assert.Equal(t, 5*time.Second, policy.backoff)
+
+	exit := errors.New("exit status 1")
+	assert.True(t, policy.isTransient(&containerError{err: exit, output: "Error: initializing source: Error pulling image: TLS handshake timeout"}))
+	assert.True(t, policy.isTransient(&containerError{err: exit, output: "quota exceeded for registry"}), "extra patterns are matched")
+	assert.False(t, policy.isTransient(&containerError{err: exit, output: "invalid config: no scenarios enabled"}))
+	assert.False(t, policy.isTransient(errors.New("no container runtime found")), "only container failures are retried")
+	assert.False(t, policy.isTransient(fmt.Errorf("%w: connection refused", context.DeadlineExceeded)))
+
+	// A 401 is only retried when the kubeconfig is rotated
+	unauthorized := &containerError{err: exit, output: "error: You must be logged in to the server (Unauthorized)"}
+	assert.False(t, policy.isTransient(unauthorized))
+	viper.Set(config.KrknAI.KubeconfigSource, "file:/var/run/kubeconfig")
+	assert.True(t, retryPolicyFromConfig().isTransient(unauthorized))
+}
+
+func TestRetryTransient(t *testing.T) {
+	policy := runRetryPolicy{maxRetries: 2, backoff: time.Millisecond, patterns: transientPatterns}
+	transient := &containerError{err: errors.New("exit status 125"), output: "Error: failed to pull image: toomanyrequests"}
+	permanent := &containerError{err: errors.New("exit status 1"), output: "Traceback: KeyError"}
+
+	run := func(results ...error) (func() error, *int) {
+		calls := 0
+		return func() error {
+			err := results[calls]
+			calls++
+			return err
+		}, &calls
+	}
+
+	fn, calls := run(transient, transient, nil)
+	require.NoError(t, retryTransient(context.Background(), policy, "run", fn))
+	assert.Equal(t, 3, *calls)
+
+	fn, calls = run(transient, transient, transient)
+	assert.ErrorIs(t, retryTransient(context.Background(), policy, "run", fn), transient)
+	assert.Equal(t, 3, *calls, "gives up after the last retry")
+
+	fn, calls = run(permanent)
+	assert.ErrorIs(t, retryTransient(context.Background(), policy, "run", fn), permanent)
+	assert.Equal(t, 1, *calls, "permanent failures are not retried")
+
+	fn, calls = run(transient)
+	assert.Error(t, retryTransient(context.Background(), runRetryPolicy{patterns: transientPatterns}, "run", fn))
+	assert.Equal(t, 1, *calls, "no retries by default")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fn, calls = run(transient)
+	assert.Error(t, retryTransient(ctx, policy, "run", fn))
+	assert.Equal(t, 1, *calls, "a cancelled run is not retried")
+}
+
 func TestDefaultKrknAIImage(t *testing.T) {
 	expected := "quay.io/krkn-chaos/krkn-ai:latest"
 	if DefaultKrknAIImage != expected {
@@ -103,7 +191,7 @@ func TestValidateHealthCheckURLsReachable(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateHealthCheckURLsReachable(context.Background(), tt.apps)
+			err := validateHealthCheckURLsReachable(context.Background(), tt.apps, 2, 0)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
@@ -113,6 +201,66 @@ func TestValidateHealthCheckURLsReachable(t *testing.T) {
 	}
 }
 
+func TestValidateHealthCheckURLsReachable_Concurrent(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer slowServer.Close()
+
+	var apps []map[string]interface{}
+	for i := 0; i < 6; i++ {
+		apps = append(apps, map[string]interface{}{"name": fmt.Sprintf("app-%d", i), "url": slowServer.URL})
+	}
+	apps = append(apps, map[string]interface{}{"name": "strict", "url": slowServer.URL, "status_code": 200})
+
+	err := validateHealthCheckURLsReachable(context.Background(), apps, 3, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "strict")
+	assert.Contains(t, err.Error(), "HTTP 204, expected 200")
+	assert.NotContains(t, err.Error(), "app-0", "2xx passes when no status_code is set")
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+}
+
+func TestValidateHealthCheckURLsReachable_Timeouts(t *testing.T) {
+	hangServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer hangServer.Close()
+
+	// The per-app timeout applies to each probe
+	start := time.Now()
+	err := validateHealthCheckURLsReachable(context.Background(), []map[string]interface{}{
+		{"name": "slow", "url": hangServer.URL, "timeout": 0.1},
+	}, 1, 0)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// The overall deadline stops probes that are still queued
+	start = time.Now()
+	err = validateHealthCheckURLsReachable(context.Background(), []map[string]interface{}{
+		{"name": "first", "url": hangServer.URL},
+		{"name": "second", "url": hangServer.URL},
+	}, 1, 100*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "first")
+	assert.Contains(t, err.Error(), "second")
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
 func TestParseHealthCheckEndpoints(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -139,6 +287,22 @@ func TestParseHealthCheckEndpoints(t *testing.T) {
 			wantCount: 2,
 			wantNames: []string{"console", "api"},
 		},
+		{
+			name:      "per-application settings",
+			input:     "console=https://console.example.com/health;status_code=204;timeout=10,api=https://api.example.com/ready",
+			wantCount: 2,
+			wantNames: []string{"console", "api"},
+		},
+		{
+			name:    "unknown setting rejected",
+			input:   "console=https://console.example.com/health;retries=3",
+			wantErr: true,
+		},
+		{
+			name:    "non-positive setting rejected",
+			input:   "console=https://console.example.com/health;timeout=0",
+			wantErr: true,
+		},
 		{
 			name:    "missing scheme rejected",
 			input:   "console=console.example.com/health",
@@ -229,3 +393,1313 @@ func TestKrknAIViperConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateScenariosEnabled(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       map[string]interface{}
+		allowNone bool
+		wantErr   bool
+	}{
+		{
+			name: "one scenario enabled",
+			cfg: map[string]interface{}{"scenario": map[string]interface{}{
+				"pod_scenarios": map[string]interface{}{"enable": true},
+				"dns_outage":    map[string]interface{}{"enable": false},
+			}},
+		},
+		{
+			name: "all scenarios disabled",
+			cfg: map[string]interface{}{"scenario": map[string]interface{}{
+				"pod_scenarios": map[string]interface{}{"enable": false},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "all scenarios disabled but allowed",
+			cfg: map[string]interface{}{"scenario": map[string]interface{}{
+				"pod_scenarios": map[string]interface{}{"enable": false},
+			}},
+			allowNone: true,
+		},
+		{
+			name: "no scenario section",
+			cfg:  map[string]interface{}{"generations": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScenariosEnabled(tt.cfg, tt.allowNone)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "no scenarios are enabled")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateMinGenerations(t *testing.T) {
+	require.NoError(t, validateMinGenerations(3, 2, true))
+	require.NoError(t, validateMinGenerations(1, 0, true), "a zero minimum disables the check")
+	require.NoError(t, validateMinGenerations(1, 2, false), "below the minimum only warns by default")
+
+	err := validateMinGenerations(1, 2, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "generations (1) is below the minimum of 2")
+}
+
+func TestUpdateKrknConfig_MinGenerations(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Generations:      1,
+		config.KrknAI.StrictValidation: true,
+	})
+	err := (&KrknAI{}).updateKrknConfig(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "below the minimum")
+	assert.NotEqual(t, 1, readKrknConfig(t, yamlFile)["generations"], "config must not be written")
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.Generations: 1})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+}
+
+func TestParseGenericScenarios(t *testing.T) {
+	got, err := parseGenericScenarios(" new_scenario , pod_scenarios=false,,experimental=TRUE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"new_scenario": true, "pod_scenarios": false, "experimental": true}, got)
+
+	_, err = parseGenericScenarios("=true")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "name required")
+
+	_, err = parseGenericScenarios("new_scenario=maybe")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value for generic scenario "new_scenario"`)
+}
+
+func TestUpdateKrknConfig_GenericScenarios(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.GenericScenarios: "brand_new_scenario,pod_scenarios=false",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	scenarioCfg := readKrknConfig(t, yamlFile)["scenario"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"enable": true}, scenarioCfg["brand_new_scenario"])
+	assert.Equal(t, false, scenarioCfg["pod_scenarios"].(map[string]interface{})["enable"])
+	assert.Equal(t, true, scenarioCfg["node_cpu_hog"].(map[string]interface{})["enable"], "other scenarios are untouched")
+}
+
+func TestMergeHealthCheckApps(t *testing.T) {
+	existing := []interface{}{
+		map[string]interface{}{"name": "console", "url": "https://old.example.com", "status_code": 200, "timeout": 4, "interval": 2},
+		map[string]interface{}{"name": "oauth", "url": "https://oauth.example.com", "status_code": 200, "timeout": 4, "interval": 2},
+	}
+	overrides, err := parseHealthCheckEndpoints("console=https://ingress.example.com/console;timeout=8,api=https://api.example.com;status_code=204")
+	require.NoError(t, err)
+
+	merged := mergeHealthCheckApps(existing, overrides)
+	require.Len(t, merged, 3)
+	assert.Equal(t, map[string]interface{}{"name": "console", "url": "https://ingress.example.com/console", "status_code": 200, "timeout": 8, "interval": 2}, merged[0])
+	assert.Equal(t, existing[1], merged[1], "unmatched discovered applications are kept")
+	assert.Equal(t, map[string]interface{}{"name": "api", "url": "https://api.example.com", "status_code": 204, "timeout": 4, "interval": 2}, merged[2])
+
+	assert.Len(t, mergeHealthCheckApps(nil, overrides), 2)
+}
+
+func TestParseHealthCheckApps(t *testing.T) {
+	apps, err := parseHealthCheckApps(`
+- name: console
+  url: https://console.example.com/health
+  status_code: 204
+  timeout: 10
+- {name: api, url: "https://api.example.com/readyz", interval: 5}
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "console", "url": "https://console.example.com/health", "status_code": 204, "timeout": 10},
+		{"name": "api", "url": "https://api.example.com/readyz", "interval": 5},
+	}, apps)
+
+	apps, err = parseHealthCheckApps(`[{"name": "route", "url": "http://app.example.com"}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"name": "route", "url": "http://app.example.com"}}, apps)
+
+	apps, err = parseHealthCheckApps("  ")
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+
+	for name, input := range map[string]string{
+		"not a list":     "name: console",
+		"missing url":    "[{name: console}]",
+		"missing name":   "[{url: https://console.example.com}]",
+		"bad scheme":     "[{name: console, url: ftp://console.example.com}]",
+		"zero timeout":   "[{name: console, url: https://console.example.com, timeout: 0}]",
+		"duplicate name": "[{name: a, url: https://a.example.com}, {name: a, url: https://b.example.com}]",
+	} {
+		_, err := parseHealthCheckApps(input)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestUpdateKrknConfig_HealthCheckApps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/readyz" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.HealthCheck:     "console=" + server.URL + "/console",
+		config.KrknAI.HealthCheckApps: fmt.Sprintf(`[{name: api, url: "%s/readyz", status_code: 204}, {name: route, url: "%s/app"}]`, server.URL, server.URL),
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	apps := readKrknConfig(t, yamlFile)["health_checks"].(map[string]interface{})["applications"].([]interface{})
+	require.Len(t, apps, 3)
+	assert.Equal(t, server.URL+"/console", apps[0].(map[string]interface{})["url"])
+	assert.Equal(t, map[string]interface{}{"name": "api", "url": server.URL + "/readyz", "status_code": 204, "timeout": 4, "interval": 2}, apps[1])
+	assert.Equal(t, "route", apps[2].(map[string]interface{})["name"])
+
+	viper.Set(config.KrknAI.HealthCheckApps, fmt.Sprintf(`[{name: console, url: "%s"}]`, server.URL))
+	assert.ErrorContains(t, (&KrknAI{}).updateKrknConfig(context.Background()), `health check "console" is set by both KRKN_HEALTH_CHECK and KRKN_HEALTH_CHECK_APPS`)
+}
+
+func TestParseGAParams(t *testing.T) {
+	params, err := parseGAParams(map[string]string{
+		"mutation_rate":             "0.2",
+		"crossover_rate":            " 1 ",
+		"scenario_mutation_rate":    "",
+		"population_injection_size": "3",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"mutation_rate": 0.2, "crossover_rate": 1.0, "population_injection_size": 3}, params)
+
+	for name, values := range map[string]map[string]string{
+		"rate above 1":     {"mutation_rate": "1.5"},
+		"negative rate":    {"population_injection_rate": "-0.1"},
+		"non-numeric rate": {"crossover_rate": "high"},
+		"fractional count": {"population_injection_size": "2.5"},
+		"negative count":   {"population_injection_size": "-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseGAParams(values)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseRunSizeParams(t *testing.T) {
+	params, err := parseRunSizeParams(map[string]string{"generations": " 4 ", "population_size": ""})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"generations": 4}, params)
+
+	for _, value := range []string{"0", "-2", "many", "1.5"} {
+		_, err := parseRunSizeParams(map[string]string{"population_size": value})
+		assert.ErrorContains(t, err, "population_size", "value %q", value)
+	}
+}
+
+func TestValidateConfigBounds(t *testing.T) {
+	healthChecks := func(url string) map[string]interface{} {
+		return map[string]interface{}{"applications": []interface{}{
+			map[string]interface{}{"name": "console", "url": url},
+		}}
+	}
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr string
+	}{
+		{name: "valid", cfg: map[string]interface{}{
+			"generations": 5, "population_size": 10, "wait_duration": 120,
+			"health_checks": healthChecks("https://console.example.com/health"),
+		}},
+		{name: "absent settings", cfg: map[string]interface{}{}},
+		{name: "negative generations", cfg: map[string]interface{}{"generations": -1}, wantErr: "generations"},
+		{name: "zero population", cfg: map[string]interface{}{"population_size": 0}, wantErr: "population_size"},
+		{name: "non-integer wait duration", cfg: map[string]interface{}{"wait_duration": "2m"}, wantErr: "wait_duration"},
+		{name: "URL without scheme", cfg: map[string]interface{}{"health_checks": healthChecks("console.example.com/health")}, wantErr: "console"},
+		{name: "unsupported scheme", cfg: map[string]interface{}{"health_checks": healthChecks("ftp://console.example.com")}, wantErr: "console"},
+		{name: "valid rates", cfg: map[string]interface{}{"mutation_rate": 0.7, "crossover_rate": 1, "population_injection_size": 0}},
+		{name: "rate above 1", cfg: map[string]interface{}{"mutation_rate": 7.5}, wantErr: "mutation_rate must be a number between 0.0 and 1.0, got 7.5"},
+		{name: "non-numeric rate", cfg: map[string]interface{}{"crossover_rate": "high"}, wantErr: "crossover_rate"},
+		{name: "negative injection size", cfg: map[string]interface{}{"population_injection_size": -2}, wantErr: "population_injection_size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfigBounds(tt.cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateMergedConfig(t *testing.T) {
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"generations":      5,
+			"fitness_function": map[string]interface{}{"query": "sum(probe_success)"},
+			"scenario":         map[string]interface{}{"pod_scenarios": map[string]interface{}{"enable": true}},
+		}
+	}
+	assert.NoError(t, validateMergedConfig(valid(), false))
+
+	itemsOnly := valid()
+	itemsOnly["fitness_function"] = map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "a"}}}
+	assert.NoError(t, validateMergedConfig(itemsOnly, false), "fitness items stand in for the query")
+
+	cfg := valid()
+	cfg["population_size"] = 0
+	cfg["mutation_rate"] = 7.5
+	cfg["fitness_function"] = map[string]interface{}{"query": " "}
+	cfg["scenario"] = map[string]interface{}{"pod_scenarios": map[string]interface{}{"enable": false}}
+	err := validateMergedConfig(cfg, false)
+	require.Error(t, err)
+	for _, want := range []string{"no scenarios are enabled", "fitness_function.query must be a non-empty query", "population_size must be a positive integer", "mutation_rate must be a number between 0.0 and 1.0"} {
+		assert.Contains(t, err.Error(), want)
+	}
+	assert.NotContains(t, validateMergedConfig(cfg, true).Error(), "no scenarios are enabled")
+
+	delete(cfg, "fitness_function")
+	assert.ErrorContains(t, validateMergedConfig(cfg, true), "no fitness_function section")
+}
+
+func TestUpdateKrknConfig_InvalidMergedConfig(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Overrides: "mutation_rate=7.5,fitness_function.query=\"\"",
+	})
+	before, err := os.ReadFile(yamlFile)
+	require.NoError(t, err)
+
+	err = (&KrknAI{}).updateKrknConfig(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mutation_rate must be a number between 0.0 and 1.0, got 7.5")
+	assert.Contains(t, err.Error(), "fitness_function.query must be a non-empty query")
+
+	after, err := os.ReadFile(yamlFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after), "an invalid config must not be written")
+}
+
+func TestUpdateKrknConfig_InvalidRunSize(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{config.KrknAI.Population: "0"})
+	before, err := os.ReadFile(yamlFile)
+	require.NoError(t, err)
+
+	err = (&KrknAI{}).updateKrknConfig(context.Background())
+	assert.ErrorContains(t, err, "population_size")
+
+	after, err := os.ReadFile(yamlFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after), "an invalid parameter should not rewrite the config")
+}
+
+func TestUpdateKrknConfig_GAParams(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.MutationRate:            "0.3",
+		config.KrknAI.ScenarioMutationRate:    "0.1",
+		config.KrknAI.CrossoverRate:           "0.9",
+		config.KrknAI.PopulationInjectionRate: "0.05",
+		config.KrknAI.PopulationInjectionSize: "2",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	cfg := readKrknConfig(t, yamlFile)
+	assert.Equal(t, 0.3, cfg["mutation_rate"])
+	assert.Equal(t, 0.1, cfg["scenario_mutation_rate"])
+	assert.Equal(t, 0.9, cfg["crossover_rate"])
+	assert.Equal(t, 0.05, cfg["population_injection_rate"])
+	assert.Equal(t, 2, cfg["population_injection_size"])
+	assert.Equal(t, 5, cfg["generations"], "unset parameters should keep the discovered value")
+
+	viper.Set(config.KrknAI.CrossoverRate, "2")
+	assert.ErrorContains(t, (&KrknAI{}).updateKrknConfig(context.Background()), "crossover_rate must be between 0.0 and 1.0")
+}
+
+func TestUpdateKrknConfig_Overlay(t *testing.T) {
+	overlayFile := filepath.Join(t.TempDir(), "overlay.yaml")
+	require.NoError(t, os.WriteFile(overlayFile, []byte(`generations: 8
+population_size: 20
+fitness_function:
+  query: sum(kube_pod_container_status_restarts_total)
+scenario:
+  dns_outage:
+    enable: true
+`), 0o644))
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.OverlayPath: overlayFile,
+		config.KrknAI.Population:  "12",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	cfg := readKrknConfig(t, yamlFile)
+	assert.Equal(t, 8, cfg["generations"], "overlay overrides the discovered value")
+	assert.Equal(t, 12, cfg["population_size"], "parameters override the overlay")
+	ff := cfg["fitness_function"].(map[string]interface{})
+	assert.Equal(t, "sum(kube_pod_container_status_restarts_total)", ff["query"])
+	assert.Equal(t, "range", ff["type"], "keys absent from the overlay keep the discovered value")
+	scenarioCfg := cfg["scenario"].(map[string]interface{})
+	assert.Equal(t, true, scenarioCfg["dns_outage"].(map[string]interface{})["enable"])
+	assert.Equal(t, true, scenarioCfg["pod_scenarios"].(map[string]interface{})["enable"])
+
+	viper.Set(config.KrknAI.OverlayPath, filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, (&KrknAI{}).updateKrknConfig(context.Background()), "failed to read krkn-ai overlay")
+}
+
+func TestParseOverrides(t *testing.T) {
+	got, err := parseOverrides("fitness_function.type=weighted, scenario.syn_flood.enable=true\ngenerations=5,,future.name=\"\"")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"fitness_function": map[string]interface{}{"type": "weighted"},
+		"scenario":         map[string]interface{}{"syn_flood": map[string]interface{}{"enable": true}},
+		"generations":      5,
+		"future":           map[string]interface{}{"name": ""},
+	}, got)
+
+	for name, input := range map[string]string{
+		"missing value":    "generations",
+		"empty value":      "generations=",
+		"empty path":       "=5",
+		"empty segment":    "scenario..enable=true",
+		"duplicate":        "generations=5,generations=6",
+		"leaf then nested": "fitness_function=x,fitness_function.type=weighted",
+		"nested then leaf": "fitness_function.type=weighted,fitness_function=x",
+	} {
+		_, err := parseOverrides(input)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestUpdateKrknConfig_Overrides(t *testing.T) {
+	overlayFile := filepath.Join(t.TempDir(), "overlay.yaml")
+	require.NoError(t, os.WriteFile(overlayFile, []byte("generations: 8\nwait_duration: 45\n"), 0o644))
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.OverlayPath: overlayFile,
+		config.KrknAI.Overrides:   "wait_duration=60,fitness_function.type=weighted,scenario.syn_flood.enable=true,population_size=30,future_section.knob=1.5",
+		config.KrknAI.Population:  "12",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	cfg := readKrknConfig(t, yamlFile)
+	assert.Equal(t, 8, cfg["generations"])
+	assert.Equal(t, 60, cfg["wait_duration"], "overrides win over the overlay")
+	assert.Equal(t, 12, cfg["population_size"], "dedicated parameters win over overrides")
+	assert.Equal(t, 1.5, cfg["future_section"].(map[string]interface{})["knob"])
+	ff := cfg["fitness_function"].(map[string]interface{})
+	assert.Equal(t, "weighted", ff["type"])
+	assert.NotEmpty(t, ff["query"], "sibling keys keep their discovered value")
+	scenarioCfg := cfg["scenario"].(map[string]interface{})
+	assert.Equal(t, true, scenarioCfg["syn_flood"].(map[string]interface{})["enable"])
+
+	viper.Set(config.KrknAI.Overrides, "generations")
+	assert.ErrorContains(t, (&KrknAI{}).updateKrknConfig(context.Background()), `invalid krkn-ai override "generations"`)
+}
+
+func TestParseScenarioParams(t *testing.T) {
+	got, err := parseScenarioParams(" node_cpu_hog.duration=60, pod_scenarios.kill_count=2,,node_cpu_hog.namespace=openshift-etcd,network_scenarios.latency_ms=12.5")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"node_cpu_hog":      {"duration": 60, "namespace": "openshift-etcd"},
+		"pod_scenarios":     {"kill_count": 2},
+		"network_scenarios": {"latency_ms": 12.5},
+	}, got)
+
+	for name, input := range map[string]string{
+		"missing param":  "node_cpu_hog=60",
+		"nested param":   "node_cpu_hog.a.b=1",
+		"empty value":    "node_cpu_hog.duration=",
+		"enable":         "node_cpu_hog.enable=true",
+		"negative":       "pod_scenarios.kill_count=-1",
+		"list value":     "node_cpu_hog.nodes=[a]",
+		"duplicate":      "node_cpu_hog.duration=1,node_cpu_hog.duration=2",
+		"empty scenario": ".duration=1",
+	} {
+		_, err := parseScenarioParams(input)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestUpdateKrknConfig_ScenarioParams(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.ScenarioParams: "node_cpu_hog.duration=60,pod_scenarios.kill_count=2,network_scenarios.latency_ms=100",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	scenarioCfg := readKrknConfig(t, yamlFile)["scenario"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"enable": true, "duration": 60}, scenarioCfg["node_cpu_hog"])
+	assert.Equal(t, map[string]interface{}{"enable": true, "kill_count": 2}, scenarioCfg["pod_scenarios"])
+	assert.Equal(t, map[string]interface{}{"latency_ms": 100}, scenarioCfg["network_scenarios"], "parameters do not enable a scenario")
+}
+
+func TestUpdateKrknConfig_ScenarioToggles(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.GenericScenarios:         "syn_flood=true",
+		config.KrknAI.EnableApplicationOutages: "true",
+		config.KrknAI.EnableSynFlood:           "false",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	scenarioCfg := readKrknConfig(t, yamlFile)["scenario"].(map[string]interface{})
+	assert.Equal(t, true, scenarioCfg["application_outages"].(map[string]interface{})["enable"])
+	assert.Equal(t, false, scenarioCfg["syn_flood"].(map[string]interface{})["enable"], "toggle should override the generic entry")
+	assert.Equal(t, true, scenarioCfg["node_cpu_hog"].(map[string]interface{})["enable"])
+
+	// The effective config report shows the toggles as requested
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(yamlFile), effectiveConfigFileName))
+	require.NoError(t, err)
+	var report struct {
+		Parameters map[string]ParameterValues `yaml:"parameters"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &report))
+	assert.Equal(t, ParameterValues{Requested: true, Discovered: nil, Effective: true}, report.Parameters["scenario.application_outages.enable"])
+	assert.Equal(t, ParameterValues{Requested: false, Discovered: nil, Effective: false}, report.Parameters["scenario.syn_flood.enable"])
+
+	viper.Set(config.KrknAI.EnableSynFlood, "sometimes")
+	err = (&KrknAI{}).updateKrknConfig(context.Background())
+	assert.ErrorContains(t, err, "scenario.syn_flood.enable")
+}
+
+func TestUpdateKrknConfig_NoScenariosEnabled(t *testing.T) {
+	t.Run("unknown scenario list is rejected", func(t *testing.T) {
+		yamlFile := setupKrknConfig(t, map[string]any{config.KrknAI.Scenarios: "does_not_exist"})
+		before, err := os.ReadFile(yamlFile)
+		require.NoError(t, err)
+
+		err = (&KrknAI{}).updateKrknConfig(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no scenarios are enabled")
+
+		after, err := os.ReadFile(yamlFile)
+		require.NoError(t, err)
+		assert.Equal(t, string(before), string(after), "config must not be written")
+	})
+
+	t.Run("disable all scenarios bypasses the guard", func(t *testing.T) {
+		yamlFile := setupKrknConfig(t, map[string]any{config.KrknAI.DisableAllScenarios: true})
+		require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+		cfg := readKrknConfig(t, yamlFile)
+		for name, val := range cfg["scenario"].(map[string]interface{}) {
+			assert.Equal(t, false, val.(map[string]interface{})["enable"], name)
+		}
+	})
+}
+
+func TestUpdateKrknConfig_WritesDiff(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Generations: 7,
+		config.KrknAI.WriteDiff:   true,
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	diff, err := os.ReadFile(filepath.Join(filepath.Dir(yamlFile), krknConfigDiffFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(diff), "--- a/krkn-ai.yaml")
+	assert.Contains(t, string(diff), "+++ b/krkn-ai.yaml")
+	assert.Contains(t, string(diff), "-generations: 5")
+	assert.Contains(t, string(diff), "+generations: 7")
+}
+
+func TestUpdateKrknConfig_WritesEffectiveConfig(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Generations: 7,
+		config.KrknAI.Scenarios:   "node_cpu_hog, dns_outage",
+		config.KrknAI.Overrides:   "fitness_function.type=weighted",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(yamlFile), effectiveConfigFileName))
+	require.NoError(t, err)
+	var report struct {
+		Parameters map[string]ParameterValues `yaml:"parameters"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &report))
+
+	assert.Equal(t, ParameterValues{Requested: 7, Discovered: 5, Effective: 7}, report.Parameters["generations"])
+	assert.Equal(t, ParameterValues{Requested: nil, Discovered: 10, Effective: 10}, report.Parameters["population_size"])
+	assert.Equal(t, "sum(probe_success)", report.Parameters["fitness_function.query"].Effective)
+	assert.Equal(t, ParameterValues{
+		Requested:  []any{"dns_outage", "node_cpu_hog"},
+		Discovered: []any{"node_cpu_hog", "pod_scenarios"},
+		Effective:  []any{"dns_outage", "node_cpu_hog"},
+	}, report.Parameters["scenarios"])
+	assert.Equal(t, ParameterValues{Requested: "weighted", Discovered: "range", Effective: "weighted"}, report.Parameters["fitness_function.type"])
+}
+
+func TestUpdateKrknConfig_FitnessIncludeFlags(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.IncludeHealthCheckFailure: "false",
+		config.KrknAI.IncludeKrknFailure:        "TRUE",
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	ff, ok := readKrknConfig(t, yamlFile)["fitness_function"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, false, ff["include_health_check_failure"])
+	assert.Equal(t, true, ff["include_krkn_failure"])
+	assert.NotContains(t, ff, "include_health_check_response_time", "empty params keep the discovered value")
+	assert.Equal(t, "sum(probe_success)", ff["query"])
+}
+
+func TestUpdateKrknConfig_InvalidFitnessIncludeFlag(t *testing.T) {
+	setupKrknConfig(t, map[string]any{
+		config.KrknAI.IncludeHealthCheckResponseTime: "sometimes",
+	})
+	err := (&KrknAI{}).updateKrknConfig(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value for fitness_function.include_health_check_response_time (expected true or false): "sometimes"`)
+}
+
+func TestParseFitnessItems(t *testing.T) {
+	items, err := parseFitnessItems(`[{name: restarts, query: "sum(restarts)", weight: 0.6}, {name: latency, query: "avg(latency)", weight: 0.4, type: range}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []fitnessItem{
+		{Name: "restarts", Query: "sum(restarts)", Weight: 0.6, Type: "point", Direction: "increase"},
+		{Name: "latency", Query: "avg(latency)", Weight: 0.4, Type: "range", Direction: "increase"},
+	}, items)
+
+	// JSON as passed by Jenkins, with names left to their position
+	items, err = parseFitnessItems(`[{"query": "histogram_quantile(0.99, apiserver_latency)", "weight": 0.5}, {"query": "avg(probe_success)", "weight": 0.5, "direction": "Decrease"}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []fitnessItem{
+		{Name: "item_1", Query: "histogram_quantile(0.99, apiserver_latency)", Weight: 0.5, Type: "point", Direction: "increase"},
+		{Name: "item_2", Query: "avg(probe_success)", Weight: 0.5, Type: "point", Direction: "decrease"},
+	}, items)
+	assert.Equal(t, "histogram_quantile(0.99, apiserver_latency)", items[0].toMap()["query"])
+	assert.Equal(t, "-1 * (avg(probe_success))", items[1].toMap()["query"])
+
+	items, err = parseFitnessItems("  ")
+	require.NoError(t, err)
+	assert.Nil(t, items)
+
+	for input, want := range map[string]string{
+		`[{name: a, weight: 1}]`:                                               "query required",
+		`[{query: q, weight: 1, direction: up}]`:                               `unknown direction "up" for fitness item "item_1"`,
+		`[{name: a, query: q, weight: 0}]`:                                     "invalid weight",
+		`[{name: a, query: q, weight: 1, type: average}]`:                      `unknown type "average"`,
+		`[{name: a, query: q, weight: 0.5}, {name: a, query: r, weight: 0.5}]`: `duplicate fitness item "a"`,
+		`name: a`: "invalid fitness items",
+	} {
+		_, err := parseFitnessItems(input)
+		require.Error(t, err, input)
+		assert.Contains(t, err.Error(), want, input)
+	}
+}
+
+func TestValidateFitnessItems(t *testing.T) {
+	item := func(name string, weight interface{}, itemType string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "query": "q", "weight": weight, "type": itemType}
+	}
+	assert.NoError(t, validateFitnessItems(nil))
+	assert.NoError(t, validateFitnessItems([]interface{}{item("a", 0.7, "point"), item("b", 0.3, "range")}))
+	assert.NoError(t, validateFitnessItems([]interface{}{item("a", 1, "point")}))
+
+	err := validateFitnessItems([]interface{}{item("a", 0.7, "point"), item("b", 0.7, "point")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "weights must sum to 1, got 1.4 across a, b")
+
+	err = validateFitnessItems([]interface{}{item("a", 1, "histogram")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown type "histogram" for fitness item "a"`)
+
+	err = validateFitnessItems([]interface{}{item("a", "heavy", "point")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid weight for fitness item "a"`)
+}
+
+func TestUpdateKrknConfig_FitnessItems(t *testing.T) {
+	writeItems := func(t *testing.T, yamlFile string) {
+		cfg := readKrknConfig(t, yamlFile)
+		cfg["fitness_function"].(map[string]interface{})["items"] = []interface{}{
+			map[string]interface{}{"name": "restarts", "query": "sum(restarts)", "weight": 0.5, "type": "point"},
+			map[string]interface{}{"name": "errors", "query": "sum(errors)", "weight": 0.5, "type": "point"},
+		}
+		content, err := yaml.Marshal(cfg)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(yamlFile, content, 0o644))
+	}
+	items := func(t *testing.T, yamlFile string) []interface{} {
+		ff, ok := readKrknConfig(t, yamlFile)["fitness_function"].(map[string]interface{})
+		require.True(t, ok)
+		return ff["items"].([]interface{})
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		yamlFile := setupKrknConfig(t, map[string]any{
+			config.KrknAI.FitnessItems: `[{name: errors, query: "sum(rate(errors[5m]))", weight: 0.3, type: range}, {name: latency, query: "avg(latency)", weight: 0.2}]`,
+		})
+		writeItems(t, yamlFile)
+		require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "restarts", "query": "sum(restarts)", "weight": 0.5, "type": "point"},
+			map[string]interface{}{"name": "errors", "query": "sum(rate(errors[5m]))", "weight": 0.3, "type": "range"},
+			map[string]interface{}{"name": "latency", "query": "avg(latency)", "weight": 0.2, "type": "point"},
+		}, items(t, yamlFile))
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		yamlFile := setupKrknConfig(t, map[string]any{
+			config.KrknAI.FitnessItems:        `[{name: latency, query: "avg(latency)", weight: 1}]`,
+			config.KrknAI.FitnessItemsReplace: true,
+		})
+		writeItems(t, yamlFile)
+		require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "latency", "query": "avg(latency)", "weight": 1, "type": "point"},
+		}, items(t, yamlFile))
+	})
+
+	t.Run("weights checked after merging", func(t *testing.T) {
+		yamlFile := setupKrknConfig(t, map[string]any{
+			config.KrknAI.FitnessItems: `[{name: latency, query: "avg(latency)", weight: 0.5}]`,
+		})
+		writeItems(t, yamlFile)
+		before, err := os.ReadFile(yamlFile)
+		require.NoError(t, err)
+
+		err = (&KrknAI{}).updateKrknConfig(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "weights must sum to 1, got 1.5")
+		after, err := os.ReadFile(yamlFile)
+		require.NoError(t, err)
+		assert.Equal(t, string(before), string(after), "an invalid config is not written")
+	})
+}
+
+func TestPreflight(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"32"}`))
+	}))
+	defer apiServer.Close()
+	healthServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }))
+	defer healthServer.Close()
+
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.HealthCheck: "app=" + healthServer.URL,
+		config.KrknAI.Generations: 3,
+	})
+	kubeconfig := filepath.Join(filepath.Dir(yamlFile), kubeconfigFileName)
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: fake
+`, apiServer.URL)), 0o644))
+
+	report := Preflight(context.Background(), PreflightConfig{KubeconfigPath: kubeconfig}, yamlFile)
+	require.NoError(t, report.Err())
+	assert.True(t, report.Passed())
+	var names []string
+	for _, check := range report.Checks {
+		names = append(names, check.Name)
+		assert.False(t, check.Skipped)
+	}
+	assert.Equal(t, []string{PreflightCheckParams, PreflightCheckConfig, PreflightCheckCluster, PreflightCheckHealthChecks, PreflightCheckFitnessQuery}, names)
+
+	// Every failing check is reported, and skipped checks are not run
+	viper.Set(config.KrknAI.GenericScenarios, "bad=maybe")
+	report = Preflight(context.Background(), PreflightConfig{
+		KubeconfigPath:   filepath.Join(t.TempDir(), "missing"),
+		SkipHealthChecks: true,
+	}, filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.False(t, report.Passed())
+	err := report.Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "preflight params")
+	assert.Contains(t, err.Error(), "preflight config")
+	assert.Contains(t, err.Error(), "preflight cluster")
+	assert.NotContains(t, err.Error(), "preflight health_checks")
+	assert.True(t, report.Checks[3].Skipped)
+}
+
+func TestParsePreflightSkip(t *testing.T) {
+	var cfg PreflightConfig
+	require.NoError(t, parsePreflightSkip(" cluster, health_checks ,fitness_query", &cfg))
+	assert.Equal(t, PreflightConfig{SkipCluster: true, SkipHealthChecks: true, SkipFitnessQuery: true}, cfg)
+
+	assert.ErrorContains(t, parsePreflightSkip("network", &cfg), `unknown preflight check "network"`)
+}
+
+func TestValidateParams(t *testing.T) {
+	setupKrknConfig(t, map[string]any{})
+	require.NoError(t, validateParams())
+
+	// Every invalid parameter is reported, each with its own message
+	viper.Set(config.KrknAI.GenericScenarios, "bad=maybe")
+	viper.Set(config.KrknAI.IncludeKrknFailure, "sometimes")
+	viper.Set(config.KrknAI.Population, "-1")
+	viper.Set(config.KrknAI.CrossoverRate, "1.5")
+	viper.Set(config.KrknAI.Mode, "restart")
+	err := validateParams()
+	require.Error(t, err)
+	joined, ok := err.(interface{ Unwrap() []error })
+	require.True(t, ok)
+	assert.Len(t, joined.Unwrap(), 5)
+	assert.Contains(t, err.Error(), `invalid krkn-ai mode "restart"`)
+	assert.Contains(t, err.Error(), `invalid value for generic scenario "bad"`)
+	assert.Contains(t, err.Error(), `fitness_function.include_krkn_failure (expected true or false): "sometimes"`)
+	assert.Contains(t, err.Error(), "population_size")
+	assert.Contains(t, err.Error(), "crossover_rate must be between 0.0 and 1.0")
+
+	err = (&KrknAI{}).updateKrknConfig(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value for generic scenario "bad"`)
+	assert.Contains(t, err.Error(), "population_size")
+}
+
+func TestUpdateKrknConfig_PreservesLayout(t *testing.T) {
+	discovered := `# Generated by krkn-ai discover
+population_size: 10 # per generation
+generations: 5
+wait_duration: 30
+future_option: ""
+fitness_function:
+  # Prometheus range query
+  query: sum(probe_success)
+  type: range
+scenario:
+  node_cpu_hog:
+    enable: true
+    cpu_percentage: [50, 60]
+  pod_scenarios:
+    enable: false
+`
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Generations:  7,
+		config.KrknAI.MutationRate: "0.2",
+		config.KrknAI.Scenarios:    "pod_scenarios",
+		config.KrknAI.FitnessQuery: "sum(up)",
+	})
+	require.NoError(t, os.WriteFile(yamlFile, []byte(discovered), 0o644))
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	content, err := os.ReadFile(yamlFile)
+	require.NoError(t, err)
+	assert.Equal(t, `# Generated by krkn-ai discover
+population_size: 10 # per generation
+generations: 7
+wait_duration: 30
+future_option: ""
+fitness_function:
+  # Prometheus range query
+  query: sum(up)
+  type: range
+scenario:
+  node_cpu_hog:
+    enable: false
+    cpu_percentage: [50, 60]
+  pod_scenarios:
+    enable: true
+mutation_rate: 0.2
+`, string(content))
+}
+
+func TestUpdateKrknConfig_ConfigDryRun(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Generations:  7,
+		config.KrknAI.MutationRate: "0.4",
+		config.KrknAI.Scenarios:    "node_cpu_hog",
+		config.KrknAI.ConfigDryRun: true,
+		config.KrknAI.WriteDiff:    true,
+	})
+
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	content, err := os.ReadFile(yamlFile)
+	require.NoError(t, err)
+	assert.Equal(t, testKrknConfigYAML, string(content), "dry run must not modify the discovered config")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(yamlFile), effectiveConfigFileName))
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(yamlFile), krknConfigDiffFileName))
+
+	output := logs.String()
+	assert.Contains(t, output, "-generations: 5")
+	assert.Contains(t, output, "+generations: 7")
+	assert.Contains(t, output, "+mutation_rate: 0.4")
+	assert.NotContains(t, output, "-population_size", "unchanged fields should not appear in the diff")
+	assert.Contains(t, output, "  generations: 5 -> 7\n")
+	assert.Contains(t, output, "  mutation_rate: <unset> -> 0.4\n")
+	assert.Contains(t, output, "  scenario.pod_scenarios.enable: true -> false\n")
+}
+
+func TestConfigChanges(t *testing.T) {
+	before := []byte("generations: 5\nwait_duration: 30\nscenario:\n  a:\n    enable: true\nitems: [x, y]\n")
+	after := []byte("generations: 7\nscenario:\n  a:\n    enable: true\n  b:\n    enable: true\nitems: [x]\n")
+
+	changes, err := configChanges(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, []fieldChange{
+		{Path: "generations", Old: 5, New: 7},
+		{Path: "items", Old: []interface{}{"x", "y"}, New: []interface{}{"x"}},
+		{Path: "scenario.b", Old: nil, New: map[string]interface{}{"enable": true}},
+		{Path: "wait_duration", Old: 30, New: nil},
+	}, changes)
+	assert.Equal(t, "wait_duration: 30 -> <unset>", changes[3].String())
+
+	changes, err = configChanges(before, before)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestComponentFilter(t *testing.T) {
+	newCfg := func() map[string]interface{} {
+		return map[string]interface{}{"cluster_components": map[string]interface{}{
+			"namespaces": []interface{}{
+				map[string]interface{}{"name": "openshift-monitoring"},
+				map[string]interface{}{"name": "robot-shop", "pods": []interface{}{}},
+				"openshift-console",
+				"payments",
+			},
+			"nodes": []interface{}{
+				map[string]interface{}{"name": "worker-1", "labels": map[string]interface{}{"node-role.kubernetes.io/worker": ""}},
+				map[string]interface{}{"name": "master-1", "labels": map[string]interface{}{"node-role.kubernetes.io/master": ""}},
+				map[string]interface{}{"name": "infra-1", "labels": map[string]interface{}{"node-role.kubernetes.io/infra": "", "zone": "a"}},
+				"worker-2",
+			},
+		}}
+	}
+	names := func(cfg map[string]interface{}, key string) []string {
+		var out []string
+		for _, entry := range cfg["cluster_components"].(map[string]interface{})[key].([]interface{}) {
+			out = append(out, componentName(entry))
+		}
+		return out
+	}
+
+	f, err := parseComponentFilter(componentFilterOptions{excludeNamespaces: "openshift-*", nodeSelector: "node-role.kubernetes.io/worker"})
+	require.NoError(t, err)
+	cfg := newCfg()
+	f.apply(cfg)
+	assert.Equal(t, []string{"robot-shop", "payments"}, names(cfg, "namespaces"))
+	assert.Equal(t, []string{"worker-1"}, names(cfg, "nodes"), "unlabeled entries cannot match the selector")
+
+	f, err = parseComponentFilter(componentFilterOptions{includeNamespaces: "openshift-*, payments", excludeNamespaces: "openshift-console"})
+	require.NoError(t, err)
+	cfg = newCfg()
+	f.apply(cfg)
+	assert.Equal(t, []string{"openshift-monitoring", "payments"}, names(cfg, "namespaces"))
+	assert.Len(t, names(cfg, "nodes"), 4, "nodes are untouched without a selector")
+
+	f, err = parseComponentFilter(componentFilterOptions{includeNamespaceRegex: "^pay", excludeNamespaceRegex: "^openshift-(etcd|console)$"})
+	require.NoError(t, err)
+	cfg = newCfg()
+	f.apply(cfg)
+	assert.Equal(t, []string{"payments"}, names(cfg, "namespaces"))
+
+	f, err = parseComponentFilter(componentFilterOptions{includeNamespaces: "robot-*", excludeNamespaceRegex: "^openshift-"})
+	require.NoError(t, err)
+	cfg = newCfg()
+	f.apply(cfg)
+	assert.Equal(t, []string{"robot-shop"}, names(cfg, "namespaces"))
+
+	f, err = parseComponentFilter(componentFilterOptions{nodeRoles: "worker, infra"})
+	require.NoError(t, err)
+	cfg = newCfg()
+	f.apply(cfg)
+	assert.Equal(t, []string{"worker-1", "infra-1"}, names(cfg, "nodes"))
+	assert.Len(t, names(cfg, "namespaces"), 4, "namespaces are untouched without namespace filters")
+
+	f, err = parseComponentFilter(componentFilterOptions{nodeSelector: "zone=a", nodeRoles: "worker,infra"})
+	require.NoError(t, err)
+	cfg = newCfg()
+	f.apply(cfg)
+	assert.Equal(t, []string{"infra-1"}, names(cfg, "nodes"), "selector and roles must both match")
+
+	f, err = parseComponentFilter(componentFilterOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	_, err = parseComponentFilter(componentFilterOptions{includeNamespaces: "["})
+	assert.Error(t, err)
+	_, err = parseComponentFilter(componentFilterOptions{includeNamespaceRegex: "("})
+	assert.ErrorContains(t, err, "invalid namespace include regex")
+	_, err = parseComponentFilter(componentFilterOptions{nodeSelector: "role in ("})
+	assert.Error(t, err)
+	_, err = parseComponentFilter(componentFilterOptions{nodeRoles: "worker,bad role"})
+	assert.ErrorContains(t, err, "invalid node role")
+}
+
+func TestUpdateKrknConfig_ComponentFilter(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{config.KrknAI.ExcludeNamespaces: "openshift-*"})
+	cfg := readKrknConfig(t, yamlFile)
+	cfg["cluster_components"] = map[string]interface{}{
+		"namespaces": []interface{}{"openshift-etcd", map[string]interface{}{"name": "app"}},
+	}
+	content, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(yamlFile, content, 0o644))
+
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	cfg = readKrknConfig(t, yamlFile)
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "app"}},
+		cfg["cluster_components"].(map[string]interface{})["namespaces"])
+}
+
+func TestUpdateKrknConfig_AppliedParams(t *testing.T) {
+	overlayFile := filepath.Join(t.TempDir(), "overlay.yaml")
+	require.NoError(t, os.WriteFile(overlayFile, []byte("generations: 8\nwait_duration: 45\n"), 0o644))
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.OverlayPath:       overlayFile,
+		config.KrknAI.Overrides:         "wait_duration=60",
+		config.KrknAI.Population:        "12",
+		config.KrknAI.ExcludeNamespaces: "openshift-*",
+	})
+	cfg := readKrknConfig(t, yamlFile)
+	cfg["cluster_components"] = map[string]interface{}{"namespaces": []interface{}{"openshift-etcd", "app"}}
+	content, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(yamlFile, content, 0o644))
+
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(yamlFile), appliedParamsFileName))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"changes": [
+		{"path": "cluster_components.namespaces", "old": ["openshift-etcd", "app"], "new": ["app"], "source": "filter"},
+		{"path": "generations", "old": 5, "new": 8, "source": "overlay"},
+		{"path": "population_size", "old": 10, "new": 12, "source": "parameter"},
+		{"path": "wait_duration", "old": null, "new": 60, "source": "override"}
+	]}`, string(data))
+}
+
+func TestParseMode(t *testing.T) {
+	for input, want := range map[string]string{"": config.KrknAIModeRun, "run": config.KrknAIModeRun, " Resume ": config.KrknAIModeResume, "krkn": config.KrknAIModeKrkn} {
+		mode, err := parseMode(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, mode, input)
+	}
+	_, err := parseMode("discover")
+	assert.ErrorContains(t, err, "expected run, resume or krkn")
+}
+
+func TestFindLatestCheckpoint(t *testing.T) {
+	resultsDir := t.TempDir()
+	_, err := findLatestCheckpoint(resultsDir)
+	assert.ErrorContains(t, err, "no generation checkpoint found")
+
+	dir := filepath.Join(resultsDir, checkpointDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "generation_99.json"), 0o755))
+	for _, name := range []string{"generation_2.json", "generation_10.json", "generation_11.json.tmp", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644))
+	}
+	checkpoint, err := findLatestCheckpoint(resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, &generationCheckpoint{Generation: 10, Path: filepath.Join(checkpointDir, "generation_10.json")}, checkpoint)
+}
+
+func TestPrepareResume(t *testing.T) {
+	sharedDir, resultsDir := t.TempDir(), t.TempDir()
+	_, err := prepareResume(sharedDir, resultsDir)
+	assert.ErrorContains(t, err, "no generation checkpoint found")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(resultsDir, checkpointDir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultsDir, checkpointDir, "generation_3.json"), []byte("{}"), 0o644))
+	_, err = prepareResume(sharedDir, resultsDir)
+	assert.ErrorContains(t, err, "failed to read the config of the interrupted run")
+
+	// A new job restores the interrupted run's config from the results directory
+	require.NoError(t, os.WriteFile(filepath.Join(resultsDir, krknConfigFileName), []byte("generations: 20\n"), 0o644))
+	checkpoint, err := prepareResume(sharedDir, resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, checkpoint.Generation)
+	data, err := os.ReadFile(filepath.Join(sharedDir, krknConfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "generations: 20\n", string(data))
+
+	// A config already in the shared directory is kept
+	require.NoError(t, os.WriteFile(filepath.Join(sharedDir, krknConfigFileName), []byte("generations: 30\n"), 0o644))
+	_, err = prepareResume(sharedDir, resultsDir)
+	require.NoError(t, err)
+	data, err = os.ReadFile(filepath.Join(sharedDir, krknConfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "generations: 30\n", string(data))
+}
+
+func TestGenerateKrknConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		yamlFile := setupKrknConfig(t, map[string]any{
+			config.KrknAI.Scenarios:    "node_io_hog",
+			config.KrknAI.Generations:  "8",
+			config.KrknAI.MutationRate: "0.25",
+		})
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(yamlFile), kubeconfigFileName), []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://api.example.com:6443/
+contexts:
+- name: test
+  context:
+    cluster: test
+current-context: test
+`), 0o644))
+		output := filepath.Join(t.TempDir(), krknConfigFileName)
+		require.NoError(t, (&KrknAI{}).GenerateKrknConfig(output))
+
+		cfg := readKrknConfig(t, output)
+		assert.Equal(t, "/mount/kubeconfig", cfg["kubeconfig_file_path"])
+		assert.Equal(t, 8, cfg["generations"])
+		assert.Equal(t, 10, cfg["population_size"], "unset parameters get a runnable default")
+		assert.Equal(t, 120, cfg["wait_duration"])
+		assert.Equal(t, 0.25, cfg["mutation_rate"])
+		assert.Equal(t, map[string]interface{}{"query": defaultGeneratedFitnessQuery, "type": "point"}, cfg["fitness_function"])
+		assert.Equal(t, map[string]interface{}{"node_io_hog": map[string]interface{}{"enable": true}}, cfg["scenario"])
+		assert.Equal(t, map[string]interface{}{"applications": []interface{}{map[string]interface{}{
+			"name": "kube-apiserver", "url": "https://api.example.com:6443/readyz", "status_code": 200, "timeout": 4, "interval": 2,
+		}}}, cfg["health_checks"])
+	})
+
+	t.Run("explicit health check and default scenarios", func(t *testing.T) {
+		setupKrknConfig(t, map[string]any{config.KrknAI.HealthCheck: "console=https://console.example.com;status_code=302"})
+		output := filepath.Join(t.TempDir(), krknConfigFileName)
+		require.NoError(t, (&KrknAI{}).GenerateKrknConfig(output))
+
+		cfg := readKrknConfig(t, output)
+		scenarios := cfg["scenario"].(map[string]interface{})
+		assert.Len(t, scenarios, len(defaultGeneratedScenarios))
+		apps := cfg["health_checks"].(map[string]interface{})["applications"].([]interface{})
+		require.Len(t, apps, 1)
+		assert.Equal(t, 302, apps[0].(map[string]interface{})["status_code"])
+	})
+
+	t.Run("invalid parameter", func(t *testing.T) {
+		setupKrknConfig(t, map[string]any{config.KrknAI.Population: "0"})
+		output := filepath.Join(t.TempDir(), krknConfigFileName)
+		assert.ErrorContains(t, (&KrknAI{}).GenerateKrknConfig(output), "population_size")
+		assert.NoFileExists(t, output)
+	})
+}
+
+func TestUpdateKrknConfig_ConfigBackup(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		backupDir := t.TempDir()
+		yamlFile := setupKrknConfig(t, map[string]any{
+			config.KrknAI.Generations:      7,
+			config.KrknAI.ConfigBackupPath: backupDir,
+		})
+		require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+		entries, err := os.ReadDir(backupDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Regexp(t, `^krkn-ai-\d{8}T\d{6}\.\d{3}Z\.yaml$`, entries[0].Name())
+		backup, err := os.ReadFile(filepath.Join(backupDir, entries[0].Name()))
+		require.NoError(t, err)
+		assert.Equal(t, testKrknConfigYAML, string(backup), "the backup holds the discovered config")
+		assert.Equal(t, 7, readKrknConfig(t, yamlFile)["generations"])
+	})
+
+	t.Run("file path", func(t *testing.T) {
+		backupFile := filepath.Join(t.TempDir(), "discovered.yaml")
+		setupKrknConfig(t, map[string]any{
+			config.KrknAI.Generations:      7,
+			config.KrknAI.ConfigBackupPath: backupFile,
+		})
+		require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+		assert.FileExists(t, backupFile)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		yamlFile := setupKrknConfig(t, map[string]any{config.KrknAI.Generations: 7})
+		require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+		entries, err := os.ReadDir(filepath.Dir(yamlFile))
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.NotRegexp(t, `^krkn-ai-\d{8}T`, entry.Name())
+		}
+	})
+}
+
+func TestUpdateKrknConfig_ConfigHistory(t *testing.T) {
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Generations:   7,
+		config.KrknAI.ConfigHistory: true,
+	})
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+	viper.Set(config.KrknAI.Generations, 9)
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	historyDir := filepath.Join(filepath.Dir(yamlFile), configHistoryDirName)
+	data, err := os.ReadFile(filepath.Join(historyDir, configHistoryIndexName))
+	require.NoError(t, err)
+	var index []configHistoryEntry
+	require.NoError(t, yaml.Unmarshal(data, &index))
+	require.Len(t, index, 2, "each update appends to the index")
+
+	for i, generations := range []int{7, 9} {
+		assert.Regexp(t, `^krkn-ai-\d{8}T\d{6}\.\d{3}Z(-\d+)?\.yaml$`, index[i].File)
+		assert.Equal(t, generations, index[i].Parameters["generations"])
+		cfg := readKrknConfig(t, filepath.Join(historyDir, index[i].File))
+		assert.Equal(t, generations, cfg["generations"], "the copy holds the merged config")
+	}
+}
+
+func TestRecordConfigHistory_CorruptIndex(t *testing.T) {
+	historyDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(historyDir, configHistoryIndexName), []byte("{not: [a list"), 0o644))
+	_, err := recordConfigHistory(historyDir, []byte("generations: 1\n"), nil, time.Now())
+	assert.ErrorContains(t, err, "failed to parse config history index")
+}
+
+func TestRecordConfigHistory_SameTimestamp(t *testing.T) {
+	historyDir := t.TempDir()
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first, err := recordConfigHistory(historyDir, []byte("generations: 1\n"), nil, now)
+	require.NoError(t, err)
+	second, err := recordConfigHistory(historyDir, []byte("generations: 2\n"), nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, "krkn-ai-20250102T030405.000Z.yaml", filepath.Base(first))
+	assert.Equal(t, "krkn-ai-20250102T030405.000Z-2.yaml", filepath.Base(second))
+}
+
+func TestConfigDiff_Identical(t *testing.T) {
+	diff, err := configDiff([]byte("a: 1\n"), []byte("a: 1\n"))
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+// testKrknConfigYAML is a discovered krkn-ai.yaml used by updateKrknConfig tests.
+const testKrknConfigYAML = `generations: 5
+population_size: 10
+fitness_function:
+  query: sum(probe_success)
+  type: range
+health_checks:
+  applications:
+    - name: console
+      url: https://console.example.com
+scenario:
+  pod_scenarios:
+    enable: true
+  node_cpu_hog:
+    enable: true
+  dns_outage:
+    enable: false
+`
+
+// setupKrknConfig writes testKrknConfigYAML to a temporary shared dir and points viper at it.
+// The krkn-ai viper keys are reset to their zero values, then overridden by values.
+// All touched keys are restored when the test finishes.
+func setupKrknConfig(t *testing.T, values map[string]any) string {
+	t.Helper()
+
+	sharedDir := t.TempDir()
+	yamlFile := filepath.Join(sharedDir, krknConfigFileName)
+	require.NoError(t, os.WriteFile(yamlFile, []byte(testKrknConfigYAML), 0o644))
+
+	keys := map[string]any{
+		config.SharedDir:                  sharedDir,
+		config.KrknAI.FitnessQuery:        "",
+		config.KrknAI.Scenarios:           "",
+		config.KrknAI.Generations:         "",
+		config.KrknAI.Population:          "",
+		config.KrknAI.HealthCheck:         "",
+		config.KrknAI.HealthCheckApps:     "",
+		config.KrknAI.DisableAllScenarios: false,
+		config.KrknAI.AllowNoScenarios:    false,
+		config.KrknAI.WriteDiff:           false,
+		config.KrknAI.ConfigDryRun:        false,
+
+		config.KrknAI.IncludeHealthCheckFailure:      "",
+		config.KrknAI.IncludeHealthCheckResponseTime: "",
+		config.KrknAI.IncludeKrknFailure:             "",
+		config.KrknAI.FitnessItems:                   "",
+		config.KrknAI.FitnessItemsReplace:            false,
+		config.KrknAI.MinGenerations:                 2,
+		config.KrknAI.StrictValidation:               false,
+		config.KrknAI.GenericScenarios:               "",
+		config.KrknAI.EnableApplicationOutages:       "",
+		config.KrknAI.EnableSynFlood:                 "",
+		config.KrknAI.ScenarioParams:                 "",
+		config.KrknAI.MutationRate:                   "",
+		config.KrknAI.ScenarioMutationRate:           "",
+		config.KrknAI.CrossoverRate:                  "",
+		config.KrknAI.PopulationInjectionRate:        "",
+		config.KrknAI.PopulationInjectionSize:        "",
+		config.KrknAI.IncludeNamespaces:              "",
+		config.KrknAI.ExcludeNamespaces:              "",
+		config.KrknAI.IncludeNamespaceRegex:          "",
+		config.KrknAI.ExcludeNamespaceRegex:          "",
+		config.KrknAI.NodeRoles:                      "",
+		config.KrknAI.Mode:                           "",
+		config.KrknAI.ScenarioList:                   "",
+		config.KrknAI.NodeSelector:                   "",
+		config.KrknAI.ConfigBackupPath:               "",
+		config.KrknAI.ConfigHistory:                  false,
+		config.KrknAI.SensitiveParams:                "",
+		config.KrknAI.Profile:                        "",
+		config.KrknAI.FitnessQueryCheck:              true,
+		config.KrknAI.Scope:                          "cluster",
+		config.KrknAI.ScenarioRules:                  "disable",
+		config.KrknAI.AbortQuery:                     "",
+		config.KrknAI.AbortOnDegradedOperators:       false,
+		config.KrknAI.AbortFor:                       "10m",
+		config.KrknAI.AbortPollInterval:              "30s",
+		config.KrknAI.AbortRemediationHooks:          "",
+		config.KrknAI.Backend:                        "container",
+		config.KrknAI.LocalBinary:                    "krkn-ai",
+		config.KrknAI.LocalKrknBinary:                "krkn",
+		config.KrknAI.BackendKubeconfig:              "",
+		config.KrknAI.MetricsAddress:                 "",
+		config.KrknAI.KubeconfigSource:               "",
+		config.KrknAI.KubeconfigRefreshInterval:      "1h",
+		config.KrknAI.PreRunHooks:                    "",
+		config.KrknAI.PostRunHooks:                   "",
+		config.KrknAI.RunRetries:                     0,
+		config.KrknAI.RunRetryPatterns:               "",
+		config.KrknAI.OverlayPath:                    "",
+		config.KrknAI.Overrides:                      "",
+		config.KrknAI.HealthCheckDiscoveryNamespaces: "",
+	}
+	for k, v := range values {
+		keys[k] = v
+	}
+	for k, v := range keys {
+		old := viper.Get(k)
+		t.Cleanup(func() { viper.Set(k, old) })
+		viper.Set(k, v)
+	}
+
+	return yamlFile
+}
+
+// readKrknConfig parses a krkn-ai.yaml file into a map.
+func readKrknConfig(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var cfg map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	return cfg
+}
@@ -0,0 +1,322 @@
+package krknai
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/openshift/osde2e/pkg/common/spi"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	kubeconfigSourceOCM        = "ocm"
+	kubeconfigSourceFilePrefix = "file:"
+
+	// kubeconfigExpiryMargin is how long before its credentials expire a kubeconfig is refreshed.
+	kubeconfigExpiryMargin = 15 * time.Minute
+
+	// kubeconfigRetryInterval is how soon a failed refresh is retried, unless the refresh
+	// interval is shorter.
+	kubeconfigRetryInterval = time.Minute
+)
+
+// kubeconfigSource fetches current credentials for the cluster under test.
+type kubeconfigSource interface {
+	Kubeconfig(ctx context.Context) ([]byte, error)
+	String() string
+}
+
+// ocmKubeconfigSource fetches the kubeconfig from the cluster provider, which issues new
+// credentials on every call.
+type ocmKubeconfigSource struct {
+	provider  spi.Provider
+	clusterID string
+}
+
+func (s ocmKubeconfigSource) Kubeconfig(ctx context.Context) ([]byte, error) {
+	return s.provider.ClusterKubeconfig(s.clusterID)
+}
+
+func (s ocmKubeconfigSource) String() string {
+	return fmt.Sprintf("OCM (cluster %s)", s.clusterID)
+}
+
+// fileKubeconfigSource re-reads a kubeconfig that something outside the executor keeps fresh.
+type fileKubeconfigSource struct {
+	path string
+}
+
+func (s fileKubeconfigSource) Kubeconfig(ctx context.Context) ([]byte, error) {
+	return os.ReadFile(s.path)
+}
+
+func (s fileKubeconfigSource) String() string {
+	return s.path
+}
+
+// parseKubeconfigSource validates KRKN_KUBECONFIG_SOURCE: empty, ocm, or file:<path>. It
+// returns the source kind and, for file sources, the path.
+func parseKubeconfigSource(input string) (kind, sourcePath string, err error) {
+	input = strings.TrimSpace(input)
+	switch {
+	case input == "":
+		return "", "", nil
+	case strings.EqualFold(input, kubeconfigSourceOCM):
+		return kubeconfigSourceOCM, "", nil
+	case strings.HasPrefix(input, kubeconfigSourceFilePrefix):
+		sourcePath = strings.TrimSpace(strings.TrimPrefix(input, kubeconfigSourceFilePrefix))
+		if sourcePath == "" {
+			return "", "", fmt.Errorf("invalid kubeconfig source %q (file path required)", input)
+		}
+		return "file", sourcePath, nil
+	}
+	return "", "", fmt.Errorf("invalid kubeconfig source %q (expected ocm or file:<path>)", input)
+}
+
+// kubeconfigRefreshInterval parses KRKN_KUBECONFIG_REFRESH_INTERVAL.
+func kubeconfigRefreshInterval() (time.Duration, error) {
+	value := viper.GetString(config.KrknAI.KubeconfigRefreshInterval)
+	interval, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid kubeconfig refresh interval %q (expected a positive duration, e.g. 1h)", value)
+	}
+	return interval, nil
+}
+
+// kubeconfigSource returns the configured kubeconfig source, or nil when the kubeconfig in
+// the shared directory is used as is. OCM credentials are fetched for the orchestrator's
+// cluster through its provider.
+func (k *KrknAI) kubeconfigSource() (kubeconfigSource, error) {
+	kind, sourcePath, err := parseKubeconfigSource(viper.GetString(config.KrknAI.KubeconfigSource))
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kubeconfigSourceOCM:
+		clusterID := k.result.ClusterID
+		if clusterID == "" {
+			clusterID = viper.GetString(config.Cluster.ID)
+		}
+		if clusterID == "" || k.provider == nil {
+			return nil, fmt.Errorf("the ocm kubeconfig source needs a cluster ID and a cluster provider")
+		}
+		return ocmKubeconfigSource{provider: k.provider, clusterID: clusterID}, nil
+	case "file":
+		return fileKubeconfigSource{path: sourcePath}, nil
+	}
+	return nil, nil
+}
+
+// kubeconfigRotator keeps the kubeconfig the krkn-ai container reads fresh for the length
+// of a run, so credentials that expire before the run ends don't fail it partway.
+type kubeconfigRotator struct {
+	source   kubeconfigSource
+	path     string
+	interval time.Duration
+}
+
+// refresh fetches the kubeconfig from the source and replaces the file atomically, so the
+// container never reads a partial kubeconfig. It returns when the credentials expire, or
+// the zero time when that's unknown.
+func (r *kubeconfigRotator) refresh(ctx context.Context) (time.Time, error) {
+	data, err := r.source.Kubeconfig(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch kubeconfig from %s: %w", r.source, err)
+	}
+	if _, err := clientcmd.Load(data); err != nil {
+		return time.Time{}, fmt.Errorf("invalid kubeconfig from %s: %w", r.source, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), "."+kubeconfigFileName+"-*")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return time.Time{}, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return time.Time{}, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return time.Time{}, fmt.Errorf("failed to replace kubeconfig: %w", err)
+	}
+
+	expiry := kubeconfigExpiry(data)
+	if expiry.IsZero() {
+		log.Printf("Refreshed kubeconfig from %s", r.source)
+	} else {
+		log.Printf("Refreshed kubeconfig from %s, credentials expire at %s", r.source, expiry.UTC().Format(time.RFC3339))
+	}
+	return expiry, nil
+}
+
+// nextRefresh returns when to refresh next: after the interval, or earlier when the
+// credentials expire within it, but no sooner than the retry interval.
+func (r *kubeconfigRotator) nextRefresh(now, expiry time.Time) time.Duration {
+	wait := r.interval
+	if !expiry.IsZero() {
+		wait = min(wait, expiry.Add(-kubeconfigExpiryMargin).Sub(now))
+	}
+	return max(wait, min(r.interval, kubeconfigRetryInterval))
+}
+
+// run refreshes the kubeconfig after wait and then until ctx is done, retrying failed refreshes.
+func (r *kubeconfigRotator) run(ctx context.Context, wait time.Duration) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		next, err := r.refresh(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			retry := min(r.interval, kubeconfigRetryInterval)
+			log.Printf("Warning - kubeconfig refresh failed, retrying in %s: %v", retry, err)
+			timer.Reset(retry)
+			continue
+		}
+		timer.Reset(r.nextRefresh(time.Now(), next))
+	}
+}
+
+// withKubeconfigRotation runs run while the kubeconfig in the shared directory is refreshed
+// from KRKN_KUBECONFIG_SOURCE. The kubeconfig is refreshed once before run starts; a failed
+// first refresh leaves the current kubeconfig in place and is retried. In run mode the
+// kubeconfig_file_path in krkn-ai.yaml is pointed at the refreshed kubeconfig, where the
+// KRKN_BACKEND backend sees it.
+func (k *KrknAI) withKubeconfigRotation(ctx context.Context, mode string, run func() error) error {
+	source, err := k.kubeconfigSource()
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return run()
+	}
+	interval, err := kubeconfigRefreshInterval()
+	if err != nil {
+		return err
+	}
+
+	if mode == config.KrknAIModeRun {
+		b, err := backendFromConfig()
+		if err != nil {
+			return err
+		}
+		if err := pinKubeconfigPath(filepath.Join(k.sharedDir(), krknConfigFileName), b.paths(k.hostPaths()).shared); err != nil {
+			return err
+		}
+	}
+
+	rotator := &kubeconfigRotator{source: source, path: filepath.Join(k.sharedDir(), kubeconfigFileName), interval: interval}
+	var wait time.Duration
+	if expiry, err := rotator.refresh(ctx); err != nil {
+		wait = min(interval, kubeconfigRetryInterval)
+		log.Printf("Warning - %v, continuing with the current kubeconfig and retrying in %s", err, wait)
+	} else {
+		wait = rotator.nextRefresh(time.Now(), expiry)
+	}
+
+	rotateCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rotator.run(rotateCtx, wait)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	log.Printf("Refreshing the kubeconfig from %s every %s during the run", source, interval)
+	return run()
+}
+
+// pinKubeconfigPath points kubeconfig_file_path in the krkn-ai config at the kubeconfig in
+// the shared directory, which is the one kept fresh, keeping the rest of the file as is.
+// sharedDir is the shared directory as the backend's paths return it.
+func pinKubeconfigPath(configPath, sharedDir string) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read Krkn-ai config file: %w", err)
+	}
+	var cfg map[string]interface{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse Krkn-ai config file: %w", err)
+	}
+	want := path.Join(sharedDir, kubeconfigFileName)
+	if cfg["kubeconfig_file_path"] == want {
+		return nil
+	}
+
+	updated, err := marshalPreservingLayout(data, map[string]interface{}{"kubeconfig_file_path": want})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, updated, 0o644); err != nil {
+		return fmt.Errorf("failed to write Krkn-ai config file: %w", err)
+	}
+	log.Printf("Set kubeconfig_file_path in %s to %s (was %v)", configPath, want, cfg["kubeconfig_file_path"])
+	return nil
+}
+
+// kubeconfigExpiry returns when the credentials of the kubeconfig's current context
+// expire: a client certificate's NotAfter, or the exp claim of a JWT bearer token. It
+// returns the zero time when the expiry can't be told, e.g. for opaque OAuth tokens.
+func kubeconfigExpiry(data []byte) time.Time {
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return time.Time{}
+	}
+	kubeContext, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	if !ok {
+		return time.Time{}
+	}
+	authInfo, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return time.Time{}
+	}
+
+	if block, _ := pem.Decode(authInfo.ClientCertificateData); block != nil {
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			return cert.NotAfter
+		}
+	}
+	if parts := strings.Split(authInfo.Token, "."); len(parts) == 3 {
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return time.Time{}
+		}
+		var claims struct {
+			Exp int64 `json:"exp"`
+		}
+		if err := json.Unmarshal(payload, &claims); err == nil && claims.Exp > 0 {
+			return time.Unix(claims.Exp, 0)
+		}
+	}
+	return time.Time{}
+}
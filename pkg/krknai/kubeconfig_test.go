@@ -0,0 +1,280 @@
+package krknai
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/openshift/osde2e/pkg/common/orchestrator"
+	"github.com/openshift/osde2e/pkg/common/spi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// testKubeconfig returns a kubeconfig authenticating with token, or with a client
+// certificate when certPEM is set.
+func testKubeconfig(token string, certPEM []byte) []byte {
+	user := "    token: " + token
+	if certPEM != nil {
+		user = "    client-certificate-data: " + base64.StdEncoding.EncodeToString(certPEM)
+	}
+	return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://api.test.example.com:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: admin
+current-context: test
+users:
+- name: admin
+  user:
+` + user + "\n")
+}
+
+// testCertificate returns a PEM encoded self-signed certificate valid until notAfter.
+func testCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "system:admin"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// testJWT returns an unsigned JWT expiring at exp.
+func testJWT(exp time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))) + ".sig"
+}
+
+// countingKubeconfigSource returns a kubeconfig with a new token on every fetch.
+type countingKubeconfigSource struct {
+	mu      sync.Mutex
+	fetches int
+	err     error
+}
+
+func (s *countingKubeconfigSource) Kubeconfig(ctx context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return testKubeconfig(fmt.Sprintf("sha256~token-%d", s.fetches), nil), nil
+}
+
+func (s *countingKubeconfigSource) String() string {
+	return "test source"
+}
+
+// kubeconfigProvider is a cluster provider that only returns kubeconfigs.
+type kubeconfigProvider struct {
+	spi.Provider
+	clusterIDs []string
+}
+
+func (p *kubeconfigProvider) ClusterKubeconfig(clusterID string) ([]byte, error) {
+	p.clusterIDs = append(p.clusterIDs, clusterID)
+	return testKubeconfig("sha256~from-ocm", nil), nil
+}
+
+func TestParseKubeconfigSource(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    string
+		path    string
+		wantErr string
+	}{
+		{input: ""},
+		{input: "ocm", kind: "ocm"},
+		{input: " OCM ", kind: "ocm"},
+		{input: "file:/var/run/kubeconfig", kind: "file", path: "/var/run/kubeconfig"},
+		{input: "file:", wantErr: `invalid kubeconfig source "file:" (file path required)`},
+		{input: "vault", wantErr: `invalid kubeconfig source "vault" (expected ocm or file:<path>)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			kind, path, err := parseKubeconfigSource(tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, kind)
+			assert.Equal(t, tt.path, path)
+		})
+	}
+}
+
+func TestKubeconfigExpiry(t *testing.T) {
+	expiry := time.Now().Add(6 * time.Hour).Truncate(time.Second)
+
+	assert.True(t, kubeconfigExpiry(testKubeconfig("", testCertificate(t, expiry))).Equal(expiry.UTC()))
+	assert.True(t, kubeconfigExpiry(testKubeconfig(testJWT(expiry), nil)).Equal(expiry))
+	assert.True(t, kubeconfigExpiry(testKubeconfig("sha256~opaque", nil)).IsZero(), "opaque tokens have no known expiry")
+	assert.True(t, kubeconfigExpiry([]byte("not a kubeconfig")).IsZero())
+}
+
+func TestKubeconfigNextRefresh(t *testing.T) {
+	now := time.Now()
+	r := &kubeconfigRotator{interval: time.Hour}
+
+	assert.Equal(t, time.Hour, r.nextRefresh(now, time.Time{}), "unknown expiry refreshes on the interval")
+	assert.Equal(t, time.Hour, r.nextRefresh(now, now.Add(6*time.Hour)))
+	assert.Equal(t, 30*time.Minute, r.nextRefresh(now, now.Add(45*time.Minute)), "refreshes before the credentials expire")
+	assert.Equal(t, kubeconfigRetryInterval, r.nextRefresh(now, now.Add(-time.Hour)), "expired credentials don't refresh in a busy loop")
+}
+
+func TestKubeconfigSourceFromConfig(t *testing.T) {
+	setupKrknConfig(t, map[string]any{config.KrknAI.KubeconfigSource: "ocm", config.Cluster.ID: "2a3b4c"})
+
+	provider := &kubeconfigProvider{}
+	k := &KrknAI{provider: provider, result: &orchestrator.Result{}}
+	source, err := k.kubeconfigSource()
+	require.NoError(t, err)
+	_, err = source.Kubeconfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2a3b4c"}, provider.clusterIDs)
+
+	k.provider = nil
+	_, err = k.kubeconfigSource()
+	assert.EqualError(t, err, "the ocm kubeconfig source needs a cluster ID and a cluster provider")
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.KubeconfigSource: ""})
+	source, err = k.kubeconfigSource()
+	require.NoError(t, err)
+	assert.Nil(t, source)
+}
+
+func TestWithKubeconfigRotation(t *testing.T) {
+	sourceDir := t.TempDir()
+	sourcePath := filepath.Join(sourceDir, "rotated")
+	require.NoError(t, os.WriteFile(sourcePath, testKubeconfig("sha256~rotated-1", nil), 0o600))
+	sharedDir := filepath.Dir(setupKrknConfig(t, map[string]any{
+		config.KrknAI.KubeconfigSource:          "file:" + sourcePath,
+		config.KrknAI.KubeconfigRefreshInterval: "20ms",
+	}))
+	kubeconfigPath := filepath.Join(sharedDir, kubeconfigFileName)
+	require.NoError(t, os.WriteFile(kubeconfigPath, testKubeconfig("sha256~stale", nil), 0o644))
+
+	k := &KrknAI{result: &orchestrator.Result{}}
+	err := k.withKubeconfigRotation(context.Background(), config.KrknAIModeRun, func() error {
+		// Refreshed before the run starts
+		data, err := os.ReadFile(kubeconfigPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "sha256~rotated-1")
+
+		// And again during it, once the source changes
+		require.NoError(t, os.WriteFile(sourcePath, testKubeconfig("sha256~rotated-2", nil), 0o600))
+		assert.Eventually(t, func() bool {
+			data, err := os.ReadFile(kubeconfigPath)
+			return err == nil && strings.Contains(string(data), "sha256~rotated-2")
+		}, 5*time.Second, 10*time.Millisecond)
+		return errors.New("run failed")
+	})
+	assert.EqualError(t, err, "run failed", "the run's error is returned")
+
+	info, err := os.Stat(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	leftovers, err := filepath.Glob(filepath.Join(sharedDir, "."+kubeconfigFileName+"-*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+
+	var cfg map[string]any
+	data, err := os.ReadFile(filepath.Join(sharedDir, krknConfigFileName))
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	assert.Equal(t, "/mount/kubeconfig", cfg["kubeconfig_file_path"])
+	assert.Equal(t, 5, cfg["generations"], "the rest of the config is kept")
+}
+
+func TestWithKubeconfigRotationLocalBackend(t *testing.T) {
+	sourcePath := filepath.Join(t.TempDir(), "rotated")
+	require.NoError(t, os.WriteFile(sourcePath, testKubeconfig("sha256~rotated", nil), 0o600))
+	sharedDir := filepath.Dir(setupKrknConfig(t, map[string]any{
+		config.KrknAI.Backend:                   backendLocal,
+		config.KrknAI.KubeconfigSource:          "file:" + sourcePath,
+		config.KrknAI.KubeconfigRefreshInterval: "1h",
+	}))
+
+	k := &KrknAI{result: &orchestrator.Result{}}
+	require.NoError(t, k.withKubeconfigRotation(context.Background(), config.KrknAIModeRun, func() error { return nil }))
+
+	// The local binary reads the refreshed kubeconfig from the shared directory on this machine
+	var cfg map[string]any
+	data, err := os.ReadFile(filepath.Join(sharedDir, krknConfigFileName))
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	assert.Equal(t, filepath.Join(sharedDir, kubeconfigFileName), cfg["kubeconfig_file_path"])
+	data, err = os.ReadFile(filepath.Join(sharedDir, kubeconfigFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "sha256~rotated")
+}
+
+func TestWithKubeconfigRotationFailedRefresh(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), kubeconfigFileName)
+	require.NoError(t, os.WriteFile(kubeconfigPath, testKubeconfig("sha256~current", nil), 0o600))
+
+	source := &countingKubeconfigSource{err: errors.New("ocm unavailable")}
+	rotator := &kubeconfigRotator{source: source, path: kubeconfigPath, interval: 10 * time.Millisecond}
+	_, err := rotator.refresh(context.Background())
+	assert.EqualError(t, err, "failed to fetch kubeconfig from test source: ocm unavailable")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rotator.run(ctx, 0)
+	}()
+
+	// Failed refreshes are retried and leave the current kubeconfig in place until one succeeds
+	assert.Eventually(t, func() bool {
+		source.mu.Lock()
+		defer source.mu.Unlock()
+		return source.fetches >= 3
+	}, 5*time.Second, 5*time.Millisecond)
+	data, err := os.ReadFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "sha256~current")
+
+	source.mu.Lock()
+	source.err = nil
+	source.mu.Unlock()
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(kubeconfigPath)
+		return err == nil && strings.Contains(string(data), "sha256~token-")
+	}, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,208 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	prometheusclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Run statuses counted by krknai_runs_total.
+const (
+	runStatusSucceeded = "succeeded"
+	runStatusFailed    = "failed"
+	runStatusAborted   = "aborted"
+)
+
+// metricsShutdownTimeout bounds serving the scrapes in flight when the metrics server stops.
+const metricsShutdownTimeout = 5 * time.Second
+
+var (
+	runDurationDesc = prometheusclient.NewDesc(
+		"krknai_run_duration_seconds",
+		"Duration of the latest krkn-ai run per cluster and mode, growing while it runs.",
+		[]string{"cluster", "mode"}, nil,
+	)
+	runInProgressDesc = prometheusclient.NewDesc(
+		"krknai_run_in_progress",
+		"Whether the latest krkn-ai run per cluster and mode is still running (1) or has finished (0).",
+		[]string{"cluster", "mode"}, nil,
+	)
+)
+
+// Metrics records krkn-ai runs as Prometheus metrics: scenarios executed, failed health
+// checks, the current generation, and run durations and outcomes. Clusters are labelled by
+// their name in a multi-cluster run and by their ID otherwise. Safe for concurrent use.
+type Metrics struct {
+	scenarios           *prometheusclient.CounterVec
+	healthCheckFailures *prometheusclient.CounterVec
+	generation          *prometheusclient.GaugeVec
+	lastProgress        *prometheusclient.GaugeVec
+	runs                *prometheusclient.CounterVec
+
+	mu      sync.Mutex
+	timings map[runKey]runTiming
+}
+
+type runKey struct {
+	cluster string
+	mode    string
+}
+
+// runTiming is when a run started and, once it has finished, ended.
+type runTiming struct {
+	start time.Time
+	end   time.Time
+}
+
+// NewMetrics creates the krkn-ai run collectors and registers them with registerer, e.g.
+// prometheus.DefaultRegisterer.
+func NewMetrics(registerer prometheusclient.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		scenarios: prometheusclient.NewCounterVec(prometheusclient.CounterOpts{
+			Name: "krknai_scenarios_executed_total",
+			Help: "Chaos scenarios krkn-ai started executing, by cluster and scenario.",
+		}, []string{"cluster", "scenario"}),
+		healthCheckFailures: prometheusclient.NewCounterVec(prometheusclient.CounterOpts{
+			Name: "krknai_health_check_failures_total",
+			Help: "Failed health checks krkn-ai reported, by cluster.",
+		}, []string{"cluster"}),
+		generation: prometheusclient.NewGaugeVec(prometheusclient.GaugeOpts{
+			Name: "krknai_current_generation",
+			Help: "The generation the cluster's krkn-ai run is on, 0 before the first one.",
+		}, []string{"cluster"}),
+		lastProgress: prometheusclient.NewGaugeVec(prometheusclient.GaugeOpts{
+			Name: "krknai_last_progress_timestamp_seconds",
+			Help: "Unix time of the latest progress krkn-ai reported for the cluster.",
+		}, []string{"cluster"}),
+		runs: prometheusclient.NewCounterVec(prometheusclient.CounterOpts{
+			Name: "krknai_runs_total",
+			Help: "Finished krkn-ai runs by cluster, mode, and status (succeeded, failed, or aborted).",
+		}, []string{"cluster", "mode", "status"}),
+		timings: make(map[runKey]runTiming),
+	}
+	if err := registerer.Register(m); err != nil {
+		return nil, fmt.Errorf("failed to register krkn-ai metrics: %w", err)
+	}
+	return m, nil
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheusclient.Desc) {
+	m.scenarios.Describe(ch)
+	m.healthCheckFailures.Describe(ch)
+	m.generation.Describe(ch)
+	m.lastProgress.Describe(ch)
+	m.runs.Describe(ch)
+	ch <- runDurationDesc
+	ch <- runInProgressDesc
+}
+
+// Collect implements prometheus.Collector. Durations of running runs are measured at
+// scrape time, so a stuck run shows as one that keeps growing.
+func (m *Metrics) Collect(ch chan<- prometheusclient.Metric) {
+	m.scenarios.Collect(ch)
+	m.healthCheckFailures.Collect(ch)
+	m.generation.Collect(ch)
+	m.lastProgress.Collect(ch)
+	m.runs.Collect(ch)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for key, timing := range m.timings {
+		end, running := timing.end, 0.0
+		if end.IsZero() {
+			end, running = now, 1
+		}
+		ch <- prometheusclient.MustNewConstMetric(runDurationDesc, prometheusclient.GaugeValue, end.Sub(timing.start).Seconds(), key.cluster, key.mode)
+		ch <- prometheusclient.MustNewConstMetric(runInProgressDesc, prometheusclient.GaugeValue, running, key.cluster, key.mode)
+	}
+}
+
+// observe records a progress event of the cluster's run.
+func (m *Metrics) observe(cluster string, event ProgressEvent) {
+	switch event.Type {
+	case ProgressGenerationStarted:
+		m.generation.WithLabelValues(cluster).Set(float64(event.Generation))
+	case ProgressScenarioExecuted:
+		m.scenarios.WithLabelValues(cluster, event.Scenario).Inc()
+	case ProgressHealthCheckFailed:
+		m.healthCheckFailures.WithLabelValues(cluster).Inc()
+	}
+	m.lastProgress.WithLabelValues(cluster).Set(float64(event.Time.Unix()))
+}
+
+// runStarted records the cluster starting a run in mode.
+func (m *Metrics) runStarted(cluster, mode string) {
+	m.generation.WithLabelValues(cluster).Set(0)
+	m.lastProgress.WithLabelValues(cluster).SetToCurrentTime()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timings[runKey{cluster: cluster, mode: mode}] = runTiming{start: time.Now()}
+}
+
+// runFinished records the cluster's run in mode ending with status.
+func (m *Metrics) runFinished(cluster, mode, status string) {
+	m.runs.WithLabelValues(cluster, mode, status).Inc()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := runKey{cluster: cluster, mode: mode}
+	if timing, ok := m.timings[key]; ok {
+		timing.end = time.Now()
+		m.timings[key] = timing
+	}
+}
+
+// runStatus classifies the outcome of a run stopped with runErr under ctx.
+func runStatus(ctx context.Context, runErr error) string {
+	switch {
+	case isAborted(context.Cause(ctx)):
+		return runStatusAborted
+	case runErr != nil:
+		return runStatusFailed
+	default:
+		return runStatusSucceeded
+	}
+}
+
+// RecordMetrics sets m to record the runs that follow, labelled with cluster.
+func (k *KrknAI) RecordMetrics(m *Metrics, cluster string) {
+	k.metrics = m
+	k.metricsCluster = cluster
+}
+
+// ServeMetrics serves the metrics gatherer collects at /metrics on addr, e.g. :9090, until
+// the returned stop is called.
+func ServeMetrics(addr string, gatherer prometheusclient.Gatherer) (stop func(), err error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning - metrics server stopped: %v", err)
+		}
+	}()
+	log.Printf("Serving krkn-ai metrics at http://%s/metrics", listener.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Warning - failed to stop the metrics server: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,120 @@
+package krknai
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := NewMetrics(registry)
+	require.NoError(t, err)
+	_, err = NewMetrics(registry)
+	assert.ErrorContains(t, err, "failed to register krkn-ai metrics")
+
+	now := time.Now()
+	m.runStarted("fleet-1", config.KrknAIModeRun)
+	m.observe("fleet-1", ProgressEvent{Type: ProgressGenerationStarted, Generation: 2, Time: now})
+	m.observe("fleet-1", ProgressEvent{Type: ProgressScenarioExecuted, Scenario: "pod_scenarios", Generation: 2, Time: now})
+	m.observe("fleet-1", ProgressEvent{Type: ProgressScenarioExecuted, Scenario: "pod_scenarios", Generation: 2, Time: now})
+	m.observe("fleet-1", ProgressEvent{Type: ProgressHealthCheckFailed, Target: "console", Generation: 2, Time: now})
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.scenarios.WithLabelValues("fleet-1", "pod_scenarios")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.healthCheckFailures.WithLabelValues("fleet-1")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.generation.WithLabelValues("fleet-1")))
+	assert.Equal(t, float64(now.Unix()), testutil.ToFloat64(m.lastProgress.WithLabelValues("fleet-1")))
+	require.NoError(t, testutil.CollectAndCompare(m, strings.NewReader(`
+# HELP krknai_run_in_progress Whether the latest krkn-ai run per cluster and mode is still running (1) or has finished (0).
+# TYPE krknai_run_in_progress gauge
+krknai_run_in_progress{cluster="fleet-1",mode="run"} 1
+`), "krknai_run_in_progress"))
+
+	m.runFinished("fleet-1", config.KrknAIModeRun, runStatusAborted)
+	require.NoError(t, testutil.CollectAndCompare(m, strings.NewReader(`
+# HELP krknai_run_in_progress Whether the latest krkn-ai run per cluster and mode is still running (1) or has finished (0).
+# TYPE krknai_run_in_progress gauge
+krknai_run_in_progress{cluster="fleet-1",mode="run"} 0
+# HELP krknai_runs_total Finished krkn-ai runs by cluster, mode, and status (succeeded, failed, or aborted).
+# TYPE krknai_runs_total counter
+krknai_runs_total{cluster="fleet-1",mode="run",status="aborted"} 1
+`), "krknai_run_in_progress", "krknai_runs_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(m, "krknai_run_duration_seconds"))
+}
+
+func TestRunStatus(t *testing.T) {
+	assert.Equal(t, runStatusSucceeded, runStatus(context.Background(), nil))
+	assert.Equal(t, runStatusFailed, runStatus(context.Background(), io.ErrUnexpectedEOF))
+
+	ctx, abort := context.WithCancelCause(context.Background())
+	abort(&abortError{reason: "cluster operators held for 10m"})
+	assert.Equal(t, runStatusAborted, runStatus(ctx, context.Canceled))
+}
+
+func TestRunKrknMetrics(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"echo '[INFO] | Generation 3 |' >&2\n" +
+		"echo 'Running scenario: node_cpu_hog'\n" +
+		"echo 'Health check failed for console'\n" +
+		"exit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "podman"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	setupKrknConfig(t, map[string]any{config.ReportDir: t.TempDir()})
+
+	m, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+	var events int
+	k := &KrknAI{}
+	k.OnProgress(func(ProgressEvent) { events++ })
+	k.RecordMetrics(m, "abc123")
+	require.Error(t, k.runKrkn(context.Background(), config.KrknAIModeRun))
+
+	assert.Equal(t, 3, events, "progress events still reach OnProgress")
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.generation.WithLabelValues("abc123")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.scenarios.WithLabelValues("abc123", "node_cpu_hog")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.healthCheckFailures.WithLabelValues("abc123")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.runs.WithLabelValues("abc123", config.KrknAIModeRun, runStatusFailed)))
+}
+
+func TestServeMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := NewMetrics(registry)
+	require.NoError(t, err)
+	m.runStarted("abc123", config.KrknAIModeRun)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	stop, err := ServeMetrics(addr, registry)
+	require.NoError(t, err)
+	_, err = ServeMetrics(addr, registry)
+	assert.ErrorContains(t, err, "failed to listen for metrics on "+addr)
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `krknai_run_in_progress{cluster="abc123",mode="run"} 1`)
+	assert.Contains(t, string(body), `krknai_current_generation{cluster="abc123"} 0`)
+
+	stop()
+	_, err = http.Get("http://" + addr + "/metrics")
+	assert.Error(t, err, "the server is stopped")
+}
@@ -0,0 +1,211 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/openshift/osde2e/pkg/common/orchestrator"
+	"github.com/openshift/osde2e/pkg/common/providers"
+	"github.com/openshift/osde2e/pkg/common/spi"
+	"gopkg.in/yaml.v3"
+)
+
+// multiSummaryFileName is the per-cluster outcome summary written to the results directory.
+const multiSummaryFileName = "clusters.yaml"
+
+// dirNamePattern keeps cluster and hook names usable as directory names.
+var dirNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ClusterTarget is one cluster of a multi-cluster run, reached through its kubeconfig or,
+// when none is given, the kubeconfig the cluster provider returns for its ID.
+type ClusterTarget struct {
+	Name       string `yaml:"name"` // Results subdirectory; defaults to the cluster ID
+	ClusterID  string `yaml:"cluster_id"`
+	Kubeconfig string `yaml:"kubeconfig"` // Path to the cluster's kubeconfig
+}
+
+// MultiOptions configures RunMulti.
+type MultiOptions struct {
+	ResultsDir  string       // Parent of the per-cluster results directories
+	SharedDir   string       // Parent of the per-cluster working directories holding kubeconfigs and configs
+	Concurrency int          // Clusters run at once; values below 1 run them one at a time
+	Provider    spi.Provider // Fetches kubeconfigs for targets without one; defaults to the configured provider
+	Progress    ProgressFunc // Receives every cluster's progress events, with Cluster set
+	Metrics     *Metrics     // Records every cluster's runs, labelled with the cluster's name
+}
+
+// ClusterResult is the outcome of one cluster's krkn-ai run.
+type ClusterResult struct {
+	Name       string `yaml:"name"`
+	ClusterID  string `yaml:"cluster_id,omitempty"`
+	ResultsDir string `yaml:"results_dir"`
+	Passed     bool   `yaml:"passed"`
+	Error      string `yaml:"error,omitempty"`
+}
+
+// ParseClusterTargets parses a YAML or JSON list of clusters, e.g.
+// [{name: fleet-1, kubeconfig: /kube/fleet-1}, {cluster_id: 2a3b4c}]. Each needs a
+// kubeconfig or a cluster ID, and names must be unique.
+func ParseClusterTargets(input string) ([]ClusterTarget, error) {
+	var targets []ClusterTarget
+	if err := yaml.Unmarshal([]byte(input), &targets); err != nil {
+		return nil, fmt.Errorf("invalid clusters (expected a YAML or JSON list of name, cluster_id, and kubeconfig): %w", err)
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no clusters to run")
+	}
+	seen := make(map[string]bool, len(targets))
+	for idx := range targets {
+		target := &targets[idx]
+		target.Name = strings.TrimSpace(target.Name)
+		target.ClusterID = strings.TrimSpace(target.ClusterID)
+		target.Kubeconfig = strings.TrimSpace(target.Kubeconfig)
+		if target.Kubeconfig == "" && target.ClusterID == "" {
+			return nil, fmt.Errorf("invalid cluster %d (kubeconfig or cluster_id required)", idx+1)
+		}
+		if target.Name == "" {
+			target.Name = target.ClusterID
+		}
+		if !dirNamePattern.MatchString(target.Name) {
+			return nil, fmt.Errorf("invalid cluster name %q (expected letters, digits, '.', '_', or '-')", target.Name)
+		}
+		if seen[target.Name] {
+			return nil, fmt.Errorf("duplicate cluster %q", target.Name)
+		}
+		seen[target.Name] = true
+	}
+	return targets, nil
+}
+
+// RunMulti runs krkn-ai against every target, up to opts.Concurrency clusters at once.
+// Each cluster runs the single-cluster flow (discovery, config update, run mode, and the
+// log analysis when enabled) with its own working directory under opts.SharedDir and
+// results directory under opts.ResultsDir, where clusters.yaml summarizes the outcomes.
+// Clusters are neither provisioned nor cleaned up. The error reports runs that could not
+// be set up; per-cluster failures are in the results.
+func RunMulti(ctx context.Context, targets []ClusterTarget, opts MultiOptions) ([]ClusterResult, error) {
+	return runMulti(ctx, targets, opts, runCluster)
+}
+
+// runCluster runs the single-cluster flow against k's directories.
+func runCluster(ctx context.Context, k *KrknAI) error {
+	err := k.Execute(ctx)
+	if viper.GetBool(config.LogAnalysis.EnableAnalysis) {
+		if analysisErr := k.AnalyzeLogs(ctx, err); analysisErr != nil {
+			log.Printf("Log analysis of %s failed: %v", k.reportDir(), analysisErr)
+		}
+	}
+	return err
+}
+
+func runMulti(ctx context.Context, targets []ClusterTarget, opts MultiOptions, run func(context.Context, *KrknAI) error) ([]ClusterResult, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no clusters to run")
+	}
+	if opts.ResultsDir == "" || opts.SharedDir == "" {
+		return nil, errors.New("multi-cluster runs need a results and a shared directory")
+	}
+
+	// Set up every cluster before any chaos starts so a bad target doesn't leave a partial fleet run
+	runs := make([]*KrknAI, len(targets))
+	for i, target := range targets {
+		if target.Kubeconfig == "" && opts.Provider == nil {
+			provider, err := providers.ClusterProvider()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get cluster provider: %w", err)
+			}
+			opts.Provider = provider
+		}
+		k, err := prepareClusterRun(target, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up cluster %s: %w", target.Name, err)
+		}
+		runs[i] = k
+	}
+
+	results := make([]ClusterResult, len(targets))
+	sem := make(chan struct{}, max(opts.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i, k := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			target := targets[i]
+			log.Printf("Starting krkn-ai run for cluster %s", target.Name)
+			err := run(ctx, k)
+			result := ClusterResult{
+				Name:       target.Name,
+				ClusterID:  target.ClusterID,
+				ResultsDir: k.reportDirPath,
+				Passed:     err == nil && k.result.ExitCode == config.Success,
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			log.Printf("Finished krkn-ai run for cluster %s (passed: %t)", target.Name, result.Passed)
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	content, err := yaml.Marshal(map[string]any{"clusters": results})
+	if err != nil {
+		return results, fmt.Errorf("failed to marshal cluster summary: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.ResultsDir, multiSummaryFileName), content, 0o644); err != nil {
+		return results, fmt.Errorf("failed to write cluster summary: %w", err)
+	}
+	return results, nil
+}
+
+// prepareClusterRun creates the target's directories, writes its kubeconfig to the working
+// directory, and returns an orchestrator bound to them.
+func prepareClusterRun(target ClusterTarget, opts MultiOptions) (*KrknAI, error) {
+	k := &KrknAI{
+		provider:      opts.Provider,
+		result:        &orchestrator.Result{ClusterID: target.ClusterID, ExitCode: config.Success},
+		sharedDirPath: filepath.Join(opts.SharedDir, target.Name),
+		reportDirPath: filepath.Join(opts.ResultsDir, target.Name),
+	}
+	if opts.Progress != nil {
+		k.OnProgress(func(event ProgressEvent) {
+			event.Cluster = target.Name
+			opts.Progress(event)
+		})
+	}
+	if opts.Metrics != nil {
+		k.RecordMetrics(opts.Metrics, target.Name)
+	}
+	for _, dir := range []string{k.sharedDirPath, k.reportDirPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	var kubeconfig []byte
+	var err error
+	if target.Kubeconfig != "" {
+		kubeconfig, err = os.ReadFile(target.Kubeconfig)
+	} else {
+		kubeconfig, err = opts.Provider.ClusterKubeconfig(target.ClusterID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(k.sharedDirPath, kubeconfigFileName), kubeconfig, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return k, nil
+}
@@ -0,0 +1,117 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseClusterTargets(t *testing.T) {
+	targets, err := ParseClusterTargets(`[{"name": "fleet-1", "kubeconfig": "/kube/fleet-1"}, {"cluster_id": " 2a3b4c "}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []ClusterTarget{
+		{Name: "fleet-1", Kubeconfig: "/kube/fleet-1"},
+		{Name: "2a3b4c", ClusterID: "2a3b4c"},
+	}, targets)
+
+	targets, err = ParseClusterTargets("- name: fleet-1\n  cluster_id: abc\n")
+	require.NoError(t, err)
+	assert.Equal(t, []ClusterTarget{{Name: "fleet-1", ClusterID: "abc"}}, targets)
+
+	for input, want := range map[string]string{
+		"[]":                                     "no clusters to run",
+		"name: fleet-1":                          "invalid clusters",
+		"[{name: fleet-1}]":                      "kubeconfig or cluster_id required",
+		"[{name: ../up, cluster_id: abc}]":       `invalid cluster name "../up"`,
+		"[{cluster_id: abc}, {cluster_id: abc}]": `duplicate cluster "abc"`,
+	} {
+		_, err := ParseClusterTargets(input)
+		assert.ErrorContains(t, err, want, input)
+	}
+}
+
+func TestRunMulti(t *testing.T) {
+	resultsDir, sharedDir, kubeDir := t.TempDir(), t.TempDir(), t.TempDir()
+	var targets []ClusterTarget
+	for _, name := range []string{"fleet-1", "fleet-2", "fleet-3"} {
+		kubeconfig := filepath.Join(kubeDir, name)
+		require.NoError(t, os.WriteFile(kubeconfig, []byte("kubeconfig: "+name), 0o644))
+		targets = append(targets, ClusterTarget{Name: name, Kubeconfig: kubeconfig})
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	run := func(_ context.Context, k *KrknAI) error {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		kubeconfig, err := os.ReadFile(filepath.Join(k.sharedDir(), kubeconfigFileName))
+		if err != nil {
+			return err
+		}
+		switch filepath.Base(k.reportDir()) {
+		case "fleet-2":
+			return errors.New("container execution failed")
+		case "fleet-3":
+			k.result.ExitCode = config.Failure
+		}
+		return os.WriteFile(filepath.Join(k.reportDir(), "kubeconfig"), kubeconfig, 0o644)
+	}
+
+	results, err := runMulti(context.Background(), targets, MultiOptions{
+		ResultsDir:  resultsDir,
+		SharedDir:   sharedDir,
+		Concurrency: 2,
+	}, run)
+	require.NoError(t, err)
+	assert.Equal(t, 2, maxRunning, "at most Concurrency clusters run at once")
+
+	want := []ClusterResult{
+		{Name: "fleet-1", ResultsDir: filepath.Join(resultsDir, "fleet-1"), Passed: true},
+		{Name: "fleet-2", ResultsDir: filepath.Join(resultsDir, "fleet-2"), Error: "container execution failed"},
+		{Name: "fleet-3", ResultsDir: filepath.Join(resultsDir, "fleet-3")},
+	}
+	assert.Equal(t, want, results)
+
+	kubeconfig, err := os.ReadFile(filepath.Join(resultsDir, "fleet-1", "kubeconfig"))
+	require.NoError(t, err)
+	assert.Equal(t, "kubeconfig: fleet-1", string(kubeconfig), "each cluster runs with its own kubeconfig")
+	info, err := os.Stat(filepath.Join(sharedDir, "fleet-1", kubeconfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	data, err := os.ReadFile(filepath.Join(resultsDir, multiSummaryFileName))
+	require.NoError(t, err)
+	var summary struct {
+		Clusters []ClusterResult `yaml:"clusters"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &summary))
+	assert.Equal(t, want, summary.Clusters)
+}
+
+func TestRunMultiSetupFailure(t *testing.T) {
+	ran := false
+	run := func(context.Context, *KrknAI) error {
+		ran = true
+		return nil
+	}
+	targets := []ClusterTarget{{Name: "fleet-1", Kubeconfig: filepath.Join(t.TempDir(), "missing")}}
+	_, err := runMulti(context.Background(), targets, MultiOptions{ResultsDir: t.TempDir(), SharedDir: t.TempDir()}, run)
+	assert.ErrorContains(t, err, "failed to set up cluster fleet-1")
+	assert.False(t, ran, "no cluster runs when one cannot be set up")
+}
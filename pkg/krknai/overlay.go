@@ -0,0 +1,131 @@
+package krknai
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// overlayField is a single value an overlay file sets, addressed by its key path.
+type overlayField struct {
+	path  []string
+	value interface{}
+}
+
+// name returns the field's dotted key path, e.g. fitness_function.query.
+func (f overlayField) name() string {
+	return strings.Join(f.path, ".")
+}
+
+// readOverlay parses the partial krkn-ai.yaml at path. An empty path means an empty overlay.
+func readOverlay(path string) (map[string]interface{}, error) {
+	if path == "" {
+		return map[string]interface{}{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read krkn-ai overlay: %w", err)
+	}
+	var overlay map[string]interface{}
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse krkn-ai overlay %s: %w", path, err)
+	}
+	return overlay, nil
+}
+
+// parseOverrides parses comma- or newline-separated path=value overrides, e.g.
+// "fitness_function.type=weighted,scenario.syn_flood.enable=true", into an overlay.
+// Values are YAML scalars, so true and 5 keep their types; values containing commas
+// belong in an overlay file instead.
+func parseOverrides(input string) (map[string]interface{}, error) {
+	overlay := map[string]interface{}{}
+	entries := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == '\n' })
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		raw = strings.TrimSpace(raw)
+		if !ok || key == "" || raw == "" {
+			return nil, fmt.Errorf("invalid krkn-ai override %q (expected path=value; use \"\" for an empty string)", entry)
+		}
+		path := strings.Split(key, ".")
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, fmt.Errorf("invalid value for krkn-ai override %s: %w", key, err)
+		}
+
+		node := overlay
+		for i, segment := range path {
+			if segment == "" {
+				return nil, fmt.Errorf("invalid krkn-ai override path %q (empty key)", key)
+			}
+			existing, set := node[segment]
+			if i == len(path)-1 {
+				if set {
+					return nil, fmt.Errorf("krkn-ai override %s is set more than once or conflicts with a nested override", key)
+				}
+				node[segment] = value
+				break
+			}
+			child, isMap := existing.(map[string]interface{})
+			if set && !isMap {
+				return nil, fmt.Errorf("krkn-ai override %s conflicts with override %s", key, strings.Join(path[:i+1], "."))
+			}
+			if !set {
+				child = map[string]interface{}{}
+				node[segment] = child
+			}
+			node = child
+		}
+	}
+	return overlay, nil
+}
+
+// applyOverlay merges overlay into cfg. Maps are merged key by key so keys the overlay
+// leaves out keep their discovered value; any other value, lists included, replaces the
+// discovered one. It returns the fields it set, sorted by key path.
+func applyOverlay(cfg, overlay map[string]interface{}) []overlayField {
+	var fields []overlayField
+	var merge func(dst, src map[string]interface{}, path []string)
+	merge = func(dst, src map[string]interface{}, path []string) {
+		for key, value := range src {
+			keyPath := append(append([]string{}, path...), key)
+			if srcMap, ok := value.(map[string]interface{}); ok {
+				dstMap, ok := dst[key].(map[string]interface{})
+				if !ok {
+					dstMap = map[string]interface{}{}
+					dst[key] = dstMap
+				}
+				merge(dstMap, srcMap, keyPath)
+				continue
+			}
+			dst[key] = value
+			fields = append(fields, overlayField{path: keyPath, value: value})
+		}
+	}
+	merge(cfg, overlay, nil)
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name() < fields[j].name() })
+	return fields
+}
+
+// logOverlayPrecedence logs, for every field the overlay set, whether its value survived
+// into cfg or a krkn-ai parameter applied afterwards replaced it. source names the
+// overlay in the log, e.g. overlay or override.
+func logOverlayPrecedence(cfg map[string]interface{}, fields []overlayField, source string) {
+	for _, field := range fields {
+		current := pathValue(cfg, field.path)
+		if reflect.DeepEqual(current, field.value) {
+			log.Printf("Config %s: %v (from %s)", field.name(), field.value, source)
+		} else {
+			log.Printf("Config %s: %v (parameter overrides %s value %v)", field.name(), current, source, field.value)
+		}
+	}
+}
@@ -1,13 +1,20 @@
-// Health-check URL parsing and validation for krkn-ai config.
+// Parameter parsing and validation for krkn-ai config.
 package krknai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // redactURL returns a URL string safe for logging: userinfo and query are stripped.
@@ -23,32 +30,42 @@ func redactURL(rawURL string) string {
 	return u.String()
 }
 
-// validateHealthCheckURLsReachable performs HTTP GET on each health check URL and returns an error
-// if any return non-2xx or are unreachable. URLs in errors are redacted.
-func validateHealthCheckURLsReachable(ctx context.Context, apps []map[string]interface{}) error {
-	const perRequestTimeout = 10 * time.Second
-	client := &http.Client{Timeout: perRequestTimeout}
+// defaultProbeTimeout applies to health checks without a timeout of their own.
+const defaultProbeTimeout = 10 * time.Second
+
+// healthCheckProbe is the pre-flight outcome for a single health check endpoint.
+type healthCheckProbe struct {
+	name   string
+	url    string // Redacted
+	passed bool
+	status int // HTTP status of the response, 0 when none arrived
+	detail string
+}
+
+// validateHealthCheckURLsReachable probes every health check URL with an HTTP GET, at most
+// concurrency at a time and within the overall deadline (0 disables it). Each probe uses
+// the app's timeout (seconds) and passes when the response matches the app's status_code,
+// or any 2xx when unset. All outcomes are logged as one report; the returned error lists
+// every failed endpoint. URLs in the report and errors are redacted.
+func validateHealthCheckURLsReachable(ctx context.Context, apps []map[string]interface{}, concurrency int, deadline time.Duration) error {
 	var errs []string
-	for _, app := range apps {
-		name, _ := app["name"].(string)
-		rawURL, _ := app["url"].(string)
-		if rawURL == "" {
-			continue
-		}
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s (%s): %v", name, redactURL(rawURL), err))
-			continue
-		}
-		resp, err := client.Do(req)
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s (%s): %v", name, redactURL(rawURL), err))
+	passed, total := 0, 0
+	for _, probe := range probeHealthChecks(ctx, apps, concurrency, deadline) {
+		if probe == nil {
 			continue
 		}
-		_ = resp.Body.Close()
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			errs = append(errs, fmt.Sprintf("%s (%s): HTTP %d", name, redactURL(rawURL), resp.StatusCode))
+		total++
+		status := "FAIL"
+		if probe.passed {
+			status = "ok"
+			passed++
+		} else {
+			errs = append(errs, fmt.Sprintf("%s (%s): %s", probe.name, probe.url, probe.detail))
 		}
+		log.Printf("  [%s] %s (%s): %s", status, probe.name, probe.url, probe.detail)
+	}
+	if total > 0 {
+		log.Printf("Health check pre-flight: %d/%d endpoints passed", passed, total)
 	}
 	if len(errs) > 0 {
 		return fmt.Errorf("health check URL validation failed: %s", strings.Join(errs, "; "))
@@ -56,9 +73,104 @@ func validateHealthCheckURLsReachable(ctx context.Context, apps []map[string]int
 	return nil
 }
 
+// probeHealthChecks probes apps at most concurrency at a time and within deadline (0
+// disables it), returning the outcome for each app in order; apps without a URL get nil.
+func probeHealthChecks(ctx context.Context, apps []map[string]interface{}, concurrency int, deadline time.Duration) []*healthCheckProbe {
+	if deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	probes := make([]*healthCheckProbe, len(apps))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, app := range apps {
+		if rawURL, _ := app["url"].(string); rawURL == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+			}
+			probes[i] = probeHealthCheck(ctx, app)
+		}()
+	}
+	wg.Wait()
+	return probes
+}
+
+// probeHealthCheck issues a single pre-flight request for a health check app.
+func probeHealthCheck(ctx context.Context, app map[string]interface{}) *healthCheckProbe {
+	name, _ := app["name"].(string)
+	rawURL, _ := app["url"].(string)
+	probe := &healthCheckProbe{name: name, url: redactURL(rawURL)}
+
+	if err := ctx.Err(); err != nil {
+		probe.detail = fmt.Sprintf("not probed: %v", err)
+		return probe
+	}
+
+	timeout := defaultProbeTimeout
+	if seconds, ok := numberValue(app["timeout"]); ok && seconds > 0 {
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	client := &http.Client{Timeout: timeout}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		probe.detail = err.Error()
+		return probe
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		probe.detail = err.Error()
+		return probe
+	}
+	_ = resp.Body.Close()
+
+	probe.status = resp.StatusCode
+	probe.detail = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	if expected, ok := numberValue(app["status_code"]); ok && expected > 0 {
+		probe.passed = resp.StatusCode == int(expected)
+		if !probe.passed {
+			probe.detail = fmt.Sprintf("HTTP %d, expected %d", resp.StatusCode, int(expected))
+		}
+	} else {
+		probe.passed = resp.StatusCode >= 200 && resp.StatusCode < 300
+	}
+	return probe
+}
+
+// numberValue converts the numeric types found in parsed health check entries to float64.
+func numberValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// healthCheckDefaults fill in the settings of health check applications added by name.
+var healthCheckDefaults = map[string]int{"status_code": 200, "timeout": 4, "interval": 2}
+
 // parseHealthCheckEndpoints parses a comma-separated string of name=url pairs
-// into health check application entries for the krkn-ai config. Returns an error
-// on the first invalid entry (invalid URL, empty name, unsupported scheme, etc.).
+// into health check application entries for the krkn-ai config. Each URL may be
+// followed by ;status_code=N, ;timeout=N and ;interval=N settings; only the
+// settings given are included. Returns an error on the first invalid entry
+// (invalid URL, empty name, unsupported scheme, unknown setting, etc.).
 func parseHealthCheckEndpoints(input string) ([]map[string]interface{}, error) {
 	var apps []map[string]interface{}
 	for _, entry := range strings.Split(input, ",") {
@@ -71,24 +183,428 @@ func parseHealthCheckEndpoints(input string) ([]map[string]interface{}, error) {
 			return nil, fmt.Errorf("invalid health-check entry (expected name=url): %q", entry)
 		}
 		name := strings.TrimSpace(parts[0])
-		rawURL := strings.TrimSpace(parts[1])
+		rawURL, settings, _ := strings.Cut(parts[1], ";")
+		rawURL = strings.TrimSpace(rawURL)
 		if name == "" || rawURL == "" {
 			return nil, fmt.Errorf("invalid health-check entry (name and url required): %q", entry)
 		}
-		u, err := url.Parse(rawURL)
-		if err != nil || u.Scheme == "" || u.Host == "" {
-			return nil, fmt.Errorf("invalid URL for %q (must include scheme and host, e.g. https://host/path): %q", name, redactURL(rawURL))
+		if err := validateHealthCheckURL(name, rawURL); err != nil {
+			return nil, err
 		}
-		if u.Scheme != "http" && u.Scheme != "https" {
-			return nil, fmt.Errorf("unsupported scheme %q for %q (must be http or https)", u.Scheme, name)
+		app := map[string]interface{}{"name": name, "url": rawURL}
+		if settings != "" {
+			for _, setting := range strings.Split(settings, ";") {
+				key, value, _ := strings.Cut(setting, "=")
+				key = strings.TrimSpace(key)
+				if _, ok := healthCheckDefaults[key]; !ok {
+					return nil, fmt.Errorf("unknown setting %q for %q (expected status_code, timeout or interval)", key, name)
+				}
+				n, err := strconv.Atoi(strings.TrimSpace(value))
+				if err != nil || n <= 0 {
+					return nil, fmt.Errorf("invalid %s for %q (expected a positive integer): %q", key, name, value)
+				}
+				app[key] = n
+			}
 		}
-		apps = append(apps, map[string]interface{}{
-			"name":        name,
-			"url":         rawURL,
-			"status_code": 200,
-			"timeout":     4,
-			"interval":    2,
-		})
+		apps = append(apps, app)
 	}
 	return apps, nil
 }
+
+// validateHealthCheckURL checks that the health check named name has an http or https
+// URL with a host.
+func validateHealthCheckURL(name, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid URL for %q (must include scheme and host, e.g. https://host/path): %q", name, redactURL(rawURL))
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q for %q (must be http or https)", u.Scheme, name)
+	}
+	return nil
+}
+
+// healthCheckAppEntry is one application of a KRKN_HEALTH_CHECK_APPS list.
+type healthCheckAppEntry struct {
+	Name       string `yaml:"name"`
+	URL        string `yaml:"url"`
+	StatusCode *int   `yaml:"status_code"`
+	Timeout    *int   `yaml:"timeout"`
+	Interval   *int   `yaml:"interval"`
+}
+
+// parseHealthCheckApps parses a YAML or JSON list of health check applications, e.g.
+// [{name: console, url: "https://console.example.com", status_code: 200, timeout: 4}],
+// into entries for the krkn-ai config. Each needs a unique name and an http or https URL;
+// status_code, timeout and interval are optional positive integers.
+func parseHealthCheckApps(input string) ([]map[string]interface{}, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+	var entries []healthCheckAppEntry
+	if err := yaml.Unmarshal([]byte(input), &entries); err != nil {
+		return nil, fmt.Errorf("invalid health check applications (expected a YAML or JSON list of name, url, status_code, timeout, and interval): %w", err)
+	}
+	apps := make([]map[string]interface{}, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for idx, entry := range entries {
+		name := strings.TrimSpace(entry.Name)
+		rawURL := strings.TrimSpace(entry.URL)
+		if name == "" || rawURL == "" {
+			return nil, fmt.Errorf("invalid health check application %d (name and url required)", idx+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate health check application %q", name)
+		}
+		seen[name] = true
+		if err := validateHealthCheckURL(name, rawURL); err != nil {
+			return nil, err
+		}
+		app := map[string]interface{}{"name": name, "url": rawURL}
+		for key, value := range map[string]*int{"status_code": entry.StatusCode, "timeout": entry.Timeout, "interval": entry.Interval} {
+			if value == nil {
+				continue
+			}
+			if *value <= 0 {
+				return nil, fmt.Errorf("invalid %s for %q (expected a positive integer): %d", key, name, *value)
+			}
+			app[key] = *value
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// mergeHealthCheckApps applies overrides to the discovered health check applications,
+// matching on name. Matched applications keep any setting the override leaves unset;
+// unmatched overrides are appended with healthCheckDefaults filling the gaps.
+func mergeHealthCheckApps(existing interface{}, overrides []map[string]interface{}) []interface{} {
+	apps, _ := existing.([]interface{})
+	for _, override := range overrides {
+		var matched map[string]interface{}
+		for _, app := range apps {
+			if m, ok := app.(map[string]interface{}); ok && m["name"] == override["name"] {
+				matched = m
+				break
+			}
+		}
+		if matched == nil {
+			matched = map[string]interface{}{}
+			for key, value := range healthCheckDefaults {
+				matched[key] = value
+			}
+			apps = append(apps, matched)
+			log.Printf("Added health check application %s", override["name"])
+		} else {
+			log.Printf("Updated health check application %s", override["name"])
+		}
+		for key, value := range override {
+			matched[key] = value
+		}
+	}
+	return apps
+}
+
+// validateScenariosEnabled returns an error when the config has a scenario section
+// but none of its scenarios are enabled, unless allowNone is set.
+func validateScenariosEnabled(cfg map[string]interface{}, allowNone bool) error {
+	if allowNone {
+		return nil
+	}
+	scenarioCfg, ok := cfg["scenario"].(map[string]interface{})
+	if !ok || len(scenarioCfg) == 0 {
+		return nil
+	}
+	for _, val := range scenarioCfg {
+		if scenarioMap, ok := val.(map[string]interface{}); ok && scenarioMap["enable"] == true {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to write krkn-ai config: no scenarios are enabled (check KRKN_SCENARIOS, or set KRKN_ALLOW_NO_SCENARIOS=true to allow this)")
+}
+
+// validateMinGenerations flags runs configured with fewer generations than minGenerations,
+// which rarely give the genetic algorithm room to converge. It logs a warning, or returns
+// an error when strict is set. A non-positive minGenerations disables the check.
+func validateMinGenerations(generations, minGenerations int, strict bool) error {
+	if minGenerations <= 0 || generations >= minGenerations {
+		return nil
+	}
+	msg := fmt.Sprintf("generations (%d) is below the minimum of %d; the genetic algorithm is unlikely to produce useful results", generations, minGenerations)
+	if strict {
+		return errors.New(msg)
+	}
+	log.Printf("Warning: %s", msg)
+	return nil
+}
+
+// parseGenericScenarios parses a comma-separated list of scenario toggles, each either a
+// bare name (enabled) or name=true|false. Names are not checked against known scenario
+// types so that newly added krkn-ai scenarios can be enabled.
+func parseGenericScenarios(input string) (map[string]bool, error) {
+	scenarios := make(map[string]bool)
+	for _, entry := range strings.Split(input, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid generic scenario entry (name required): %q", entry)
+		}
+		enabled := true
+		if hasValue {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for generic scenario %q (expected true or false): %q", name, value)
+			}
+			enabled = parsed
+		}
+		scenarios[name] = enabled
+	}
+	return scenarios, nil
+}
+
+// parseRunSizeParams parses run size settings keyed by their krkn-ai.yaml name, each of
+// which must be a positive integer. Empty values are skipped.
+func parseRunSizeParams(values map[string]string) (map[string]int, error) {
+	params := make(map[string]int)
+	for key, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid value for %s (expected a positive integer): %q", key, value)
+		}
+		params[key] = n
+	}
+	return params, nil
+}
+
+// validateConfigBounds checks the settings krkn-ai would otherwise reject deep into a run:
+// generations, population_size, and wait_duration must be positive integers, the
+// genetic algorithm rates must lie within [0, 1], and population_injection_size must be
+// a non-negative integer, each when present. Every health check application needs an
+// http or https URL. All problems are reported, joined.
+func validateConfigBounds(cfg map[string]interface{}) error {
+	var errs []error
+	for _, key := range []string{"generations", "population_size", "wait_duration"} {
+		value, ok := cfg[key]
+		if !ok {
+			continue
+		}
+		if n, ok := value.(int); !ok || n <= 0 {
+			errs = append(errs, fmt.Errorf("krkn-ai config %s must be a positive integer, got %v", key, value))
+		}
+	}
+
+	for _, key := range []string{"mutation_rate", "scenario_mutation_rate", "crossover_rate", "population_injection_rate"} {
+		value, ok := cfg[key]
+		if !ok {
+			continue
+		}
+		if rate, ok := numberValue(value); !ok || rate < 0 || rate > 1 {
+			errs = append(errs, fmt.Errorf("krkn-ai config %s must be a number between 0.0 and 1.0, got %v", key, value))
+		}
+	}
+	if value, ok := cfg["population_injection_size"]; ok {
+		if n, ok := value.(int); !ok || n < 0 {
+			errs = append(errs, fmt.Errorf("krkn-ai config population_injection_size must be a non-negative integer, got %v", value))
+		}
+	}
+
+	hc, _ := cfg["health_checks"].(map[string]interface{})
+	apps, _ := hc["applications"].([]interface{})
+	for _, app := range apps {
+		m, _ := app.(map[string]interface{})
+		rawURL, _ := m["url"].(string)
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("krkn-ai config health check %v must have an http or https URL with a host, got %q", m["name"], redactURL(rawURL)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateFitnessQuery checks that the config gives krkn-ai something to score runs with:
+// a non-empty fitness_function.query, or fitness_function.items.
+func validateFitnessQuery(cfg map[string]interface{}) error {
+	ff, ok := cfg["fitness_function"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("krkn-ai config has no fitness_function section")
+	}
+	if items, _ := ff["items"].([]interface{}); len(items) > 0 {
+		return nil
+	}
+	if query, _ := ff["query"].(string); strings.TrimSpace(query) == "" {
+		return fmt.Errorf("krkn-ai config fitness_function.query must be a non-empty query (check KRKN_FITNESS_QUERY)")
+	}
+	return nil
+}
+
+// validateMergedConfig checks the config the updater is about to write, so krkn-ai isn't
+// started with settings it would crash on mid-run. Every problem is reported, joined.
+func validateMergedConfig(cfg map[string]interface{}, allowNoScenarios bool) error {
+	return errors.Join(
+		validateScenariosEnabled(cfg, allowNoScenarios),
+		validateFitnessQuery(cfg),
+		validateConfigBounds(cfg),
+	)
+}
+
+// gaRateParams are the genetic algorithm settings that are probabilities between 0 and 1.
+var gaRateParams = map[string]bool{
+	"mutation_rate":             true,
+	"scenario_mutation_rate":    true,
+	"crossover_rate":            true,
+	"population_injection_rate": true,
+}
+
+// parseGAParams parses genetic algorithm settings keyed by their krkn-ai.yaml name. Rates must
+// lie within [0, 1] and counts must be non-negative; empty values are skipped.
+func parseGAParams(values map[string]string) (map[string]any, error) {
+	params := make(map[string]any)
+	for key, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if gaRateParams[key] {
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s (expected a number): %q", key, value)
+			}
+			if rate < 0 || rate > 1 {
+				return nil, fmt.Errorf("%s must be between 0.0 and 1.0, got %g", key, rate)
+			}
+			params[key] = rate
+			continue
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s (expected an integer): %q", key, value)
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("%s must not be negative, got %d", key, count)
+		}
+		params[key] = count
+	}
+	return params, nil
+}
+
+// parseScenarioToggles parses per-scenario enable values keyed by scenario name. Empty values
+// are skipped so the discovered setting is kept.
+func parseScenarioToggles(values map[string]string) (map[string]bool, error) {
+	toggles := make(map[string]bool)
+	for name, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for scenario.%s.enable (expected true or false): %q", name, value)
+		}
+		toggles[name] = enabled
+	}
+	return toggles, nil
+}
+
+// parseScenarioParams parses comma-separated scenario.param=value settings, e.g.
+// "node_cpu_hog.duration=60,pod_scenarios.kill_count=2", keyed by scenario then parameter.
+// Values are YAML scalars and numbers must not be negative. enable is left to the
+// scenario toggles.
+func parseScenarioParams(input string) (map[string]map[string]interface{}, error) {
+	params := make(map[string]map[string]interface{})
+	for _, entry := range strings.Split(input, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(entry, "=")
+		scenario, param, dotted := strings.Cut(strings.TrimSpace(key), ".")
+		raw = strings.TrimSpace(raw)
+		if !ok || !dotted || scenario == "" || param == "" || strings.Contains(param, ".") || raw == "" {
+			return nil, fmt.Errorf("invalid scenario parameter %q (expected scenario.param=value)", entry)
+		}
+		if param == "enable" {
+			return nil, fmt.Errorf("invalid scenario parameter %q (use KRKN_SCENARIOS or KRKN_GENERIC_SCENARIOS to enable scenarios)", entry)
+		}
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, fmt.Errorf("invalid value for scenario parameter %s.%s: %w", scenario, param, err)
+		}
+		switch v := value.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return nil, fmt.Errorf("invalid value for scenario parameter %s.%s (expected a number, boolean or string): %q", scenario, param, raw)
+		default:
+			if n, ok := numberValue(v); ok && n < 0 {
+				return nil, fmt.Errorf("invalid value for scenario parameter %s.%s (must not be negative): %v", scenario, param, v)
+			}
+		}
+		if params[scenario] == nil {
+			params[scenario] = make(map[string]interface{})
+		}
+		if _, dup := params[scenario][param]; dup {
+			return nil, fmt.Errorf("duplicate scenario parameter %s.%s", scenario, param)
+		}
+		params[scenario][param] = value
+	}
+	return params, nil
+}
+
+// applyScenarioParams writes params into cfg's scenario entries, adding entries the
+// discovered config lacks. It does not enable or disable any scenario.
+func applyScenarioParams(cfg map[string]interface{}, params map[string]map[string]interface{}) {
+	if len(params) == 0 {
+		return
+	}
+	scenarioCfg, ok := cfg["scenario"].(map[string]interface{})
+	if !ok {
+		scenarioCfg = map[string]interface{}{}
+		cfg["scenario"] = scenarioCfg
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		scenarioMap, ok := scenarioCfg[name].(map[string]interface{})
+		if !ok {
+			scenarioMap = map[string]interface{}{}
+			scenarioCfg[name] = scenarioMap
+			log.Printf("Scenario %s is not in the config; adding it with its parameters but not enabling it", name)
+		}
+		keys := make([]string, 0, len(params[name]))
+		for key := range params[name] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			log.Printf("Updated scenario.%s.%s from %v to %v", name, key, scenarioMap[key], params[name][key])
+			scenarioMap[key] = params[name][key]
+		}
+	}
+}
+
+// parseFitnessIncludeFlags parses the optional fitness_function include_* flags, keyed by
+// their krkn-ai config name. Empty values are omitted so the discovered value is kept.
+func parseFitnessIncludeFlags(values map[string]string) (map[string]bool, error) {
+	flags := make(map[string]bool)
+	for key, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for fitness_function.%s (expected true or false): %q", key, value)
+		}
+		flags[key] = enabled
+	}
+	return flags, nil
+}
@@ -0,0 +1,277 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Preflight check names, as accepted by KRKN_PREFLIGHT_SKIP.
+const (
+	PreflightCheckParams       = "params"
+	PreflightCheckConfig       = "config"
+	PreflightCheckCluster      = "cluster"
+	PreflightCheckHealthChecks = "health_checks"
+	PreflightCheckFitnessQuery = "fitness_query"
+)
+
+// clusterProbeTimeout bounds the API server request made by the cluster check.
+const clusterProbeTimeout = 30 * time.Second
+
+// PreflightConfig selects which preflight checks run and where the cluster kubeconfig is.
+type PreflightConfig struct {
+	KubeconfigPath   string
+	SkipParams       bool
+	SkipConfig       bool
+	SkipCluster      bool
+	SkipHealthChecks bool
+	SkipFitnessQuery bool
+
+	templates *templateResolver // Defaults to the cluster behind KubeconfigPath
+}
+
+// PreflightCheck is the outcome of a single preflight check.
+type PreflightCheck struct {
+	Name    string `yaml:"name"`
+	Passed  bool   `yaml:"passed"`
+	Skipped bool   `yaml:"skipped,omitempty"`
+	Message string `yaml:"message"`
+}
+
+// PreflightReport collects the outcome of every preflight check, in the order they ran.
+type PreflightReport struct {
+	Checks []PreflightCheck `yaml:"checks"`
+}
+
+// Passed reports whether every check that ran passed.
+func (r *PreflightReport) Passed() bool {
+	return r.Err() == nil
+}
+
+// Err joins the messages of the failed checks, or returns nil when none failed.
+func (r *PreflightReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if !check.Passed && !check.Skipped {
+			errs = append(errs, fmt.Errorf("preflight %s: %s", check.Name, check.Message))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Preflight validates the krkn-ai parameters, the discovered config at discoveredPath, cluster
+// connectivity, health check endpoints, and the fitness query before a run. Every check runs even if an earlier
+// one fails, so the report lists all problems at once.
+func Preflight(ctx context.Context, cfg PreflightConfig, discoveredPath string) *PreflightReport {
+	report := &PreflightReport{}
+	templates := cfg.templates
+	if templates == nil {
+		templates = clusterTemplates(cfg.KubeconfigPath, "")
+	}
+	run := func(name string, skip bool, check func() (string, error)) {
+		if skip {
+			report.Checks = append(report.Checks, PreflightCheck{Name: name, Passed: true, Skipped: true, Message: "skipped"})
+			return
+		}
+		message, err := check()
+		if err != nil {
+			report.Checks = append(report.Checks, PreflightCheck{Name: name, Message: err.Error()})
+			return
+		}
+		report.Checks = append(report.Checks, PreflightCheck{Name: name, Passed: true, Message: message})
+	}
+
+	run(PreflightCheckParams, cfg.SkipParams, preflightParams)
+	run(PreflightCheckConfig, cfg.SkipConfig, func() (string, error) { return preflightConfig(discoveredPath) })
+	run(PreflightCheckCluster, cfg.SkipCluster, func() (string, error) { return preflightCluster(ctx, cfg.KubeconfigPath) })
+	run(PreflightCheckHealthChecks, cfg.SkipHealthChecks, func() (string, error) { return preflightHealthChecks(ctx, templates) })
+	run(PreflightCheckFitnessQuery, cfg.SkipFitnessQuery, func() (string, error) { return preflightFitnessQuery(ctx, cfg.KubeconfigPath, templates) })
+	return report
+}
+
+// parsePreflightSkip turns a comma-separated list of check names into skip flags on cfg.
+func parsePreflightSkip(input string, cfg *PreflightConfig) error {
+	for _, name := range strings.Split(input, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case PreflightCheckParams:
+			cfg.SkipParams = true
+		case PreflightCheckConfig:
+			cfg.SkipConfig = true
+		case PreflightCheckCluster:
+			cfg.SkipCluster = true
+		case PreflightCheckHealthChecks:
+			cfg.SkipHealthChecks = true
+		case PreflightCheckFitnessQuery:
+			cfg.SkipFitnessQuery = true
+		default:
+			return fmt.Errorf("unknown preflight check %q (valid: %s, %s, %s, %s, %s)", strings.TrimSpace(name),
+				PreflightCheckParams, PreflightCheckConfig, PreflightCheckCluster, PreflightCheckHealthChecks, PreflightCheckFitnessQuery)
+		}
+	}
+	return nil
+}
+
+// preflightParams parses every krkn-ai parameter the same way updateKrknConfig does.
+func preflightParams() (string, error) {
+	if err := validateParams(); err != nil {
+		return "", err
+	}
+	return "parameters are valid", nil
+}
+
+// validateParams parses every krkn-ai parameter and joins the errors of all that are
+// invalid, so one run reports every problem. Each failure keeps its parser's message and
+// is reachable through the joined error's Unwrap() []error.
+func validateParams() error {
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	_, err := parseMode(viper.GetString(config.KrknAI.Mode))
+	check(err)
+	_, err = parseFitnessIncludeFlags(map[string]string{
+		"include_health_check_failure":       viper.GetString(config.KrknAI.IncludeHealthCheckFailure),
+		"include_health_check_response_time": viper.GetString(config.KrknAI.IncludeHealthCheckResponseTime),
+		"include_krkn_failure":               viper.GetString(config.KrknAI.IncludeKrknFailure),
+	})
+	check(err)
+	// Templates are checked against placeholder cluster variables; the cluster isn't known yet
+	ctx := context.Background()
+	if fitnessItems, err := placeholderTemplates.param(ctx, config.KrknAI.FitnessItems); err != nil {
+		check(err)
+	} else {
+		_, err = parseFitnessItems(fitnessItems)
+		check(err)
+	}
+	_, err = parseGenericScenarios(viper.GetString(config.KrknAI.GenericScenarios))
+	check(err)
+	_, err = parseScenarioToggles(scenarioToggleValues())
+	check(err)
+	_, err = parseGAParams(gaParamValues())
+	check(err)
+	if scenarioParams, err := placeholderTemplates.param(ctx, config.KrknAI.ScenarioParams); err != nil {
+		check(err)
+	} else {
+		_, err = parseScenarioParams(scenarioParams)
+		check(err)
+	}
+	_, err = componentFilterFromConfig()
+	check(err)
+	_, err = scopeFromConfig()
+	check(err)
+	_, err = scenarioRulesModeFromConfig()
+	check(err)
+	_, err = configuredHealthChecks(ctx, placeholderTemplates)
+	check(err)
+	if overlay, err := readOverlay(viper.GetString(config.KrknAI.OverlayPath)); err != nil {
+		check(err)
+	} else {
+		check(placeholderTemplates.expandConfig(ctx, overlay))
+	}
+	if overrides, err := placeholderTemplates.param(ctx, config.KrknAI.Overrides); err != nil {
+		check(err)
+	} else {
+		_, err = parseOverrides(overrides)
+		check(err)
+	}
+	if name := strings.TrimSpace(viper.GetString(config.KrknAI.Profile)); name != "" {
+		_, err = loadProfile(name)
+		check(err)
+	}
+	if _, err := parseHooks(viper.GetString(config.KrknAI.PreRunHooks)); err != nil {
+		check(fmt.Errorf("invalid pre-run hooks: %w", err))
+	}
+	if _, err := parseHooks(viper.GetString(config.KrknAI.PostRunHooks)); err != nil {
+		check(fmt.Errorf("invalid post-run hooks: %w", err))
+	}
+	if _, err := parseHooks(viper.GetString(config.KrknAI.AbortRemediationHooks)); err != nil {
+		check(fmt.Errorf("invalid abort remediation hooks: %w", err))
+	}
+	_, err = abortGuardrailsFromConfig(ctx, placeholderTemplates)
+	check(err)
+	_, err = backendFromConfig()
+	check(err)
+	_, _, err = parseKubeconfigSource(viper.GetString(config.KrknAI.KubeconfigSource))
+	check(err)
+	_, err = kubeconfigRefreshInterval()
+	check(err)
+	runSize, err := parseRunSizeParams(runSizeValues())
+	check(err)
+	if generations := runSize["generations"]; err == nil && generations > 0 {
+		check(validateMinGenerations(generations, viper.GetInt(config.KrknAI.MinGenerations), viper.GetBool(config.KrknAI.StrictValidation)))
+	}
+	return errors.Join(errs...)
+}
+
+// preflightConfig checks that the discovered config parses and has the sections the updater writes to.
+func preflightConfig(discoveredPath string) (string, error) {
+	data, err := os.ReadFile(discoveredPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read discovered config: %w", err)
+	}
+	var cfg map[string]interface{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("failed to parse discovered config: %w", err)
+	}
+
+	scenarioCfg, ok := cfg["scenario"].(map[string]interface{})
+	if !ok || len(scenarioCfg) == 0 {
+		return "", fmt.Errorf("discovered config has no scenario section")
+	}
+	if viper.GetString(config.KrknAI.FitnessQuery) != "" {
+		if _, ok := cfg["fitness_function"].(map[string]interface{}); !ok {
+			return "", fmt.Errorf("a fitness query is set but the discovered config has no fitness_function section")
+		}
+	}
+	if err := validateConfigBounds(cfg); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("discovered config has %d scenario(s)", len(scenarioCfg)), nil
+}
+
+// preflightCluster checks that the API server is reachable with the kubeconfig.
+func preflightCluster(ctx context.Context, kubeconfigPath string) (string, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	restConfig.Timeout = clusterProbeTimeout
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create kube client: %w", err)
+	}
+	if err := client.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error(); err != nil {
+		return "", fmt.Errorf("failed to reach API server %s: %w", redactURL(restConfig.Host), err)
+	}
+	return fmt.Sprintf("reached API server %s", redactURL(restConfig.Host)), nil
+}
+
+// preflightHealthChecks probes the configured health check endpoints.
+func preflightHealthChecks(ctx context.Context, templates *templateResolver) (string, error) {
+	apps, err := configuredHealthChecks(ctx, templates)
+	if err != nil {
+		return "", err
+	}
+	if len(apps) == 0 {
+		return "no health check endpoints configured", nil
+	}
+	concurrency := viper.GetInt(config.KrknAI.HealthCheckProbeConcurrency)
+	deadline := viper.GetDuration(config.KrknAI.HealthCheckProbeDeadline)
+	if err := validateHealthCheckURLsReachable(ctx, apps, concurrency, deadline); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d endpoint(s) reachable", len(apps)), nil
+}
@@ -0,0 +1,93 @@
+package krknai
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles/*.yaml
+var embeddedProfiles embed.FS
+
+// loadProfile returns the parameters of the named embedded profile keyed by viper key.
+// Profile files name parameters by their key without the krknAI. prefix, e.g.
+// generations or enableSynFlood.
+func loadProfile(name string) (map[string]any, error) {
+	content, err := embeddedProfiles.ReadFile(path.Join("profiles", name+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown krkn-ai profile %q (available: %s)", name, strings.Join(availableProfiles(), ", "))
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse krkn-ai profile %q: %w", name, err)
+	}
+
+	keys := profileParamKeys()
+	params := make(map[string]any, len(values))
+	for name, value := range values {
+		key, ok := keys[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("krkn-ai profile sets unknown parameter %q", name)
+		}
+		switch value.(type) {
+		case string, int, float64, bool:
+		default:
+			return nil, fmt.Errorf("krkn-ai profile parameter %s must be a scalar, got %T", name, value)
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// profileParamKeys maps the lowercase name of every krkn-ai parameter a profile may set to
+// its viper key. A profile can't select another profile.
+func profileParamKeys() map[string]string {
+	keys := make(map[string]string)
+	fields := reflect.ValueOf(config.KrknAI)
+	for i := 0; i < fields.NumField(); i++ {
+		key := fields.Field(i).String()
+		if key != config.KrknAI.Profile {
+			keys[strings.ToLower(strings.TrimPrefix(key, krknAIKeyPrefix))] = key
+		}
+	}
+	return keys
+}
+
+// availableProfiles lists the embedded profile names.
+func availableProfiles() []string {
+	entries, _ := fs.ReadDir(embeddedProfiles, "profiles")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile makes the parameters of the KRKN_AI_PROFILE profile the krkn-ai defaults,
+// so parameters set individually, through the environment or a config file, still take
+// precedence. It does nothing when no profile is selected.
+func ApplyProfile() error {
+	name := strings.TrimSpace(viper.GetString(config.KrknAI.Profile))
+	if name == "" {
+		return nil
+	}
+	params, err := loadProfile(name)
+	if err != nil {
+		return err
+	}
+	for key, value := range params {
+		viper.SetDefault(key, value)
+	}
+	log.Printf("Applied krkn-ai profile %s (%d parameters); parameters set individually take precedence", name, len(params))
+	return nil
+}
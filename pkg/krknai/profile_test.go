@@ -0,0 +1,57 @@
+package krknai
+
+import (
+	"testing"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProfile(t *testing.T) {
+	assert.Equal(t, []string{"nightly", "smoke", "weekend-deep"}, availableProfiles())
+
+	for _, name := range availableProfiles() {
+		t.Run(name, func(t *testing.T) {
+			params, err := loadProfile(name)
+			require.NoError(t, err)
+			assert.Contains(t, params, config.KrknAI.Generations)
+			assert.Contains(t, params, config.KrknAI.Population)
+
+			// Every profile value must be a valid parameter value
+			setupKrknConfig(t, params)
+			assert.NoError(t, validateParams())
+		})
+	}
+
+	_, err := loadProfile("quick")
+	assert.EqualError(t, err, `unknown krkn-ai profile "quick" (available: nightly, smoke, weekend-deep)`)
+}
+
+func TestApplyProfile(t *testing.T) {
+	params, err := loadProfile("smoke")
+	require.NoError(t, err)
+	// Profiles replace the parameter defaults, so put the previous defaults back afterwards
+	for key := range params {
+		previous := viper.Get(key)
+		t.Cleanup(func() { viper.SetDefault(key, previous) })
+	}
+
+	// A nil value falls through to the default, as if the parameter was never set
+	setupKrknConfig(t, map[string]any{
+		config.KrknAI.Profile:     "smoke",
+		config.KrknAI.Generations: nil,
+		config.KrknAI.Population:  nil,
+		config.KrknAI.Scenarios:   "pod_scenarios",
+	})
+
+	require.NoError(t, ApplyProfile())
+	assert.Equal(t, 2, viper.GetInt(config.KrknAI.Generations))
+	assert.Equal(t, 4, viper.GetInt(config.KrknAI.Population))
+	assert.Equal(t, "pod_scenarios", viper.GetString(config.KrknAI.Scenarios), "parameters set individually take precedence")
+
+	viper.Set(config.KrknAI.Profile, "quick")
+	assert.ErrorContains(t, ApplyProfile(), `unknown krkn-ai profile "quick"`)
+	assert.ErrorContains(t, validateParams(), `unknown krkn-ai profile "quick"`)
+}
@@ -0,0 +1,154 @@
+package krknai
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress event types.
+const (
+	ProgressGenerationStarted = "generation_started"
+	ProgressScenarioExecuted  = "scenario_executed"
+	ProgressHealthCheckFailed = "health_check_failed"
+)
+
+// ProgressEvent is a step of a krkn-ai run, parsed from the container output as it runs.
+type ProgressEvent struct {
+	Type       string
+	Time       time.Time
+	Mode       string // The container mode, e.g. run or krkn
+	Cluster    string // The cluster's name in a multi-cluster run
+	Generation int    // The generation running, 0 before the first one or in krkn mode
+	Scenario   string // Scenario events only
+	Target     string // Health check events only: the application or URL that failed, when logged
+	Line       string // The output line the event was parsed from
+}
+
+func (e ProgressEvent) String() string {
+	var msg string
+	switch e.Type {
+	case ProgressGenerationStarted:
+		msg = fmt.Sprintf("generation %d started", e.Generation)
+	case ProgressScenarioExecuted:
+		msg = "scenario " + e.Scenario + " executing"
+		if e.Generation > 0 {
+			msg = fmt.Sprintf("generation %d: %s", e.Generation, msg)
+		}
+	case ProgressHealthCheckFailed:
+		msg = "health check failed"
+		if e.Target != "" {
+			msg += ": " + e.Target
+		}
+	default:
+		msg = e.Type
+	}
+	if e.Cluster != "" {
+		msg = e.Cluster + ": " + msg
+	}
+	return msg
+}
+
+// ProgressFunc receives progress events. It is called from the goroutines copying the
+// container output, so it should return quickly.
+type ProgressFunc func(ProgressEvent)
+
+// OnProgress sets fn to receive the progress events of the runs that follow: a
+// generation starting, a scenario executing, and a failed health check.
+func (k *KrknAI) OnProgress(fn ProgressFunc) {
+	k.progress = fn
+}
+
+var (
+	// logPrefixPattern matches the timestamp and level Python logging puts before a
+	// message, e.g. "2025-01-02 10:00:00,123 [INFO] " or "INFO:krkn_ai: ".
+	logPrefixPattern = regexp.MustCompile(`^.*?\b(?:DEBUG|INFO|WARNING|WARN|ERROR|CRITICAL)\b\]?(?:\s*:[\w.]*:)?\s*(?:[-:|]\s*)?`)
+
+	// generationPattern matches a generation starting, e.g. "| Generation 3 |" or
+	// "Starting generation 3/10".
+	generationPattern = regexp.MustCompile(`(?i)^\|?\s*(?:starting\s+)?generation\s*[#:]?\s*(\d+)\b`)
+
+	// scenarioPattern matches a scenario starting to execute, e.g. "Running scenario: pod_scenarios".
+	scenarioPattern = regexp.MustCompile(`(?i)^(?:running|executing)\s+scenario\s*[:=]?\s*([\w.-]+)`)
+
+	// healthCheckFailedPattern matches a failed health check anywhere in a line, e.g.
+	// "Health check failed for console: 503".
+	healthCheckFailedPattern = regexp.MustCompile(`(?i)health\s*check\s+(?:failed|failure)\b(?:\s+for)?\s*:?\s*([^\s,;:]+)?`)
+)
+
+// parseProgressLine returns the event a line of krkn-ai output reports, if any. Only the
+// type and the fields parsed from the line are set.
+func parseProgressLine(line string) (ProgressEvent, bool) {
+	msg := strings.TrimSpace(logPrefixPattern.ReplaceAllString(strings.TrimSpace(line), ""))
+	if m := generationPattern.FindStringSubmatch(msg); m != nil {
+		generation, err := strconv.Atoi(m[1])
+		if err == nil {
+			return ProgressEvent{Type: ProgressGenerationStarted, Generation: generation}, true
+		}
+	}
+	if m := scenarioPattern.FindStringSubmatch(msg); m != nil {
+		return ProgressEvent{Type: ProgressScenarioExecuted, Scenario: m[1]}, true
+	}
+	if m := healthCheckFailedPattern.FindStringSubmatch(msg); m != nil {
+		return ProgressEvent{Type: ProgressHealthCheckFailed, Target: m[1]}, true
+	}
+	return ProgressEvent{}, false
+}
+
+// progressParser turns container output into progress events. Scenario events carry the
+// generation last seen on any of the container's streams.
+type progressParser struct {
+	mu         sync.Mutex
+	mode       string
+	generation int
+	emit       ProgressFunc
+}
+
+// handle parses one output line and emits its event.
+func (p *progressParser) handle(line string) {
+	event, ok := parseProgressLine(line)
+	if !ok {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if event.Type == ProgressGenerationStarted {
+		p.generation = event.Generation
+	}
+	event.Generation = p.generation
+	event.Time = time.Now()
+	event.Mode = p.mode
+	event.Line = strings.TrimSpace(line)
+	p.emit(event)
+}
+
+// progressWriter passes each complete line written to it to the parser.
+type progressWriter struct {
+	parser  *progressParser
+	pending []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.parser.handle(string(w.pending[:i]))
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush parses an unterminated last line.
+func (w *progressWriter) flush() {
+	if len(w.pending) > 0 {
+		w.parser.handle(string(w.pending))
+		w.pending = nil
+	}
+}
@@ -0,0 +1,91 @@
+package krknai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want ProgressEvent
+		ok   bool
+	}{
+		{line: "2025-01-02 10:00:00,123 [INFO] | Generation 3 |", want: ProgressEvent{Type: ProgressGenerationStarted, Generation: 3}, ok: true},
+		{line: "INFO:krkn_ai: Starting generation 4/10", want: ProgressEvent{Type: ProgressGenerationStarted, Generation: 4}, ok: true},
+		{line: "2025-01-02 10:00:01 - INFO - Running scenario: pod_scenarios", want: ProgressEvent{Type: ProgressScenarioExecuted, Scenario: "pod_scenarios"}, ok: true},
+		{line: "executing scenario node-cpu-hog (pod 1/4)", want: ProgressEvent{Type: ProgressScenarioExecuted, Scenario: "node-cpu-hog"}, ok: true},
+		{line: "2025-01-02 10:00:02 [WARNING] Health check failed for console: 503", want: ProgressEvent{Type: ProgressHealthCheckFailed, Target: "console"}, ok: true},
+		{line: "[ERROR] health check failure", want: ProgressEvent{Type: ProgressHealthCheckFailed}, ok: true},
+		{line: "2025-01-02 10:00:03 [INFO] Best fitness in generation 3: 0.82"},
+		{line: "Saved population of 10 for the next generation"},
+		{line: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			event, ok := parseProgressLine(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, event)
+		})
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	var events []ProgressEvent
+	parser := &progressParser{mode: config.KrknAIModeRun, emit: func(e ProgressEvent) { events = append(events, e) }}
+	w := &progressWriter{parser: parser}
+
+	// Lines split across writes are parsed once complete
+	for _, chunk := range []string{"[INFO] | Generation 1 |\n[INFO] Running sce", "nario: pod_scenarios\n", "[INFO] Health check failed for console\n[INFO] Running scenario: node_cpu_hog"} {
+		_, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	require.Len(t, events, 3)
+	w.flush()
+	require.Len(t, events, 4)
+
+	assert.Equal(t, "generation 1 started", events[0].String())
+	assert.Equal(t, "generation 1: scenario pod_scenarios executing", events[1].String())
+	assert.Equal(t, "health check failed: console", events[2].String())
+	assert.Equal(t, 1, events[3].Generation, "scenario events carry the current generation")
+	assert.Equal(t, "[INFO] Running scenario: node_cpu_hog", events[3].Line)
+	for _, event := range events {
+		assert.Equal(t, config.KrknAIModeRun, event.Mode)
+		assert.False(t, event.Time.IsZero())
+	}
+
+	events[1].Cluster = "fleet-1"
+	assert.Equal(t, "fleet-1: generation 1: scenario pod_scenarios executing", events[1].String())
+}
+
+func TestRunKrknProgress(t *testing.T) {
+	// A fake runtime that logs like krkn-ai on both of its streams
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"echo '[INFO] | Generation 1 |' >&2\n" +
+		"echo 'Running scenario: pod_scenarios'\n" +
+		"echo '[INFO] | Generation 2 |' >&2\n" +
+		"printf 'Health check failed for console'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "podman"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	setupKrknConfig(t, map[string]any{config.ReportDir: t.TempDir()})
+
+	var mu sync.Mutex
+	var types []string
+	k := &KrknAI{}
+	k.OnProgress(func(e ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		types = append(types, e.Type)
+	})
+	require.NoError(t, k.runKrkn(context.Background(), config.KrknAIModeKrkn))
+
+	assert.ElementsMatch(t, []string{ProgressGenerationStarted, ProgressScenarioExecuted, ProgressGenerationStarted, ProgressHealthCheckFailed}, types)
+}
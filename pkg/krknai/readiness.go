@@ -0,0 +1,116 @@
+package krknai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/common/cluster/healthchecks"
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+const (
+	// readinessPollInterval is how often the readiness gate re-checks a degraded cluster.
+	readinessPollInterval = 30 * time.Second
+
+	// pendingPodMinAge keeps pods that are only briefly pending while they schedule
+	// from counting against the readiness gate.
+	pendingPodMinAge = time.Minute
+)
+
+// clusterReadiness lists why the cluster is not ready for chaos: unready or unschedulable
+// nodes, unavailable, progressing or degraded cluster operators (Tests.OperatorSkip is
+// honored), and more than maxPending pods pending for over a minute. An empty list means
+// the cluster is ready.
+func clusterReadiness(ctx context.Context, kube kubernetes.Interface, configClient configclient.ConfigV1Interface, maxPending int) []string {
+	var problems []string
+	if ok, err := healthchecks.CheckNodeHealth(kube.CoreV1(), nil); err != nil {
+		problems = append(problems, err.Error())
+	} else if !ok {
+		problems = append(problems, "nodes are not ready")
+	}
+	if ok, err := healthchecks.CheckOperatorReadiness(configClient, nil); err != nil {
+		problems = append(problems, err.Error())
+	} else if !ok {
+		problems = append(problems, "cluster operators are not ready")
+	}
+
+	pods, err := kube.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase=" + string(corev1.PodPending),
+	})
+	if err != nil {
+		return append(problems, fmt.Sprintf("failed to list pending pods: %v", err))
+	}
+	var pending []string
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodPending && time.Since(pod.CreationTimestamp.Time) > pendingPodMinAge {
+			pending = append(pending, pod.Namespace+"/"+pod.Name)
+		}
+	}
+	if len(pending) > maxPending {
+		sort.Strings(pending)
+		problems = append(problems, fmt.Sprintf("%d pod(s) pending for over %s (max %d): %s",
+			len(pending), pendingPodMinAge, maxPending, strings.Join(pending, ", ")))
+	}
+	return problems
+}
+
+// waitForReadiness runs check until it reports no problems. With a zero timeout the
+// cluster is checked once; otherwise it is re-checked every interval until timeout.
+func waitForReadiness(ctx context.Context, check func(context.Context) []string, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		problems := check(ctx)
+		if len(problems) == 0 {
+			log.Println("Cluster is ready for chaos")
+			return nil
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("cluster is not ready for chaos: %s", strings.Join(problems, "; "))
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("cluster is not ready for chaos after waiting %s: %s", timeout, strings.Join(problems, "; "))
+		}
+
+		log.Printf("Cluster is not ready for chaos, checking again in %s: %s", interval, strings.Join(problems, "; "))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// waitForClusterReady gates run mode on the cluster's readiness, using the kubeconfig in
+// the shared directory.
+func (k *KrknAI) waitForClusterReady(ctx context.Context) error {
+	kubeconfigPath := filepath.Join(k.sharedDir(), kubeconfigFileName)
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kube client: %w", err)
+	}
+	configClient, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create config client: %w", err)
+	}
+
+	maxPending := viper.GetInt(config.KrknAI.ReadinessMaxPendingPods)
+	return waitForReadiness(ctx, func(ctx context.Context) []string {
+		return clusterReadiness(ctx, kube, configClient, maxPending)
+	}, viper.GetDuration(config.KrknAI.ReadinessTimeout), readinessPollInterval)
+}
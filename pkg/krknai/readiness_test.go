@@ -0,0 +1,99 @@
+package krknai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func readinessNode(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}},
+	}
+}
+
+func readinessOperator(name string, degraded configv1.ConditionStatus) *configv1.ClusterOperator {
+	return &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+			{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+			{Type: configv1.OperatorDegraded, Status: degraded},
+		}},
+	}
+}
+
+func pendingPod(namespace, name string, age time.Duration) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+}
+
+func TestClusterReadiness(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		kube := kubefake.NewSimpleClientset(
+			readinessNode("worker-1", corev1.ConditionTrue),
+			pendingPod("app", "scheduling", 10*time.Second),
+		)
+		configClient := configfake.NewSimpleClientset(readinessOperator("dns", configv1.ConditionFalse))
+		assert.Empty(t, clusterReadiness(context.Background(), kube, configClient.ConfigV1(), 0))
+	})
+
+	t.Run("degraded", func(t *testing.T) {
+		kube := kubefake.NewSimpleClientset(
+			readinessNode("worker-1", corev1.ConditionTrue),
+			readinessNode("worker-2", corev1.ConditionFalse),
+			pendingPod("app", "stuck-b", 5*time.Minute),
+			pendingPod("app", "stuck-a", 5*time.Minute),
+		)
+		configClient := configfake.NewSimpleClientset(readinessOperator("dns", configv1.ConditionTrue))
+		problems := clusterReadiness(context.Background(), kube, configClient.ConfigV1(), 1)
+		assert.Equal(t, []string{
+			"nodes are not ready",
+			"cluster operators are not ready",
+			"2 pod(s) pending for over 1m0s (max 1): app/stuck-a, app/stuck-b",
+		}, problems)
+	})
+
+	t.Run("pending pods within the limit", func(t *testing.T) {
+		kube := kubefake.NewSimpleClientset(
+			readinessNode("worker-1", corev1.ConditionTrue),
+			pendingPod("app", "stuck", 5*time.Minute),
+		)
+		configClient := configfake.NewSimpleClientset(readinessOperator("dns", configv1.ConditionFalse))
+		assert.Empty(t, clusterReadiness(context.Background(), kube, configClient.ConfigV1(), 1))
+	})
+}
+
+func TestWaitForReadiness(t *testing.T) {
+	checks := 0
+	recovering := func(context.Context) []string {
+		checks++
+		if checks < 3 {
+			return []string{"nodes are not ready"}
+		}
+		return nil
+	}
+	require.NoError(t, waitForReadiness(context.Background(), recovering, time.Minute, time.Millisecond))
+	assert.Equal(t, 3, checks, "a degraded cluster is re-checked until it recovers")
+
+	degraded := func(context.Context) []string { return []string{"nodes are not ready"} }
+	err := waitForReadiness(context.Background(), degraded, 0, time.Millisecond)
+	assert.EqualError(t, err, "cluster is not ready for chaos: nodes are not ready")
+
+	err = waitForReadiness(context.Background(), degraded, 10*time.Millisecond, time.Millisecond)
+	assert.ErrorContains(t, err, "cluster is not ready for chaos after waiting 10ms")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, waitForReadiness(ctx, degraded, time.Minute, time.Millisecond), context.Canceled)
+}
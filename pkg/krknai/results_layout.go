@@ -0,0 +1,139 @@
+package krknai
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+)
+
+// resultsLayout is the layout of every results directory the executor writes.
+var resultsLayout = krknAggregator.DefaultLayout()
+
+// resultConfigFiles are the working directory files copied to the config directory. The
+// krkn configs are redacted on the way; the rest are written redacted already.
+var resultConfigFiles = []struct {
+	name   string
+	redact bool
+}{
+	{krknConfigFileName, true},
+	{krknScenarioConfigFileName, true},
+	{krknConfigDiffFileName, false},
+	{effectiveConfigFileName, false},
+	{appliedParamsFileName, false},
+}
+
+// generationsDir returns the directory krkn-ai writes its output to.
+func (k *KrknAI) generationsDir() string {
+	return filepath.Join(k.reportDir(), resultsLayout.Generations)
+}
+
+// beginResults creates the results layout and writes a manifest recording the run as started.
+func (k *KrknAI) beginResults() (*krknAggregator.Manifest, error) {
+	reportDir := k.reportDir()
+	for _, dir := range []string{resultsLayout.Config, resultsLayout.Logs, resultsLayout.Generations, resultsLayout.Analysis} {
+		if err := os.MkdirAll(filepath.Join(reportDir, dir), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create results directory: %w", err)
+		}
+	}
+
+	clusterID := k.result.ClusterID
+	if clusterID == "" {
+		clusterID = viper.GetString(config.Cluster.ID)
+	}
+	mode := strings.TrimSpace(viper.GetString(config.KrknAI.Mode))
+	if parsed, err := parseMode(mode); err == nil {
+		mode = parsed
+	}
+	manifest := &krknAggregator.Manifest{
+		ClusterID:      clusterID,
+		ClusterVersion: viper.GetString(config.Cluster.Version),
+		Mode:           mode,
+		Parameters:     manifestParameters(),
+		StartTime:      time.Now().UTC(),
+		ExitStatus:     krknAggregator.ExitStatusRunning,
+		Layout:         resultsLayout,
+	}
+	return manifest, manifest.Write(reportDir)
+}
+
+// finishResults copies the configs the run used to the config directory and records the
+// run's end and exit status in the manifest, aborted when the abort guardrails stopped it.
+func (k *KrknAI) finishResults(manifest *krknAggregator.Manifest, runErr error) error {
+	redact := redactorFromConfig()
+	configDir := filepath.Join(k.reportDir(), resultsLayout.Config)
+	var errs []error
+	for _, file := range resultConfigFiles {
+		data, err := os.ReadFile(filepath.Join(k.sharedDir(), file.name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err == nil && file.redact {
+			data, err = redact.redactYAML(data)
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(configDir, file.name), data, 0o644)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to copy %s to the results: %w", file.name, err))
+		}
+	}
+
+	manifest.EndTime = time.Now().UTC()
+	manifest.ExitStatus = krknAggregator.ExitStatusPassed
+	switch {
+	case isAborted(runErr):
+		manifest.ExitStatus = krknAggregator.ExitStatusAborted
+	case runErr != nil || k.result.ExitCode != config.Success:
+		manifest.ExitStatus = krknAggregator.ExitStatusFailed
+	}
+	if runErr != nil {
+		manifest.Error = redact.redactString(runErr.Error())
+	}
+	return errors.Join(append(errs, manifest.Write(k.reportDir()))...)
+}
+
+// manifestParameters returns the krkn-ai parameters that have a value, named by their key
+// without the krknAI. prefix. No parameter is a credential itself, so the values are only
+// scrubbed of the credentials they embed, e.g. in a health check URL or an override.
+func manifestParameters() map[string]string {
+	redact := redactorFromConfig()
+	params := make(map[string]string)
+	fields := reflect.ValueOf(config.KrknAI)
+	for i := 0; i < fields.NumField(); i++ {
+		key := fields.Field(i).String()
+		value := strings.TrimSpace(viper.GetString(key))
+		if value == "" {
+			continue
+		}
+		params[strings.TrimPrefix(key, krknAIKeyPrefix)] = redact.redactString(value)
+	}
+	return params
+}
+
+// writeContainerLog appends a container run's output to the mode's file in the logs
+// directory, credentials masked, so retries of a mode share one file.
+func (k *KrknAI) writeContainerLog(mode string, output []byte) {
+	dir := filepath.Join(k.reportDir(), resultsLayout.Logs)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("Warning - failed to create the logs directory: %v", err)
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(dir, mode+".log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("Warning - failed to write the %s container log: %v", mode, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(redactorFromConfig().redactString(string(output))); err != nil {
+		log.Printf("Warning - failed to write the %s container log: %v", mode, err)
+	}
+}
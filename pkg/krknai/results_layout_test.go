@@ -0,0 +1,82 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/openshift/osde2e/pkg/common/orchestrator"
+	krknAggregator "github.com/openshift/osde2e/pkg/krknai/aggregator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteResultsLayout(t *testing.T) {
+	// A fake runtime that leaves a report in the results mount and logs a credential
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"for arg in \"$@\"; do case \"$arg\" in *:/krknresults/:Z) touch \"${arg%%:/krknresults/:Z}/kraken.report\";; esac; done\n" +
+		"echo 'Authorization: Bearer s3cr3t'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "podman"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	files := writeScenarioFiles(t, "etcd.yml")
+	reportDir := t.TempDir()
+	setupKrknConfig(t, map[string]any{
+		config.ReportDir:           reportDir,
+		config.Cluster.ID:          "abc123",
+		config.Cluster.Version:     "4.16.2",
+		config.KrknAI.Mode:         config.KrknAIModeKrkn,
+		config.KrknAI.ScenarioList: fmt.Sprintf("[{type: pod_disruption_scenarios, file: %s}]", files[0]),
+		config.KrknAI.Overrides:    "cluster.api_key=hunter2",
+	})
+
+	k := &KrknAI{result: &orchestrator.Result{ExitCode: config.Success}}
+	require.NoError(t, k.Execute(context.Background()))
+
+	manifest, err := krknAggregator.ReadManifest(reportDir)
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	assert.Equal(t, "abc123", manifest.ClusterID)
+	assert.Equal(t, "4.16.2", manifest.ClusterVersion)
+	assert.Equal(t, config.KrknAIModeKrkn, manifest.Mode)
+	assert.Equal(t, krknAggregator.ExitStatusPassed, manifest.ExitStatus)
+	assert.Empty(t, manifest.Error)
+	assert.False(t, manifest.EndTime.Before(manifest.StartTime))
+	assert.Equal(t, krknAggregator.DefaultLayout(), manifest.Layout)
+	assert.Equal(t, "cluster.api_key=<redacted>", manifest.Parameters["overrides"])
+	assert.Contains(t, manifest.Parameters, "scenarioList")
+
+	for _, dir := range []string{"config", "logs", "generations", "analysis"} {
+		assert.DirExists(t, filepath.Join(reportDir, dir))
+	}
+	assert.FileExists(t, filepath.Join(reportDir, "generations", krknReportFileName), "krkn writes to the generations directory")
+	containerLog, err := os.ReadFile(filepath.Join(reportDir, "logs", "krkn.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "Authorization: Bearer <redacted>\n", string(containerLog))
+	krknConfig, err := os.ReadFile(filepath.Join(reportDir, "config", krknScenarioConfigFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(krknConfig), "api_key: <redacted>")
+	assert.NotContains(t, string(krknConfig), "hunter2")
+	assert.FileExists(t, filepath.Join(reportDir, "config", krknConfigFileName))
+	assert.NoFileExists(t, filepath.Join(reportDir, "config", effectiveConfigFileName), "only the configs in the working directory are copied")
+
+	// A failed run records its error
+	k.result.ExitCode = config.Failure
+	require.NoError(t, k.finishResults(manifest, errors.New("krkn run failed: token=abc")))
+	manifest, err = krknAggregator.ReadManifest(reportDir)
+	require.NoError(t, err)
+	assert.Equal(t, krknAggregator.ExitStatusFailed, manifest.ExitStatus)
+	assert.Equal(t, "krkn run failed: token=<redacted>", manifest.Error)
+
+	// A run the abort guardrails stopped is recorded as aborted
+	require.NoError(t, k.finishResults(manifest, fmt.Errorf("krkn run failed: %w", &abortError{reason: "abort query held for 10m0s"})))
+	manifest, err = krknAggregator.ReadManifest(reportDir)
+	require.NoError(t, err)
+	assert.Equal(t, krknAggregator.ExitStatusAborted, manifest.ExitStatus)
+	assert.Equal(t, "krkn run failed: run aborted: abort query held for 10m0s", manifest.Error)
+}
@@ -0,0 +1,91 @@
+package krknai
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+// checkpointDir is where krkn-ai writes a population checkpoint after each generation,
+// relative to its output directory.
+const checkpointDir = "checkpoints"
+
+// checkpointPattern matches a generation checkpoint file name, e.g. generation_12.json.
+var checkpointPattern = regexp.MustCompile(`^generation_(\d+)\.json$`)
+
+// generationCheckpoint is a krkn-ai checkpoint a run can resume from.
+type generationCheckpoint struct {
+	Generation int
+	Path       string // Relative to the results directory
+}
+
+// parseMode validates the execution mode, defaulting to run.
+func parseMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return config.KrknAIModeRun, nil
+	case config.KrknAIModeRun, config.KrknAIModeResume, config.KrknAIModeKrkn:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid krkn-ai mode %q (expected %s, %s or %s)", mode, config.KrknAIModeRun, config.KrknAIModeResume, config.KrknAIModeKrkn)
+}
+
+// findLatestCheckpoint returns the checkpoint of the last generation krkn-ai completed
+// in resultsDir.
+func findLatestCheckpoint(resultsDir string) (*generationCheckpoint, error) {
+	entries, err := os.ReadDir(filepath.Join(resultsDir, checkpointDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+	var latest *generationCheckpoint
+	for _, entry := range entries {
+		match := checkpointPattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		generation, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		if latest == nil || generation > latest.Generation {
+			latest = &generationCheckpoint{Generation: generation, Path: filepath.Join(checkpointDir, entry.Name())}
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no generation checkpoint found in %s", filepath.Join(resultsDir, checkpointDir))
+	}
+	return latest, nil
+}
+
+// prepareResume finds the checkpoint to resume from and makes sure sharedDir holds the
+// config of the interrupted run, restoring the copy krkn-ai left in resultsDir when a
+// new job starts with an empty shared directory. The updater then merges the current
+// parameters into that config.
+func prepareResume(sharedDir, resultsDir string) (*generationCheckpoint, error) {
+	checkpoint, err := findLatestCheckpoint(resultsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(sharedDir, krknConfigFileName)
+	if _, err := os.Stat(configPath); err == nil {
+		log.Printf("Resuming from generation %d with the config in %s", checkpoint.Generation, configPath)
+		return checkpoint, nil
+	}
+	data, err := os.ReadFile(filepath.Join(resultsDir, krknConfigFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config of the interrupted run: %w", err)
+	}
+	if err := os.WriteFile(configPath, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to restore config: %w", err)
+	}
+	log.Printf("Resuming from generation %d with the config restored from %s", checkpoint.Generation, resultsDir)
+	return checkpoint, nil
+}
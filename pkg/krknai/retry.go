@@ -0,0 +1,139 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+// transientPatterns mark a failed container run as worth retrying when its output
+// contains one of them: image pull and registry errors and API server connectivity.
+// Matching is case-insensitive.
+var transientPatterns = []string{
+	"error pulling image",
+	"failed to pull image",
+	"toomanyrequests",
+	"tls handshake timeout",
+	"i/o timeout",
+	"connection refused",
+	"connection reset by peer",
+	"no route to host",
+	"service unavailable",
+	"etcdserver: request timed out",
+}
+
+// rotatedCredentialPatterns mark a failure as transient only when KRKN_KUBECONFIG_SOURCE
+// refreshes the kubeconfig, so a retry can pick up credentials that expired mid-run.
+// Otherwise they point at a wrong token or expired credentials that a retry won't fix.
+var rotatedCredentialPatterns = []string{
+	"unauthorized",
+	"must be logged in to the server",
+}
+
+// containerError is a krkn-ai container run that exited with an error. Output holds what
+// the container wrote so the failure can be classified.
+type containerError struct {
+	err    error
+	output string
+}
+
+func (e *containerError) Error() string {
+	return "container execution failed: " + e.err.Error()
+}
+
+func (e *containerError) Unwrap() error {
+	return e.err
+}
+
+// runRetryPolicy controls how often a failed krkn-ai container run is retried.
+type runRetryPolicy struct {
+	maxRetries int
+	backoff    time.Duration // Delay before the first retry, doubled on each subsequent retry
+	patterns   []string      // Lowercase output fragments that make a failure transient
+}
+
+// retryPolicyFromConfig builds the retry policy from the krkn-ai parameters. Extra
+// patterns are added to transientPatterns, as are rotatedCredentialPatterns when a
+// kubeconfig source is configured.
+func retryPolicyFromConfig() runRetryPolicy {
+	policy := runRetryPolicy{
+		maxRetries: viper.GetInt(config.KrknAI.RunRetries),
+		backoff:    viper.GetDuration(config.KrknAI.RunRetryBackoff),
+		patterns:   append([]string{}, transientPatterns...),
+	}
+	if strings.TrimSpace(viper.GetString(config.KrknAI.KubeconfigSource)) != "" {
+		policy.patterns = append(policy.patterns, rotatedCredentialPatterns...)
+	}
+	for _, pattern := range strings.Split(viper.GetString(config.KrknAI.RunRetryPatterns), ",") {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			policy.patterns = append(policy.patterns, pattern)
+		}
+	}
+	return policy
+}
+
+// isTransient reports whether err is a container failure whose output matches one of
+// the policy's patterns. Cancellations and failures to start the container are not.
+func (p runRetryPolicy) isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var containerErr *containerError
+	if !errors.As(err, &containerErr) {
+		return false
+	}
+	output := strings.ToLower(containerErr.output)
+	for _, pattern := range p.patterns {
+		if strings.Contains(output, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWithRetry runs the krkn-ai container in mode, retrying transient failures.
+func (k *KrknAI) runWithRetry(ctx context.Context, mode string) error {
+	return retryTransient(ctx, retryPolicyFromConfig(), mode, func() error {
+		return k.runKrkn(ctx, mode)
+	})
+}
+
+// retryTransient calls run until it succeeds, fails with a non-transient error, or
+// runs out of retries.
+func retryTransient(ctx context.Context, policy runRetryPolicy, mode string, run func() error) error {
+	attempts := max(policy.maxRetries, 0) + 1
+	backoff := policy.backoff
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = run()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Krkn-ai %s mode succeeded on attempt %d/%d", mode, attempt, attempts)
+			}
+			return nil
+		}
+		if ctx.Err() != nil || !policy.isTransient(err) {
+			return err
+		}
+		if attempt == attempts {
+			if attempts > 1 {
+				log.Printf("Krkn-ai %s mode attempt %d/%d failed, giving up: %v", mode, attempt, attempts, err)
+			}
+			return err
+		}
+
+		log.Printf("Krkn-ai %s mode attempt %d/%d failed with a transient error, retrying in %s: %v", mode, attempt, attempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
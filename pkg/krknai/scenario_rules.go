@@ -0,0 +1,149 @@
+package krknai
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+// Scenario rule modes.
+const (
+	scenarioRulesDisable = "disable"
+	scenarioRulesError   = "error"
+	scenarioRulesOff     = "off"
+)
+
+// nodeHogMinWorkers is the fewest workers node hogs may run on; with fewer, the hogged
+// node's workloads have nowhere to reschedule and the run measures an outage.
+const nodeHogMinWorkers = 3
+
+// clusterTopology is the shape of the cluster as discovered, before any node filter or
+// scope narrows cluster_components.
+type clusterTopology struct {
+	nodes   int  // Zero when the config lists no nodes
+	workers int  // Nodes with the worker role label
+	labeled bool // Whether any node has labels; without them the worker count is unknown
+}
+
+// discoveredTopology counts the nodes in cfg's cluster_components.
+func discoveredTopology(cfg map[string]interface{}) clusterTopology {
+	var topology clusterTopology
+	components, _ := cfg["cluster_components"].(map[string]interface{})
+	nodes, _ := components["nodes"].([]interface{})
+	for _, entry := range nodes {
+		topology.nodes++
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		nodeLabels, ok := m["labels"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		topology.labeled = true
+		if _, ok := nodeLabels[nodeRoleLabelPrefix+"worker"]; ok {
+			topology.workers++
+		}
+	}
+	return topology
+}
+
+// ruleViolation is an enabled scenario a rule disallows, and why.
+type ruleViolation struct {
+	scenario string
+	reason   string
+}
+
+// scenarioRule returns the enabled scenarios it disallows on the topology.
+type scenarioRule func(topology clusterTopology, enabled map[string]bool) []ruleViolation
+
+// scenarioRules are the rules applyScenarioRules enforces, in order.
+var scenarioRules = []scenarioRule{nodeHogWorkersRule, singleNodeNetworkRule}
+
+// nodeHogWorkersRule keeps node hogs off clusters with fewer than nodeHogMinWorkers workers.
+func nodeHogWorkersRule(topology clusterTopology, enabled map[string]bool) []ruleViolation {
+	if !topology.labeled || topology.workers >= nodeHogMinWorkers {
+		return nil
+	}
+	var violations []ruleViolation
+	for _, name := range []string{"node_cpu_hog", "node_io_hog", "node_memory_hog"} {
+		if enabled[name] {
+			violations = append(violations, ruleViolation{
+				scenario: name,
+				reason:   fmt.Sprintf("needs at least %d worker nodes, the cluster has %d", nodeHogMinWorkers, topology.workers),
+			})
+		}
+	}
+	return violations
+}
+
+// singleNodeNetworkRule keeps network_scenarios from running with dns_outage on a
+// single-node cluster, where together they cut the only node off and the run can't
+// observe its recovery. dns_outage is kept as the narrower of the two.
+func singleNodeNetworkRule(topology clusterTopology, enabled map[string]bool) []ruleViolation {
+	if topology.nodes != 1 || !enabled["dns_outage"] || !enabled["network_scenarios"] {
+		return nil
+	}
+	return []ruleViolation{{scenario: "network_scenarios", reason: "can't run alongside dns_outage on a single-node cluster"}}
+}
+
+// parseScenarioRulesMode validates a scenario rules mode, defaulting to disable.
+func parseScenarioRulesMode(input string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(input)); mode {
+	case "":
+		return scenarioRulesDisable, nil
+	case scenarioRulesDisable, scenarioRulesError, scenarioRulesOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid scenario rules mode %q (expected %s, %s or %s)", input, scenarioRulesDisable, scenarioRulesError, scenarioRulesOff)
+}
+
+// scenarioRulesModeFromConfig returns the mode set by KRKN_SCENARIO_RULES.
+func scenarioRulesModeFromConfig() (string, error) {
+	return parseScenarioRulesMode(viper.GetString(config.KrknAI.ScenarioRules))
+}
+
+// applyScenarioRules checks the scenarios enabled in cfg against the rules for topology.
+// In disable mode the scenarios a rule disallows are disabled and returned; in error mode
+// they fail the run, with what to change.
+func applyScenarioRules(cfg map[string]interface{}, topology clusterTopology, mode string) ([]string, error) {
+	if mode == scenarioRulesOff {
+		return nil, nil
+	}
+	enabled := make(map[string]bool)
+	for _, name := range enabledScenarioNames(cfg) {
+		enabled[name] = true
+	}
+	var violations []ruleViolation
+	for _, rule := range scenarioRules {
+		violations = append(violations, rule(topology, enabled)...)
+	}
+	if len(violations) == 0 {
+		return nil, nil
+	}
+
+	if mode == scenarioRulesError {
+		lines := make([]string, 0, len(violations))
+		for _, v := range violations {
+			lines = append(lines, fmt.Sprintf("  %s %s", v.scenario, v.reason))
+		}
+		return nil, fmt.Errorf("enabled scenarios can't run on this cluster:\n%s\nleave them out of KRKN_SCENARIOS, or set KRKN_SCENARIO_RULES=%s to disable them automatically",
+			strings.Join(lines, "\n"), scenarioRulesDisable)
+	}
+
+	scenarioCfg := cfg["scenario"].(map[string]interface{})
+	var disabled []string
+	for _, v := range violations {
+		scenarioMap := scenarioCfg[v.scenario].(map[string]interface{})
+		if scenarioMap["enable"] == false {
+			continue
+		}
+		scenarioMap["enable"] = false
+		disabled = append(disabled, v.scenario)
+		log.Printf("Scenario rule: disabled %s, it %s", v.scenario, v.reason)
+	}
+	return disabled, nil
+}
@@ -0,0 +1,149 @@
+package krknai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// topologyConfig returns a config with the given nodes and scenarios enabled.
+func topologyConfig(nodes []interface{}, scenarios ...string) map[string]interface{} {
+	scenarioCfg := map[string]interface{}{}
+	for _, name := range scenarios {
+		scenarioCfg[name] = map[string]interface{}{"enable": true}
+	}
+	return map[string]interface{}{
+		"cluster_components": map[string]interface{}{"nodes": nodes},
+		"scenario":           scenarioCfg,
+	}
+}
+
+func node(name string, roles ...string) map[string]interface{} {
+	nodeLabels := map[string]interface{}{"kubernetes.io/hostname": name}
+	for _, role := range roles {
+		nodeLabels[nodeRoleLabelPrefix+role] = ""
+	}
+	return map[string]interface{}{"name": name, "labels": nodeLabels}
+}
+
+func TestDiscoveredTopology(t *testing.T) {
+	assert.Equal(t, clusterTopology{}, discoveredTopology(map[string]interface{}{}))
+	assert.Equal(t, clusterTopology{nodes: 2}, discoveredTopology(topologyConfig([]interface{}{"worker-0", "worker-1"})), "plain names leave the workers unknown")
+	assert.Equal(t, clusterTopology{nodes: 3, workers: 1, labeled: true}, discoveredTopology(topologyConfig([]interface{}{
+		node("master-0", "master"), node("worker-0", "worker"), "infra-0",
+	})))
+	assert.Equal(t, clusterTopology{nodes: 1, workers: 1, labeled: true}, discoveredTopology(topologyConfig([]interface{}{node("sno", "master", "worker")})))
+}
+
+func TestApplyScenarioRules(t *testing.T) {
+	twoWorkers := []interface{}{node("master-0", "master"), node("worker-0", "worker"), node("worker-1", "worker")}
+	singleNode := []interface{}{node("sno", "master", "worker")}
+
+	tests := []struct {
+		name      string
+		cfg       map[string]interface{}
+		mode      string
+		disabled  []string
+		remaining []string
+		wantErr   string
+	}{
+		{
+			name:      "node hogs need three workers",
+			cfg:       topologyConfig(twoWorkers, "node_cpu_hog", "node_memory_hog", "pod_scenarios"),
+			mode:      scenarioRulesDisable,
+			disabled:  []string{"node_cpu_hog", "node_memory_hog"},
+			remaining: []string{"pod_scenarios"},
+		},
+		{
+			name:      "enough workers",
+			cfg:       topologyConfig(append(twoWorkers, node("worker-2", "worker")), "node_cpu_hog"),
+			mode:      scenarioRulesDisable,
+			remaining: []string{"node_cpu_hog"},
+		},
+		{
+			name:      "unknown workers",
+			cfg:       topologyConfig([]interface{}{"worker-0"}, "node_cpu_hog"),
+			mode:      scenarioRulesDisable,
+			remaining: []string{"node_cpu_hog"},
+		},
+		{
+			name:      "dns outage and network scenarios on a single node",
+			cfg:       topologyConfig(singleNode, "dns_outage", "network_scenarios", "node_io_hog"),
+			mode:      scenarioRulesDisable,
+			disabled:  []string{"node_io_hog", "network_scenarios"},
+			remaining: []string{"dns_outage"},
+		},
+		{
+			name:      "dns outage and network scenarios on several nodes",
+			cfg:       topologyConfig([]interface{}{"a", "b"}, "dns_outage", "network_scenarios"),
+			mode:      scenarioRulesDisable,
+			remaining: []string{"dns_outage", "network_scenarios"},
+		},
+		{
+			name:    "error mode",
+			cfg:     topologyConfig(twoWorkers, "node_cpu_hog"),
+			mode:    scenarioRulesError,
+			wantErr: "enabled scenarios can't run on this cluster:\n  node_cpu_hog needs at least 3 worker nodes, the cluster has 2\nleave them out of KRKN_SCENARIOS, or set KRKN_SCENARIO_RULES=disable to disable them automatically",
+		},
+		{
+			name:      "off",
+			cfg:       topologyConfig(twoWorkers, "node_cpu_hog"),
+			mode:      scenarioRulesOff,
+			remaining: []string{"node_cpu_hog"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disabled, err := applyScenarioRules(tt.cfg, discoveredTopology(tt.cfg), tt.mode)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.disabled, disabled)
+			assert.Equal(t, tt.remaining, enabledScenarioNames(tt.cfg))
+		})
+	}
+}
+
+func TestParseScenarioRulesMode(t *testing.T) {
+	for input, want := range map[string]string{"": scenarioRulesDisable, " Error ": scenarioRulesError, "off": scenarioRulesOff} {
+		mode, err := parseScenarioRulesMode(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, mode)
+	}
+	_, err := parseScenarioRulesMode("warn")
+	assert.EqualError(t, err, `invalid scenario rules mode "warn" (expected disable, error or off)`)
+}
+
+func TestUpdateKrknConfig_ScenarioRules(t *testing.T) {
+	// Two workers, one of them filtered out: the rules still count both
+	writeTopology := func(t *testing.T, yamlFile string) {
+		cfg := readKrknConfig(t, yamlFile)
+		cfg["cluster_components"] = map[string]interface{}{
+			"nodes": []interface{}{node("worker-0", "worker"), node("worker-1", "worker", "infra")},
+		}
+		content, err := yaml.Marshal(cfg)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(yamlFile, content, 0o644))
+	}
+
+	yamlFile := setupKrknConfig(t, map[string]any{config.KrknAI.NodeRoles: "infra"})
+	writeTopology(t, yamlFile)
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+	assert.Equal(t, []string{"pod_scenarios"}, enabledScenarioNames(readKrknConfig(t, yamlFile)))
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(yamlFile), appliedParamsFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"source": "rule"`)
+
+	// The rules apply to the discovered config even without other parameters
+	yamlFile = setupKrknConfig(t, map[string]any{config.KrknAI.ScenarioRules: scenarioRulesError})
+	writeTopology(t, yamlFile)
+	assert.ErrorContains(t, (&KrknAI{}).updateKrknConfig(context.Background()), "node_cpu_hog needs at least 3 worker nodes, the cluster has 2")
+}
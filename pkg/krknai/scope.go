@@ -0,0 +1,133 @@
+package krknai
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	scopeCluster         = "cluster"
+	scopeNamespacePrefix = "namespace:"
+
+	// nodeScenarioPrefix starts the names of krkn-ai scenarios that act on nodes, e.g. node_cpu_hog.
+	nodeScenarioPrefix = "node_"
+)
+
+// clusterWideKrknScenarioTypes are the krkn scenario types that act on nodes or the whole
+// cluster rather than on the workloads of one namespace.
+var clusterWideKrknScenarioTypes = map[string]bool{
+	"node_scenarios":              true,
+	"hog_scenarios":               true,
+	"network_chaos_scenarios":     true,
+	"cluster_shut_down_scenarios": true,
+	"zone_outages_scenarios":      true,
+}
+
+// chaosScope is where a run may inject chaos: the whole cluster, or a single namespace on
+// a cluster shared with other teams.
+type chaosScope struct {
+	namespace string // Empty for cluster scope
+}
+
+// parseScope parses a scope: cluster (or empty), or namespace:<name>.
+func parseScope(input string) (chaosScope, error) {
+	input = strings.TrimSpace(input)
+	if input == "" || input == scopeCluster {
+		return chaosScope{}, nil
+	}
+	if !strings.HasPrefix(input, scopeNamespacePrefix) {
+		return chaosScope{}, fmt.Errorf("invalid scope %q (expected cluster or namespace:<name>)", input)
+	}
+	namespace := strings.TrimSpace(strings.TrimPrefix(input, scopeNamespacePrefix))
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return chaosScope{}, fmt.Errorf("invalid scope %q namespace: %s", input, strings.Join(errs, "; "))
+	}
+	return chaosScope{namespace: namespace}, nil
+}
+
+// scopeFromConfig returns the scope set by KRKN_SCOPE.
+func scopeFromConfig() (chaosScope, error) {
+	return parseScope(viper.GetString(config.KrknAI.Scope))
+}
+
+func (s chaosScope) namespaced() bool {
+	return s.namespace != ""
+}
+
+func (s chaosScope) String() string {
+	if s.namespaced() {
+		return scopeNamespacePrefix + s.namespace
+	}
+	return scopeCluster
+}
+
+// discoverNamespace returns the namespace discover mode looks for targets in: the scope's
+// namespace, or KRKN_NAMESPACE in cluster scope.
+func discoverNamespace() string {
+	if scope, err := scopeFromConfig(); err == nil && scope.namespaced() {
+		return scope.namespace
+	}
+	return viper.GetString(config.KrknAI.Namespace)
+}
+
+// apply confines cfg to the scope's namespace: cluster_components keeps only that
+// namespace and no nodes, and the node scenarios are disabled, whatever the parameters
+// enabled. Cluster scope leaves cfg as is.
+func (s chaosScope) apply(cfg map[string]interface{}) {
+	if !s.namespaced() {
+		return
+	}
+
+	if components, ok := cfg["cluster_components"].(map[string]interface{}); ok {
+		if namespaces, ok := components["namespaces"].([]interface{}); ok {
+			kept := filterComponents(namespaces, func(entry interface{}) bool {
+				return componentName(entry) == s.namespace
+			})
+			if len(kept) == 0 {
+				log.Printf("Warning - namespace %s was not discovered, no namespace is left in cluster_components", s.namespace)
+			}
+			components["namespaces"] = kept
+		}
+		if nodes, ok := components["nodes"].([]interface{}); ok && len(nodes) > 0 {
+			components["nodes"] = []interface{}{}
+		}
+	}
+
+	var disabled []string
+	if scenarioCfg, ok := cfg["scenario"].(map[string]interface{}); ok {
+		for name, val := range scenarioCfg {
+			scenarioMap, ok := val.(map[string]interface{})
+			if !ok || !strings.HasPrefix(name, nodeScenarioPrefix) {
+				continue
+			}
+			if enabled, _ := scenarioMap["enable"].(bool); enabled {
+				scenarioMap["enable"] = false
+				disabled = append(disabled, name)
+			}
+		}
+	}
+	sort.Strings(disabled)
+	if len(disabled) > 0 {
+		log.Printf("Scope %s: disabled node scenarios %s", s, strings.Join(disabled, ", "))
+	}
+	log.Printf("Scope %s: chaos confined to namespace %s", s, s.namespace)
+}
+
+// checkKrknScenarios rejects krkn mode scenarios that act beyond the scope's namespace.
+func (s chaosScope) checkKrknScenarios(scenarios []krknScenario) error {
+	if !s.namespaced() {
+		return nil
+	}
+	for idx, scenario := range scenarios {
+		if clusterWideKrknScenarioTypes[scenario.Type] {
+			return fmt.Errorf("scenario %d type %s acts on nodes or the whole cluster and can't run in scope %s", idx+1, scenario.Type, s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package krknai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		input     string
+		namespace string
+		wantErr   string
+	}{
+		{input: ""},
+		{input: "cluster"},
+		{input: "namespace:robot-shop", namespace: "robot-shop"},
+		{input: " namespace: robot-shop ", namespace: "robot-shop"},
+		{input: "namespace:", wantErr: `invalid scope "namespace:" namespace`},
+		{input: "namespace:Robot_Shop", wantErr: `invalid scope "namespace:Robot_Shop" namespace`},
+		{input: "project:robot-shop", wantErr: `invalid scope "project:robot-shop" (expected cluster or namespace:<name>)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			scope, err := parseScope(tt.input)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.namespace, scope.namespace)
+		})
+	}
+}
+
+func TestDiscoverNamespace(t *testing.T) {
+	setupKrknConfig(t, map[string]any{config.KrknAI.Namespace: "default"})
+	assert.Equal(t, "default", discoverNamespace())
+
+	setupKrknConfig(t, map[string]any{config.KrknAI.Namespace: "default", config.KrknAI.Scope: "namespace:robot-shop"})
+	assert.Equal(t, "robot-shop", discoverNamespace())
+}
+
+func TestScopeApply(t *testing.T) {
+	newConfig := func() map[string]interface{} {
+		return map[string]interface{}{
+			"cluster_components": map[string]interface{}{
+				"namespaces": []interface{}{"openshift-etcd", map[string]interface{}{"name": "robot-shop"}},
+				"nodes":      []interface{}{map[string]interface{}{"name": "worker-0"}},
+			},
+			"scenario": map[string]interface{}{
+				"pod_scenarios":   map[string]interface{}{"enable": true},
+				"node_cpu_hog":    map[string]interface{}{"enable": true},
+				"node_memory_hog": map[string]interface{}{"enable": false},
+			},
+		}
+	}
+
+	cfg := newConfig()
+	chaosScope{}.apply(cfg)
+	assert.Equal(t, newConfig(), cfg, "cluster scope leaves the config as is")
+
+	chaosScope{namespace: "robot-shop"}.apply(cfg)
+	assert.Equal(t, map[string]interface{}{
+		"namespaces": []interface{}{map[string]interface{}{"name": "robot-shop"}},
+		"nodes":      []interface{}{},
+	}, cfg["cluster_components"])
+	assert.Equal(t, map[string]interface{}{
+		"pod_scenarios":   map[string]interface{}{"enable": true},
+		"node_cpu_hog":    map[string]interface{}{"enable": false},
+		"node_memory_hog": map[string]interface{}{"enable": false},
+	}, cfg["scenario"])
+}
+
+func TestScopeCheckKrknScenarios(t *testing.T) {
+	scenarios := []krknScenario{{Type: "pod_disruption_scenarios"}, {Type: "hog_scenarios"}}
+
+	assert.NoError(t, chaosScope{}.checkKrknScenarios(scenarios))
+	assert.NoError(t, chaosScope{namespace: "robot-shop"}.checkKrknScenarios(scenarios[:1]))
+	assert.EqualError(t, chaosScope{namespace: "robot-shop"}.checkKrknScenarios(scenarios),
+		"scenario 2 type hog_scenarios acts on nodes or the whole cluster and can't run in scope namespace:robot-shop")
+}
+
+func TestUpdateKrknConfig_NamespaceScope(t *testing.T) {
+	// Node scenarios stay disabled even when the parameters enable them
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.Scope:     "namespace:app",
+		config.KrknAI.Scenarios: "pod_scenarios,node_cpu_hog",
+	})
+	cfg := readKrknConfig(t, yamlFile)
+	cfg["cluster_components"] = map[string]interface{}{
+		"namespaces": []interface{}{"openshift-etcd", "app"},
+		"nodes":      []interface{}{map[string]interface{}{"name": "worker-0"}},
+	}
+	content, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(yamlFile, content, 0o644))
+
+	require.NoError(t, (&KrknAI{}).updateKrknConfig(context.Background()))
+
+	cfg = readKrknConfig(t, yamlFile)
+	assert.Equal(t, map[string]interface{}{"namespaces": []interface{}{"app"}, "nodes": []interface{}{}}, cfg["cluster_components"])
+	scenarios := cfg["scenario"].(map[string]interface{})
+	assert.Equal(t, true, scenarios["pod_scenarios"].(map[string]interface{})["enable"])
+	assert.Equal(t, false, scenarios["node_cpu_hog"].(map[string]interface{})["enable"])
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(yamlFile), appliedParamsFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"path": "scenario.node_cpu_hog.enable"`)
+}
+
+func TestGenerateKrknConfig_NamespaceScope(t *testing.T) {
+	setupKrknConfig(t, map[string]any{
+		config.KrknAI.Scope:       "namespace:app",
+		config.KrknAI.HealthCheck: "console=https://console.example.com",
+	})
+	output := filepath.Join(t.TempDir(), krknConfigFileName)
+	require.NoError(t, (&KrknAI{}).GenerateKrknConfig(output))
+
+	// The default node scenarios are generated disabled
+	cfg := readKrknConfig(t, output)
+	assert.Equal(t, map[string]interface{}{
+		"pod_scenarios":   map[string]interface{}{"enable": true},
+		"node_cpu_hog":    map[string]interface{}{"enable": false},
+		"node_memory_hog": map[string]interface{}{"enable": false},
+	}, cfg["scenario"])
+
+	// A config left without enabled scenarios is refused
+	setupKrknConfig(t, map[string]any{
+		config.KrknAI.Scope:       "namespace:app",
+		config.KrknAI.HealthCheck: "console=https://console.example.com",
+		config.KrknAI.Scenarios:   "node_io_hog",
+	})
+	assert.ErrorContains(t, (&KrknAI{}).GenerateKrknConfig(output), "no scenarios are enabled")
+}
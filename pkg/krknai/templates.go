@@ -0,0 +1,194 @@
+package krknai
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+)
+
+// templateLookupTimeout bounds looking up a cluster's template variables.
+const templateLookupTimeout = time.Minute
+
+// templateVars are the cluster metadata krkn-ai parameters and krkn-ai.yaml values can
+// reference, e.g. https://console-openshift-console.{{.AppsDomain}}, so one parameter set
+// works across clusters with different domains.
+type templateVars struct {
+	ClusterID      string // The cluster provider's ID, or the cluster's own when the provider's isn't known
+	ClusterVersion string
+	BaseDomain     string // e.g. mycluster.abcd.p1.openshiftapps.com
+	AppsDomain     string // The default ingress domain, e.g. apps.mycluster.abcd.p1.openshiftapps.com
+	ConsoleURL     string
+	APIURL         string
+}
+
+// placeholderTemplates stand in for a cluster's variables where parameters are only
+// validated, so templated URLs and values still parse.
+var placeholderTemplates = &templateResolver{vars: &templateVars{
+	ClusterID:      "cluster-id",
+	ClusterVersion: "4.0.0",
+	BaseDomain:     "cluster.example.com",
+	AppsDomain:     "apps.cluster.example.com",
+	ConsoleURL:     "https://console-openshift-console.apps.cluster.example.com",
+	APIURL:         "https://api.cluster.example.com:6443",
+}}
+
+// templateResolver expands templates, looking the cluster's variables up the first time a
+// value needs them so runs without templates never query the cluster for them.
+type templateResolver struct {
+	lookup func(ctx context.Context) (templateVars, error)
+
+	mu   sync.Mutex
+	vars *templateVars
+}
+
+// clusterTemplates returns a resolver for the cluster behind kubeconfigPath. An empty
+// clusterID falls back to the cluster's own ID.
+func clusterTemplates(kubeconfigPath, clusterID string) *templateResolver {
+	return &templateResolver{lookup: func(ctx context.Context) (templateVars, error) {
+		ctx, cancel := context.WithTimeout(ctx, templateLookupTimeout)
+		defer cancel()
+		restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		if err != nil {
+			return templateVars{}, fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		configClient, err := configclient.NewForConfig(restConfig)
+		if err != nil {
+			return templateVars{}, fmt.Errorf("failed to create config client: %w", err)
+		}
+		return lookupTemplateVars(ctx, configClient, clusterID, restConfig.Host)
+	}}
+}
+
+// lookupTemplateVars reads the cluster's DNS, ingress, console, and version configs.
+func lookupTemplateVars(ctx context.Context, configClient configclient.ConfigV1Interface, clusterID, apiURL string) (templateVars, error) {
+	vars := templateVars{ClusterID: clusterID, APIURL: apiURL}
+	dns, err := configClient.DNSes().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return vars, fmt.Errorf("failed to get the cluster DNS config: %w", err)
+	}
+	vars.BaseDomain = dns.Spec.BaseDomain
+	ingress, err := configClient.Ingresses().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return vars, fmt.Errorf("failed to get the cluster ingress config: %w", err)
+	}
+	vars.AppsDomain = ingress.Spec.Domain
+	console, err := configClient.Consoles().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return vars, fmt.Errorf("failed to get the cluster console config: %w", err)
+	}
+	vars.ConsoleURL = console.Status.ConsoleURL
+	version, err := configClient.ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return vars, fmt.Errorf("failed to get the cluster version: %w", err)
+	}
+	vars.ClusterVersion = version.Status.Desired.Version
+	if vars.ClusterID == "" {
+		vars.ClusterID = string(version.Spec.ClusterID)
+	}
+	return vars, nil
+}
+
+// expand resolves the templates in s; strings without any are returned as is.
+func (r *templateResolver) expand(ctx context.Context, s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	r.mu.Lock()
+	if r.vars == nil {
+		vars, err := r.lookup(ctx)
+		if err != nil {
+			r.mu.Unlock()
+			return "", fmt.Errorf("failed to look up the cluster's template variables: %w", err)
+		}
+		r.vars = &vars
+	}
+	vars := *r.vars
+	r.mu.Unlock()
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// param returns the krkn-ai parameter key with its templates resolved.
+func (r *templateResolver) param(ctx context.Context, key string) (string, error) {
+	value, err := r.expand(ctx, viper.GetString(key))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", strings.TrimPrefix(key, krknAIKeyPrefix), err)
+	}
+	return value, nil
+}
+
+// expandConfig resolves the templates in every string value of a krkn-ai config section,
+// in place.
+func (r *templateResolver) expandConfig(ctx context.Context, cfg map[string]interface{}) error {
+	_, err := r.expandValue(ctx, "", cfg)
+	return err
+}
+
+func (r *templateResolver) expandValue(ctx context.Context, path string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		expanded, err := r.expand(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("krkn-ai config %s: %w", path, err)
+		}
+		return expanded, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			expanded, err := r.expandValue(ctx, fieldPath, v[key])
+			if err != nil {
+				return nil, err
+			}
+			v[key] = expanded
+		}
+	case []interface{}:
+		for i, item := range v {
+			expanded, err := r.expandValue(ctx, fmt.Sprintf("%s[%d]", path, i), item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+	}
+	return value, nil
+}
+
+// templates returns the resolver for the run's cluster, created on first use.
+func (k *KrknAI) templates() *templateResolver {
+	if k.templateResolver == nil {
+		var clusterID string
+		if k.result != nil {
+			clusterID = k.result.ClusterID
+		}
+		k.templateResolver = clusterTemplates(filepath.Join(k.sharedDir(), kubeconfigFileName), clusterID)
+	}
+	return k.templateResolver
+}
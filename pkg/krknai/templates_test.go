@@ -0,0 +1,151 @@
+package krknai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	viper "github.com/openshift/osde2e/pkg/common/concurrentviper"
+	"github.com/openshift/osde2e/pkg/common/config"
+)
+
+var testTemplateVars = templateVars{
+	ClusterID:      "abc123",
+	ClusterVersion: "4.16.3",
+	BaseDomain:     "mycluster.x1y2.p1.openshiftapps.com",
+	AppsDomain:     "apps.mycluster.x1y2.p1.openshiftapps.com",
+	ConsoleURL:     "https://console-openshift-console.apps.mycluster.x1y2.p1.openshiftapps.com",
+	APIURL:         "https://api.mycluster.x1y2.p1.openshiftapps.com:6443",
+}
+
+func TestTemplateResolverExpand(t *testing.T) {
+	lookups := 0
+	resolver := &templateResolver{lookup: func(context.Context) (templateVars, error) {
+		lookups++
+		return testTemplateVars, nil
+	}}
+
+	got, err := resolver.expand(context.Background(), "sum(up)")
+	require.NoError(t, err)
+	assert.Equal(t, "sum(up)", got)
+	assert.Zero(t, lookups, "values without templates don't look the cluster up")
+
+	got, err = resolver.expand(context.Background(), "https://console-openshift-console.{{.AppsDomain}}/healthz")
+	require.NoError(t, err)
+	assert.Equal(t, "https://console-openshift-console.apps.mycluster.x1y2.p1.openshiftapps.com/healthz", got)
+	got, err = resolver.expand(context.Background(), `{cluster="{{.ClusterID}}"} on {{.ClusterVersion}}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{cluster="abc123"} on 4.16.3`, got)
+	assert.Equal(t, 1, lookups, "the cluster's variables are looked up once")
+
+	for name, input := range map[string]string{
+		"unknown variable": "{{.Domain}}",
+		"unclosed action":  "{{.BaseDomain",
+	} {
+		_, err := resolver.expand(context.Background(), input)
+		assert.ErrorContains(t, err, "invalid template", name)
+	}
+
+	failing := &templateResolver{lookup: func(context.Context) (templateVars, error) {
+		return templateVars{}, errors.New("cluster unreachable")
+	}}
+	_, err = failing.expand(context.Background(), "{{.BaseDomain}}")
+	assert.ErrorContains(t, err, "failed to look up the cluster's template variables: cluster unreachable")
+}
+
+func TestTemplateResolverParam(t *testing.T) {
+	setupKrknConfig(t, map[string]any{config.KrknAI.FitnessQuery: `sum(rate(apiserver_request_total{cluster="{{.ClusterID}}"}[5m]))`})
+	resolver := &templateResolver{vars: &testTemplateVars}
+
+	got, err := resolver.param(context.Background(), config.KrknAI.FitnessQuery)
+	require.NoError(t, err)
+	assert.Equal(t, `sum(rate(apiserver_request_total{cluster="abc123"}[5m]))`, got)
+
+	viper.Set(config.KrknAI.FitnessQuery, "{{.Nope}}")
+	_, err = resolver.param(context.Background(), config.KrknAI.FitnessQuery)
+	assert.ErrorContains(t, err, "fitnessQuery: invalid template")
+}
+
+func TestTemplateResolverExpandConfig(t *testing.T) {
+	resolver := &templateResolver{vars: &testTemplateVars}
+	cfg := map[string]interface{}{
+		"generations": 5,
+		"health_checks": map[string]interface{}{
+			"applications": []interface{}{
+				map[string]interface{}{"name": "console", "url": "{{.ConsoleURL}}"},
+				map[string]interface{}{"name": "api", "url": "{{.APIURL}}/readyz"},
+			},
+		},
+	}
+	require.NoError(t, resolver.expandConfig(context.Background(), cfg))
+	assert.Equal(t, 5, cfg["generations"])
+	apps := cfg["health_checks"].(map[string]interface{})["applications"].([]interface{})
+	assert.Equal(t, testTemplateVars.ConsoleURL, apps[0].(map[string]interface{})["url"])
+	assert.Equal(t, testTemplateVars.APIURL+"/readyz", apps[1].(map[string]interface{})["url"])
+
+	err := resolver.expandConfig(context.Background(), map[string]interface{}{
+		"health_checks": map[string]interface{}{"applications": []interface{}{map[string]interface{}{"url": "{{.Console}}"}}},
+	})
+	assert.ErrorContains(t, err, "krkn-ai config health_checks.applications[0].url: invalid template")
+}
+
+func TestLookupTemplateVars(t *testing.T) {
+	clusterVersion := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "5d8e0c1b-uuid"},
+		Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.16.3"}},
+	}
+	configClient := configfake.NewSimpleClientset(
+		&configv1.DNS{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Spec: configv1.DNSSpec{BaseDomain: testTemplateVars.BaseDomain}},
+		&configv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Spec: configv1.IngressSpec{Domain: testTemplateVars.AppsDomain}},
+		&configv1.Console{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Status: configv1.ConsoleStatus{ConsoleURL: testTemplateVars.ConsoleURL}},
+		clusterVersion,
+	).ConfigV1()
+
+	vars, err := lookupTemplateVars(context.Background(), configClient, "abc123", testTemplateVars.APIURL)
+	require.NoError(t, err)
+	assert.Equal(t, testTemplateVars, vars)
+
+	vars, err = lookupTemplateVars(context.Background(), configClient, "", testTemplateVars.APIURL)
+	require.NoError(t, err)
+	assert.Equal(t, "5d8e0c1b-uuid", vars.ClusterID, "the cluster's own ID is used without a provider ID")
+
+	_, err = lookupTemplateVars(context.Background(), configfake.NewSimpleClientset().ConfigV1(), "", "")
+	assert.ErrorContains(t, err, "failed to get the cluster DNS config")
+}
+
+func TestValidateParams_Templates(t *testing.T) {
+	setupKrknConfig(t, map[string]any{
+		config.KrknAI.HealthCheck: "console=https://console-openshift-console.{{.AppsDomain}}",
+		config.KrknAI.Overrides:   "scenario.node_cpu_hog.namespace={{.ClusterID}}",
+	})
+	require.NoError(t, validateParams(), "templated values are validated against placeholder variables")
+
+	viper.Set(config.KrknAI.AbortQuery, "up{cluster=\"{{.Cluster}}\"} == 0")
+	assert.ErrorContains(t, validateParams(), "abortQuery: invalid template")
+}
+
+func TestUpdateKrknConfig_Templates(t *testing.T) {
+	overlayFile := filepath.Join(t.TempDir(), "overlay.yaml")
+	require.NoError(t, os.WriteFile(overlayFile, []byte("output:\n  result_name_fmt: \"{{.ClusterID}}-%s\"\n"), 0o644))
+	yamlFile := setupKrknConfig(t, map[string]any{
+		config.KrknAI.OverlayPath:  overlayFile,
+		config.KrknAI.Overrides:    "scenario.node_cpu_hog.namespace=ns-{{.ClusterID}}",
+		config.KrknAI.FitnessQuery: `sum(up{cluster="{{.ClusterID}}"})`,
+	})
+	k := &KrknAI{templateResolver: &templateResolver{vars: &testTemplateVars}}
+	require.NoError(t, k.updateKrknConfig(context.Background()))
+
+	cfg := readKrknConfig(t, yamlFile)
+	assert.Equal(t, "abc123-%s", cfg["output"].(map[string]interface{})["result_name_fmt"])
+	assert.Equal(t, "ns-abc123", cfg["scenario"].(map[string]interface{})["node_cpu_hog"].(map[string]interface{})["namespace"])
+	assert.Equal(t, `sum(up{cluster="abc123"})`, cfg["fitness_function"].(map[string]interface{})["query"])
+}